@@ -0,0 +1,44 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 物件不存在
+var ErrNotFound = errors.New("blobstore: object not found")
+
+// Object 從儲存空間讀取的物件
+type Object struct {
+	Data         []byte
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// Store 二進位物件 (例如停車影像) 的儲存空間，物件以 key 識別
+// key 由 "/" 分隔的路徑組成，例如 "parking-records/2026/10/17/3f9a.jpg"
+type Store interface {
+	// Put 寫入物件，相同 key 的物件會被取代
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get 讀取物件，物件不存在時回傳 ErrNotFound
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete 刪除物件，物件不存在時不視為錯誤
+	Delete(ctx context.Context, key string) error
+}
+
+// validateKey 檢查 key 是否為不會跳出儲存空間的相對路徑
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("blobstore: invalid key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("blobstore: invalid key %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testBucket    = "parking-images"
+	testAccessKey = "test-access-key"
+	testSecretKey = "test-secret-key"
+	testRegion    = "ap-northeast-1"
+)
+
+// fakeS3 以記憶體模擬 S3 的 PUT、GET 與 DELETE Object，並以相同的密鑰驗證 SigV4 簽章
+// 簽章以實際送達的路徑 (RequestURI) 重新計算，客戶端簽章的路徑與送出的路徑不一致時回傳 403
+type fakeS3 struct {
+	pathStyle bool
+
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	paths   []string
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, r.Host+" "+r.RequestURI)
+
+	body, _ := io.ReadAll(r.Body)
+	if err := f.verifySignature(r, body); err != nil {
+		http.Error(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>"+err.Error()+"</Message></Error>", http.StatusForbidden)
+		return
+	}
+
+	var key string
+	if f.pathStyle {
+		prefix := "/" + testBucket + "/"
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+			return
+		}
+		key = strings.TrimPrefix(r.URL.Path, prefix)
+	} else {
+		if !strings.HasPrefix(r.Host, testBucket+".") {
+			http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+			return
+		}
+		key = strings.TrimPrefix(r.URL.Path, "/")
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeObject{data: body, contentType: r.Header.Get("Content-Type")}
+		w.Header().Set("ETag", `"`+sha256Hex(body)[:32]+`"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		object, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("ETag", `"`+sha256Hex(object.data)[:32]+`"`)
+		w.Header().Set("Last-Modified", time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+		w.Write(object.data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+var authorizationPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([^,]+), Signature=([0-9a-f]{64})$`)
+
+// verifySignature 依 SigV4 以送達的請求重新計算簽章並與 Authorization 標頭比對
+func (f *fakeS3) verifySignature(r *http.Request, body []byte) error {
+	m := authorizationPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return errors.New("malformed Authorization header")
+	}
+	accessKey, date, region, signedHeaders, signature := m[1], m[2], m[3], m[4], m[5]
+	if accessKey != testAccessKey || region != testRegion {
+		return errors.New("unexpected credential scope")
+	}
+	if payloadHash := r.Header.Get("X-Amz-Content-Sha256"); payloadHash != sha256Hex(body) {
+		return errors.New("payload hash mismatch")
+	}
+
+	path, query, _ := strings.Cut(r.RequestURI, "?")
+	names := strings.Split(signedHeaders, ";")
+	if !sort.StringsAreSorted(names) {
+		return errors.New("signed headers are not sorted")
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{r.Method, path, query, canonicalHeaders.String(), signedHeaders, sha256Hex(body)}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", r.Header.Get("X-Amz-Date"), scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range []string{date, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	if expected := hex.EncodeToString(mac.Sum(nil)); !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch for path " + path)
+	}
+	return nil
+}
+
+// newTestS3Store 啟動模擬的 S3 並建立連線到它的 S3Store
+// 虛擬主機模式的 {bucket}.{host} 無法解析，所有連線都導向模擬伺服器
+func newTestS3Store(t *testing.T, pathStyle bool) (*S3Store, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{pathStyle: pathStyle, objects: map[string]fakeObject{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:        server.URL,
+		Region:          testRegion,
+		Bucket:          testBucket,
+		AccessKeyID:     testAccessKey,
+		SecretAccessKey: testSecretKey,
+		UsePathStyle:    pathStyle,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store returned error: %v", err)
+	}
+	serverAddr := server.Listener.Addr().String()
+	store.httpClient.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, serverAddr)
+		},
+	}
+	return store, fake
+}
+
+func TestS3StoreRoundTrip(t *testing.T) {
+	for _, pathStyle := range []bool{true, false} {
+		store, fake := newTestS3Store(t, pathStyle)
+		ctx := context.Background()
+		key := "parking-records/2026/10/17/ABC-1234 entry+1.jpg"
+		data := []byte("image bytes")
+
+		if err := store.Put(ctx, key, data, "image/jpeg"); err != nil {
+			t.Fatalf("pathStyle=%v: Put returned error: %v", pathStyle, err)
+		}
+		object, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("pathStyle=%v: Get returned error: %v", pathStyle, err)
+		}
+		if string(object.Data) != string(data) || object.ContentType != "image/jpeg" || object.ETag == "" || object.LastModified.IsZero() {
+			t.Errorf("pathStyle=%v: Get = %+v, want the stored image", pathStyle, object)
+		}
+
+		if err := store.Delete(ctx, key); err != nil {
+			t.Fatalf("pathStyle=%v: Delete returned error: %v", pathStyle, err)
+		}
+		if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("pathStyle=%v: Get after Delete returned %v, want ErrNotFound", pathStyle, err)
+		}
+		// S3 刪除不存在的物件也回傳成功
+		if err := store.Delete(ctx, key); err != nil {
+			t.Errorf("pathStyle=%v: Delete of a missing object returned error: %v", pathStyle, err)
+		}
+
+		wantPath := "/parking-records/2026/10/17/ABC-1234%20entry%2B1.jpg"
+		if pathStyle {
+			wantPath = "/" + testBucket + wantPath
+		}
+		for _, requested := range fake.paths {
+			host, path, _ := strings.Cut(requested, " ")
+			if path != wantPath {
+				t.Errorf("pathStyle=%v: request path = %s, want %s", pathStyle, path, wantPath)
+			}
+			if hasBucketHost := strings.HasPrefix(host, testBucket+"."); hasBucketHost == pathStyle {
+				t.Errorf("pathStyle=%v: request host = %s", pathStyle, host)
+			}
+		}
+	}
+}
+
+func TestS3StoreErrors(t *testing.T) {
+	store, _ := newTestS3Store(t, true)
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "parking-records/missing.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing object returned %v, want ErrNotFound", err)
+	}
+	if err := store.Put(ctx, "../escape.jpg", []byte("x"), "image/jpeg"); err == nil {
+		t.Errorf("Put accepted a key outside the bucket")
+	}
+
+	store.config.SecretAccessKey = "wrong-secret"
+	if err := store.Put(ctx, "parking-records/a.jpg", []byte("x"), "image/jpeg"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put with a wrong secret returned %v, want a 403 error", err)
+	}
+}
+
+func TestS3StoreTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          testBucket,
+		AccessKeyID:     testAccessKey,
+		SecretAccessKey: testSecretKey,
+		UsePathStyle:    true,
+		Timeout:         50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store returned error: %v", err)
+	}
+	started := time.Now()
+	if err := store.Put(context.Background(), "parking-records/a.jpg", []byte("x"), "image/jpeg"); err == nil {
+		t.Errorf("Put to a hanging endpoint returned no error")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Put to a hanging endpoint took %v", elapsed)
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "parking-records/2026/10/17/a.jpg", want: "parking-records/2026/10/17/a.jpg"},
+		{input: "a b/c+d", want: "a%20b/c%2Bd"},
+		{input: "-._~", want: "-._~"},
+		{input: "100%/x=y&z", want: "100%25/x%3Dy%26z"},
+		{input: "車牌.jpg", want: "%E8%BB%8A%E7%89%8C.jpg"},
+	}
+	for _, tt := range tests {
+		if got := escapePath(tt.input); got != tt.want {
+			t.Errorf("escapePath(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
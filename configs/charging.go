@@ -0,0 +1,16 @@
+package configs
+
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
+// 充電結束後仍佔用充電車位的寬限時間，超過後開始計收佔用費
+const ChargingIdleGracePeriod = 15 * time.Minute
+
+var (
+	// EnergyPricePerKWh 每度電 (kWh) 的充電電費
+	EnergyPricePerKWh = models.NewMoney(800, Currency)
+	// IdleFeePerMinute 充電結束超過寬限時間後，每分鐘的充電車位佔用費
+	IdleFeePerMinute = models.MoneyFromMajor(2, Currency)
+)
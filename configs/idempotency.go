@@ -0,0 +1,20 @@
+package configs
+
+import "time"
+
+const (
+	// IdempotencyKeyHeader 客戶端用來辨識重送請求的 HTTP 標頭
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyReplayedHeader 回應為重送結果時附加的 HTTP 標頭
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+	// IdempotencyKeyMaxLength Idempotency-Key 的最大長度
+	IdempotencyKeyMaxLength = 255
+	// IdempotencyKeyTTL 保存第一次回應的時間，超過後相同 key 視為新的請求
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyLeaseDuration 請求處理中的 key 保留給第一次請求的時間，超過後 (例如處理程序當機) 相同 key 的重送可接手處理
+	IdempotencyLeaseDuration = 2 * time.Minute
+	// IdempotencyCleanupInterval 清除過期 Idempotency 記錄的間隔
+	IdempotencyCleanupInterval = time.Hour
+	// IdempotencyMaxBodyBytes 帶有 Idempotency-Key 的請求內容上限，需容納上傳影像與其他表單欄位
+	IdempotencyMaxBodyBytes = MaxImageUploadBytes + 1<<20
+)
@@ -0,0 +1,65 @@
+package configs
+
+import (
+	"fmt"
+	"hello-professor_backend/blobstore"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// 影像儲存空間的種類 (環境變數 IMAGE_STORE)
+	ImageStoreLocal = "local"
+	ImageStoreS3    = "s3"
+
+	// 未設定 IMAGE_STORE_DIR 時本機影像儲存空間的根目錄
+	DefaultImageStoreDir = "data/images"
+	// 進場上傳影像的大小上限
+	MaxImageUploadBytes = 10 << 20
+
+	// 上傳影像的像素數 (寬 x 高) 上限，超過時拒絕上傳，避免解碼時耗盡記憶體
+	MaxImagePixels = 50_000_000
+	// 上傳時產生的縮圖長邊的像素上限與 JPEG 品質
+	ThumbnailMaxSize     = 320
+	ThumbnailJPEGQuality = 80
+	// 影像回應的快取時間，影像上傳後不會變更，過期後以 ETag 重新驗證
+	ImageCacheMaxAge = 24 * time.Hour
+	// S3 影像儲存空間每個請求的時間上限，進場時會等待影像存放完成
+	S3RequestTimeout = 5 * time.Second
+)
+
+// ImageStore 依環境變數建立停車影像的儲存空間
+// IMAGE_STORE=local (預設) 時存放在 IMAGE_STORE_DIR 目錄下；
+// IMAGE_STORE=s3 時存放在 S3 相容的儲存空間，需設定 S3_ENDPOINT、S3_BUCKET、S3_ACCESS_KEY_ID 與 S3_SECRET_ACCESS_KEY，
+// 可選 S3_REGION (預設 us-east-1) 與 S3_FORCE_PATH_STYLE (MinIO 等本機替代服務設為 true)
+func ImageStore() (blobstore.Store, error) {
+	switch backend := strings.ToLower(os.Getenv("IMAGE_STORE")); backend {
+	case "", ImageStoreLocal:
+		dir := os.Getenv("IMAGE_STORE_DIR")
+		if dir == "" {
+			dir = DefaultImageStoreDir
+		}
+		store, err := blobstore.NewLocalStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case ImageStoreS3:
+		store, err := blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			UsePathStyle:    strings.EqualFold(os.Getenv("S3_FORCE_PATH_STYLE"), "true"),
+			Timeout:         S3RequestTimeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown IMAGE_STORE %q: must be %s or %s", backend, ImageStoreLocal, ImageStoreS3)
+	}
+}
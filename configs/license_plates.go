@@ -0,0 +1,25 @@
+package configs
+
+import "time"
+
+const (
+	// 出場車牌找不到完全相符的停車記錄時，列為候選記錄的最低車牌相似度 (pg_trgm，0.0 到 1.0 之間)
+	ExitMatchMinSimilarity = 0.3
+	// 出場模糊比對自動採用候選記錄所需的最低相似度
+	ExitMatchAutoAcceptSimilarity = 0.5
+	// 出場模糊比對自動採用時，最相似的候選記錄需領先第二名的相似度差距
+	ExitMatchAutoAcceptMargin = 0.15
+	// 出場模糊比對最多回傳的候選記錄數
+	ExitMatchMaxCandidates = 5
+)
+
+// OcrReviewConfidenceThreshold 進場 OCR 信心分數低於此門檻 (0.0 到 1.0 之間) 的停車記錄會排入車牌人工審核，未提供信心分數則不審核
+const OcrReviewConfidenceThreshold = 0.8
+
+const (
+	// 車牌與監控名單不完全相符時，視為模糊符合的最低車牌相似度 (pg_trgm，0.0 到 1.0 之間)
+	// 模糊符合只會發出警示，不會拒絕進場
+	WatchlistFuzzyMinSimilarity = 0.6
+	// 監控名單警示串流的心跳間隔，避免閒置的連線被代理伺服器中斷
+	WatchlistAlertStreamHeartbeat = 15 * time.Second
+)
@@ -1,9 +1,15 @@
 package configs
 
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
 const (
-	ParkingLotName     = "教授，你好停車場"
-	ParkingLotAddress  = "高雄市燕巢區深中路58號"
-	ParkingLotCapacity = 100
+	// 預設停車場，執行 scripts/setup.go 時若資料庫中沒有同名的停車場則以此建立，既有的停車記錄皆歸屬於此停車場
+	DefaultParkingLotName     = "教授，你好停車場"
+	DefaultParkingLotAddress  = "高雄市燕巢區深中路58號"
+	DefaultParkingLotCapacity = 100
 
 	// 每單位時間的費用
 	RatePerUnit = 10
@@ -11,4 +17,14 @@ const (
 	UnitDurationHours = 1.0 / 60
 	// 每日最高費用
 	MaxDailyCharge = 500
+
+	// 幣別
+	Currency = models.DefaultCurrency
+	// 費用試算結果的最長有效時間
+	QuoteMaxValidity = 5 * time.Minute
+	// 付款後的出場寬限期，超過後出場需補繳超時費用
+	PaymentExitGracePeriod = 15 * time.Minute
 )
+
+// ParkingLotLocation 停車場所在時區，用於判斷計費的日曆日 (台灣不實施日光節約時間)
+var ParkingLotLocation = time.FixedZone("Asia/Taipei", 8*60*60)
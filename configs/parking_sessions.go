@@ -0,0 +1,31 @@
+package configs
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	// 尚未出場的停車記錄超過此時間視為疑似遺漏出場 (未觸發出口感應器) 的預設值
+	DefaultStaleSessionMaxAge = 72 * time.Hour
+	// 檢查疑似遺漏出場的停車記錄的間隔
+	StaleSessionCheckInterval = 15 * time.Minute
+	// 同一車牌在此時間內再次進場視為入口重複辨識，仍回傳車輛已在場內，不會結束原本的停車記錄
+	DuplicateEntryWindow = time.Minute
+)
+
+// StaleSessionMaxAge 取得尚未出場的停車記錄視為疑似遺漏出場的時間 (環境變數 STALE_SESSION_MAX_AGE，例如 48h)
+// 未設定或格式錯誤時使用 DefaultStaleSessionMaxAge
+func StaleSessionMaxAge() time.Duration {
+	value := os.Getenv("STALE_SESSION_MAX_AGE")
+	if value == "" {
+		return DefaultStaleSessionMaxAge
+	}
+	maxAge, err := time.ParseDuration(value)
+	if err != nil || maxAge <= 0 {
+		log.Printf("Invalid STALE_SESSION_MAX_AGE %q, using %v", value, DefaultStaleSessionMaxAge)
+		return DefaultStaleSessionMaxAge
+	}
+	return maxAge
+}
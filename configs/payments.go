@@ -0,0 +1,58 @@
+package configs
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	// 支付供應商名稱
+	PaymentProviderCash      = "Cash"
+	PaymentProviderSimulator = "Simulator"
+
+	// 付款意圖的有效時間，超過仍未請款則視為過期
+	PaymentIntentTTL = 10 * time.Minute
+	// 檢查過期付款意圖的間隔
+	PaymentIntentExpiryCheckInterval = time.Minute
+	// 支付供應商未確認結果的退款維持 Pending，建立超過此時間後以相同的退款參考編號重送
+	RefundRetryDelay = 2 * time.Minute
+	// 檢查待重送退款的間隔
+	RefundRetryInterval = time.Minute
+
+	// 模擬支付閘道確認付款所需的時間
+	SimulatorConfirmDelay = 2 * time.Second
+	// 模擬支付閘道隨機拒絕付款的機率 (0.0 - 1.0)
+	SimulatorDeclineRate = 0.0
+	// 模擬支付閘道通知送達失敗時的重送次數
+	SimulatorCallbackRetries = 3
+)
+
+// PaymentMethodProviders 付款方式對應的支付供應商
+var PaymentMethodProviders = map[string]string{
+	"Cash":       PaymentProviderCash,
+	"CreditCard": PaymentProviderSimulator,
+	"MobilePay":  PaymentProviderSimulator,
+	"LinePay":    PaymentProviderSimulator,
+	"JKOPay":     PaymentProviderSimulator,
+}
+
+// PaymentWebhookSecret 取得驗證支付供應商通知簽章用的密鑰 (環境變數 PAYMENT_WEBHOOK_SECRET)
+// 未設定時回傳空字串，此時不啟用需要通知的支付供應商；只有明確設定 PAYMENT_DEV_MODE=true 的開發環境才使用內建的開發用密鑰
+func PaymentWebhookSecret() string {
+	if secret := os.Getenv("PAYMENT_WEBHOOK_SECRET"); secret != "" {
+		return secret
+	}
+	if os.Getenv("PAYMENT_DEV_MODE") == "true" {
+		return "local-development-webhook-secret"
+	}
+	return ""
+}
+
+// PaymentCallbackBaseURL 取得支付供應商通知的基礎網址 (環境變數 PAYMENT_CALLBACK_BASE_URL)
+// 實際通知網址為 {base}/{provider}
+func PaymentCallbackBaseURL() string {
+	if baseURL := os.Getenv("PAYMENT_CALLBACK_BASE_URL"); baseURL != "" {
+		return baseURL
+	}
+	return "http://localhost:8080/api/v1/payments/callbacks"
+}
@@ -0,0 +1,191 @@
+package configs
+
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
+// RateBand 一天之中的一個計費時段，時間以當日 00:00 起算的分鐘數表示 (End 不包含)
+type RateBand struct {
+	Name        string
+	StartMinute int
+	EndMinute   int
+	// 此時段每單位時間的費用
+	RatePerUnit models.Money
+	// 此時段每日最高費用，0 表示不設上限
+	MaxCharge models.Money
+}
+
+// RateSchedule 一種日別 (平日、週末、假日) 的費率表
+type RateSchedule struct {
+	Name string
+	// Bands 需依序涵蓋 00:00 至 24:00
+	Bands []RateBand
+	// 每日最高費用，0 表示不設上限
+	MaxDailyCharge models.Money
+}
+
+// RateCalendar 決定每個日曆日要套用哪一種費率表
+type RateCalendar struct {
+	Weekday RateSchedule
+	Weekend RateSchedule
+	Holiday RateSchedule
+	// WeekendDays 視為週末的星期
+	WeekendDays []time.Weekday
+	// Holidays 國定假日，key 為 "2006-01-02" 格式的日期，value 為假日名稱
+	Holidays map[string]string
+}
+
+const (
+	minutesPerDay = 24 * 60
+
+	// 夜間 (20:00 - 08:00) 每單位時間的費用
+	NightRatePerUnit = 5
+	// 夜間每個時段的最高費用
+	NightMaxCharge = 100
+
+	// 週末每單位時間的費用
+	WeekendRatePerUnit = 8
+	// 週末每日最高費用
+	WeekendMaxDailyCharge = 400
+
+	// 國定假日每單位時間的費用
+	HolidayRatePerUnit = 8
+	// 國定假日每日最高費用
+	HolidayMaxDailyCharge = 400
+
+	// 機車全天每單位時間的費用
+	MotorcycleRatePerUnit = 2
+	// 機車每日最高費用
+	MotorcycleMaxDailyCharge = 100
+
+	// 大型車全天每單位時間的費用
+	LargeVehicleRatePerUnit = 20
+	// 大型車每日最高費用
+	LargeVehicleMaxDailyCharge = 1000
+)
+
+// DefaultRateCalendar 預設費率行事曆
+var DefaultRateCalendar = RateCalendar{
+	Weekday: RateSchedule{
+		Name: "Weekday",
+		Bands: []RateBand{
+			{Name: "Night", StartMinute: 0, EndMinute: 8 * 60, RatePerUnit: amount(NightRatePerUnit), MaxCharge: amount(NightMaxCharge)},
+			{Name: "Day", StartMinute: 8 * 60, EndMinute: 20 * 60, RatePerUnit: amount(RatePerUnit)},
+			{Name: "Night", StartMinute: 20 * 60, EndMinute: minutesPerDay, RatePerUnit: amount(NightRatePerUnit), MaxCharge: amount(NightMaxCharge)},
+		},
+		MaxDailyCharge: amount(MaxDailyCharge),
+	},
+	Weekend: RateSchedule{
+		Name: "Weekend",
+		Bands: []RateBand{
+			{Name: "AllDay", StartMinute: 0, EndMinute: minutesPerDay, RatePerUnit: amount(WeekendRatePerUnit)},
+		},
+		MaxDailyCharge: amount(WeekendMaxDailyCharge),
+	},
+	Holiday: RateSchedule{
+		Name: "Holiday",
+		Bands: []RateBand{
+			{Name: "AllDay", StartMinute: 0, EndMinute: minutesPerDay, RatePerUnit: amount(HolidayRatePerUnit)},
+		},
+		MaxDailyCharge: amount(HolidayMaxDailyCharge),
+	},
+	WeekendDays: []time.Weekday{time.Saturday, time.Sunday},
+	Holidays:    NationalHolidays,
+}
+
+// MotorcycleRateCalendar 機車費率行事曆，不分日別
+var MotorcycleRateCalendar = flatRateCalendar(amount(MotorcycleRatePerUnit), amount(MotorcycleMaxDailyCharge))
+
+// LargeVehicleRateCalendar 大型車費率行事曆，不分日別
+var LargeVehicleRateCalendar = flatRateCalendar(amount(LargeVehicleRatePerUnit), amount(LargeVehicleMaxDailyCharge))
+
+const (
+	// DefaultRateCalendarName 預設費率行事曆的名稱，停車場未指定費率時使用
+	DefaultRateCalendarName = "Default"
+	// MotorcycleRateCalendarName 機車費率行事曆的名稱
+	MotorcycleRateCalendarName = "Motorcycle"
+	// LargeVehicleRateCalendarName 大型車費率行事曆的名稱
+	LargeVehicleRateCalendarName = "LargeVehicle"
+)
+
+// RateCalendars 可供停車場選用的費率行事曆，key 為停車場 RateCalendar 欄位的值
+var RateCalendars = map[string]RateCalendar{
+	DefaultRateCalendarName:      DefaultRateCalendar,
+	MotorcycleRateCalendarName:   MotorcycleRateCalendar,
+	LargeVehicleRateCalendarName: LargeVehicleRateCalendar,
+}
+
+// DefaultVehicleClassRateCalendars 停車場開放汽車以外車種但未指定費率時，各車種使用的費率行事曆名稱
+var DefaultVehicleClassRateCalendars = map[string]string{
+	models.VehicleClassMotorcycle: MotorcycleRateCalendarName,
+	models.VehicleClassLarge:      LargeVehicleRateCalendarName,
+}
+
+// NationalHolidays 國定假日 (含補假)，需依行政院人事行政總處公告的行事曆每年更新
+var NationalHolidays = map[string]string{
+	"2025-01-01": "中華民國開國紀念日",
+	"2025-01-25": "春節",
+	"2025-01-26": "春節",
+	"2025-01-27": "春節",
+	"2025-01-28": "農曆除夕",
+	"2025-01-29": "春節",
+	"2025-01-30": "春節",
+	"2025-01-31": "春節",
+	"2025-02-28": "和平紀念日",
+	"2025-04-03": "兒童節補假",
+	"2025-04-04": "兒童節及民族掃墓節",
+	"2025-05-01": "勞動節",
+	"2025-05-30": "端午節補假",
+	"2025-05-31": "端午節",
+	"2025-09-28": "孔子誕辰紀念日",
+	"2025-09-29": "孔子誕辰紀念日補假",
+	"2025-10-06": "中秋節",
+	"2025-10-10": "國慶日",
+	"2025-10-24": "臺灣光復暨金門古寧頭大捷紀念日",
+	"2025-12-25": "行憲紀念日",
+	"2026-01-01": "中華民國開國紀念日",
+	"2026-02-14": "春節",
+	"2026-02-15": "春節",
+	"2026-02-16": "農曆除夕",
+	"2026-02-17": "春節",
+	"2026-02-18": "春節",
+	"2026-02-19": "春節",
+	"2026-02-20": "春節",
+	"2026-02-27": "和平紀念日補假",
+	"2026-02-28": "和平紀念日",
+	"2026-04-03": "兒童節補假",
+	"2026-04-04": "兒童節",
+	"2026-04-05": "民族掃墓節",
+	"2026-04-06": "民族掃墓節補假",
+	"2026-05-01": "勞動節",
+	"2026-06-19": "端午節",
+	"2026-09-25": "中秋節",
+	"2026-09-28": "孔子誕辰紀念日",
+	"2026-10-09": "國慶日補假",
+	"2026-10-10": "國慶日",
+	"2026-10-25": "臺灣光復暨金門古寧頭大捷紀念日",
+	"2026-10-26": "臺灣光復暨金門古寧頭大捷紀念日補假",
+	"2026-12-25": "行憲紀念日",
+}
+
+// flatRateCalendar 建立全天單一費率、不分平日假日的費率行事曆
+func flatRateCalendar(ratePerUnit, maxDailyCharge models.Money) RateCalendar {
+	flat := RateSchedule{
+		Name:           "Flat",
+		Bands:          []RateBand{{Name: "AllDay", StartMinute: 0, EndMinute: minutesPerDay, RatePerUnit: ratePerUnit}},
+		MaxDailyCharge: maxDailyCharge,
+	}
+	return RateCalendar{
+		Weekday:     flat,
+		Weekend:     flat,
+		Holiday:     flat,
+		WeekendDays: []time.Weekday{time.Saturday, time.Sunday},
+		Holidays:    NationalHolidays,
+	}
+}
+
+// amount 將以元為單位的費率設定轉換為 Currency 的金額
+func amount(major int64) models.Money {
+	return models.MoneyFromMajor(major, Currency)
+}
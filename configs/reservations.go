@@ -0,0 +1,20 @@
+package configs
+
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
+const (
+	// 預約車輛可提早進場的時間，預約的車位自此開始保留
+	ReservationEarlyArrival = 15 * time.Minute
+	// 預約開始後仍可進場的寬限時間，超過仍未進場視為未到
+	ReservationNoShowGrace = 30 * time.Minute
+	// 預約時段的最長長度
+	ReservationMaxDuration = 24 * time.Hour
+	// 檢查逾時未到預約的間隔
+	ReservationExpiryCheckInterval = time.Minute
+)
+
+// ReservationDeposit 預約時選擇預付訂金的金額，進場後折抵停車費用
+var ReservationDeposit = models.MoneyFromMajor(100, Currency)
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"hello-professor_backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChargingController 定義電動車充電控制器
+type ChargingController struct {
+	chargingService services.ChargingService
+}
+
+// NewChargingController 建立一個新的 ChargingController 實例
+func NewChargingController(cs services.ChargingService) *ChargingController {
+	return &ChargingController{chargingService: cs}
+}
+
+// StartChargingSessionHandler godoc
+// @Summary Start charging a parked vehicle
+// @Description Called by a charger when it starts charging a vehicle in the lot, identified by parkingRecordId or by the license plate of its active parking record. meterWh is the charger's cumulative energy meter in watt-hours. Energy delivered is billed per kWh, and once charging stops, the time the vehicle keeps occupying the charger past the idle grace period is billed per minute; both appear as separate line items of the parking payment.
+// @Tags Charging
+// @Accept json
+// @Produce json
+// @Param charging body dtos.StartChargingPayload true "Charging Information"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.ChargingSession}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload"
+// @Failure 404 {object} dtos.ErrorResponse "Parking record not found"
+// @Failure 409 {object} dtos.ErrorResponse "Charger busy, vehicle already charging, already paid or already exited"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /charging-sessions [post]
+func (cc *ChargingController) StartChargingSessionHandler(c *gin.Context) {
+	var payload dtos.StartChargingPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	session, err := cc.chargingService.StartChargingSession(payload)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errMsg, "invalid_charging:"):
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		case strings.HasPrefix(errMsg, "charger_busy:"), strings.HasPrefix(errMsg, "charging_in_progress:"), strings.HasPrefix(errMsg, "already_paid:"), strings.HasPrefix(errMsg, "vehicle_exited:"):
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		case strings.Contains(errMsg, "not found"):
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		default:
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to start charging session: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Charging session started successfully.", session)
+}
+
+// RecordMeterReadingHandler godoc
+// @Summary Report a meter reading of an active charging session
+// @Description Called by a charger while charging to report its cumulative energy meter. Readings cannot go backwards. A reading reported after the parking record was prepared for payment clears the prepared fee, so the record must be prepared again.
+// @Tags Charging
+// @Accept json
+// @Produce json
+// @Param id path uint true "Charging Session ID"
+// @Param reading body dtos.MeterReadingPayload true "Meter Reading"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.ChargingSession}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format, payload or meter reading"
+// @Failure 404 {object} dtos.ErrorResponse "Charging session not found"
+// @Failure 409 {object} dtos.ErrorResponse "Charging session already stopped"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /charging-sessions/{id}/meter-readings [post]
+func (cc *ChargingController) RecordMeterReadingHandler(c *gin.Context) {
+	cc.handleMeterReading(c, cc.chargingService.RecordMeterReading, "Meter reading recorded successfully.")
+}
+
+// StopChargingSessionHandler godoc
+// @Summary Stop a charging session
+// @Description Called by a charger when charging ends, with the final meter reading. Idle fees start once the idle grace period after this time has passed and the vehicle is still parked. Charging sessions still active when the vehicle exits are stopped at their last reading.
+// @Tags Charging
+// @Accept json
+// @Produce json
+// @Param id path uint true "Charging Session ID"
+// @Param reading body dtos.MeterReadingPayload true "Final Meter Reading"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.ChargingSession}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format, payload or meter reading"
+// @Failure 404 {object} dtos.ErrorResponse "Charging session not found"
+// @Failure 409 {object} dtos.ErrorResponse "Charging session already stopped"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /charging-sessions/{id}/stop [post]
+func (cc *ChargingController) StopChargingSessionHandler(c *gin.Context) {
+	cc.handleMeterReading(c, cc.chargingService.StopChargingSession, "Charging session stopped successfully.")
+}
+
+// handleMeterReading 解析電錶讀數請求並呼叫 apply，回報讀數與結束充電共用
+func (cc *ChargingController) handleMeterReading(c *gin.Context, apply func(uint, dtos.MeterReadingPayload) (*models.ChargingSession, error), message string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid charging session ID format")
+		return
+	}
+
+	var payload dtos.MeterReadingPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	session, err := apply(uint(id), payload)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errMsg, "invalid_meter_reading:"):
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		case strings.HasPrefix(errMsg, "charging_stopped:"):
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		case strings.Contains(errMsg, "not found"):
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		default:
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to record meter reading: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, message, session)
+}
+
+// GetChargingSessionByIDHandler godoc
+// @Summary Get a charging session by ID
+// @Description Retrieves a charging session with all of its meter readings.
+// @Tags Charging
+// @Produce json
+// @Param id path uint true "Charging Session ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.ChargingSession}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Charging session not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /charging-sessions/{id} [get]
+func (cc *ChargingController) GetChargingSessionByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid charging session ID format")
+		return
+	}
+
+	session, err := cc.chargingService.GetChargingSessionByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get charging session: "+err.Error())
+		return
+	}
+	if session == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Charging session not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Charging session retrieved successfully.", session)
+}
+
+// GetChargingSessionsByParkingRecordIDHandler godoc
+// @Summary List the charging sessions of a parking record
+// @Description Retrieves all charging sessions of a parking record ordered by start time.
+// @Tags Charging
+// @Produce json
+// @Param id path uint true "Parking Record ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.ChargingSession}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-records/{id}/charging-sessions [get]
+func (cc *ChargingController) GetChargingSessionsByParkingRecordIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking record ID format")
+		return
+	}
+
+	sessions, err := cc.chargingService.GetChargingSessionsByParkingRecordID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get charging sessions: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Charging sessions retrieved successfully.", sessions)
+}
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MerchantController 定義商家與停車折抵控制器
+type MerchantController struct {
+	validationService services.ValidationService
+}
+
+// NewMerchantController 建立一個新的 MerchantController 實例
+func NewMerchantController(vs services.ValidationService) *MerchantController {
+	return &MerchantController{validationService: vs}
+}
+
+// CreateMerchantHandler godoc
+// @Summary Register a merchant
+// @Description Registers a shop that can issue parking validation codes to its customers.
+// @Tags Merchants
+// @Accept json
+// @Produce json
+// @Param merchant body dtos.CreateMerchantPayload true "Merchant Information"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.Merchant}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /merchants [post]
+func (mc *MerchantController) CreateMerchantHandler(c *gin.Context) {
+	var payload dtos.CreateMerchantPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	merchant, err := mc.validationService.CreateMerchant(payload)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create merchant: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Merchant created successfully.", merchant)
+}
+
+// GetAllMerchantsHandler godoc
+// @Summary Get all merchants
+// @Description Retrieves all registered merchants.
+// @Tags Merchants
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.Merchant}
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /merchants [get]
+func (mc *MerchantController) GetAllMerchantsHandler(c *gin.Context) {
+	merchants, err := mc.validationService.GetAllMerchants()
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get merchants: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Merchants retrieved successfully.", merchants)
+}
+
+// GetMerchantByIDHandler godoc
+// @Summary Get a merchant by ID
+// @Description Retrieves a single merchant.
+// @Tags Merchants
+// @Produce json
+// @Param id path uint true "Merchant ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.Merchant}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Merchant not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /merchants/{id} [get]
+func (mc *MerchantController) GetMerchantByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid merchant ID format")
+		return
+	}
+
+	merchant, err := mc.validationService.GetMerchantByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get merchant: "+err.Error())
+		return
+	}
+	if merchant == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Merchant not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Merchant retrieved successfully.", merchant)
+}
+
+// CreateValidationCodeHandler godoc
+// @Summary Issue a validation code
+// @Description Issues a parking validation code for a merchant: a fixed amount off, a percentage off, the first N minutes free, or a flat fee. Codes can be limited in number of uses and expire.
+// @Tags Merchants
+// @Accept json
+// @Produce json
+// @Param id path uint true "Merchant ID"
+// @Param validationCode body dtos.CreateValidationCodePayload true "Validation Code Details"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.ValidationCode}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload or discount settings"
+// @Failure 404 {object} dtos.ErrorResponse "Merchant not found"
+// @Failure 409 {object} dtos.ErrorResponse "Code already exists or merchant inactive"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /merchants/{id}/validation-codes [post]
+func (mc *MerchantController) CreateValidationCodeHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid merchant ID format")
+		return
+	}
+
+	var payload dtos.CreateValidationCodePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	code, err := mc.validationService.CreateValidationCode(uint(id), payload)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid_validation_code:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.HasPrefix(errMsg, "validation_code_exists:") || strings.HasPrefix(errMsg, "merchant_inactive:") {
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		} else if strings.Contains(errMsg, "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create validation code: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Validation code created successfully.", code)
+}
+
+// GetValidationCodesByMerchantIDHandler godoc
+// @Summary Get validation codes of a merchant
+// @Description Retrieves all validation codes issued by a merchant, including their usage counts.
+// @Tags Merchants
+// @Produce json
+// @Param id path uint true "Merchant ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.ValidationCode}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /merchants/{id}/validation-codes [get]
+func (mc *MerchantController) GetValidationCodesByMerchantIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid merchant ID format")
+		return
+	}
+
+	codes, err := mc.validationService.GetValidationCodesByMerchantID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get validation codes: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Validation codes retrieved successfully.", codes)
+}
+
+// ApplyValidationHandler godoc
+// @Summary Apply a validation code to a parking record
+// @Description Applies a merchant validation code to an unpaid parking record that is still in the lot. The discount is reflected in the fee quote and the amount due; if the fee was already prepared, prepare-payment must be called again.
+// @Tags Merchants
+// @Accept json
+// @Produce json
+// @Param id path uint true "Parking Record ID"
+// @Param validation body dtos.ApplyValidationPayload true "Validation Code"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.ParkingValidation}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request or inactive code"
+// @Failure 404 {object} dtos.ErrorResponse "Parking record or validation code not found"
+// @Failure 409 {object} dtos.ErrorResponse "Code expired, exhausted, already applied, or record already paid/exited"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-records/{id}/validations [post]
+func (mc *MerchantController) ApplyValidationHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking record ID format")
+		return
+	}
+
+	var payload dtos.ApplyValidationPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	validation, err := mc.validationService.ApplyValidation(uint(id), payload.Code)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid_validation_code:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.HasPrefix(errMsg, "validation_code_expired:") || strings.HasPrefix(errMsg, "validation_code_exhausted:") ||
+			strings.HasPrefix(errMsg, "validation_already_applied:") || strings.HasPrefix(errMsg, "already_paid:") || strings.HasPrefix(errMsg, "vehicle_exited:") {
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		} else if strings.Contains(errMsg, "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to apply validation: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Validation applied successfully.", validation)
+}
+
+// GetValidationsByParkingRecordIDHandler godoc
+// @Summary Get validations applied to a parking record
+// @Description Retrieves the merchant validations applied to a parking record, in the order they were applied.
+// @Tags Merchants
+// @Produce json
+// @Param id path uint true "Parking Record ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.ParkingValidation}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-records/{id}/validations [get]
+func (mc *MerchantController) GetValidationsByParkingRecordIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking record ID format")
+		return
+	}
+
+	validations, err := mc.validationService.GetValidationsByParkingRecordID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get validations: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Validations retrieved successfully.", validations)
+}
+
+// GetMerchantSubsidyReportHandler godoc
+// @Summary Get how much each merchant subsidised
+// @Description Sums, per merchant, the validation discounts granted on paid parking records that entered within the time range, optionally in a single parking lot.
+// @Tags reports
+// @Produce json
+// @Param parkingLotId query int false "Only include this parking lot (all lots when omitted)"
+// @Param startTime query string false "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)"
+// @Param endTime query string false "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.MerchantSubsidyReportResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid time format or parking lot ID"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /reports/merchants/subsidies [get]
+func (mc *MerchantController) GetMerchantSubsidyReportHandler(c *gin.Context) {
+	startTime, endTime, err := parseTimeRangeParameters(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid time format: "+err.Error())
+		return
+	}
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	report, err := mc.validationService.GetMerchantSubsidyReport(parkingLotID, startTime, endTime)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get merchant subsidy report: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Merchant subsidy report retrieved successfully.", report)
+}
@@ -0,0 +1,436 @@
+package controllers
+
+import (
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParkingLotController 定義停車場控制器
+type ParkingLotController struct {
+	parkingLotService services.ParkingLotService
+}
+
+// NewParkingLotController 建立一個新的 ParkingLotController 實例
+func NewParkingLotController(pls services.ParkingLotService) *ParkingLotController {
+	return &ParkingLotController{parkingLotService: pls}
+}
+
+// CreateParkingLotHandler godoc
+// @Summary Create a parking lot
+// @Description Creates a parking lot with its own capacity and rate calendar for cars; vehicleClasses opens it to motorcycles and large vehicles, each with its own capacity and rate calendar. reservedSpots of the car capacity are held for permit holders; other cars are refused once the rest is taken. Entry and exit sensors are registered separately.
+// @Tags ParkingLots
+// @Accept json
+// @Produce json
+// @Param parkingLot body dtos.ParkingLotPayload true "Parking Lot Information"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.ParkingLot}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload or unknown rate calendar"
+// @Failure 409 {object} dtos.ErrorResponse "A parking lot with the same name exists"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots [post]
+func (plc *ParkingLotController) CreateParkingLotHandler(c *gin.Context) {
+	var payload dtos.ParkingLotPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	parkingLot, err := plc.parkingLotService.CreateParkingLot(payload)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid_parking_lot:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.HasPrefix(errMsg, "parking_lot_exists:") {
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create parking lot: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Parking lot created successfully.", parkingLot)
+}
+
+// GetAllParkingLotsHandler godoc
+// @Summary Get all parking lots
+// @Description Retrieves all parking lots with their sensors.
+// @Tags ParkingLots
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.ParkingLot}
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots [get]
+func (plc *ParkingLotController) GetAllParkingLotsHandler(c *gin.Context) {
+	parkingLots, err := plc.parkingLotService.GetAllParkingLots()
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get parking lots: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Parking lots retrieved successfully.", parkingLots)
+}
+
+// GetParkingLotByIDHandler godoc
+// @Summary Get a parking lot by ID
+// @Description Retrieves a single parking lot with its sensors.
+// @Tags ParkingLots
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.ParkingLot}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id} [get]
+func (plc *ParkingLotController) GetParkingLotByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking lot ID format")
+		return
+	}
+
+	parkingLot, err := plc.parkingLotService.GetParkingLotByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get parking lot: "+err.Error())
+		return
+	}
+	if parkingLot == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Parking lot not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Parking lot retrieved successfully.", parkingLot)
+}
+
+// UpdateParkingLotHandler godoc
+// @Summary Update a parking lot
+// @Description Updates a parking lot's name, address, capacity, reserved spots, rate calendar, vehicle classes and whether it accepts vehicles. A given vehicleClasses list replaces the current one. A new rate calendar applies to every fee calculated afterwards, including vehicles already parked.
+// @Tags ParkingLots
+// @Accept json
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Param parkingLot body dtos.ParkingLotPayload true "Parking Lot Information"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.ParkingLot}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload or unknown rate calendar"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot not found"
+// @Failure 409 {object} dtos.ErrorResponse "A parking lot with the same name exists"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id} [put]
+func (plc *ParkingLotController) UpdateParkingLotHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking lot ID format")
+		return
+	}
+
+	var payload dtos.ParkingLotPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	parkingLot, err := plc.parkingLotService.UpdateParkingLot(uint(id), payload)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid_parking_lot:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.HasPrefix(errMsg, "parking_lot_exists:") {
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		} else if strings.Contains(errMsg, "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update parking lot: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Parking lot updated successfully.", parkingLot)
+}
+
+// AddParkingLotSensorHandler godoc
+// @Summary Register a sensor of a parking lot
+// @Description Registers an entry or exit sensor. Entry and exit requests that carry the sensorId are attributed to the sensor's parking lot. An entry sensor with a vehicleClass, such as on a motorcycle lane, gives that class to vehicles entering without one.
+// @Tags ParkingLots
+// @Accept json
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Param sensor body dtos.ParkingLotSensorPayload true "Sensor Information"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.ParkingLotSensor}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot not found"
+// @Failure 409 {object} dtos.ErrorResponse "Sensor already registered"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id}/sensors [post]
+func (plc *ParkingLotController) AddParkingLotSensorHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking lot ID format")
+		return
+	}
+
+	var payload dtos.ParkingLotSensorPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	sensor, err := plc.parkingLotService.AddParkingLotSensor(uint(id), payload)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid_sensor:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.HasPrefix(errMsg, "sensor_exists:") {
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		} else if strings.Contains(errMsg, "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to register sensor: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Sensor registered successfully.", sensor)
+}
+
+// RemoveParkingLotSensorHandler godoc
+// @Summary Remove a sensor of a parking lot
+// @Description Removes a registered entry or exit sensor from a parking lot.
+// @Tags ParkingLots
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Param sensorId path string true "Sensor ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Sensor not found in the parking lot"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id}/sensors/{sensorId} [delete]
+func (plc *ParkingLotController) RemoveParkingLotSensorHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking lot ID format")
+		return
+	}
+
+	if err := plc.parkingLotService.RemoveParkingLotSensor(uint(id), c.Param("sensorId")); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to remove sensor: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponse(c, http.StatusOK, "Sensor removed successfully")
+}
+
+// CreateZoneHandler godoc
+// @Summary Create a zone of a parking lot
+// @Description Creates a zone such as a floor or a motorcycle area. Spots with their spot sensors are added to the zone separately.
+// @Tags ParkingLots
+// @Accept json
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Param zone body dtos.ZonePayload true "Zone Information"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.Zone}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot not found"
+// @Failure 409 {object} dtos.ErrorResponse "A zone with the same name exists in the parking lot"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id}/zones [post]
+func (plc *ParkingLotController) CreateZoneHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking lot ID format")
+		return
+	}
+
+	var payload dtos.ZonePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	zone, err := plc.parkingLotService.CreateZone(uint(id), payload)
+	if err != nil {
+		sendZoneError(c, "Failed to create zone: ", err)
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Zone created successfully.", zone)
+}
+
+// GetZonesHandler godoc
+// @Summary Get the zones of a parking lot
+// @Description Retrieves the zones of a parking lot ordered by level, each with its spots and their latest reported status.
+// @Tags ParkingLots
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.Zone}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id}/zones [get]
+func (plc *ParkingLotController) GetZonesHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking lot ID format")
+		return
+	}
+
+	zones, err := plc.parkingLotService.GetZonesByParkingLotID(uint(id))
+	if err != nil {
+		sendZoneError(c, "Failed to get zones: ", err)
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Zones retrieved successfully.", zones)
+}
+
+// UpdateZoneHandler godoc
+// @Summary Update a zone of a parking lot
+// @Description Updates a zone's name, level and description.
+// @Tags ParkingLots
+// @Accept json
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Param zoneId path uint true "Zone ID"
+// @Param zone body dtos.ZonePayload true "Zone Information"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.Zone}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload"
+// @Failure 404 {object} dtos.ErrorResponse "Zone not found in the parking lot"
+// @Failure 409 {object} dtos.ErrorResponse "A zone with the same name exists in the parking lot"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id}/zones/{zoneId} [put]
+func (plc *ParkingLotController) UpdateZoneHandler(c *gin.Context) {
+	id, zoneID, ok := parseZonePathParameters(c)
+	if !ok {
+		return
+	}
+
+	var payload dtos.ZonePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	zone, err := plc.parkingLotService.UpdateZone(id, zoneID, payload)
+	if err != nil {
+		sendZoneError(c, "Failed to update zone: ", err)
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Zone updated successfully.", zone)
+}
+
+// DeleteZoneHandler godoc
+// @Summary Delete a zone of a parking lot
+// @Description Deletes a zone with all of its spots.
+// @Tags ParkingLots
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Param zoneId path uint true "Zone ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Zone not found in the parking lot"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id}/zones/{zoneId} [delete]
+func (plc *ParkingLotController) DeleteZoneHandler(c *gin.Context) {
+	id, zoneID, ok := parseZonePathParameters(c)
+	if !ok {
+		return
+	}
+
+	if err := plc.parkingLotService.DeleteZone(id, zoneID); err != nil {
+		sendZoneError(c, "Failed to delete zone: ", err)
+		return
+	}
+	dtos.SendSuccessResponse(c, http.StatusOK, "Zone deleted successfully")
+}
+
+// AddSpotHandler godoc
+// @Summary Add a spot to a zone
+// @Description Adds a spot with the spot sensor that reports whether it is occupied. New spots count as free until their sensor reports.
+// @Tags ParkingLots
+// @Accept json
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Param zoneId path uint true "Zone ID"
+// @Param spot body dtos.SpotPayload true "Spot Information"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.Spot}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload"
+// @Failure 404 {object} dtos.ErrorResponse "Zone not found in the parking lot"
+// @Failure 409 {object} dtos.ErrorResponse "Spot code or spot sensor already registered"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id}/zones/{zoneId}/spots [post]
+func (plc *ParkingLotController) AddSpotHandler(c *gin.Context) {
+	id, zoneID, ok := parseZonePathParameters(c)
+	if !ok {
+		return
+	}
+
+	var payload dtos.SpotPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	spot, err := plc.parkingLotService.AddSpot(id, zoneID, payload)
+	if err != nil {
+		sendZoneError(c, "Failed to add spot: ", err)
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Spot added successfully.", spot)
+}
+
+// RemoveSpotHandler godoc
+// @Summary Remove a spot from a zone
+// @Description Removes a spot and its spot sensor from a zone.
+// @Tags ParkingLots
+// @Produce json
+// @Param id path uint true "Parking Lot ID"
+// @Param zoneId path uint true "Zone ID"
+// @Param spotId path uint true "Spot ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Spot not found in the zone"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-lots/{id}/zones/{zoneId}/spots/{spotId} [delete]
+func (plc *ParkingLotController) RemoveSpotHandler(c *gin.Context) {
+	id, zoneID, ok := parseZonePathParameters(c)
+	if !ok {
+		return
+	}
+	spotID, err := strconv.ParseUint(c.Param("spotId"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid spot ID format")
+		return
+	}
+
+	if err := plc.parkingLotService.RemoveSpot(id, zoneID, uint(spotID)); err != nil {
+		sendZoneError(c, "Failed to remove spot: ", err)
+		return
+	}
+	dtos.SendSuccessResponse(c, http.StatusOK, "Spot removed successfully")
+}
+
+// parseZonePathParameters 解析路徑中的停車場 ID 與分區 ID，格式錯誤時回傳 400 並回傳 ok 為 false
+func parseZonePathParameters(c *gin.Context) (parkingLotID uint, zoneID uint, ok bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking lot ID format")
+		return 0, 0, false
+	}
+	zone, err := strconv.ParseUint(c.Param("zoneId"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid zone ID format")
+		return 0, 0, false
+	}
+	return uint(id), uint(zone), true
+}
+
+// sendZoneError 將分區與車位服務的錯誤對應到 HTTP 狀態碼
+func sendZoneError(c *gin.Context, failureMessage string, err error) {
+	errMsg := err.Error()
+	if strings.HasPrefix(errMsg, "invalid_zone:") || strings.HasPrefix(errMsg, "invalid_spot:") {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+	} else if strings.HasPrefix(errMsg, "zone_exists:") || strings.HasPrefix(errMsg, "spot_exists:") || strings.HasPrefix(errMsg, "sensor_exists:") {
+		dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+	} else if strings.Contains(errMsg, "not found") {
+		dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+	} else {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, failureMessage+errMsg)
+	}
+}
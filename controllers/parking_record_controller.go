@@ -1,11 +1,13 @@
 package controllers
 
 import (
-	"encoding/base64"
+	"bytes"
+	"fmt"
+	"hello-professor_backend/configs"
 	"hello-professor_backend/dtos"
 	"hello-professor_backend/models"
 	"hello-professor_backend/services"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -81,6 +83,89 @@ func (prc *ParkingRecordController) GetParkingRecordByIDHandler(c *gin.Context)
 	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Parking record retrieved successfully.", record)
 }
 
+// GetParkingRecordImageHandler godoc
+// @Summary Get the entry image of a parking record
+// @Description Streams the entry image of a parking record: the original upload, or a JPEG thumbnail generated once at upload (at most 320 pixels on the long side). Images whose format cannot be thumbnailed (WebP) return the original for the thumbnail. Parking records link here through imageUrl and thumbnailUrl. The response carries Content-Type, ETag, Last-Modified and Cache-Control; a request with a matching If-None-Match returns 304, and an Accept header that does not allow the image's type returns 406.
+// @Tags parking_records
+// @Produce image/jpeg,image/png,image/gif,image/webp
+// @Param id path int true "Parking Record ID"
+// @Param kind path string true "original or thumbnail"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {file} binary "Image"
+// @Success 304 "Cached copy is current"
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format or image kind"
+// @Failure 404 {object} dtos.ErrorResponse "Parking record or image not found"
+// @Failure 406 {object} dtos.ErrorResponse "Image type not acceptable"
+// @Failure 500 {object} dtos.ErrorResponse
+// @Router /parking-records/{id}/images/{kind} [get]
+func (prc *ParkingRecordController) GetParkingRecordImageHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking record ID format")
+		return
+	}
+
+	image, err := prc.parkingRecordService.GetParkingRecordImage(uint(id), c.Param("kind"))
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errMsg, "invalid_image_kind:"):
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		case strings.Contains(errMsg, "not found"):
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		default:
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get parking record image: "+errMsg)
+		}
+		return
+	}
+	if !acceptsContentType(c.GetHeader("Accept"), image.ContentType) {
+		dtos.SendErrorResponse(c, http.StatusNotAcceptable, fmt.Sprintf("Image is %s, which the Accept header does not allow", image.ContentType))
+		return
+	}
+
+	// 影像上傳後不會變更，快取過期後以 ETag 重新驗證；ServeContent 會處理 If-None-Match 與 Range
+	c.Header("Content-Type", image.ContentType)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(configs.ImageCacheMaxAge.Seconds())))
+	if image.ETag != "" {
+		c.Header("ETag", image.ETag)
+	}
+	http.ServeContent(c.Writer, c.Request, "", image.LastModified, bytes.NewReader(image.Data))
+}
+
+// acceptsContentType 判斷 Accept 標頭是否允許 contentType，未提供 Accept 時視為允許
+// 支援 type/subtype、type/* 與 */*，以最明確的項目為準，q=0 的項目視為不允許
+func acceptsContentType(accept string, contentType string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+	mainType, _, _ := strings.Cut(contentType, "/")
+	bestSpecificity, accepted := 0, false
+	for _, mediaRange := range strings.Split(accept, ",") {
+		parts := strings.Split(mediaRange, ";")
+		specificity := 0
+		switch strings.ToLower(strings.TrimSpace(parts[0])) {
+		case contentType:
+			specificity = 3
+		case mainType + "/*":
+			specificity = 2
+		case "*/*":
+			specificity = 1
+		}
+		if specificity <= bestSpecificity {
+			continue
+		}
+		bestSpecificity, accepted = specificity, true
+		for _, param := range parts[1:] {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && key == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+					accepted = false
+				}
+			}
+		}
+	}
+	return accepted
+}
+
 // GetParkingRecordsByLicensePlateHandler godoc
 // @Summary Get parking records by License Plate
 // @Description Get all parking records associated with a specific License Plate
@@ -108,7 +193,7 @@ func (prc *ParkingRecordController) GetParkingRecordsByLicensePlateHandler(c *gi
 
 // SearchParkingRecordsByLicensePlateHandler godoc
 // @Summary Search parking records by License Plate (fuzzy search)
-// @Description Search all parking records by a partial or full License Plate (case-insensitive)
+// @Description Search all parking records by a partial or full License Plate, ignoring case and separators and treating O/0, I/1 and B/8 as the same character
 // @Tags parking_records
 // @Produce json
 // @Param q query string true "License Plate Query"
@@ -255,16 +340,29 @@ func (prc *ParkingRecordController) GetAllParkingRecordsHandler(c *gin.Context)
 
 // RecordVehicleEntryHandler godoc
 // @Summary Record a vehicle entry event
-// @Description Records when a vehicle enters the parking lot, accepting license plate and an optional image file.
+// @Description Records a vehicle entering a parking lot, with an optional image of the vehicle. The license plate is stored in the canonical Taiwanese format, and the lot is chosen by parkingLotId or a registered entry sensorId. Entry is refused when the lot is full for the vehicle's class unless an operator overrides capacity. A plate that still has an open record has it closed as Abandoned, and watchlist matches raise alerts or deny entry.
 // @Tags parking_records
 // @Accept multipart/form-data
 // @Produce json
 // @Param licensePlate formData string true "Vehicle License Plate" example:"ABC-1234"
-// @Param image formData file false "Optional image of the vehicle/license plate"
+// @Param parkingLotId formData int false "Parking Lot ID"
+// @Param sensorId formData string false "Registered entry sensor ID"
+// @Param vehicleClass formData string false "Vehicle class: Car, Motorcycle or Large (defaults to the entry sensor's class, then Car)"
+// @Param overrideCapacity formData bool false "Admit the vehicle even if the lot is full (operator override)"
+// @Param overrideBy formData string false "Operator overriding capacity (required with overrideCapacity)"
+// @Param overrideReason formData string false "Why capacity is overridden (required with overrideCapacity)"
+// @Param ocrConfidence formData number false "OCR confidence of the license plate read, from 0.0 to 1.0; reads below the threshold are queued for manual review"
+// @Param ocrAlternatives formData []string false "Other plates the OCR considered, most likely first" collectionFormat(multi)
+// @Param image formData file false "Optional image of the vehicle/license plate (JPEG, PNG, GIF or WebP)"
+// @Param Idempotency-Key header string false "Unique key for safely retrying this request; retries with the same key replay the first response"
 // @Success 201 {object} dtos.SuccessResponseWithData{data=models.ParkingRecord}
-// @Failure 400 {object} dtos.ErrorResponse
-// @Failure 409 {object} dtos.ErrorResponse
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request, parking lot not specified, sensor not an entry sensor of the lot, override without operator and reason, or unsupported image type or dimensions"
+// @Failure 403 {object} dtos.ErrorResponse "Plate on the watchlist with the DenyEntry action (error starts with entry_denied)"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot or sensor not found"
+// @Failure 409 {object} dtos.ErrorResponse "Duplicate read of a vehicle that just entered, parking lot inactive, or vehicle class not accepted by the lot"
+// @Failure 413 {object} dtos.ErrorResponse "Image file too large"
 // @Failure 500 {object} dtos.ErrorResponse
+// @Failure 503 {object} dtos.ErrorResponse "Parking lot full (error starts with parking_lot_full)"
 // @Router /parking-records/entry [post]
 func (prc *ParkingRecordController) RecordVehicleEntryHandler(c *gin.Context) {
 	var payload dtos.SimpleEntryPayload
@@ -278,8 +376,12 @@ func (prc *ParkingRecordController) RecordVehicleEntryHandler(c *gin.Context) {
 		return
 	}
 
-	var imageBase64 *string
+	var image []byte
 	if payload.Image != nil {
+		if payload.Image.Size > configs.MaxImageUploadBytes {
+			dtos.SendErrorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Image file exceeds %d bytes", configs.MaxImageUploadBytes))
+			return
+		}
 		file, err := payload.Image.Open()
 		if err != nil {
 			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to open image file: "+err.Error())
@@ -287,27 +389,36 @@ func (prc *ParkingRecordController) RecordVehicleEntryHandler(c *gin.Context) {
 		}
 		defer file.Close()
 
-		bytes, err := ioutil.ReadAll(file)
+		image, err = io.ReadAll(file)
 		if err != nil {
 			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to read image file: "+err.Error())
 			return
 		}
+	}
 
-		var mimeType string
-		if len(payload.Image.Header["Content-Type"]) > 0 {
-			mimeType = payload.Image.Header["Content-Type"][0]
-		} else {
-			mimeType = "application/octet-stream"
-		}
+	var override *dtos.CapacityOverride
+	if payload.OverrideCapacity {
+		override = &dtos.CapacityOverride{OverriddenBy: payload.OverrideBy, Reason: payload.OverrideReason}
+	}
 
-		base64Str := "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(bytes)
-		imageBase64 = &base64Str
+	var ocr *dtos.OcrReading
+	if payload.OcrConfidence != nil {
+		ocr = &dtos.OcrReading{Confidence: *payload.OcrConfidence, Alternatives: payload.OcrAlternatives}
 	}
 
-	record, err := prc.parkingRecordService.RecordSimpleVehicleEntry(payload.LicensePlate, imageBase64)
+	record, err := prc.parkingRecordService.RecordSimpleVehicleEntry(payload.LicensePlate, payload.ParkingLotID, payload.SensorID, payload.VehicleClass, image, override, ocr)
 	if err != nil {
-		if strings.Contains(err.Error(), "vehicle already in parking lot") {
-			dtos.SendErrorResponse(c, http.StatusConflict, err.Error())
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "entry_denied:") {
+			dtos.SendErrorResponse(c, http.StatusForbidden, errMsg)
+		} else if strings.HasPrefix(errMsg, "parking_lot_full:") {
+			dtos.SendErrorResponse(c, http.StatusServiceUnavailable, errMsg)
+		} else if strings.Contains(errMsg, "vehicle already in parking lot") || strings.HasPrefix(errMsg, "parking_lot_inactive:") || strings.HasPrefix(errMsg, "vehicle_class_not_accepted:") {
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		} else if strings.HasPrefix(errMsg, "parking_lot_required:") || strings.HasPrefix(errMsg, "invalid_sensor:") || strings.HasPrefix(errMsg, "invalid_override:") || strings.HasPrefix(errMsg, "invalid_vehicle_class:") || strings.HasPrefix(errMsg, "invalid_license_plate:") || strings.HasPrefix(errMsg, "invalid_image:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.Contains(errMsg, "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
 		} else {
 			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to record vehicle entry: "+err.Error())
 		}
@@ -316,9 +427,91 @@ func (prc *ParkingRecordController) RecordVehicleEntryHandler(c *gin.Context) {
 	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Vehicle entry recorded successfully.", record)
 }
 
+// GetStaleParkingRecordsHandler godoc
+// @Summary List stale parking records
+// @Description Lists open parking records flagged by the background sweeper because they have been open longer than the configured maximum age (STALE_SESSION_MAX_AGE, 72h by default), oldest entry first. These usually belong to vehicles that left without triggering the exit sensor; staff can close them with /parking-records/{id}/abandon.
+// @Tags parking_records
+// @Produce json
+// @Param parkingLotId query int false "Only include this parking lot"
+// @Param limit query int false "Limit number of records returned" default(10)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.ParkingRecord}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid parking lot ID"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-records/stale [get]
+func (prc *ParkingRecordController) GetStaleParkingRecordsHandler(c *gin.Context) {
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	records, err := prc.parkingRecordService.GetStaleParkingRecords(parkingLotID, limit, offset)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get stale parking records: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Stale parking records retrieved successfully.", records)
+}
+
+// AbandonParkingRecordHandler godoc
+// @Summary Close a parking record whose vehicle left without exiting
+// @Description Closes an open parking record with SessionStatus Abandoned, freeing its spot. The operator and reason are stored in AbandonReason, active charging sessions are stopped at their last meter reading, and any unpaid fee stays on the record.
+// @Tags parking_records
+// @Accept json
+// @Produce json
+// @Param id path int true "Parking Record ID"
+// @Param abandon body dtos.AbandonParkingRecordPayload true "Operator and reason"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.ParkingRecord}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format or payload"
+// @Failure 404 {object} dtos.ErrorResponse "Parking record not found"
+// @Failure 409 {object} dtos.ErrorResponse "Parking record already exited"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-records/{id}/abandon [post]
+func (prc *ParkingRecordController) AbandonParkingRecordHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking record ID format")
+		return
+	}
+
+	var payload dtos.AbandonParkingRecordPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	record, err := prc.parkingRecordService.AbandonParkingRecord(uint(id), payload)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errMsg, "invalid_abandon:"):
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		case strings.HasPrefix(errMsg, "session_closed:"):
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		case strings.Contains(errMsg, "not found"):
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		default:
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to close parking record: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Parking record closed as abandoned.", record)
+}
+
 // UpdateUserVerifiedLicensePlateHandler godoc
 // @Summary Update user-verified license plate for a parking record
-// @Description Allows a user to correct or verify the license plate for an existing parking record.
+// @Description Allows a user to correct or verify the license plate for an existing parking record. The plate is stored in the canonical Taiwanese format.
 // @Tags parking_records
 // @Accept  json
 // @Produce  json
@@ -352,6 +545,8 @@ func (prc *ParkingRecordController) UpdateUserVerifiedLicensePlateHandler(c *gin
 	if err != nil {
 		if err.Error() == "parking record not found" {
 			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else if strings.HasPrefix(err.Error(), "invalid_license_plate:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, err.Error())
 		} else {
 			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update verified license plate: "+err.Error())
 		}
@@ -362,15 +557,18 @@ func (prc *ParkingRecordController) UpdateUserVerifiedLicensePlateHandler(c *gin
 
 // RecordVehicleExitHandler godoc
 // @Summary Record a vehicle exit event
-// @Description Records when a vehicle exits the parking lot. Checks for payment status.
+// @Description Records when a vehicle exits a parking lot. The license plate is matched in the canonical Taiwanese format, and the plate as sent is kept in ExitRawLicensePlate. The lot is chosen by parkingLotId or by a registered exit sensorId (both optional when only one lot exists), and the vehicle must be parked in that lot. Checks for payment status. A paid record that leaves after the exit grace period must pay the overstay amount (402) before the gate opens. A record fully covered by merchant validations exits without payment. Charging sessions still active on exit are stopped at their last meter reading. With fuzzyMatch, a plate without an exact active match is matched by similarity (ignoring O/0, I/1 and B/8 confusions): a single high-confidence candidate is used automatically, otherwise 300 lists the ranked candidates with plate, entry time and image URLs, and the gate operator confirms one by repeating the request with its parkingRecordId. ExitMatch on the record tells how it was matched. A matched record whose exit plate or recorded plate is on the watchlist raises an Exit alert on /watchlist-alerts, once per record.
 // @Tags parking_records
 // @Accept  json
 // @Produce  json
 // @Param   exit_info body dtos.SimpleEntryPayload true "Vehicle Exit Information (License Plate Only)"
+// @Param Idempotency-Key header string false "Unique key for safely retrying this request; retries with the same key replay the first response"
 // @Success 200 {object} dtos.SuccessResponseWithData{data=models.ParkingRecord}
-// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request, parking lot not specified, or sensor not an exit sensor of the lot"
+// @Success 300 {object} dtos.ExitCandidatesResponse "No exact match; the gate operator must confirm one of the similar active records"
 // @Failure 402 {object} dtos.ErrorResponseWithRecord
-// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse "No active parking record in the lot, or parking lot or sensor not found"
+// @Failure 409 {object} dtos.ErrorResponse "Idempotency-Key reused with a different request"
 // @Failure 500 {object} dtos.ErrorResponse
 // @Router /parking-records/exit [post]
 func (prc *ParkingRecordController) RecordVehicleExitHandler(c *gin.Context) {
@@ -385,9 +583,18 @@ func (prc *ParkingRecordController) RecordVehicleExitHandler(c *gin.Context) {
 		return
 	}
 
-	record, err := prc.parkingRecordService.RecordVehicleExit(payload.LicensePlate)
+	options := dtos.ExitMatchOptions{FuzzyMatch: payload.FuzzyMatch, ParkingRecordID: payload.ParkingRecordID}
+	record, quote, candidates, err := prc.parkingRecordService.RecordVehicleExit(payload.LicensePlate, payload.ParkingLotID, payload.SensorID, options)
 	if err != nil {
-		if strings.HasPrefix(err.Error(), "payment_required:") {
+		if strings.HasPrefix(err.Error(), "exit_match_ambiguous:") {
+			c.JSON(http.StatusMultipleChoices, dtos.ExitCandidatesResponse{
+				Error:        err.Error(),
+				LicensePlate: payload.LicensePlate,
+				Candidates:   candidates,
+			})
+			return
+		}
+		if strings.HasPrefix(err.Error(), "payment_required:") || strings.HasPrefix(err.Error(), "overstay_payment_required:") {
 			response := dtos.ErrorResponseWithRecord{
 				Error: err.Error(),
 			}
@@ -398,8 +605,14 @@ func (prc *ParkingRecordController) RecordVehicleExitHandler(c *gin.Context) {
 				response.PaymentStatus = record.PaymentStatus
 				response.EntryTime = record.EntryTime
 			}
+			if quote != nil {
+				response.AmountDue = quote.AmountDue
+				response.GracePeriodEndsAt = quote.GracePeriodEndsAt
+			}
 			c.JSON(http.StatusPaymentRequired, response)
-		} else if strings.Contains(err.Error(), "no active parking record found") {
+		} else if strings.HasPrefix(err.Error(), "parking_lot_required:") || strings.HasPrefix(err.Error(), "invalid_sensor:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		} else if strings.Contains(err.Error(), "not found") {
 			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
 		} else {
 			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to record vehicle exit: "+err.Error())
@@ -412,11 +625,11 @@ func (prc *ParkingRecordController) RecordVehicleExitHandler(c *gin.Context) {
 
 // PrepareParkingRecordForPaymentHandler godoc
 // @Summary Prepare a parking record for payment by calculating/retrieving its fee
-// @Description Calculates and stores the parking fee if not already calculated for an active parking record. Returns the record with payment details.
+// @Description Calculates and stores the parking fee if not already calculated for an active parking record, net of applied merchant validations. Returns the record with payment details, the fee breakdown per rate band and calendar day, the validation discounts, the EV charging charges, and the amount still due after any reservation deposit. Meter readings reported after preparing require preparing again.
 // @Tags parking_records
 // @Produce  json
 // @Param   id path int true "Parking Record ID"
-// @Success 200 {object} dtos.SuccessResponseWithData{data=models.ParkingRecord} "Successfully calculated/retrieved fee, record ready for payment"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.ParkingRecordWithFeeBreakdownResponse} "Successfully calculated/retrieved fee, record ready for payment"
 // @Failure 400 {object} dtos.ErrorResponse "Invalid Record ID or record already exited/paid"
 // @Failure 404 {object} dtos.ErrorResponse "Parking Record not found"
 // @Failure 500 {object} dtos.ErrorResponse "Internal server error"
@@ -429,7 +642,7 @@ func (prc *ParkingRecordController) PrepareParkingRecordForPaymentHandler(c *gin
 		return
 	}
 
-	record, err := prc.parkingRecordService.PrepareParkingRecordForPayment(uint(id))
+	response, err := prc.parkingRecordService.PrepareParkingRecordForPayment(uint(id))
 	if err != nil {
 		if strings.HasPrefix(err.Error(), "vehicle_exited:") || strings.HasPrefix(err.Error(), "already_paid:") {
 			dtos.SendErrorResponse(c, http.StatusBadRequest, err.Error())
@@ -440,21 +653,85 @@ func (prc *ParkingRecordController) PrepareParkingRecordForPaymentHandler(c *gin
 		}
 		return
 	}
-	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Parking fee prepared successfully.", record)
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Parking fee prepared successfully.", response)
+}
+
+// GetParkingFeeQuoteHandler godoc
+// @Summary Get a read-only fee quote for a parking record
+// @Description Returns an itemized quote (duration, billable units, per-band charges, caps, discounts, EV energy and idle fees, total and expiry) for the parking record without modifying it.
+// @Tags parking_records
+// @Produce  json
+// @Param   id path int true "Parking Record ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.FeeQuote}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid Record ID"
+// @Failure 404 {object} dtos.ErrorResponse "Parking Record not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-records/{id}/quote [get]
+func (prc *ParkingRecordController) GetParkingFeeQuoteHandler(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking record ID format")
+		return
+	}
+
+	quote, err := prc.parkingRecordService.GetParkingFeeQuote(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get fee quote: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Fee quote retrieved successfully.", quote)
+}
+
+// GetParkingFeeQuoteByLicensePlateHandler godoc
+// @Summary Get a read-only fee quote by License Plate
+// @Description Returns an itemized quote for the active parking record of a License Plate without modifying it.
+// @Tags parking_records
+// @Produce  json
+// @Param   licensePlate path string true "License Plate"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.FeeQuote}
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse "No active parking record for this license plate"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-records/license/{licensePlate}/quote [get]
+func (prc *ParkingRecordController) GetParkingFeeQuoteByLicensePlateHandler(c *gin.Context) {
+	licensePlate := c.Param("licensePlate")
+	if licensePlate == "" {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "License plate cannot be empty")
+		return
+	}
+
+	quote, err := prc.parkingRecordService.GetParkingFeeQuoteByLicensePlate(licensePlate)
+	if err != nil {
+		if strings.Contains(err.Error(), "no active parking record found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get fee quote: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Fee quote retrieved successfully.", quote)
 }
 
 // PayForParkingRecordHandler handles the request to pay for a parking record.
 // @Summary Pay for a parking record
-// @Description Marks a parking record as paid and ideally creates a transaction record.
+// @Description Creates a payment intent for the amount due with the payment provider selected by paymentMethod. The amount due is the prepared fee less any reservation deposit already credited to the record; a fee prepared longer ago than the quote validity must be prepared again. The exit grace period starts when the paid fee was calculated. Providers that authorize immediately (e.g. Cash) are captured right away: the parking record is marked as paid and a transaction record is created. Other providers return 202 with a pending payment intent; the record is marked as paid only after the provider's signed callback confirms the payment. For a paid record that exceeded the exit grace period, accepts a supplementary overstay payment. The payment is itemized into parking, EV energy and idle fee line items, each only for the part not billed by an earlier payment; a record that is still charging cannot be paid until the charging session is stopped.
 // @Tags Parking Records
 // @Accept json
 // @Produce json
 // @Param id path uint true "Parking Record ID"
 // @Param paymentPayload body dtos.ParkingPaymentPayload true "Payment Details"
+// @Param Idempotency-Key header string false "Unique key for safely retrying this request; retries with the same key replay the first response"
 // @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.ParkingRecordWithTransactionResponse} "Payment successful"
-// @Failure 400 {object} dtos.ErrorResponse "Invalid request (e.g., validation error, amount mismatch)"
-// @Failure 402 {object} dtos.ErrorResponse "Payment required conditions not met (e.g., fee not calculated, already paid, vehicle exited)"
+// @Success 202 {object} dtos.SuccessResponseWithData{data=models.PaymentIntent} "Payment intent created, waiting for provider confirmation"
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request (e.g., validation error, unsupported payment method)"
+// @Failure 402 {object} dtos.ErrorResponse "Payment required conditions not met (e.g., fee not calculated or expired, amount mismatch, already paid, no payment due, vehicle exited, payment declined)"
 // @Failure 404 {object} dtos.ErrorResponse "Parking record not found"
+// @Failure 409 {object} dtos.ErrorResponse "Idempotency-Key reused with a different request, or the vehicle is still charging"
 // @Failure 500 {object} dtos.ErrorResponse "Internal server error"
 // @Router /parking-records/{id}/pay [post]
 func (prc *ParkingRecordController) PayForParkingRecordHandler(c *gin.Context) {
@@ -471,22 +748,32 @@ func (prc *ParkingRecordController) PayForParkingRecordHandler(c *gin.Context) {
 		return
 	}
 
-	parkingRecord, transaction, err := prc.parkingRecordService.PayForParkingRecord(uint(id), payload)
+	parkingRecord, transaction, paymentIntent, err := prc.parkingRecordService.PayForParkingRecord(uint(id), payload)
 	if err != nil {
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "not found") {
 			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
-		} else if strings.Contains(errMsg, "amount_mismatch") || strings.Contains(errMsg, "fee_not_calculated") || strings.Contains(errMsg, "already_paid") || strings.Contains(errMsg, "vehicle_exited") {
+		} else if strings.Contains(errMsg, "amount_mismatch") || strings.Contains(errMsg, "fee_not_calculated") || strings.Contains(errMsg, "fee_expired") || strings.Contains(errMsg, "already_paid") || strings.Contains(errMsg, "no_payment_due") || strings.Contains(errMsg, "vehicle_exited") || strings.Contains(errMsg, "payment_declined") {
 			dtos.SendErrorResponse(c, http.StatusPaymentRequired, errMsg)
+		} else if strings.HasPrefix(errMsg, "unsupported_payment_method:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.HasPrefix(errMsg, "charging_in_progress:") {
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
 		} else {
 			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to process payment: "+errMsg)
 		}
 		return
 	}
 
+	if transaction == nil {
+		dtos.SendSuccessResponseWithData(c, http.StatusAccepted, "Payment intent created. Waiting for payment provider confirmation.", paymentIntent)
+		return
+	}
+
 	response := dtos.ParkingRecordWithTransactionResponse{
 		ParkingRecord: *parkingRecord,
 		Transaction:   *transaction,
+		PaymentIntent: paymentIntent,
 	}
 	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Payment processed successfully.", response)
 }
@@ -515,15 +802,43 @@ func parseTimeRangeParameters(c *gin.Context) (startTime, endTime *time.Time, er
 	return startTime, endTime, nil
 }
 
+// parseParkingLotIDParameter 輔助函數，用於解析報表的停車場參數，未指定時回傳 nil 表示所有停車場
+func parseParkingLotIDParameter(c *gin.Context) (*uint, error) {
+	parkingLotIDStr := c.Query("parkingLotId")
+	if parkingLotIDStr == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(parkingLotIDStr, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	parkingLotID := uint(id)
+	return &parkingLotID, nil
+}
+
+// parseVehicleClassParameter 解析報表的 vehicleClass 查詢參數，未提供時回傳 nil
+func parseVehicleClassParameter(c *gin.Context) (*string, error) {
+	vehicleClass := c.Query("vehicleClass")
+	if vehicleClass == "" {
+		return nil, nil
+	}
+	if !models.IsValidVehicleClass(vehicleClass) {
+		return nil, fmt.Errorf("must be one of %s", strings.Join(models.VehicleClasses, ", "))
+	}
+	return &vehicleClass, nil
+}
+
 // GetTotalParkingCountHandler godoc
 // @Summary Get total parking count within a time range
-// @Description Retrieves the total number of parking events (vehicle entries).
+// @Description Retrieves the total number of parking events (vehicle entries), grouped by vehicle class.
 // @Tags reports
 // @Produce json
+// @Param parkingLotId query int false "Only include this parking lot (all lots when omitted)"
+// @Param vehicleClass query string false "Only include this vehicle class: Car, Motorcycle or Large (all classes when omitted)"
 // @Param startTime query string false "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)"
 // @Param endTime query string false "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)"
 // @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.TotalParkingCountResponse}
-// @Failure 400 {object} dtos.ErrorResponse "Invalid time format"
+// @Failure 400 {object} dtos.ErrorResponse "Invalid time format, parking lot ID or vehicle class"
 // @Failure 500 {object} dtos.ErrorResponse "Internal server error"
 // @Router /reports/traffic/total-count [get]
 func (prc *ParkingRecordController) GetTotalParkingCountHandler(c *gin.Context) {
@@ -532,25 +847,37 @@ func (prc *ParkingRecordController) GetTotalParkingCountHandler(c *gin.Context)
 		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid time format: "+err.Error())
 		return
 	}
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	vehicleClass, err := parseVehicleClassParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid vehicleClass: "+err.Error())
+		return
+	}
 
-	count, err := prc.parkingRecordService.GetTotalParkingCount(startTime, endTime)
+	responseData, err := prc.parkingRecordService.GetTotalParkingCount(parkingLotID, vehicleClass, startTime, endTime)
 	if err != nil {
 		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get total parking count: "+err.Error())
 		return
 	}
-	responseData := dtos.TotalParkingCountResponse{TotalCount: count}
 	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Total parking count retrieved successfully.", responseData)
 }
 
 // GetTotalRevenueHandler godoc
 // @Summary Get total revenue from parking fees within a time range
-// @Description Retrieves the total revenue collected from parking fees.
+// @Description Retrieves the total revenue collected from parking fees and forfeited reservation deposits, net of refunds, grouped by vehicle class.
 // @Tags reports
 // @Produce json
+// @Param parkingLotId query int false "Only include this parking lot (all lots when omitted)"
+// @Param vehicleClass query string false "Only include this vehicle class: Car, Motorcycle or Large (all classes when omitted)"
 // @Param startTime query string false "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)"
 // @Param endTime query string false "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)"
 // @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.TotalRevenueResponse}
-// @Failure 400 {object} dtos.ErrorResponse "Invalid time format"
+// @Failure 400 {object} dtos.ErrorResponse "Invalid time format, parking lot ID or vehicle class"
 // @Failure 500 {object} dtos.ErrorResponse "Internal server error"
 // @Router /reports/revenue/total [get]
 func (prc *ParkingRecordController) GetTotalRevenueHandler(c *gin.Context) {
@@ -559,14 +886,24 @@ func (prc *ParkingRecordController) GetTotalRevenueHandler(c *gin.Context) {
 		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid time format: "+err.Error())
 		return
 	}
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	vehicleClass, err := parseVehicleClassParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid vehicleClass: "+err.Error())
+		return
+	}
 
-	revenue, err := prc.parkingRecordService.GetTotalRevenue(startTime, endTime)
+	revenueResponse, err := prc.parkingRecordService.GetTotalRevenue(parkingLotID, vehicleClass, startTime, endTime)
 	if err != nil {
 		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get total revenue: "+err.Error())
 		return
 	}
-	responseData := dtos.TotalRevenueResponse{TotalRevenue: revenue, Currency: "TWD"} // 假設幣別為 TWD
-	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Total revenue retrieved successfully.", responseData)
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Total revenue retrieved successfully.", revenueResponse)
 }
 
 // GetImageAttachmentRateHandler godoc
@@ -574,10 +911,11 @@ func (prc *ParkingRecordController) GetTotalRevenueHandler(c *gin.Context) {
 // @Description Calculates the percentage of vehicle entries that have an associated image.
 // @Tags reports
 // @Produce json
+// @Param parkingLotId query int false "Only include this parking lot (all lots when omitted)"
 // @Param startTime query string false "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)"
 // @Param endTime query string false "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)"
 // @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.ImageAttachmentRateResponse}
-// @Failure 400 {object} dtos.ErrorResponse "Invalid time format"
+// @Failure 400 {object} dtos.ErrorResponse "Invalid time format or parking lot ID"
 // @Failure 500 {object} dtos.ErrorResponse "Internal server error"
 // @Router /reports/operations/image-attachment-rate [get]
 func (prc *ParkingRecordController) GetImageAttachmentRateHandler(c *gin.Context) {
@@ -586,8 +924,13 @@ func (prc *ParkingRecordController) GetImageAttachmentRateHandler(c *gin.Context
 		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid time format: "+err.Error())
 		return
 	}
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
 
-	rateResponse, err := prc.parkingRecordService.GetImageAttachmentRate(startTime, endTime)
+	rateResponse, err := prc.parkingRecordService.GetImageAttachmentRate(parkingLotID, startTime, endTime)
 	if err != nil {
 		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get image attachment rate: "+err.Error())
 		return
@@ -597,17 +940,106 @@ func (prc *ParkingRecordController) GetImageAttachmentRateHandler(c *gin.Context
 
 // GetAvailableParkingSpotsHandler godoc
 // @Summary Get available parking spots
-// @Description Retrieves the total capacity, occupied spots, and available spots in the parking lot.
+// @Description Retrieves the total capacity, occupied spots, and available spots of each parking lot and summed over all lots. Spots are also broken down by vehicle class per lot and over all lots. Each lot also lists its zones with the spots free according to the spot sensors.
 // @Tags reports
 // @Produce json
+// @Param parkingLotId query int false "Only include this parking lot (all lots when omitted)"
+// @Param vehicleClass query string false "Only include this vehicle class: Car, Motorcycle or Large (all classes when omitted)"
 // @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.AvailableSpotsResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid parking lot ID or vehicle class"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot not found"
 // @Failure 500 {object} dtos.ErrorResponse "Internal server error"
 // @Router /reports/parking-lot/available-spots [get]
 func (prc *ParkingRecordController) GetAvailableParkingSpotsHandler(c *gin.Context) {
-	spotsResponse, err := prc.parkingRecordService.GetAvailableParkingSpots()
+	parkingLotID, err := parseParkingLotIDParameter(c)
 	if err != nil {
-		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get available parking spots: "+err.Error())
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	vehicleClass, err := parseVehicleClassParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid vehicleClass: "+err.Error())
+		return
+	}
+
+	spotsResponse, err := prc.parkingRecordService.GetAvailableParkingSpots(parkingLotID, vehicleClass)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get available parking spots: "+err.Error())
+		}
 		return
 	}
 	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Available parking spots retrieved successfully.", spotsResponse)
 }
+
+// GetSignboardHandler godoc
+// @Summary Get the signboard of a parking lot
+// @Description Retrieves the lines shown on the entrance signboard, one per zone ordered by level (e.g. "B2: 14 free", or "B2: FULL"). A lot without zones shows a single line with the spots free for vehicles without a permit.
+// @Tags reports
+// @Produce json
+// @Param parkingLotId query int false "Parking lot ID (may be omitted when only one lot exists)"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.SignboardResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid parking lot ID or parking lot not specified"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /reports/parking-lot/signboard [get]
+func (prc *ParkingRecordController) GetSignboardHandler(c *gin.Context) {
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+	var id uint
+	if parkingLotID != nil {
+		id = *parkingLotID
+	}
+
+	signboard, err := prc.parkingRecordService.GetSignboard(id)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "parking_lot_required:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.Contains(errMsg, "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get signboard: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Signboard retrieved successfully.", signboard)
+}
+
+// RecordSpotStatusHandler godoc
+// @Summary Report whether a spot is occupied
+// @Description Called by a spot sensor when its spot becomes occupied or free. Repeating the current status is harmless. When an occupied report carries licensePlate, the vehicle's active parking record in the same lot is linked to the spot.
+// @Tags ParkingLots
+// @Accept json
+// @Produce json
+// @Param sensorId path string true "Spot Sensor ID"
+// @Param status body dtos.SpotStatusPayload true "Spot Status"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.SpotStatusResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload"
+// @Failure 404 {object} dtos.ErrorResponse "Spot sensor not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /spot-sensors/{sensorId}/status [post]
+func (prc *ParkingRecordController) RecordSpotStatusHandler(c *gin.Context) {
+	var payload dtos.SpotStatusPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	status, err := prc.parkingRecordService.RecordSpotStatus(c.Param("sensorId"), *payload.Occupied, payload.LicensePlate)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to record spot status: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Spot status recorded successfully.", status)
+}
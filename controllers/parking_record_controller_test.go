@@ -0,0 +1,42 @@
+package controllers
+
+import "testing"
+
+func TestAcceptsContentType(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		want        bool
+	}{
+		// 未提供 Accept 時視為允許
+		{accept: "", contentType: "image/jpeg", want: true},
+		{accept: "   ", contentType: "image/jpeg", want: true},
+
+		{accept: "image/jpeg", contentType: "image/jpeg", want: true},
+		{accept: "image/png", contentType: "image/jpeg", want: false},
+		{accept: "image/*", contentType: "image/jpeg", want: true},
+		{accept: "*/*", contentType: "image/jpeg", want: true},
+		{accept: "application/json", contentType: "image/jpeg", want: false},
+		{accept: "text/html, application/xhtml+xml, image/webp, */*;q=0.8", contentType: "image/jpeg", want: true},
+		{accept: "IMAGE/JPEG", contentType: "image/jpeg", want: true},
+
+		// q=0 的項目視為不允許
+		{accept: "image/jpeg;q=0", contentType: "image/jpeg", want: false},
+		{accept: "image/jpeg; q=0.0", contentType: "image/jpeg", want: false},
+		{accept: "image/jpeg;q=0.1", contentType: "image/jpeg", want: true},
+		{accept: "*/*;q=0", contentType: "image/jpeg", want: false},
+
+		// 以最明確的項目為準，與順序無關
+		{accept: "image/jpeg;q=0, */*", contentType: "image/jpeg", want: false},
+		{accept: "*/*, image/jpeg;q=0", contentType: "image/jpeg", want: false},
+		{accept: "image/*;q=0, image/jpeg", contentType: "image/jpeg", want: true},
+		{accept: "image/jpeg, image/*;q=0", contentType: "image/jpeg", want: true},
+		{accept: "image/*;q=0, */*", contentType: "image/jpeg", want: false},
+		{accept: "*/*;q=0, image/*", contentType: "image/jpeg", want: true},
+	}
+	for _, tt := range tests {
+		if got := acceptsContentType(tt.accept, tt.contentType); got != tt.want {
+			t.Errorf("acceptsContentType(%q, %q) = %v, want %v", tt.accept, tt.contentType, got, tt.want)
+		}
+	}
+}
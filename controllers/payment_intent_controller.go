@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentIntentController 定義付款意圖控制器
+type PaymentIntentController struct {
+	paymentIntentService services.PaymentIntentService
+}
+
+// NewPaymentIntentController 建立一個新的 PaymentIntentController 實例
+func NewPaymentIntentController(pis services.PaymentIntentService) *PaymentIntentController {
+	return &PaymentIntentController{paymentIntentService: pis}
+}
+
+// GetPaymentIntentByIDHandler godoc
+// @Summary Get a payment intent by ID
+// @Description Retrieves a payment intent so clients can poll the status of an asynchronous payment (Pending, Authorized, Captured, Failed, Expired).
+// @Tags Payments
+// @Produce json
+// @Param id path uint true "Payment Intent ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.PaymentIntent}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Payment intent not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /payment-intents/{id} [get]
+func (pic *PaymentIntentController) GetPaymentIntentByIDHandler(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid payment intent ID format")
+		return
+	}
+
+	intent, err := pic.paymentIntentService.GetPaymentIntentByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get payment intent: "+err.Error())
+		return
+	}
+	if intent == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Payment intent not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Payment intent retrieved successfully.", intent)
+}
+
+// GetPaymentIntentsByParkingRecordIDHandler godoc
+// @Summary Get payment intents of a parking record
+// @Description Retrieves all payment intents created for a parking record, newest first.
+// @Tags Payments
+// @Produce json
+// @Param id path uint true "Parking Record ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.PaymentIntent}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /parking-records/{id}/payment-intents [get]
+func (pic *PaymentIntentController) GetPaymentIntentsByParkingRecordIDHandler(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parking record ID format")
+		return
+	}
+
+	intents, err := pic.paymentIntentService.GetPaymentIntentsByParkingRecordID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get payment intents: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Payment intents retrieved successfully.", intents)
+}
+
+// PaymentCallbackHandler godoc
+// @Summary Receive a payment provider callback
+// @Description Receives an asynchronous notification from a payment provider. The request body must carry a valid provider signature. Authorized payments are captured and the parking record is marked as paid; replayed notifications are acknowledged without being processed again.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param provider path string true "Payment provider name (e.g., Simulator)"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.PaymentIntent} "Callback processed"
+// @Failure 400 {object} dtos.ErrorResponse "Malformed callback or provider without callbacks"
+// @Failure 401 {object} dtos.ErrorResponse "Invalid signature"
+// @Failure 404 {object} dtos.ErrorResponse "Unknown provider or payment intent"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /payments/callbacks/{provider} [post]
+func (pic *PaymentIntentController) PaymentCallbackHandler(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Cannot read callback body: "+err.Error())
+		return
+	}
+
+	intent, err := pic.paymentIntentService.HandleProviderCallback(c.Param("provider"), c.Request.Header, body)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid_signature:") {
+			dtos.SendErrorResponse(c, http.StatusUnauthorized, errMsg)
+		} else if strings.HasPrefix(errMsg, "invalid_callback:") || strings.HasPrefix(errMsg, "unsupported_callback:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.HasPrefix(errMsg, "unsupported_payment_provider:") || strings.Contains(errMsg, "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to process payment callback: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Payment callback processed successfully.", intent)
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermitController 定義定期停車證控制器
+type PermitController struct {
+	permitService services.PermitService
+}
+
+// NewPermitController 建立一個新的 PermitController 實例
+func NewPermitController(ps services.PermitService) *PermitController {
+	return &PermitController{permitService: ps}
+}
+
+// CreatePermitHandler godoc
+// @Summary Issue a parking permit
+// @Description Issues a monthly or season-ticket permit for one or more license plates. Vehicles entering with an active permit are not charged for the part of their stay inside the permit's validity and daily allowed hours; equal allowed start and end times mean all day, and an end before the start means an overnight window.
+// @Tags Permits
+// @Accept json
+// @Produce json
+// @Param permit body dtos.PermitPayload true "Permit Information"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.Permit}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload or permit settings"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /permits [post]
+func (pc *PermitController) CreatePermitHandler(c *gin.Context) {
+	var payload dtos.PermitPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	permit, err := pc.permitService.CreatePermit(payload)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid_permit:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create permit: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Permit created successfully.", permit)
+}
+
+// GetAllPermitsHandler godoc
+// @Summary Get all permits
+// @Description Get a list of all parking permits with their license plates, with pagination
+// @Tags Permits
+// @Produce json
+// @Param limit query int false "Limit number of permits returned" default(10)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.Permit}
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /permits [get]
+func (pc *PermitController) GetAllPermitsHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	permits, err := pc.permitService.GetAllPermits(limit, offset)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get permits: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Permits retrieved successfully.", permits)
+}
+
+// GetPermitByIDHandler godoc
+// @Summary Get a permit by ID
+// @Description Retrieves a single parking permit with its license plates.
+// @Tags Permits
+// @Produce json
+// @Param id path uint true "Permit ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.Permit}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Permit not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /permits/{id} [get]
+func (pc *PermitController) GetPermitByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid permit ID format")
+		return
+	}
+
+	permit, err := pc.permitService.GetPermitByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get permit: "+err.Error())
+		return
+	}
+	if permit == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Permit not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Permit retrieved successfully.", permit)
+}
+
+// UpdatePermitHandler godoc
+// @Summary Update a permit
+// @Description Replaces a permit's holder, validity, allowed hours and license plates. Vehicles already in the lot are billed with the updated permit.
+// @Tags Permits
+// @Accept json
+// @Produce json
+// @Param id path uint true "Permit ID"
+// @Param permit body dtos.PermitPayload true "Permit Information"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.Permit}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload or permit settings"
+// @Failure 404 {object} dtos.ErrorResponse "Permit not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /permits/{id} [put]
+func (pc *PermitController) UpdatePermitHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid permit ID format")
+		return
+	}
+
+	var payload dtos.PermitPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	permit, err := pc.permitService.UpdatePermit(uint(id), payload)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid_permit:") {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		} else if strings.Contains(errMsg, "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update permit: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Permit updated successfully.", permit)
+}
+
+// DeletePermitHandler godoc
+// @Summary Delete a permit
+// @Description Deletes a parking permit. Vehicles already in the lot on this permit are billed at the regular rate.
+// @Tags Permits
+// @Produce json
+// @Param id path uint true "Permit ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Permit not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /permits/{id} [delete]
+func (pc *PermitController) DeletePermitHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid permit ID format")
+		return
+	}
+
+	if err := pc.permitService.DeletePermit(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to delete permit: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponse(c, http.StatusOK, "Permit deleted successfully")
+}
+
+// GetPermitExpiryReportHandler godoc
+// @Summary Get permits expiring soon or recently expired
+// @Description Lists permits expiring within the next withinDays days and permits that expired within the last withinDays days, so holders can be reminded to renew.
+// @Tags reports
+// @Produce json
+// @Param withinDays query int false "Number of days to look ahead and back" default(30)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.PermitExpiryReportResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid withinDays"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /reports/permits/expiring [get]
+func (pc *PermitController) GetPermitExpiryReportHandler(c *gin.Context) {
+	withinDays, err := strconv.Atoi(c.DefaultQuery("withinDays", "30"))
+	if err != nil || withinDays <= 0 {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid withinDays: must be a positive integer")
+		return
+	}
+
+	report, err := pc.permitService.GetPermitExpiryReport(withinDays)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get permit expiry report: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Permit expiry report retrieved successfully.", report)
+}
@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"hello-professor_backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlateReviewController 定義車牌人工審核控制器
+type PlateReviewController struct {
+	plateReviewService services.PlateReviewService
+}
+
+// NewPlateReviewController 建立一個新的 PlateReviewController 實例
+func NewPlateReviewController(prs services.PlateReviewService) *PlateReviewController {
+	return &PlateReviewController{plateReviewService: prs}
+}
+
+// GetPlateReviewsHandler godoc
+// @Summary List plate reviews
+// @Description Lists the review queue of license plates read with low OCR confidence at entry, oldest first, with the OCR alternatives and the parking record (entry time and image URLs). Lists pending reviews unless another status is given.
+// @Tags Plate Reviews
+// @Produce json
+// @Param parkingLotId query int false "Only include this parking lot"
+// @Param status query string false "Only include this status: Pending, Approved or Corrected" default(Pending)
+// @Param reviewedBy query string false "Only include reviews completed by this reviewer"
+// @Param limit query int false "Limit number of reviews returned" default(10)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.PlateReview}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid parking lot ID or status"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /plate-reviews [get]
+func (prc *PlateReviewController) GetPlateReviewsHandler(c *gin.Context) {
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	status := c.DefaultQuery("status", models.PlateReviewStatusPending)
+	switch status {
+	case models.PlateReviewStatusPending, models.PlateReviewStatusApproved, models.PlateReviewStatusCorrected:
+	default:
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid status: must be one of Pending, Approved, Corrected")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	reviews, err := prc.plateReviewService.GetPlateReviews(parkingLotID, status, c.Query("reviewedBy"), limit, offset)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get plate reviews: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Plate reviews retrieved successfully.", reviews)
+}
+
+// GetPlateReviewByIDHandler godoc
+// @Summary Get a plate review by ID
+// @Description Retrieves a single plate review with the OCR alternatives and its parking record.
+// @Tags Plate Reviews
+// @Produce json
+// @Param id path uint true "Plate Review ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.PlateReview}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Plate review not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /plate-reviews/{id} [get]
+func (prc *PlateReviewController) GetPlateReviewByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid plate review ID format")
+		return
+	}
+
+	review, err := prc.plateReviewService.GetPlateReviewByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get plate review: "+err.Error())
+		return
+	}
+	if review == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Plate review not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Plate review retrieved successfully.", review)
+}
+
+// ApprovePlateReviewHandler godoc
+// @Summary Approve the OCR read of a plate
+// @Description Confirms that the plate read by the OCR is correct. The plate is stored as the parking record's UserVerifiedLicensePlate.
+// @Tags Plate Reviews
+// @Accept json
+// @Produce json
+// @Param id path uint true "Plate Review ID"
+// @Param review body dtos.ApprovePlateReviewPayload true "Reviewer"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.PlateReview}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format or payload"
+// @Failure 404 {object} dtos.ErrorResponse "Plate review not found"
+// @Failure 409 {object} dtos.ErrorResponse "Plate review already completed"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /plate-reviews/{id}/approve [post]
+func (prc *PlateReviewController) ApprovePlateReviewHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid plate review ID format")
+		return
+	}
+
+	var payload dtos.ApprovePlateReviewPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	review, err := prc.plateReviewService.ApprovePlateReview(uint(id), payload)
+	if err != nil {
+		sendPlateReviewError(c, err)
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Plate review approved successfully.", review)
+}
+
+// CorrectPlateReviewHandler godoc
+// @Summary Correct the OCR read of a plate
+// @Description Corrects the plate read by the OCR, usually to one of its alternatives. The corrected plate is stored in the canonical format as the parking record's UserVerifiedLicensePlate, so the vehicle is found by it at exit. A correction equal to the OCR read counts as an approval.
+// @Tags Plate Reviews
+// @Accept json
+// @Produce json
+// @Param id path uint true "Plate Review ID"
+// @Param review body dtos.CorrectPlateReviewPayload true "Reviewer and corrected plate"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.PlateReview}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format, payload or license plate"
+// @Failure 404 {object} dtos.ErrorResponse "Plate review not found"
+// @Failure 409 {object} dtos.ErrorResponse "Plate review already completed"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /plate-reviews/{id}/correct [post]
+func (prc *PlateReviewController) CorrectPlateReviewHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid plate review ID format")
+		return
+	}
+
+	var payload dtos.CorrectPlateReviewPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	review, err := prc.plateReviewService.CorrectPlateReview(uint(id), payload)
+	if err != nil {
+		sendPlateReviewError(c, err)
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Plate review corrected successfully.", review)
+}
+
+// sendPlateReviewError 將完成審核時的錯誤轉換為對應的 HTTP 狀態碼
+func sendPlateReviewError(c *gin.Context, err error) {
+	errMsg := err.Error()
+	switch {
+	case strings.HasPrefix(errMsg, "invalid_review:"), strings.HasPrefix(errMsg, "invalid_license_plate:"):
+		dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+	case strings.HasPrefix(errMsg, "review_closed:"):
+		dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+	case strings.Contains(errMsg, "not found"):
+		dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+	default:
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to complete plate review: "+errMsg)
+	}
+}
+
+// GetPlateReviewThroughputHandler godoc
+// @Summary Get plate review throughput
+// @Description Reports the plate reviews queued and completed within a time range, the share corrected, the average time from queuing to review and, when both ends of the range are given, reviews per hour; also broken down by reviewer. The current backlog and its oldest item are included.
+// @Tags reports
+// @Produce json
+// @Param parkingLotId query int false "Only include this parking lot (all lots when omitted)"
+// @Param startTime query string false "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)"
+// @Param endTime query string false "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.PlateReviewThroughputResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid time format or parking lot ID"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /reports/operations/plate-review-throughput [get]
+func (prc *PlateReviewController) GetPlateReviewThroughputHandler(c *gin.Context) {
+	startTime, endTime, err := parseTimeRangeParameters(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid time format: "+err.Error())
+		return
+	}
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	response, err := prc.plateReviewService.GetPlateReviewThroughput(parkingLotID, startTime, endTime)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get plate review throughput: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Plate review throughput retrieved successfully.", response)
+}
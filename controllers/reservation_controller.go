@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"hello-professor_backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReservationController 定義車位預約控制器
+type ReservationController struct {
+	reservationService services.ReservationService
+}
+
+// NewReservationController 建立一個新的 ReservationController 實例
+func NewReservationController(rs services.ReservationService) *ReservationController {
+	return &ReservationController{reservationService: rs}
+}
+
+// CreateReservationHandler godoc
+// @Summary Reserve a parking spot
+// @Description Reserves a spot of the given vehicle class for a license plate and time window. The spot is held from shortly before the start time until the end time and is subtracted from the lot's availability meanwhile. When the vehicle enters around the start time, the new parking record is linked to the reservation; reservations not used by then expire. Giving paymentMethod prepays a deposit that is credited against the parking fee on arrival; providers that do not authorize immediately return a pending payment intent.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param reservation body dtos.ReservationPayload true "Reservation Information"
+// @Param Idempotency-Key header string false "Unique key for safely retrying this request; retries with the same key replay the first response"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=dtos.ReservationResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload, time window, vehicle class or payment method, or several parking lots exist and parkingLotId is missing"
+// @Failure 404 {object} dtos.ErrorResponse "Parking lot not found"
+// @Failure 409 {object} dtos.ErrorResponse "No spots left to reserve, overlapping reservation for the plate, lot inactive or vehicle class not accepted"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /reservations [post]
+func (rc *ReservationController) CreateReservationHandler(c *gin.Context) {
+	var payload dtos.ReservationPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	reservation, intent, err := rc.reservationService.CreateReservation(payload)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errMsg, "invalid_reservation:"), strings.HasPrefix(errMsg, "invalid_vehicle_class:"), strings.HasPrefix(errMsg, "unsupported_payment_method:"), strings.HasPrefix(errMsg, "parking_lot_required:"):
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		case strings.HasPrefix(errMsg, "reservation_unavailable:"), strings.HasPrefix(errMsg, "reservation_exists:"), strings.HasPrefix(errMsg, "parking_lot_inactive:"), strings.HasPrefix(errMsg, "vehicle_class_not_accepted:"):
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		case strings.Contains(errMsg, "not found"):
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		default:
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create reservation: "+errMsg)
+		}
+		return
+	}
+
+	response := dtos.ReservationResponse{
+		Reservation:   *reservation,
+		PaymentIntent: intent,
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Reservation created successfully.", response)
+}
+
+// GetReservationsHandler godoc
+// @Summary List reservations
+// @Description Lists reservations ordered by start time, optionally filtered by parking lot, license plate and status, with pagination.
+// @Tags Reservations
+// @Produce json
+// @Param parkingLotId query int false "Only include this parking lot"
+// @Param licensePlate query string false "Only include this license plate"
+// @Param status query string false "Only include this status: Confirmed, Cancelled, Fulfilled or Expired"
+// @Param limit query int false "Limit number of reservations returned" default(10)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.Reservation}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid parking lot ID or status"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /reservations [get]
+func (rc *ReservationController) GetReservationsHandler(c *gin.Context) {
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.ReservationStatusConfirmed, models.ReservationStatusCancelled, models.ReservationStatusFulfilled, models.ReservationStatusExpired:
+	default:
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid status: must be one of Confirmed, Cancelled, Fulfilled, Expired")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	reservations, err := rc.reservationService.GetReservations(parkingLotID, c.Query("licensePlate"), status, limit, offset)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get reservations: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Reservations retrieved successfully.", reservations)
+}
+
+// GetReservationByIDHandler godoc
+// @Summary Get a reservation by ID
+// @Description Retrieves a single reservation with its status and deposit.
+// @Tags Reservations
+// @Produce json
+// @Param id path uint true "Reservation ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.Reservation}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Reservation not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /reservations/{id} [get]
+func (rc *ReservationController) GetReservationByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid reservation ID format")
+		return
+	}
+
+	reservation, err := rc.reservationService.GetReservationByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get reservation: "+err.Error())
+		return
+	}
+	if reservation == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Reservation not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Reservation retrieved successfully.", reservation)
+}
+
+// CancelReservationHandler godoc
+// @Summary Cancel a reservation
+// @Description Cancels a confirmed reservation and releases its held spot. A paid deposit is refunded in full when cancelled before the start time and forfeited afterwards. If the refund fails, the reservation stays cancelled and the error is returned so that staff can refund the deposit transaction manually.
+// @Tags Reservations
+// @Produce json
+// @Param id path uint true "Reservation ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.CancelReservationResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Reservation not found"
+// @Failure 409 {object} dtos.ErrorResponse "Reservation is no longer confirmed"
+// @Failure 502 {object} dtos.ErrorResponse "Reservation cancelled but the deposit refund failed"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /reservations/{id}/cancel [post]
+func (rc *ReservationController) CancelReservationHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid reservation ID format")
+		return
+	}
+
+	reservation, refund, err := rc.reservationService.CancelReservation(uint(id))
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errMsg, "invalid_reservation_state:"):
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		case strings.HasPrefix(errMsg, "deposit_refund_failed:"):
+			dtos.SendErrorResponse(c, http.StatusBadGateway, errMsg)
+		case strings.Contains(errMsg, "not found"):
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		default:
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to cancel reservation: "+errMsg)
+		}
+		return
+	}
+
+	response := dtos.CancelReservationResponse{
+		Reservation:   *reservation,
+		DepositRefund: refund,
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Reservation cancelled successfully.", response)
+}
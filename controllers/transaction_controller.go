@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"hello-professor_backend/dtos"
 	"hello-professor_backend/models"
 	"hello-professor_backend/services"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,8 +28,10 @@ func NewTransactionController(ts services.TransactionService) *TransactionContro
 // @Accept  json
 // @Produce  json
 // @Param   transaction_info body models.Transaction true "Transaction Information"
+// @Param Idempotency-Key header string false "Unique key for safely retrying this request; retries with the same key replay the first response"
 // @Success 201 {object} models.Transaction
 // @Failure 400 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse
 // @Failure 500 {object} dtos.ErrorResponse
 // @Router /transactions [post]
 func (tc *TransactionController) CreateTransactionHandler(c *gin.Context) {
@@ -136,6 +140,60 @@ func (tc *TransactionController) UpdateTransactionHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Transaction updated successfully"})
 }
 
+// RefundTransactionHandler godoc
+// @Summary Refund a transaction
+// @Description Fully or partially refunds a successful payment transaction. Creates a linked refund transaction with a negative amount and updates the parking record status when it is fully refunded. When the payment provider does not confirm the outcome, the refund stays Pending and is retried in the background.
+// @Tags transactions
+// @Accept  json
+// @Produce  json
+// @Param   id path int true "Transaction ID"
+// @Param   refund_info body dtos.RefundTransactionPayload true "Refund Information (omit amount for a full refund)"
+// @Param   Idempotency-Key header string false "Unique key for safely retrying this request; retries with the same key replay the first response"
+// @Success 201 {object} models.Transaction "The created refund transaction"
+// @Success 202 {object} models.Transaction "The refund transaction, still Pending until the payment provider confirms it"
+// @Failure 400 {object} dtos.ErrorResponse
+// @Failure 404 {object} dtos.ErrorResponse
+// @Failure 409 {object} dtos.ErrorResponse "Transaction already refunded or not refundable, or Idempotency-Key reused with a different request"
+// @Failure 500 {object} dtos.ErrorResponse
+// @Failure 502 {object} dtos.ErrorResponse "Payment provider rejected the refund"
+// @Router /transactions/{id}/refund [post]
+func (tc *TransactionController) RefundTransactionHandler(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID format"})
+		return
+	}
+
+	var payload dtos.RefundTransactionPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	refund, err := tc.transactionService.RefundTransaction(uint(id), payload)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": errMsg})
+		} else if strings.HasPrefix(errMsg, "invalid_refund_amount:") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		} else if strings.HasPrefix(errMsg, "already_refunded:") || strings.HasPrefix(errMsg, "invalid_refund:") {
+			c.JSON(http.StatusConflict, gin.H{"error": errMsg})
+		} else if strings.HasPrefix(errMsg, "provider_refund_failed:") {
+			c.JSON(http.StatusBadGateway, gin.H{"error": errMsg})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund transaction: " + errMsg})
+		}
+		return
+	}
+	if refund.Status == "Pending" {
+		c.JSON(http.StatusAccepted, refund)
+		return
+	}
+	c.JSON(http.StatusCreated, refund)
+}
+
 // DeleteTransactionHandler godoc
 // @Summary Delete a transaction by ID
 // @Description Remove a transaction from the system by its ID
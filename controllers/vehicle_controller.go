@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VehicleController 定義車輛資料控制器
+type VehicleController struct {
+	vehicleService services.VehicleService
+}
+
+// NewVehicleController 建立一個新的 VehicleController 實例
+func NewVehicleController(vs services.VehicleService) *VehicleController {
+	return &VehicleController{vehicleService: vs}
+}
+
+// GetVehiclesHandler godoc
+// @Summary List vehicles
+// @Description Lists vehicles, most recently seen first, with pagination. A vehicle is created automatically the first time its plate enters a lot.
+// @Tags Vehicles
+// @Produce json
+// @Param licensePlate query string false "Only include plates containing this fragment (ignoring separators and O/0, I/1, B/8 confusions)"
+// @Param limit query int false "Limit number of vehicles returned" default(10)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.Vehicle}
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /vehicles [get]
+func (vc *VehicleController) GetVehiclesHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	vehicles, err := vc.vehicleService.GetVehicles(c.Query("licensePlate"), limit, offset)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get vehicles: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Vehicles retrieved successfully.", vehicles)
+}
+
+// GetVehicleByIDHandler godoc
+// @Summary Get a vehicle profile by ID
+// @Description Retrieves a vehicle with the details kept by staff and statistics over all its visits: visit count, total spend (payments net of refunds), average dwell time of completed visits, and whether any visit exited without payment.
+// @Tags Vehicles
+// @Produce json
+// @Param id path uint true "Vehicle ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.VehicleProfileResponse}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Vehicle not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /vehicles/{id} [get]
+func (vc *VehicleController) GetVehicleByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid vehicle ID format")
+		return
+	}
+
+	profile, err := vc.vehicleService.GetVehicleProfile(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get vehicle: "+err.Error())
+		return
+	}
+	if profile == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Vehicle not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Vehicle retrieved successfully.", profile)
+}
+
+// GetVehicleByLicensePlateHandler godoc
+// @Summary Get a vehicle profile by license plate
+// @Description Retrieves the profile of the vehicle with this plate. The plate is matched in the canonical Taiwanese format.
+// @Tags Vehicles
+// @Produce json
+// @Param plate path string true "License plate"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=dtos.VehicleProfileResponse}
+// @Failure 404 {object} dtos.ErrorResponse "Vehicle not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /vehicles/license/{plate} [get]
+func (vc *VehicleController) GetVehicleByLicensePlateHandler(c *gin.Context) {
+	profile, err := vc.vehicleService.GetVehicleProfileByLicensePlate(c.Param("plate"))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get vehicle: "+err.Error())
+		return
+	}
+	if profile == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Vehicle not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Vehicle retrieved successfully.", profile)
+}
+
+// UpdateVehicleHandler godoc
+// @Summary Update a vehicle's details
+// @Description Replaces the make, color and notes kept by staff about a vehicle. Omitted fields are cleared.
+// @Tags Vehicles
+// @Accept json
+// @Produce json
+// @Param id path uint true "Vehicle ID"
+// @Param vehicle body dtos.VehicleDetailsPayload true "Vehicle details"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.Vehicle}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format or payload"
+// @Failure 404 {object} dtos.ErrorResponse "Vehicle not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /vehicles/{id} [put]
+func (vc *VehicleController) UpdateVehicleHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid vehicle ID format")
+		return
+	}
+
+	var payload dtos.VehicleDetailsPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	vehicle, err := vc.vehicleService.UpdateVehicleDetails(uint(id), payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update vehicle: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Vehicle updated successfully.", vehicle)
+}
+
+// GetVehicleVisitsHandler godoc
+// @Summary Get a vehicle's visit history
+// @Description Lists the parking records of a vehicle, newest entry first, with pagination.
+// @Tags Vehicles
+// @Produce json
+// @Param id path uint true "Vehicle ID"
+// @Param limit query int false "Limit number of visits returned" default(10)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.ParkingRecord}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Vehicle not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /vehicles/{id}/visits [get]
+func (vc *VehicleController) GetVehicleVisitsHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid vehicle ID format")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	visits, err := vc.vehicleService.GetVehicleVisits(uint(id), limit, offset)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get vehicle visits: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Vehicle visits retrieved successfully.", visits)
+}
@@ -0,0 +1,377 @@
+package controllers
+
+import (
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"hello-professor_backend/services"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WatchlistController 定義監控名單與警示控制器
+type WatchlistController struct {
+	watchlistService services.WatchlistService
+}
+
+// NewWatchlistController 建立一個新的 WatchlistController 實例
+func NewWatchlistController(ws services.WatchlistService) *WatchlistController {
+	return &WatchlistController{watchlistService: ws}
+}
+
+// CreateWatchlistEntryHandler godoc
+// @Summary Add a plate to the watchlist
+// @Description Adds a license plate to the watchlist. The plate is stored in the canonical format and checked at every entry and exit, including plates that differ only in look-alike characters (O/0, I/1, B/8) and similar plates. A match raises an alert; DenyEntry also turns the vehicle away at entry (not for merely similar plates) and Flag marks its parking record for staff.
+// @Tags Watchlist
+// @Accept json
+// @Produce json
+// @Param entry body dtos.WatchlistEntryPayload true "Watchlist entry"
+// @Success 201 {object} dtos.SuccessResponseWithData{data=models.WatchlistEntry}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid request payload, license plate, category or action"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /watchlist-entries [post]
+func (wc *WatchlistController) CreateWatchlistEntryHandler(c *gin.Context) {
+	var payload dtos.WatchlistEntryPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	entry, err := wc.watchlistService.CreateWatchlistEntry(payload)
+	if err != nil {
+		sendWatchlistEntryError(c, err, "Failed to create watchlist entry: ")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusCreated, "Watchlist entry created successfully.", entry)
+}
+
+// GetWatchlistEntriesHandler godoc
+// @Summary List watchlist entries
+// @Description Lists watchlist entries, with pagination.
+// @Tags Watchlist
+// @Produce json
+// @Param category query string false "Only include this category: Stolen, Banned, VIP or Other"
+// @Param action query string false "Only include this action: Alert, DenyEntry or Flag"
+// @Param activeOnly query bool false "Exclude inactive and expired entries" default(false)
+// @Param limit query int false "Limit number of entries returned" default(10)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.WatchlistEntry}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid category, action or activeOnly"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /watchlist-entries [get]
+func (wc *WatchlistController) GetWatchlistEntriesHandler(c *gin.Context) {
+	category := c.Query("category")
+	if category != "" && !models.IsValidWatchlistCategory(category) {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid category: must be one of Stolen, Banned, VIP, Other")
+		return
+	}
+	action := c.Query("action")
+	if action != "" && !models.IsValidWatchlistAction(action) {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid action: must be one of Alert, DenyEntry, Flag")
+		return
+	}
+	activeOnly, err := strconv.ParseBool(c.DefaultQuery("activeOnly", "false"))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid activeOnly: must be true or false")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	entries, err := wc.watchlistService.GetWatchlistEntries(category, action, activeOnly, limit, offset)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get watchlist entries: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Watchlist entries retrieved successfully.", entries)
+}
+
+// GetWatchlistEntryByIDHandler godoc
+// @Summary Get a watchlist entry by ID
+// @Description Retrieves a single watchlist entry.
+// @Tags Watchlist
+// @Produce json
+// @Param id path uint true "Watchlist Entry ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.WatchlistEntry}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Watchlist entry not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /watchlist-entries/{id} [get]
+func (wc *WatchlistController) GetWatchlistEntryByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid watchlist entry ID format")
+		return
+	}
+
+	entry, err := wc.watchlistService.GetWatchlistEntryByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get watchlist entry: "+err.Error())
+		return
+	}
+	if entry == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Watchlist entry not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Watchlist entry retrieved successfully.", entry)
+}
+
+// UpdateWatchlistEntryHandler godoc
+// @Summary Update a watchlist entry
+// @Description Replaces a watchlist entry's plate, category, action, reason, active flag and expiry. Alerts already raised keep the entry's details at the time.
+// @Tags Watchlist
+// @Accept json
+// @Produce json
+// @Param id path uint true "Watchlist Entry ID"
+// @Param entry body dtos.WatchlistEntryPayload true "Watchlist entry"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.WatchlistEntry}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format, request payload, license plate, category or action"
+// @Failure 404 {object} dtos.ErrorResponse "Watchlist entry not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /watchlist-entries/{id} [put]
+func (wc *WatchlistController) UpdateWatchlistEntryHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid watchlist entry ID format")
+		return
+	}
+
+	var payload dtos.WatchlistEntryPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	entry, err := wc.watchlistService.UpdateWatchlistEntry(uint(id), payload)
+	if err != nil {
+		sendWatchlistEntryError(c, err, "Failed to update watchlist entry: ")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Watchlist entry updated successfully.", entry)
+}
+
+// DeleteWatchlistEntryHandler godoc
+// @Summary Remove a plate from the watchlist
+// @Description Deletes a watchlist entry. Alerts already raised for it are kept.
+// @Tags Watchlist
+// @Produce json
+// @Param id path uint true "Watchlist Entry ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Watchlist entry not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /watchlist-entries/{id} [delete]
+func (wc *WatchlistController) DeleteWatchlistEntryHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid watchlist entry ID format")
+		return
+	}
+
+	if err := wc.watchlistService.DeleteWatchlistEntry(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			dtos.SendErrorResponse(c, http.StatusNotFound, err.Error())
+		} else {
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to delete watchlist entry: "+err.Error())
+		}
+		return
+	}
+	dtos.SendSuccessResponse(c, http.StatusOK, "Watchlist entry deleted successfully")
+}
+
+// sendWatchlistEntryError 將新增或更新監控名單項目時的錯誤轉換為對應的 HTTP 狀態碼
+func sendWatchlistEntryError(c *gin.Context, err error, failurePrefix string) {
+	errMsg := err.Error()
+	switch {
+	case strings.HasPrefix(errMsg, "invalid_watchlist_entry:"), strings.HasPrefix(errMsg, "invalid_license_plate:"):
+		dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+	case strings.Contains(errMsg, "not found"):
+		dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+	default:
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, failurePrefix+errMsg)
+	}
+}
+
+// GetWatchlistAlertsHandler godoc
+// @Summary List watchlist alerts
+// @Description Lists the alerts raised when a watchlisted plate entered, was denied entry or exited, newest first. Each alert keeps the watchlist entry's category, action and reason at the time, and how the plate matched (Exact, Variant or Fuzzy).
+// @Tags Watchlist
+// @Produce json
+// @Param parkingLotId query int false "Only include this parking lot"
+// @Param category query string false "Only include this category: Stolen, Banned, VIP or Other"
+// @Param acknowledged query bool false "Only include acknowledged (true) or unacknowledged (false) alerts"
+// @Param limit query int false "Limit number of alerts returned" default(10)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {object} dtos.SuccessResponseWithData{data=[]models.WatchlistAlert}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid parking lot ID, category or acknowledged"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /watchlist-alerts [get]
+func (wc *WatchlistController) GetWatchlistAlertsHandler(c *gin.Context) {
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+	category := c.Query("category")
+	if category != "" && !models.IsValidWatchlistCategory(category) {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid category: must be one of Stolen, Banned, VIP, Other")
+		return
+	}
+	var acknowledged *bool
+	if acknowledgedStr := c.Query("acknowledged"); acknowledgedStr != "" {
+		value, err := strconv.ParseBool(acknowledgedStr)
+		if err != nil {
+			dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid acknowledged: must be true or false")
+			return
+		}
+		acknowledged = &value
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	alerts, err := wc.watchlistService.GetWatchlistAlerts(parkingLotID, category, acknowledged, limit, offset)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get watchlist alerts: "+err.Error())
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Watchlist alerts retrieved successfully.", alerts)
+}
+
+// GetWatchlistAlertByIDHandler godoc
+// @Summary Get a watchlist alert by ID
+// @Description Retrieves a single watchlist alert.
+// @Tags Watchlist
+// @Produce json
+// @Param id path uint true "Watchlist Alert ID"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.WatchlistAlert}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} dtos.ErrorResponse "Watchlist alert not found"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /watchlist-alerts/{id} [get]
+func (wc *WatchlistController) GetWatchlistAlertByIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid watchlist alert ID format")
+		return
+	}
+
+	alert, err := wc.watchlistService.GetWatchlistAlertByID(uint(id))
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get watchlist alert: "+err.Error())
+		return
+	}
+	if alert == nil {
+		dtos.SendErrorResponse(c, http.StatusNotFound, "Watchlist alert not found")
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Watchlist alert retrieved successfully.", alert)
+}
+
+// AcknowledgeWatchlistAlertHandler godoc
+// @Summary Acknowledge a watchlist alert
+// @Description Records that an operator has seen and handled a watchlist alert. Consoles subscribed to the alert stream receive an "acknowledged" event.
+// @Tags Watchlist
+// @Accept json
+// @Produce json
+// @Param id path uint true "Watchlist Alert ID"
+// @Param acknowledgement body dtos.AcknowledgeWatchlistAlertPayload true "Operator and note"
+// @Success 200 {object} dtos.SuccessResponseWithData{data=models.WatchlistAlert}
+// @Failure 400 {object} dtos.ErrorResponse "Invalid ID format or payload"
+// @Failure 404 {object} dtos.ErrorResponse "Watchlist alert not found"
+// @Failure 409 {object} dtos.ErrorResponse "Watchlist alert already acknowledged"
+// @Failure 500 {object} dtos.ErrorResponse "Internal server error"
+// @Router /watchlist-alerts/{id}/acknowledge [post]
+func (wc *WatchlistController) AcknowledgeWatchlistAlertHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid watchlist alert ID format")
+		return
+	}
+
+	var payload dtos.AcknowledgeWatchlistAlertPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	alert, err := wc.watchlistService.AcknowledgeWatchlistAlert(uint(id), payload)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errMsg, "invalid_acknowledgement:"):
+			dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+		case strings.HasPrefix(errMsg, "alert_acknowledged:"):
+			dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+		case strings.Contains(errMsg, "not found"):
+			dtos.SendErrorResponse(c, http.StatusNotFound, errMsg)
+		default:
+			dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to acknowledge watchlist alert: "+errMsg)
+		}
+		return
+	}
+	dtos.SendSuccessResponseWithData(c, http.StatusOK, "Watchlist alert acknowledged successfully.", alert)
+}
+
+// StreamWatchlistAlertsHandler godoc
+// @Summary Stream watchlist alerts to an operator console
+// @Description Opens a Server-Sent Events stream. An "alert" event carrying the models.WatchlistAlert JSON is sent for each new alert, and an "acknowledged" event when an alert is acknowledged. A comment line is sent periodically to keep the connection open. Alerts raised while the console is disconnected are not replayed; list unacknowledged alerts after reconnecting.
+// @Tags Watchlist
+// @Produce text/event-stream
+// @Param parkingLotId query int false "Only stream alerts of this parking lot"
+// @Success 200 {string} string "Server-Sent Events stream"
+// @Failure 400 {object} dtos.ErrorResponse "Invalid parking lot ID"
+// @Router /watchlist-alerts/stream [get]
+func (wc *WatchlistController) StreamWatchlistAlertsHandler(c *gin.Context) {
+	parkingLotID, err := parseParkingLotIDParameter(c)
+	if err != nil {
+		dtos.SendErrorResponse(c, http.StatusBadRequest, "Invalid parkingLotId: "+err.Error())
+		return
+	}
+
+	events, unsubscribe := wc.watchlistService.SubscribeAlerts(parkingLotID)
+	defer unsubscribe()
+	heartbeat := time.NewTicker(configs.WatchlistAlertStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// 避免反向代理暫存串流內容
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Name, event.Alert)
+			return true
+		case <-heartbeat.C:
+			_, err := fmt.Fprintf(w, ": heartbeat %s\n\n", time.Now().Format(time.RFC3339))
+			return err == nil
+		}
+	})
+}
@@ -24,35 +24,33 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/parking-records": {
-            "get": {
-                "description": "Get a list of all parking records, with pagination",
+        "/charging-sessions": {
+            "post": {
+                "description": "Called by a charger when it starts charging a vehicle in the lot, identified by parkingRecordId or by the license plate of its active parking record. meterWh is the charger's cumulative energy meter in watt-hours. Energy delivered is billed per kWh, and once charging stops, the time the vehicle keeps occupying the charger past the idle grace period is billed per minute; both appear as separate line items of the parking payment.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "Charging"
                 ],
-                "summary": "Get all parking records",
+                "summary": "Start charging a parked vehicle",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Limit number of parking records returned",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Offset for pagination",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Charging Information",
+                        "name": "charging",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StartChargingPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -62,50 +60,62 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.ParkingRecord"
-                                            }
+                                            "$ref": "#/definitions/models.ChargingSession"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking record not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Charger busy, vehicle already charging, already paid or already exited",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Add a new parking record to the system. This is a general endpoint, for specific entry/exit events, use /parking-records/entry and /parking-records/exit.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/charging-sessions/{id}": {
+            "get": {
+                "description": "Retrieves a charging session with all of its meter readings.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "Charging"
                 ],
-                "summary": "Create a new parking record",
+                "summary": "Get a charging session by ID",
                 "parameters": [
                     {
-                        "description": "Parking Record Information",
-                        "name": "parking_record_info",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ParkingRecord"
-                        }
+                        "type": "integer",
+                        "description": "Charging Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -115,7 +125,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.ParkingRecord"
+                                            "$ref": "#/definitions/models.ChargingSession"
                                         }
                                     }
                                 }
@@ -123,13 +133,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Charging session not found",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -137,37 +153,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking-records/entry": {
+        "/charging-sessions/{id}/meter-readings": {
             "post": {
-                "description": "Records when a vehicle enters the parking lot, accepting license plate and an optional image file.",
+                "description": "Called by a charger while charging to report its cumulative energy meter. Readings cannot go backwards. A reading reported after the parking record was prepared for payment clears the prepared fee, so the record must be prepared again.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "Charging"
                 ],
-                "summary": "Record a vehicle entry event",
+                "summary": "Report a meter reading of an active charging session",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Vehicle License Plate",
-                        "name": "licensePlate",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "Charging Session ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "Optional image of the vehicle/license plate",
-                        "name": "image",
-                        "in": "formData"
+                        "description": "Meter Reading",
+                        "name": "reading",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MeterReadingPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -177,7 +196,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.ParkingRecord"
+                                            "$ref": "#/definitions/models.ChargingSession"
                                         }
                                     }
                                 }
@@ -185,19 +204,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid ID format, payload or meter reading",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Charging session not found",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "Conflict",
+                        "description": "Charging session already stopped",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -205,9 +230,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking-records/exit": {
+        "/charging-sessions/{id}/stop": {
             "post": {
-                "description": "Records when a vehicle exits the parking lot. Checks for payment status.",
+                "description": "Called by a charger when charging ends, with the final meter reading. Idle fees start once the idle grace period after this time has passed and the vehicle is still parked. Charging sessions still active when the vehicle exits are stopped at their last reading.",
                 "consumes": [
                     "application/json"
                 ],
@@ -215,17 +240,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "Charging"
                 ],
-                "summary": "Record a vehicle exit event",
+                "summary": "Stop a charging session",
                 "parameters": [
                     {
-                        "description": "Vehicle Exit Information (License Plate Only)",
-                        "name": "exit_info",
+                        "type": "integer",
+                        "description": "Charging Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Final Meter Reading",
+                        "name": "reading",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.SimpleEntryPayload"
+                            "$ref": "#/definitions/dtos.MeterReadingPayload"
                         }
                     }
                 ],
@@ -241,7 +273,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.ParkingRecord"
+                                            "$ref": "#/definitions/models.ChargingSession"
                                         }
                                     }
                                 }
@@ -249,25 +281,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid ID format, payload or meter reading",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
-                    "402": {
-                        "description": "Payment Required",
+                    "404": {
+                        "description": "Charging session not found",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ErrorResponseWithRecord"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Charging session already stopped",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -275,25 +307,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking-records/license/{licensePlate}": {
+        "/merchants": {
             "get": {
-                "description": "Get all parking records associated with a specific License Plate",
+                "description": "Retrieves all registered merchants.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
-                ],
-                "summary": "Get parking records by License Plate",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "License Plate",
-                        "name": "licensePlate",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Merchants"
                 ],
+                "summary": "Get all merchants",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -308,7 +331,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/models.ParkingRecord"
+                                                "$ref": "#/definitions/models.Merchant"
                                             }
                                         }
                                     }
@@ -316,43 +339,40 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.ErrorResponse"
-                        }
-                    },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/parking-records/license/{licensePlate}/latest": {
-            "get": {
-                "description": "Get the most recent parking record for a specific License Plate",
+            },
+            "post": {
+                "description": "Registers a shop that can issue parking validation codes to its customers.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "Merchants"
                 ],
-                "summary": "Get the latest parking record by License Plate",
+                "summary": "Register a merchant",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "License Plate",
-                        "name": "licensePlate",
-                        "in": "path",
-                        "required": true
+                        "description": "Merchant Information",
+                        "name": "merchant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateMerchantPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -362,7 +382,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.ParkingRecord"
+                                            "$ref": "#/definitions/models.Merchant"
                                         }
                                     }
                                 }
@@ -370,19 +390,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                        "description": "Invalid request payload",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -390,22 +404,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking-records/search/license": {
+        "/merchants/{id}": {
             "get": {
-                "description": "Search all parking records by a partial or full License Plate (case-insensitive)",
+                "description": "Retrieves a single merchant.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "Merchants"
                 ],
-                "summary": "Search parking records by License Plate (fuzzy search)",
+                "summary": "Get a merchant by ID",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "License Plate Query",
-                        "name": "q",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -421,10 +435,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.ParkingRecord"
-                                            }
+                                            "$ref": "#/definitions/models.Merchant"
                                         }
                                     }
                                 }
@@ -432,13 +443,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Merchant not found",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -446,20 +463,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking-records/{id}": {
+        "/merchants/{id}/validation-codes": {
             "get": {
-                "description": "Get details of a parking record by its ID",
+                "description": "Retrieves all validation codes issued by a merchant, including their usage counts.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "Merchants"
                 ],
-                "summary": "Get a parking record by ID",
+                "summary": "Get validation codes of a merchant",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Parking Record ID",
+                        "description": "Merchant ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -477,7 +494,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.ParkingRecord"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ValidationCode"
+                                            }
                                         }
                                     }
                                 }
@@ -485,27 +505,21 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                        "description": "Invalid ID format",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update details of an existing parking record by its ID. Can be used for manual adjustments.",
+            "post": {
+                "description": "Issues a parking validation code for a merchant: a fixed amount off, a percentage off, the first N minutes free, or a flat fee. Codes can be limited in number of uses and expire.",
                 "consumes": [
                     "application/json"
                 ],
@@ -513,91 +527,115 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "Merchants"
                 ],
-                "summary": "Update an existing parking record",
+                "summary": "Issue a validation code",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Parking Record ID",
+                        "description": "Merchant ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Parking Record Update Information",
-                        "name": "parking_record_update",
+                        "description": "Validation Code Details",
+                        "name": "validationCode",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ParkingRecord"
+                            "$ref": "#/definitions/dtos.CreateValidationCodePayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dtos.SuccessResponse"
-                        }
-                    },
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ValidationCode"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request payload or discount settings",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Merchant not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Code already exists or merchant inactive",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Remove a parking record from the system by its ID",
+            }
+        },
+        "/parking-lots": {
+            "get": {
+                "description": "Retrieves all parking lots with their sensors.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
-                ],
-                "summary": "Delete a parking record by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Parking Record ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "ParkingLots"
                 ],
+                "summary": "Get all parking lots",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.ErrorResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ParkingLot"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/parking-records/{id}/pay": {
+            },
             "post": {
-                "description": "Marks a parking record as paid and ideally creates a transaction record.",
+                "description": "Creates a parking lot with its own capacity and rate calendar for cars; vehicleClasses opens it to motorcycles and large vehicles, each with its own capacity and rate calendar. reservedSpots of the car capacity are held for permit holders; other cars are refused once the rest is taken. Entry and exit sensors are registered separately.",
                 "consumes": [
                     "application/json"
                 ],
@@ -605,30 +643,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Parking Records"
+                    "ParkingLots"
                 ],
-                "summary": "Pay for a parking record",
+                "summary": "Create a parking lot",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Parking Record ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Payment Details",
-                        "name": "paymentPayload",
+                        "description": "Parking Lot Information",
+                        "name": "parkingLot",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.ParkingPaymentPayload"
+                            "$ref": "#/definitions/dtos.ParkingLotPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Payment successful",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -638,7 +669,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dtos.ParkingRecordWithTransactionResponse"
+                                            "$ref": "#/definitions/models.ParkingLot"
                                         }
                                     }
                                 }
@@ -646,19 +677,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request (e.g., validation error, amount mismatch)",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.ErrorResponse"
-                        }
-                    },
-                    "402": {
-                        "description": "Payment required conditions not met (e.g., fee not calculated, already paid, vehicle exited)",
+                        "description": "Invalid request payload or unknown rate calendar",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Parking record not found",
+                    "409": {
+                        "description": "A parking lot with the same name exists",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -672,20 +697,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking-records/{id}/prepare-payment": {
-            "post": {
-                "description": "Calculates and stores the parking fee if not already calculated for an active parking record. Returns the record with payment details.",
+        "/parking-lots/{id}": {
+            "get": {
+                "description": "Retrieves a single parking lot with its sensors.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "ParkingLots"
                 ],
-                "summary": "Prepare a parking record for payment by calculating/retrieving its fee",
+                "summary": "Get a parking lot by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Parking Record ID",
+                        "description": "Parking Lot ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -693,7 +718,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully calculated/retrieved fee, record ready for payment",
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -703,7 +728,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.ParkingRecord"
+                                            "$ref": "#/definitions/models.ParkingLot"
                                         }
                                     }
                                 }
@@ -711,13 +736,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid Record ID or record already exited/paid",
+                        "description": "Invalid ID format",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Parking Record not found",
+                        "description": "Parking lot not found",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -729,11 +754,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/parking-records/{id}/verify-license-plate": {
-            "patch": {
-                "description": "Allows a user to correct or verify the license plate for an existing parking record.",
+            },
+            "put": {
+                "description": "Updates a parking lot's name, address, capacity, reserved spots, rate calendar, vehicle classes and whether it accepts vehicles. A given vehicleClasses list replaces the current one. A new rate calendar applies to every fee calculated afterwards, including vehicles already parked.",
                 "consumes": [
                     "application/json"
                 ],
@@ -741,24 +764,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "parking_records"
+                    "ParkingLots"
                 ],
-                "summary": "Update user-verified license plate for a parking record",
+                "summary": "Update a parking lot",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Parking Record ID",
+                        "description": "Parking Lot ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Verified License Plate Information",
-                        "name": "license_plate_info",
+                        "description": "Parking Lot Information",
+                        "name": "parkingLot",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.VerifyLicensePlatePayload"
+                            "$ref": "#/definitions/dtos.ParkingLotPayload"
                         }
                     }
                 ],
@@ -774,7 +797,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.ParkingRecord"
+                                            "$ref": "#/definitions/models.ParkingLot"
                                         }
                                     }
                                 }
@@ -782,19 +805,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request payload or unknown rate calendar",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Parking lot not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A parking lot with the same name exists",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -802,33 +831,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports/operations/image-attachment-rate": {
-            "get": {
-                "description": "Calculates the percentage of vehicle entries that have an associated image.",
+        "/parking-lots/{id}/sensors": {
+            "post": {
+                "description": "Registers an entry or exit sensor. Entry and exit requests that carry the sensorId are attributed to the sensor's parking lot. An entry sensor with a vehicleClass, such as on a motorcycle lane, gives that class to vehicles entering without one.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reports"
+                    "ParkingLots"
                 ],
-                "summary": "Get the rate of parking entries with images",
+                "summary": "Register a sensor of a parking lot",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)",
-                        "name": "startTime",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Parking Lot ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)",
-                        "name": "endTime",
-                        "in": "query"
+                        "description": "Sensor Information",
+                        "name": "sensor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ParkingLotSensorPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -838,7 +874,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dtos.ImageAttachmentRateResponse"
+                                            "$ref": "#/definitions/models.ParkingLotSensor"
                                         }
                                     }
                                 }
@@ -846,47 +882,21 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid time format",
+                        "description": "Invalid request payload",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Parking lot not found",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/reports/parking-lot/available-spots": {
-            "get": {
-                "description": "Retrieves the total capacity, occupied spots, and available spots in the parking lot.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "reports"
-                ],
-                "summary": "Get available parking spots",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "409": {
+                        "description": "Sensor already registered",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/dtos.AvailableSpotsResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
@@ -898,51 +908,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports/revenue/total": {
-            "get": {
-                "description": "Retrieves the total revenue collected from parking fees.",
+        "/parking-lots/{id}/sensors/{sensorId}": {
+            "delete": {
+                "description": "Removes a registered entry or exit sensor from a parking lot.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reports"
+                    "ParkingLots"
                 ],
-                "summary": "Get total revenue from parking fees within a time range",
+                "summary": "Remove a sensor of a parking lot",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)",
-                        "name": "startTime",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Parking Lot ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)",
-                        "name": "endTime",
-                        "in": "query"
+                        "description": "Sensor ID",
+                        "name": "sensorId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/dtos.TotalRevenueResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/dtos.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid time format",
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sensor not found in the parking lot",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -956,28 +962,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports/traffic/total-count": {
+        "/parking-lots/{id}/zones": {
             "get": {
-                "description": "Retrieves the total number of parking events (vehicle entries).",
+                "description": "Retrieves the zones of a parking lot ordered by level, each with its spots and their latest reported status.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reports"
+                    "ParkingLots"
                 ],
-                "summary": "Get total parking count within a time range",
+                "summary": "Get the zones of a parking lot",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)",
-                        "name": "startTime",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)",
-                        "name": "endTime",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Parking Lot ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -992,7 +993,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dtos.TotalParkingCountResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Zone"
+                                            }
                                         }
                                     }
                                 }
@@ -1000,58 +1004,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid time format",
+                        "description": "Invalid ID format",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Parking lot not found",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/transactions": {
-            "get": {
-                "description": "Get a list of all transactions, with pagination",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "transactions"
-                ],
-                "summary": "Get all transactions",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Limit number of transactions returned",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Offset for pagination",
-                        "name": "offset",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Transaction"
-                            }
-                        }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -1059,7 +1024,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Add a new transaction to the system",
+                "description": "Creates a zone such as a floor or a motorcycle area. Spots with their spot sensors are added to the zone separately.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1067,17 +1032,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "ParkingLots"
                 ],
-                "summary": "Create a new transaction",
+                "summary": "Create a zone of a parking lot",
                 "parameters": [
                     {
-                        "description": "Transaction Information",
-                        "name": "transaction_info",
+                        "type": "integer",
+                        "description": "Parking Lot ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Zone Information",
+                        "name": "zone",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/dtos.ZonePayload"
                         }
                     }
                 ],
@@ -1085,17 +1057,41 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Zone"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking lot not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A zone with the same name exists in the parking lot",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
@@ -1103,98 +1099,145 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/parking/{parkingRecordID}": {
-            "get": {
-                "description": "Get all transactions associated with a specific ParkingRecord ID",
+        "/parking-lots/{id}/zones/{zoneId}": {
+            "put": {
+                "description": "Updates a zone's name, level and description.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "ParkingLots"
                 ],
-                "summary": "Get transactions by ParkingRecord ID",
+                "summary": "Update a zone of a parking lot",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Parking Record ID",
-                        "name": "parkingRecordID",
+                        "description": "Parking Lot ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Zone ID",
+                        "name": "zoneId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Zone Information",
+                        "name": "zone",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ZonePayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Transaction"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Zone"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Zone not found in the parking lot",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A zone with the same name exists in the parking lot",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/transactions/{id}": {
-            "get": {
-                "description": "Get details of a transaction by its ID",
+            },
+            "delete": {
+                "description": "Deletes a zone with all of its spots.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "ParkingLots"
                 ],
-                "summary": "Get a transaction by ID",
+                "summary": "Delete a zone of a parking lot",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
+                        "description": "Parking Lot ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Zone ID",
+                        "name": "zoneId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/dtos.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid ID format",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Zone not found in the parking lot",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update details of an existing transaction by its ID",
+            }
+        },
+        "/parking-lots/{id}/zones/{zoneId}/spots": {
+            "post": {
+                "description": "Adds a spot with the spot sensor that reports whether it is occupied. New spots count as free until their sensor reports.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1202,64 +1245,111 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "ParkingLots"
                 ],
-                "summary": "Update an existing transaction",
+                "summary": "Add a spot to a zone",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
+                        "description": "Parking Lot ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Transaction Update Information",
-                        "name": "transaction_update",
+                        "type": "integer",
+                        "description": "Zone ID",
+                        "name": "zoneId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Spot Information",
+                        "name": "spot",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/dtos.SpotPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dtos.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Spot"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Zone not found in the parking lot",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Spot code or spot sensor already registered",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/parking-lots/{id}/zones/{zoneId}/spots/{spotId}": {
             "delete": {
-                "description": "Remove a transaction from the system by its ID",
+                "description": "Removes a spot and its spot sensor from a zone.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "ParkingLots"
                 ],
-                "summary": "Delete a transaction by ID",
+                "summary": "Remove a spot from a zone",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
+                        "description": "Parking Lot ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Zone ID",
+                        "name": "zoneId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Spot ID",
+                        "name": "spotId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1270,319 +1360,7247 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Spot not found in the zone",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dtos.AvailableSpotsResponse": {
-            "type": "object",
-            "properties": {
-                "available_spots": {
-                    "type": "integer"
-                },
-                "occupied_spots": {
-                    "type": "integer"
-                },
-                "total_capacity": {
-                    "type": "integer"
-                }
-            }
         },
-        "dtos.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "details": {
-                    "type": "string",
-                    "example": "Optional additional details"
-                },
-                "error": {
-                    "type": "string",
-                    "example": "Detailed error message"
-                }
-            }
-        },
-        "dtos.ErrorResponseWithRecord": {
-            "type": "object",
-            "properties": {
+        "/parking-records": {
+            "get": {
+                "description": "Get a list of all parking records, with pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Get all parking records",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of parking records returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ParkingRecord"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new parking record to the system. This is a general endpoint, for specific entry/exit events, use /parking-records/entry and /parking-records/exit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Create a new parking record",
+                "parameters": [
+                    {
+                        "description": "Parking Record Information",
+                        "name": "parking_record_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingRecord"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ParkingRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/entry": {
+            "post": {
+                "description": "Records a vehicle entering a parking lot, with an optional image of the vehicle. The license plate is stored in the canonical Taiwanese format, and the lot is chosen by parkingLotId or a registered entry sensorId. Entry is refused when the lot is full for the vehicle's class unless an operator overrides capacity. A plate that still has an open record has it closed as Abandoned, and watchlist matches raise alerts or deny entry.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Record a vehicle entry event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vehicle License Plate",
+                        "name": "licensePlate",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Parking Lot ID",
+                        "name": "parkingLotId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered entry sensor ID",
+                        "name": "sensorId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Vehicle class: Car, Motorcycle or Large (defaults to the entry sensor's class, then Car)",
+                        "name": "vehicleClass",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Admit the vehicle even if the lot is full (operator override)",
+                        "name": "overrideCapacity",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operator overriding capacity (required with overrideCapacity)",
+                        "name": "overrideBy",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Why capacity is overridden (required with overrideCapacity)",
+                        "name": "overrideReason",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "OCR confidence of the license plate read, from 0.0 to 1.0; reads below the threshold are queued for manual review",
+                        "name": "ocrConfidence",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Other plates the OCR considered, most likely first",
+                        "name": "ocrAlternatives",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Optional image of the vehicle/license plate (JPEG, PNG, GIF or WebP)",
+                        "name": "image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for safely retrying this request; retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ParkingRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, parking lot not specified, sensor not an entry sensor of the lot, override without operator and reason, or unsupported image type or dimensions",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Plate on the watchlist with the DenyEntry action (error starts with entry_denied)",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking lot or sensor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Duplicate read of a vehicle that just entered, parking lot inactive, or vehicle class not accepted by the lot",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Image file too large",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Parking lot full (error starts with parking_lot_full)",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/exit": {
+            "post": {
+                "description": "Records when a vehicle exits a parking lot. The license plate is matched in the canonical Taiwanese format, and the plate as sent is kept in ExitRawLicensePlate. The lot is chosen by parkingLotId or by a registered exit sensorId (both optional when only one lot exists), and the vehicle must be parked in that lot. Checks for payment status. A paid record that leaves after the exit grace period must pay the overstay amount (402) before the gate opens. A record fully covered by merchant validations exits without payment. Charging sessions still active on exit are stopped at their last meter reading. With fuzzyMatch, a plate without an exact active match is matched by similarity (ignoring O/0, I/1 and B/8 confusions): a single high-confidence candidate is used automatically, otherwise 300 lists the ranked candidates with plate, entry time and image URLs, and the gate operator confirms one by repeating the request with its parkingRecordId. ExitMatch on the record tells how it was matched. A matched record whose exit plate or recorded plate is on the watchlist raises an Exit alert on /watchlist-alerts, once per record.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Record a vehicle exit event",
+                "parameters": [
+                    {
+                        "description": "Vehicle Exit Information (License Plate Only)",
+                        "name": "exit_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SimpleEntryPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for safely retrying this request; retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ParkingRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "300": {
+                        "description": "No exact match; the gate operator must confirm one of the similar active records",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ExitCandidatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, parking lot not specified, or sensor not an exit sensor of the lot",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponseWithRecord"
+                        }
+                    },
+                    "404": {
+                        "description": "No active parking record in the lot, or parking lot or sensor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/license/{licensePlate}": {
+            "get": {
+                "description": "Get all parking records associated with a specific License Plate",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Get parking records by License Plate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "License Plate",
+                        "name": "licensePlate",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ParkingRecord"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/license/{licensePlate}/latest": {
+            "get": {
+                "description": "Get the most recent parking record for a specific License Plate",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Get the latest parking record by License Plate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "License Plate",
+                        "name": "licensePlate",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ParkingRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/license/{licensePlate}/quote": {
+            "get": {
+                "description": "Returns an itemized quote for the active parking record of a License Plate without modifying it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Get a read-only fee quote by License Plate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "License Plate",
+                        "name": "licensePlate",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.FeeQuote"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No active parking record for this license plate",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/search/license": {
+            "get": {
+                "description": "Search all parking records by a partial or full License Plate, ignoring case and separators and treating O/0, I/1 and B/8 as the same character",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Search parking records by License Plate (fuzzy search)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "License Plate Query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ParkingRecord"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/stale": {
+            "get": {
+                "description": "Lists open parking records flagged by the background sweeper because they have been open longer than the configured maximum age (STALE_SESSION_MAX_AGE, 72h by default), oldest entry first. These usually belong to vehicles that left without triggering the exit sensor; staff can close them with /parking-records/{id}/abandon.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "List stale parking records",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of records returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ParkingRecord"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parking lot ID",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}": {
+            "get": {
+                "description": "Get details of a parking record by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Get a parking record by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ParkingRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update details of an existing parking record by its ID. Can be used for manual adjustments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Update an existing parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parking Record Update Information",
+                        "name": "parking_record_update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingRecord"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a parking record from the system by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Delete a parking record by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/abandon": {
+            "post": {
+                "description": "Closes an open parking record with SessionStatus Abandoned, freeing its spot. The operator and reason are stored in AbandonReason, active charging sessions are stopped at their last meter reading, and any unpaid fee stays on the record.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Close a parking record whose vehicle left without exiting",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Operator and reason",
+                        "name": "abandon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AbandonParkingRecordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ParkingRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format or payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking record not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Parking record already exited",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/charging-sessions": {
+            "get": {
+                "description": "Retrieves all charging sessions of a parking record ordered by start time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Charging"
+                ],
+                "summary": "List the charging sessions of a parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ChargingSession"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/images/{kind}": {
+            "get": {
+                "description": "Streams the entry image of a parking record: the original upload, or a JPEG thumbnail generated once at upload (at most 320 pixels on the long side). Images whose format cannot be thumbnailed (WebP) return the original for the thumbnail. Parking records link here through imageUrl and thumbnailUrl. The response carries Content-Type, ETag, Last-Modified and Cache-Control; a request with a matching If-None-Match returns 304, and an Accept header that does not allow the image's type returns 406.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/webp"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Get the entry image of a parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "original or thumbnail",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Cached copy is current"
+                    },
+                    "400": {
+                        "description": "Invalid ID format or image kind",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking record or image not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "Image type not acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/pay": {
+            "post": {
+                "description": "Creates a payment intent for the amount due with the payment provider selected by paymentMethod. The amount due is the prepared fee less any reservation deposit already credited to the record; a fee prepared longer ago than the quote validity must be prepared again. The exit grace period starts when the paid fee was calculated. Providers that authorize immediately (e.g. Cash) are captured right away: the parking record is marked as paid and a transaction record is created. Other providers return 202 with a pending payment intent; the record is marked as paid only after the provider's signed callback confirms the payment. For a paid record that exceeded the exit grace period, accepts a supplementary overstay payment. The payment is itemized into parking, EV energy and idle fee line items, each only for the part not billed by an earlier payment; a record that is still charging cannot be paid until the charging session is stopped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Parking Records"
+                ],
+                "summary": "Pay for a parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment Details",
+                        "name": "paymentPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ParkingPaymentPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for safely retrying this request; retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.ParkingRecordWithTransactionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Payment intent created, waiting for provider confirmation",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PaymentIntent"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request (e.g., validation error, unsupported payment method)",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment required conditions not met (e.g., fee not calculated or expired, amount mismatch, already paid, no payment due, vehicle exited, payment declined)",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking record not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key reused with a different request, or the vehicle is still charging",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/payment-intents": {
+            "get": {
+                "description": "Retrieves all payment intents created for a parking record, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get payment intents of a parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PaymentIntent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/prepare-payment": {
+            "post": {
+                "description": "Calculates and stores the parking fee if not already calculated for an active parking record, net of applied merchant validations. Returns the record with payment details, the fee breakdown per rate band and calendar day, the validation discounts, the EV charging charges, and the amount still due after any reservation deposit. Meter readings reported after preparing require preparing again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Prepare a parking record for payment by calculating/retrieving its fee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully calculated/retrieved fee, record ready for payment",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.ParkingRecordWithFeeBreakdownResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Record ID or record already exited/paid",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/quote": {
+            "get": {
+                "description": "Returns an itemized quote (duration, billable units, per-band charges, caps, discounts, EV energy and idle fees, total and expiry) for the parking record without modifying it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Get a read-only fee quote for a parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.FeeQuote"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Record ID",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking Record not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/validations": {
+            "get": {
+                "description": "Retrieves the merchant validations applied to a parking record, in the order they were applied.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchants"
+                ],
+                "summary": "Get validations applied to a parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ParkingValidation"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Applies a merchant validation code to an unpaid parking record that is still in the lot. The discount is reflected in the fee quote and the amount due; if the fee was already prepared, prepare-payment must be called again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Merchants"
+                ],
+                "summary": "Apply a validation code to a parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Validation Code",
+                        "name": "validation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ApplyValidationPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ParkingValidation"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or inactive code",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking record or validation code not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Code expired, exhausted, already applied, or record already paid/exited",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking-records/{id}/verify-license-plate": {
+            "patch": {
+                "description": "Allows a user to correct or verify the license plate for an existing parking record. The plate is stored in the canonical Taiwanese format.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking_records"
+                ],
+                "summary": "Update user-verified license plate for a parking record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Verified License Plate Information",
+                        "name": "license_plate_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.VerifyLicensePlatePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ParkingRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment-intents/{id}": {
+            "get": {
+                "description": "Retrieves a payment intent so clients can poll the status of an asynchronous payment (Pending, Authorized, Captured, Failed, Expired).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get a payment intent by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payment Intent ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PaymentIntent"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment intent not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/callbacks/{provider}": {
+            "post": {
+                "description": "Receives an asynchronous notification from a payment provider. The request body must carry a valid provider signature. Authorized payments are captured and the parking record is marked as paid; replayed notifications are acknowledged without being processed again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Receive a payment provider callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment provider name (e.g., Simulator)",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Callback processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PaymentIntent"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed callback or provider without callbacks",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown provider or payment intent",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/permits": {
+            "get": {
+                "description": "Get a list of all parking permits with their license plates, with pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permits"
+                ],
+                "summary": "Get all permits",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of permits returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Permit"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Issues a monthly or season-ticket permit for one or more license plates. Vehicles entering with an active permit are not charged for the part of their stay inside the permit's validity and daily allowed hours; equal allowed start and end times mean all day, and an end before the start means an overnight window.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permits"
+                ],
+                "summary": "Issue a parking permit",
+                "parameters": [
+                    {
+                        "description": "Permit Information",
+                        "name": "permit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PermitPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Permit"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or permit settings",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/permits/{id}": {
+            "get": {
+                "description": "Retrieves a single parking permit with its license plates.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permits"
+                ],
+                "summary": "Get a permit by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Permit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Permit"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Permit not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces a permit's holder, validity, allowed hours and license plates. Vehicles already in the lot are billed with the updated permit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permits"
+                ],
+                "summary": "Update a permit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Permit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Permit Information",
+                        "name": "permit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PermitPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Permit"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or permit settings",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Permit not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a parking permit. Vehicles already in the lot on this permit are billed at the regular rate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permits"
+                ],
+                "summary": "Delete a permit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Permit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Permit not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plate-reviews": {
+            "get": {
+                "description": "Lists the review queue of license plates read with low OCR confidence at entry, oldest first, with the OCR alternatives and the parking record (entry time and image URLs). Lists pending reviews unless another status is given.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plate Reviews"
+                ],
+                "summary": "List plate reviews",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Pending",
+                        "description": "Only include this status: Pending, Approved or Corrected",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include reviews completed by this reviewer",
+                        "name": "reviewedBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of reviews returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PlateReview"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parking lot ID or status",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plate-reviews/{id}": {
+            "get": {
+                "description": "Retrieves a single plate review with the OCR alternatives and its parking record.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plate Reviews"
+                ],
+                "summary": "Get a plate review by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Plate Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PlateReview"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Plate review not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plate-reviews/{id}/approve": {
+            "post": {
+                "description": "Confirms that the plate read by the OCR is correct. The plate is stored as the parking record's UserVerifiedLicensePlate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plate Reviews"
+                ],
+                "summary": "Approve the OCR read of a plate",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Plate Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reviewer",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ApprovePlateReviewPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PlateReview"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format or payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Plate review not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Plate review already completed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plate-reviews/{id}/correct": {
+            "post": {
+                "description": "Corrects the plate read by the OCR, usually to one of its alternatives. The corrected plate is stored in the canonical format as the parking record's UserVerifiedLicensePlate, so the vehicle is found by it at exit. A correction equal to the OCR read counts as an approval.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plate Reviews"
+                ],
+                "summary": "Correct the OCR read of a plate",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Plate Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reviewer and corrected plate",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CorrectPlateReviewPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PlateReview"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format, payload or license plate",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Plate review not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Plate review already completed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/merchants/subsidies": {
+            "get": {
+                "description": "Sums, per merchant, the validation discounts granted on paid parking records that entered within the time range, optionally in a single parking lot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get how much each merchant subsidised",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot (all lots when omitted)",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)",
+                        "name": "endTime",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.MerchantSubsidyReportResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid time format or parking lot ID",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/operations/image-attachment-rate": {
+            "get": {
+                "description": "Calculates the percentage of vehicle entries that have an associated image.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get the rate of parking entries with images",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot (all lots when omitted)",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)",
+                        "name": "endTime",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.ImageAttachmentRateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid time format or parking lot ID",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/operations/plate-review-throughput": {
+            "get": {
+                "description": "Reports the plate reviews queued and completed within a time range, the share corrected, the average time from queuing to review and, when both ends of the range are given, reviews per hour; also broken down by reviewer. The current backlog and its oldest item are included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get plate review throughput",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot (all lots when omitted)",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)",
+                        "name": "endTime",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.PlateReviewThroughputResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid time format or parking lot ID",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/parking-lot/available-spots": {
+            "get": {
+                "description": "Retrieves the total capacity, occupied spots, and available spots of each parking lot and summed over all lots. Spots are also broken down by vehicle class per lot and over all lots. Each lot also lists its zones with the spots free according to the spot sensors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get available parking spots",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot (all lots when omitted)",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this vehicle class: Car, Motorcycle or Large (all classes when omitted)",
+                        "name": "vehicleClass",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.AvailableSpotsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parking lot ID or vehicle class",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking lot not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/parking-lot/signboard": {
+            "get": {
+                "description": "Retrieves the lines shown on the entrance signboard, one per zone ordered by level (e.g. \"B2: 14 free\", or \"B2: FULL\"). A lot without zones shows a single line with the spots free for vehicles without a permit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get the signboard of a parking lot",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking lot ID (may be omitted when only one lot exists)",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.SignboardResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parking lot ID or parking lot not specified",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking lot not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/permits/expiring": {
+            "get": {
+                "description": "Lists permits expiring within the next withinDays days and permits that expired within the last withinDays days, so holders can be reminded to renew.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get permits expiring soon or recently expired",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Number of days to look ahead and back",
+                        "name": "withinDays",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.PermitExpiryReportResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid withinDays",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/revenue/total": {
+            "get": {
+                "description": "Retrieves the total revenue collected from parking fees and forfeited reservation deposits, net of refunds, grouped by vehicle class.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get total revenue from parking fees within a time range",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot (all lots when omitted)",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this vehicle class: Car, Motorcycle or Large (all classes when omitted)",
+                        "name": "vehicleClass",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)",
+                        "name": "endTime",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.TotalRevenueResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid time format, parking lot ID or vehicle class",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/traffic/total-count": {
+            "get": {
+                "description": "Retrieves the total number of parking events (vehicle entries), grouped by vehicle class.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get total parking count within a time range",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot (all lots when omitted)",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this vehicle class: Car, Motorcycle or Large (all classes when omitted)",
+                        "name": "vehicleClass",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time for the report (RFC3339 format, e.g., 2023-01-01T00:00:00Z)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time for the report (RFC3339 format, e.g., 2023-01-31T23:59:59Z)",
+                        "name": "endTime",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.TotalParkingCountResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid time format, parking lot ID or vehicle class",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations": {
+            "get": {
+                "description": "Lists reservations ordered by start time, optionally filtered by parking lot, license plate and status, with pagination.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "List reservations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this license plate",
+                        "name": "licensePlate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this status: Confirmed, Cancelled, Fulfilled or Expired",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of reservations returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Reservation"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parking lot ID or status",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Reserves a spot of the given vehicle class for a license plate and time window. The spot is held from shortly before the start time until the end time and is subtracted from the lot's availability meanwhile. When the vehicle enters around the start time, the new parking record is linked to the reservation; reservations not used by then expire. Giving paymentMethod prepays a deposit that is credited against the parking fee on arrival; providers that do not authorize immediately return a pending payment intent.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Reserve a parking spot",
+                "parameters": [
+                    {
+                        "description": "Reservation Information",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ReservationPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for safely retrying this request; retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.ReservationResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload, time window, vehicle class or payment method, or several parking lots exist and parkingLotId is missing",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Parking lot not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "No spots left to reserve, overlapping reservation for the plate, lot inactive or vehicle class not accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}": {
+            "get": {
+                "description": "Retrieves a single reservation with its status and deposit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Get a reservation by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Reservation"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/cancel": {
+            "post": {
+                "description": "Cancels a confirmed reservation and releases its held spot. A paid deposit is refunded in full when cancelled before the start time and forfeited afterwards. If the refund fails, the reservation stays cancelled and the error is returned so that staff can refund the deposit transaction manually.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Cancel a reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.CancelReservationResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Reservation is no longer confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Reservation cancelled but the deposit refund failed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/spot-sensors/{sensorId}/status": {
+            "post": {
+                "description": "Called by a spot sensor when its spot becomes occupied or free. Repeating the current status is harmless. When an occupied report carries licensePlate, the vehicle's active parking record in the same lot is linked to the spot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ParkingLots"
+                ],
+                "summary": "Report whether a spot is occupied",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spot Sensor ID",
+                        "name": "sensorId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Spot Status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SpotStatusPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.SpotStatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Spot sensor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "get": {
+                "description": "Get a list of all transactions, with pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get all transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of transactions returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Transaction"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new transaction to the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create a new transaction",
+                "parameters": [
+                    {
+                        "description": "Transaction Information",
+                        "name": "transaction_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for safely retrying this request; retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/parking/{parkingRecordID}": {
+            "get": {
+                "description": "Get all transactions associated with a specific ParkingRecord ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get transactions by ParkingRecord ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parking Record ID",
+                        "name": "parkingRecordID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Transaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}": {
+            "get": {
+                "description": "Get details of a transaction by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get a transaction by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update details of an existing transaction by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Update an existing transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transaction Update Information",
+                        "name": "transaction_update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a transaction from the system by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Delete a transaction by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/refund": {
+            "post": {
+                "description": "Fully or partially refunds a successful payment transaction. Creates a linked refund transaction with a negative amount and updates the parking record status when it is fully refunded. When the payment provider does not confirm the outcome, the refund stays Pending and is retried in the background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Refund a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Refund Information (omit amount for a full refund)",
+                        "name": "refund_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RefundTransactionPayload"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for safely retrying this request; retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created refund transaction",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "202": {
+                        "description": "The refund transaction, still Pending until the payment provider confirms it",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transaction already refunded or not refundable, or Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Payment provider rejected the refund",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles": {
+            "get": {
+                "description": "Lists vehicles, most recently seen first, with pagination. A vehicle is created automatically the first time its plate enters a lot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "List vehicles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only include plates containing this fragment (ignoring separators and O/0, I/1, B/8 confusions)",
+                        "name": "licensePlate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of vehicles returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Vehicle"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/license/{plate}": {
+            "get": {
+                "description": "Retrieves the profile of the vehicle with this plate. The plate is matched in the canonical Taiwanese format.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "Get a vehicle profile by license plate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "License plate",
+                        "name": "plate",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.VehicleProfileResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Vehicle not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}": {
+            "get": {
+                "description": "Retrieves a vehicle with the details kept by staff and statistics over all its visits: visit count, total spend (payments net of refunds), average dwell time of completed visits, and whether any visit exited without payment.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "Get a vehicle profile by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.VehicleProfileResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Vehicle not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the make, color and notes kept by staff about a vehicle. Omitted fields are cleared.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "Update a vehicle's details",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Vehicle details",
+                        "name": "vehicle",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.VehicleDetailsPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Vehicle"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format or payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Vehicle not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/visits": {
+            "get": {
+                "description": "Lists the parking records of a vehicle, newest entry first, with pagination.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "Get a vehicle's visit history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of visits returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ParkingRecord"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Vehicle not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist-alerts": {
+            "get": {
+                "description": "Lists the alerts raised when a watchlisted plate entered, was denied entry or exited, newest first. Each alert keeps the watchlist entry's category, action and reason at the time, and how the plate matched (Exact, Variant or Fuzzy).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "List watchlist alerts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include this parking lot",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this category: Stolen, Banned, VIP or Other",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only include acknowledged (true) or unacknowledged (false) alerts",
+                        "name": "acknowledged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of alerts returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.WatchlistAlert"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parking lot ID, category or acknowledged",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist-alerts/stream": {
+            "get": {
+                "description": "Opens a Server-Sent Events stream. An \"alert\" event carrying the models.WatchlistAlert JSON is sent for each new alert, and an \"acknowledged\" event when an alert is acknowledged. A comment line is sent periodically to keep the connection open. Alerts raised while the console is disconnected are not replayed; list unacknowledged alerts after reconnecting.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Stream watchlist alerts to an operator console",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only stream alerts of this parking lot",
+                        "name": "parkingLotId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Server-Sent Events stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parking lot ID",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist-alerts/{id}": {
+            "get": {
+                "description": "Retrieves a single watchlist alert.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Get a watchlist alert by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist Alert ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.WatchlistAlert"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist alert not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist-alerts/{id}/acknowledge": {
+            "post": {
+                "description": "Records that an operator has seen and handled a watchlist alert. Consoles subscribed to the alert stream receive an \"acknowledged\" event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Acknowledge a watchlist alert",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist Alert ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Operator and note",
+                        "name": "acknowledgement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AcknowledgeWatchlistAlertPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.WatchlistAlert"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format or payload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist alert not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Watchlist alert already acknowledged",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist-entries": {
+            "get": {
+                "description": "Lists watchlist entries, with pagination.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "List watchlist entries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only include this category: Stolen, Banned, VIP or Other",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this action: Alert, DenyEntry or Flag",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Exclude inactive and expired entries",
+                        "name": "activeOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit number of entries returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.WatchlistEntry"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid category, action or activeOnly",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a license plate to the watchlist. The plate is stored in the canonical format and checked at every entry and exit, including plates that differ only in look-alike characters (O/0, I/1, B/8) and similar plates. A match raises an alert; DenyEntry also turns the vehicle away at entry (not for merely similar plates) and Flag marks its parking record for staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Add a plate to the watchlist",
+                "parameters": [
+                    {
+                        "description": "Watchlist entry",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WatchlistEntryPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.WatchlistEntry"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload, license plate, category or action",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist-entries/{id}": {
+            "get": {
+                "description": "Retrieves a single watchlist entry.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Get a watchlist entry by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.WatchlistEntry"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces a watchlist entry's plate, category, action, reason, active flag and expiry. Alerts already raised keep the entry's details at the time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Update a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Watchlist entry",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WatchlistEntryPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.SuccessResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.WatchlistEntry"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format, request payload, license plate, category or action",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a watchlist entry. Alerts already raised for it are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Remove a plate from the watchlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dtos.AbandonParkingRecordPayload": {
+            "type": "object",
+            "required": [
+                "closedBy",
+                "reason"
+            ],
+            "properties": {
+                "closedBy": {
+                    "type": "string",
+                    "example": "operator-01"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Vehicle not found on site during patrol"
+                }
+            }
+        },
+        "dtos.AcknowledgeWatchlistAlertPayload": {
+            "type": "object",
+            "required": [
+                "acknowledgedBy"
+            ],
+            "properties": {
+                "acknowledgedBy": {
+                    "type": "string",
+                    "example": "operator-01"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Police notified"
+                }
+            }
+        },
+        "dtos.ApplyValidationPayload": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "COFFEE-30"
+                }
+            }
+        },
+        "dtos.ApprovePlateReviewPayload": {
+            "type": "object",
+            "required": [
+                "reviewedBy"
+            ],
+            "properties": {
+                "reviewedBy": {
+                    "type": "string",
+                    "example": "reviewer-01"
+                }
+            }
+        },
+        "dtos.AvailableSpotsResponse": {
+            "type": "object",
+            "properties": {
+                "available_spots": {
+                    "type": "integer"
+                },
+                "by_vehicle_class": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.VehicleClassSpotsResponse"
+                    }
+                },
+                "held_spots": {
+                    "type": "integer"
+                },
+                "occupied_spots": {
+                    "type": "integer"
+                },
+                "parking_lots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ParkingLotSpotsResponse"
+                    }
+                },
+                "public_available_spots": {
+                    "type": "integer"
+                },
+                "reserved_spots": {
+                    "type": "integer"
+                },
+                "total_capacity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.CancelReservationResponse": {
+            "type": "object",
+            "properties": {
+                "cancelledAt": {
+                    "description": "CancelledAt 取消時間",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "depositAmount": {
+                    "description": "DepositAmount 訂金金額，未付訂金則為 0",
+                    "type": "number"
+                },
+                "depositRefund": {
+                    "$ref": "#/definitions/models.Transaction"
+                },
+                "depositStatus": {
+                    "description": "DepositStatus 訂金狀態：None (未付訂金), Pending, Paid, Failed, Refunded, Forfeited (逾時未到沒收)",
+                    "type": "string"
+                },
+                "depositTransactionID": {
+                    "description": "DepositTransactionID 訂金的交易記錄，尚未付款則為 NULL",
+                    "type": "integer"
+                },
+                "endTime": {
+                    "description": "EndTime 預約結束時間 (不包含)",
+                    "type": "string"
+                },
+                "licensePlate": {
+                    "description": "LicensePlate 預約的標準格式車牌號碼",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 預約的停車場",
+                    "type": "integer"
+                },
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 預約車輛進場後的停車記錄，尚未進場則為 NULL",
+                    "type": "integer"
+                },
+                "reservationID": {
+                    "description": "ReservationID 作為主鍵",
+                    "type": "integer"
+                },
+                "startTime": {
+                    "description": "StartTime 預約開始時間",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status 預約狀態：Confirmed, Cancelled, Fulfilled, Expired",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                },
+                "vehicleClass": {
+                    "description": "VehicleClass 預約的車種：Car, Motorcycle, Large",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ChargingCharge": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 138.76
+                },
+                "chargerID": {
+                    "type": "string",
+                    "example": "LOT1-EV-01"
+                },
+                "chargingSessionID": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "energyAmount": {
+                    "type": "number",
+                    "example": 98.76
+                },
+                "energyKWh": {
+                    "type": "number",
+                    "example": 12.345
+                },
+                "energyRate": {
+                    "type": "number",
+                    "example": 8
+                },
+                "idleFeeAmount": {
+                    "type": "number",
+                    "example": 40
+                },
+                "idleFeeRate": {
+                    "type": "number",
+                    "example": 2
+                },
+                "idleMinutes": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Completed"
+                }
+            }
+        },
+        "dtos.CorrectPlateReviewPayload": {
+            "type": "object",
+            "required": [
+                "licensePlate",
+                "reviewedBy"
+            ],
+            "properties": {
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1284"
+                },
+                "reviewedBy": {
+                    "type": "string",
+                    "example": "reviewer-01"
+                }
+            }
+        },
+        "dtos.CreateMerchantPayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "contactEmail": {
+                    "type": "string",
+                    "example": "owner@campuscoffee.tw"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Campus Coffee"
+                }
+            }
+        },
+        "dtos.CreateValidationCodePayload": {
+            "type": "object",
+            "required": [
+                "discountType"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 30
+                },
+                "code": {
+                    "type": "string",
+                    "example": "COFFEE-30"
+                },
+                "discountType": {
+                    "type": "string",
+                    "enum": [
+                        "FixedAmount",
+                        "Percentage",
+                        "FreeMinutes",
+                        "FlatFee"
+                    ],
+                    "example": "FreeMinutes"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "freeMinutes": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "maxUses": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "percent": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "dtos.DailyFee": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 500
+                },
+                "billableUnits": {
+                    "type": "integer",
+                    "example": 95
+                },
+                "capApplied": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-06-01"
+                },
+                "dayType": {
+                    "type": "string",
+                    "example": "Holiday"
+                },
+                "holidayName": {
+                    "type": "string",
+                    "example": "端午節"
+                },
+                "uncappedAmount": {
+                    "type": "number",
+                    "example": 950
+                }
+            }
+        },
+        "dtos.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "string",
+                    "example": "Optional additional details"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Detailed error message"
+                }
+            }
+        },
+        "dtos.ErrorResponseWithRecord": {
+            "type": "object",
+            "properties": {
+                "amountDue": {
+                    "type": "number"
+                },
+                "calculatedAmount": {
+                    "type": "number"
+                },
+                "entryTime": {
+                    "description": "Assuming models.ParkingRecord.EntryTime is time.Time",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "gracePeriodEndsAt": {
+                    "type": "string"
+                },
+                "licensePlate": {
+                    "type": "string"
+                },
+                "parkingRecordID": {
+                    "type": "integer"
+                },
+                "paymentStatus": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ExitCandidate": {
+            "type": "object",
+            "properties": {
+                "entryTime": {
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "type": "string",
+                    "example": "/api/v1/parking-records/12/images/original"
+                },
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1234"
+                },
+                "parkingRecordID": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "similarity": {
+                    "type": "number",
+                    "example": 0.64
+                },
+                "thumbnailUrl": {
+                    "type": "string",
+                    "example": "/api/v1/parking-records/12/images/thumbnail"
+                },
+                "userVerifiedLicensePlate": {
+                    "type": "string",
+                    "example": "ABC-1234"
+                }
+            }
+        },
+        "dtos.ExitCandidatesResponse": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ExitCandidate"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1Z34"
+                }
+            }
+        },
+        "dtos.FeeBreakdown": {
+            "type": "object",
+            "properties": {
+                "billableUnits": {
+                    "type": "integer",
+                    "example": 95
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.DailyFee"
+                    }
+                },
+                "durationMinutes": {
+                    "type": "integer",
+                    "example": 95
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.FeeSegment"
+                    }
+                },
+                "totalAmount": {
+                    "type": "number",
+                    "example": 500
+                }
+            }
+        },
+        "dtos.FeeDiscount": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 50
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SHOP10"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "10% off"
+                },
+                "merchantID": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "parkingValidationID": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dtos.FeeQuote": {
+            "type": "object",
+            "properties": {
+                "amountDue": {
+                    "type": "number",
+                    "example": 500
+                },
+                "amountPaid": {
+                    "type": "number",
+                    "example": 0
+                },
+                "billableUnits": {
+                    "type": "integer",
+                    "example": 95
+                },
+                "chargingCharges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ChargingCharge"
+                    }
+                },
+                "chargingTotal": {
+                    "type": "number",
+                    "example": 0
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "TWD"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.DailyFee"
+                    }
+                },
+                "discountTotal": {
+                    "type": "number",
+                    "example": 0
+                },
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.FeeDiscount"
+                    }
+                },
+                "durationMinutes": {
+                    "type": "integer",
+                    "example": 95
+                },
+                "entryTime": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "gracePeriodEndsAt": {
+                    "type": "string"
+                },
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1234"
+                },
+                "parkingLotID": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "parkingRecordID": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "paymentStatus": {
+                    "type": "string",
+                    "example": "Pending"
+                },
+                "quotedAt": {
+                    "type": "string"
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.FeeSegment"
+                    }
+                },
+                "subtotal": {
+                    "type": "number",
+                    "example": 500
+                },
+                "totalAmount": {
+                    "type": "number",
+                    "example": 500
+                }
+            }
+        },
+        "dtos.FeeSegment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 100
+                },
+                "band": {
+                    "type": "string",
+                    "example": "Night"
+                },
+                "billableUnits": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "capApplied": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-06-06"
+                },
+                "dayType": {
+                    "type": "string",
+                    "example": "Weekday"
+                },
+                "endTime": {
+                    "type": "string"
+                },
+                "ratePerUnit": {
+                    "type": "number",
+                    "example": 5
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "uncappedAmount": {
+                    "type": "number",
+                    "example": 150
+                }
+            }
+        },
+        "dtos.ImageAttachmentRateResponse": {
+            "type": "object",
+            "properties": {
+                "attachment_rate": {
+                    "description": "Value between 0.0 and 1.0",
+                    "type": "number"
+                },
+                "entries_with_image": {
+                    "type": "integer"
+                },
+                "total_entries": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.MerchantSubsidyReportResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "merchants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.MerchantSubsidyResponse"
+                    }
+                },
+                "total_subsidy": {
+                    "type": "number"
+                }
+            }
+        },
+        "dtos.MerchantSubsidyResponse": {
+            "type": "object",
+            "properties": {
+                "merchant_id": {
+                    "type": "integer"
+                },
+                "merchant_name": {
+                    "type": "string"
+                },
+                "subsidy_amount": {
+                    "type": "number"
+                },
+                "validation_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.MeterReadingPayload": {
+            "type": "object",
+            "required": [
+                "meterWh"
+            ],
+            "properties": {
+                "meterWh": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1532345
+                },
+                "readAt": {
+                    "type": "string",
+                    "example": "2026-10-18T10:30:00+08:00"
+                }
+            }
+        },
+        "dtos.ParkingLotPayload": {
+            "type": "object",
+            "required": [
+                "capacity",
+                "name"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "address": {
+                    "type": "string",
+                    "example": "高雄市燕巢區深中路58號"
+                },
+                "capacity": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 100
+                },
+                "name": {
+                    "type": "string",
+                    "example": "教授，你好停車場"
+                },
+                "rateCalendar": {
+                    "type": "string",
+                    "example": "Default"
+                },
+                "reservedSpots": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "vehicleClasses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ParkingLotVehicleClassPayload"
+                    }
+                }
+            }
+        },
+        "dtos.ParkingLotSensorPayload": {
+            "type": "object",
+            "required": [
+                "direction",
+                "sensorId"
+            ],
+            "properties": {
+                "direction": {
+                    "type": "string",
+                    "enum": [
+                        "Entry",
+                        "Exit"
+                    ],
+                    "example": "Entry"
+                },
+                "sensorId": {
+                    "type": "string",
+                    "example": "LOT1-GATE-A-IN"
+                },
+                "vehicleClass": {
+                    "type": "string",
+                    "enum": [
+                        "Car",
+                        "Motorcycle",
+                        "Large"
+                    ],
+                    "example": "Motorcycle"
+                }
+            }
+        },
+        "dtos.ParkingLotSpotsResponse": {
+            "type": "object",
+            "properties": {
+                "available_spots": {
+                    "type": "integer"
+                },
+                "held_spots": {
+                    "type": "integer"
+                },
+                "is_full": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "occupied_spots": {
+                    "type": "integer"
+                },
+                "parking_lot_id": {
+                    "type": "integer"
+                },
+                "public_available_spots": {
+                    "type": "integer"
+                },
+                "reserved_spots": {
+                    "type": "integer"
+                },
+                "total_capacity": {
+                    "type": "integer"
+                },
+                "vehicle_classes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.VehicleClassSpotsResponse"
+                    }
+                },
+                "zones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ZoneSpotsResponse"
+                    }
+                }
+            }
+        },
+        "dtos.ParkingLotVehicleClassPayload": {
+            "type": "object",
+            "required": [
+                "vehicleClass"
+            ],
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 50
+                },
+                "rateCalendar": {
+                    "type": "string",
+                    "example": "Motorcycle"
+                },
+                "vehicleClass": {
+                    "type": "string",
+                    "enum": [
+                        "Motorcycle",
+                        "Large"
+                    ],
+                    "example": "Motorcycle"
+                }
+            }
+        },
+        "dtos.ParkingPaymentPayload": {
+            "type": "object",
+            "required": [
+                "amountPaid",
+                "paymentMethod"
+            ],
+            "properties": {
+                "amountPaid": {
+                    "type": "number",
+                    "example": 50
+                },
+                "paymentMethod": {
+                    "type": "string",
+                    "example": "MobilePay"
+                },
+                "paymentReference": {
+                    "description": "可選，如果前端有來自支付閘道的參考ID或備註 (模擬支付閘道：含 DECLINE 會被拒絕，含 TIMEOUT 會逾時)",
+                    "type": "string",
+                    "example": "TXN_REF_123XYZ"
+                }
+            }
+        },
+        "dtos.ParkingRecordWithFeeBreakdownResponse": {
+            "type": "object",
+            "properties": {
+                "abandonReason": {
+                    "description": "AbandonReason 停車記錄被結束為 Abandoned 的原因",
+                    "type": "string"
+                },
+                "actualDurationMinutes": {
+                    "description": "ActualDurationMinutes 實際停車時長（分鐘）",
+                    "type": "integer"
+                },
+                "amountDue": {
+                    "type": "number"
+                },
+                "amountPaid": {
+                    "type": "number"
+                },
+                "calculatedAmount": {
+                    "description": "CalculatedAmount 應付停車費用",
+                    "type": "number"
+                },
+                "capacityOverrideBy": {
+                    "description": "CapacityOverrideBy 停車場客滿時強制放行的操作人員，正常進場則為 NULL",
+                    "type": "string"
+                },
+                "capacityOverrideReason": {
+                    "description": "CapacityOverrideReason 停車場客滿時強制放行的原因",
+                    "type": "string"
+                },
+                "chargingCharges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ChargingCharge"
+                    }
+                },
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.FeeDiscount"
+                    }
+                },
+                "entryTime": {
+                    "description": "EntryTime 進場時間",
+                    "type": "string"
+                },
+                "exitMatch": {
+                    "description": "ExitMatch 出場時找到此停車記錄的方式：Exact, Fuzzy, Operator，尚未出場則為空字串",
+                    "type": "string"
+                },
+                "exitRawLicensePlate": {
+                    "description": "ExitRawLicensePlate 出場時 OCR 回傳的原始車牌字串，尚未出場則為 NULL",
+                    "type": "string"
+                },
+                "exitTime": {
+                    "description": "ExitTime 出場時間，如果尚未出場則為 NULL；Abandoned 的停車記錄為結束記錄的時間",
+                    "type": "string"
+                },
+                "feeBreakdown": {
+                    "$ref": "#/definitions/dtos.FeeBreakdown"
+                },
+                "feeCalculatedAt": {
+                    "description": "FeeCalculatedAt 應付停車費用的計算時間，付款明細依此時間的費用列出；付款後出場寬限期由此起算 (付款時間較早時由付款時間起算)",
+                    "type": "string"
+                },
+                "flagReason": {
+                    "description": "FlagReason 車牌符合監控名單 (處理方式為 Flag) 時標記的原因，供現場人員處理，未標記則為 NULL",
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "description": "ImageURL 與 ThumbnailURL 為取得進場影像與縮圖的 API 路徑，讀取或寫入後依 ImageKey 設定，不存放在資料庫",
+                    "type": "string",
+                    "example": "/api/v1/parking-records/12/images/original"
+                },
+                "licensePlate": {
+                    "description": "LicensePlate 標準格式的車牌號碼 (通常來自 OCR)，例如 ABC-1234",
+                    "type": "string"
+                },
+                "ocrConfidence": {
+                    "description": "OcrConfidence 進場時 OCR 的信心分數 (0.0 到 1.0 之間)，未提供則為 NULL",
+                    "type": "number"
+                },
+                "paidAt": {
+                    "description": "PaidAt 最近一次付款成功的時間，尚未付款則為 NULL",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 停車記錄所屬的停車場",
+                    "type": "integer"
+                },
+                "paymentStatus": {
+                    "description": "PaymentStatus 支付狀態：Pending, Paid, Refunded",
+                    "type": "string"
+                },
+                "permitID": {
+                    "description": "PermitID 進場時適用的定期停車證，非定期停車則為 NULL",
+                    "type": "integer"
+                },
+                "rawLicensePlate": {
+                    "description": "RawLicensePlate 進場時 OCR 回傳的原始車牌字串，保留供稽核",
+                    "type": "string"
+                },
+                "recordID": {
+                    "description": "RecordID 作為主鍵",
+                    "type": "integer"
+                },
+                "reservationID": {
+                    "description": "ReservationID 車輛於預約抵達時段內進場時所連結的預約，非預約進場則為 NULL",
+                    "type": "integer"
+                },
+                "sensorEntryID": {
+                    "description": "SensorEntryID 入場感應器記錄ID",
+                    "type": "string"
+                },
+                "sensorExitID": {
+                    "description": "SensorExitID 出場感應器記錄ID",
+                    "type": "string"
+                },
+                "sessionStatus": {
+                    "description": "SessionStatus 停車狀態：Open, Exited, Abandoned",
+                    "type": "string"
+                },
+                "spotID": {
+                    "description": "SpotID 車輛停放的車位，由車位感應器回報時連結，未知則為 NULL",
+                    "type": "integer"
+                },
+                "staleFlaggedAt": {
+                    "description": "StaleFlaggedAt 尚未出場的時間超過上限，被標記為疑似遺漏出場的時間，未標記則為 NULL",
+                    "type": "string"
+                },
+                "supersededByRecordID": {
+                    "description": "SupersededByRecordID 同一車牌再次進場而結束此停車記錄時，新的停車記錄 ID",
+                    "type": "integer"
+                },
+                "thumbnailUrl": {
+                    "type": "string",
+                    "example": "/api/v1/parking-records/12/images/thumbnail"
+                },
+                "transaction": {
+                    "description": "GORM 模型關聯定義",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    ]
+                },
+                "transactionID": {
+                    "description": "TransactionID 關聯到 Transactions 表的外鍵，如果尚未支付或無交易則為 NULL",
+                    "type": "integer"
+                },
+                "userVerifiedLicensePlate": {
+                    "description": "UserVerifiedLicensePlate 使用者驗證/修正後的標準格式車牌號碼，可以為 NULL",
+                    "type": "string"
+                },
+                "vehicleClass": {
+                    "description": "VehicleClass 車種：Car, Motorcycle, Large，決定車位數與費率",
+                    "type": "string"
+                },
+                "vehicleID": {
+                    "description": "VehicleID 停車記錄所屬的車輛資料，以車牌 (有驗證車牌時為驗證車牌) 對應",
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.ParkingRecordWithTransactionResponse": {
+            "type": "object",
+            "properties": {
+                "abandonReason": {
+                    "description": "AbandonReason 停車記錄被結束為 Abandoned 的原因",
+                    "type": "string"
+                },
+                "actualDurationMinutes": {
+                    "description": "ActualDurationMinutes 實際停車時長（分鐘）",
+                    "type": "integer"
+                },
+                "calculatedAmount": {
+                    "description": "CalculatedAmount 應付停車費用",
+                    "type": "number"
+                },
+                "capacityOverrideBy": {
+                    "description": "CapacityOverrideBy 停車場客滿時強制放行的操作人員，正常進場則為 NULL",
+                    "type": "string"
+                },
+                "capacityOverrideReason": {
+                    "description": "CapacityOverrideReason 停車場客滿時強制放行的原因",
+                    "type": "string"
+                },
+                "entryTime": {
+                    "description": "EntryTime 進場時間",
+                    "type": "string"
+                },
+                "exitMatch": {
+                    "description": "ExitMatch 出場時找到此停車記錄的方式：Exact, Fuzzy, Operator，尚未出場則為空字串",
+                    "type": "string"
+                },
+                "exitRawLicensePlate": {
+                    "description": "ExitRawLicensePlate 出場時 OCR 回傳的原始車牌字串，尚未出場則為 NULL",
+                    "type": "string"
+                },
+                "exitTime": {
+                    "description": "ExitTime 出場時間，如果尚未出場則為 NULL；Abandoned 的停車記錄為結束記錄的時間",
+                    "type": "string"
+                },
+                "feeCalculatedAt": {
+                    "description": "FeeCalculatedAt 應付停車費用的計算時間，付款明細依此時間的費用列出；付款後出場寬限期由此起算 (付款時間較早時由付款時間起算)",
+                    "type": "string"
+                },
+                "flagReason": {
+                    "description": "FlagReason 車牌符合監控名單 (處理方式為 Flag) 時標記的原因，供現場人員處理，未標記則為 NULL",
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "description": "ImageURL 與 ThumbnailURL 為取得進場影像與縮圖的 API 路徑，讀取或寫入後依 ImageKey 設定，不存放在資料庫",
+                    "type": "string",
+                    "example": "/api/v1/parking-records/12/images/original"
+                },
+                "licensePlate": {
+                    "description": "LicensePlate 標準格式的車牌號碼 (通常來自 OCR)，例如 ABC-1234",
+                    "type": "string"
+                },
+                "ocrConfidence": {
+                    "description": "OcrConfidence 進場時 OCR 的信心分數 (0.0 到 1.0 之間)，未提供則為 NULL",
+                    "type": "number"
+                },
+                "paidAt": {
+                    "description": "PaidAt 最近一次付款成功的時間，尚未付款則為 NULL",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 停車記錄所屬的停車場",
+                    "type": "integer"
+                },
+                "paymentIntent": {
+                    "$ref": "#/definitions/models.PaymentIntent"
+                },
+                "paymentStatus": {
+                    "description": "PaymentStatus 支付狀態：Pending, Paid, Refunded",
+                    "type": "string"
+                },
+                "permitID": {
+                    "description": "PermitID 進場時適用的定期停車證，非定期停車則為 NULL",
+                    "type": "integer"
+                },
+                "rawLicensePlate": {
+                    "description": "RawLicensePlate 進場時 OCR 回傳的原始車牌字串，保留供稽核",
+                    "type": "string"
+                },
+                "recordID": {
+                    "description": "RecordID 作為主鍵",
+                    "type": "integer"
+                },
+                "reservationID": {
+                    "description": "ReservationID 車輛於預約抵達時段內進場時所連結的預約，非預約進場則為 NULL",
+                    "type": "integer"
+                },
+                "sensorEntryID": {
+                    "description": "SensorEntryID 入場感應器記錄ID",
+                    "type": "string"
+                },
+                "sensorExitID": {
+                    "description": "SensorExitID 出場感應器記錄ID",
+                    "type": "string"
+                },
+                "sessionStatus": {
+                    "description": "SessionStatus 停車狀態：Open, Exited, Abandoned",
+                    "type": "string"
+                },
+                "spotID": {
+                    "description": "SpotID 車輛停放的車位，由車位感應器回報時連結，未知則為 NULL",
+                    "type": "integer"
+                },
+                "staleFlaggedAt": {
+                    "description": "StaleFlaggedAt 尚未出場的時間超過上限，被標記為疑似遺漏出場的時間，未標記則為 NULL",
+                    "type": "string"
+                },
+                "supersededByRecordID": {
+                    "description": "SupersededByRecordID 同一車牌再次進場而結束此停車記錄時，新的停車記錄 ID",
+                    "type": "integer"
+                },
+                "thumbnailUrl": {
+                    "type": "string",
+                    "example": "/api/v1/parking-records/12/images/thumbnail"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/models.Transaction"
+                },
+                "transactionID": {
+                    "description": "TransactionID 關聯到 Transactions 表的外鍵，如果尚未支付或無交易則為 NULL",
+                    "type": "integer"
+                },
+                "userVerifiedLicensePlate": {
+                    "description": "UserVerifiedLicensePlate 使用者驗證/修正後的標準格式車牌號碼，可以為 NULL",
+                    "type": "string"
+                },
+                "vehicleClass": {
+                    "description": "VehicleClass 車種：Car, Motorcycle, Large，決定車位數與費率",
+                    "type": "string"
+                },
+                "vehicleID": {
+                    "description": "VehicleID 停車記錄所屬的車輛資料，以車牌 (有驗證車牌時為驗證車牌) 對應",
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.PermitExpiryReportResponse": {
+            "type": "object",
+            "properties": {
+                "expiring": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.PermitExpiryResponse"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "recently_expired": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.PermitExpiryResponse"
+                    }
+                },
+                "within_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.PermitExpiryResponse": {
+            "type": "object",
+            "properties": {
+                "days_remaining": {
+                    "description": "Negative for permits that already expired",
+                    "type": "integer"
+                },
+                "holder_name": {
+                    "type": "string"
+                },
+                "license_plates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "permit_id": {
+                    "type": "integer"
+                },
+                "permit_type": {
+                    "type": "string"
+                },
+                "valid_until": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.PermitPayload": {
+            "type": "object",
+            "required": [
+                "holderName",
+                "licensePlates",
+                "validFrom",
+                "validUntil"
+            ],
+            "properties": {
+                "allowedEndTime": {
+                    "type": "string",
+                    "example": "19:00"
+                },
+                "allowedStartTime": {
+                    "type": "string",
+                    "example": "07:00"
+                },
+                "holderName": {
+                    "type": "string",
+                    "example": "王教授"
+                },
+                "licensePlates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ABC-1234"
+                    ]
+                },
+                "parkingLotId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "permitType": {
+                    "type": "string",
+                    "example": "Monthly"
+                },
+                "validFrom": {
+                    "type": "string",
+                    "example": "2026-10-01T00:00:00+08:00"
+                },
+                "validUntil": {
+                    "type": "string",
+                    "example": "2026-11-01T00:00:00+08:00"
+                }
+            }
+        },
+        "dtos.PlateReviewThroughputResponse": {
+            "type": "object",
+            "properties": {
+                "approved_count": {
+                    "type": "integer"
+                },
+                "average_wait_seconds": {
+                    "type": "number"
+                },
+                "by_reviewer": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ReviewerThroughputResponse"
+                    }
+                },
+                "corrected_count": {
+                    "type": "integer"
+                },
+                "correction_rate": {
+                    "description": "Value between 0.0 and 1.0",
+                    "type": "number"
+                },
+                "oldest_pending_at": {
+                    "type": "string"
+                },
+                "pending_count": {
+                    "type": "integer"
+                },
+                "queued_count": {
+                    "type": "integer"
+                },
+                "reviewed_count": {
+                    "type": "integer"
+                },
+                "reviews_per_hour": {
+                    "type": "number"
+                }
+            }
+        },
+        "dtos.RefundTransactionPayload": {
+            "type": "object",
+            "required": [
+                "operator",
+                "reason"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 20
+                },
+                "operator": {
+                    "type": "string",
+                    "example": "staff-007"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Gate malfunction, customer charged twice"
+                }
+            }
+        },
+        "dtos.ReservationPayload": {
+            "type": "object",
+            "required": [
+                "endTime",
+                "licensePlate",
+                "startTime"
+            ],
+            "properties": {
+                "endTime": {
+                    "type": "string",
+                    "example": "2026-10-18T12:00:00+08:00"
+                },
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1234"
+                },
+                "parkingLotId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "paymentMethod": {
+                    "type": "string",
+                    "example": "CreditCard"
+                },
+                "paymentReference": {
+                    "type": "string",
+                    "example": "txn_123abc"
+                },
+                "startTime": {
+                    "type": "string",
+                    "example": "2026-10-18T09:00:00+08:00"
+                },
+                "vehicleClass": {
+                    "type": "string",
+                    "enum": [
+                        "Car",
+                        "Motorcycle",
+                        "Large"
+                    ],
+                    "example": "Car"
+                }
+            }
+        },
+        "dtos.ReservationResponse": {
+            "type": "object",
+            "properties": {
+                "cancelledAt": {
+                    "description": "CancelledAt 取消時間",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "depositAmount": {
+                    "description": "DepositAmount 訂金金額，未付訂金則為 0",
+                    "type": "number"
+                },
+                "depositStatus": {
+                    "description": "DepositStatus 訂金狀態：None (未付訂金), Pending, Paid, Failed, Refunded, Forfeited (逾時未到沒收)",
+                    "type": "string"
+                },
+                "depositTransactionID": {
+                    "description": "DepositTransactionID 訂金的交易記錄，尚未付款則為 NULL",
+                    "type": "integer"
+                },
+                "endTime": {
+                    "description": "EndTime 預約結束時間 (不包含)",
+                    "type": "string"
+                },
+                "licensePlate": {
+                    "description": "LicensePlate 預約的標準格式車牌號碼",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 預約的停車場",
+                    "type": "integer"
+                },
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 預約車輛進場後的停車記錄，尚未進場則為 NULL",
+                    "type": "integer"
+                },
+                "paymentIntent": {
+                    "$ref": "#/definitions/models.PaymentIntent"
+                },
+                "reservationID": {
+                    "description": "ReservationID 作為主鍵",
+                    "type": "integer"
+                },
+                "startTime": {
+                    "description": "StartTime 預約開始時間",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status 預約狀態：Confirmed, Cancelled, Fulfilled, Expired",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                },
+                "vehicleClass": {
+                    "description": "VehicleClass 預約的車種：Car, Motorcycle, Large",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ReviewerThroughputResponse": {
+            "type": "object",
+            "properties": {
+                "approved_count": {
+                    "type": "integer"
+                },
+                "average_wait_seconds": {
+                    "type": "number"
+                },
+                "corrected_count": {
+                    "type": "integer"
+                },
+                "reviewed_by": {
+                    "type": "string"
+                },
+                "reviewed_count": {
+                    "type": "integer"
+                },
+                "reviews_per_hour": {
+                    "type": "number"
+                }
+            }
+        },
+        "dtos.SignboardLine": {
+            "type": "object",
+            "properties": {
+                "available_spots": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SignboardResponse": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.SignboardLine"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parking_lot_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.SimpleEntryPayload": {
+            "type": "object",
+            "required": [
+                "licensePlate"
+            ],
+            "properties": {
+                "fuzzyMatch": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1234"
+                },
+                "ocrAlternatives": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ABC-1284"
+                    ]
+                },
+                "ocrConfidence": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.72
+                },
+                "overrideBy": {
+                    "type": "string",
+                    "example": "operator-01"
+                },
+                "overrideCapacity": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "overrideReason": {
+                    "type": "string",
+                    "example": "Disabled driver with appointment"
+                },
+                "parkingLotId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "parkingRecordId": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "sensorId": {
+                    "type": "string",
+                    "example": "LOT1-GATE-A-IN"
+                },
+                "vehicleClass": {
+                    "type": "string",
+                    "enum": [
+                        "Car",
+                        "Motorcycle",
+                        "Large"
+                    ],
+                    "example": "Car"
+                }
+            }
+        },
+        "dtos.SpotPayload": {
+            "type": "object",
+            "required": [
+                "code",
+                "sensorId"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "B2-014"
+                },
+                "sensorId": {
+                    "type": "string",
+                    "example": "LOT1-B2-014"
+                }
+            }
+        },
+        "dtos.SpotStatusPayload": {
+            "type": "object",
+            "required": [
+                "occupied"
+            ],
+            "properties": {
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1234"
+                },
+                "occupied": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dtos.SpotStatusResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "B2-014"
+                },
+                "occupied": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "parkingRecordId": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "spotId": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "zoneId": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "zoneName": {
+                    "type": "string",
+                    "example": "B2"
+                }
+            }
+        },
+        "dtos.StartChargingPayload": {
+            "type": "object",
+            "required": [
+                "chargerId",
+                "meterWh"
+            ],
+            "properties": {
+                "chargerId": {
+                    "type": "string",
+                    "example": "LOT1-EV-01"
+                },
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1234"
+                },
+                "meterWh": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1520000
+                },
+                "parkingRecordId": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dtos.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Operation successful"
+                }
+            }
+        },
+        "dtos.SuccessResponseWithData": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.TotalParkingCountResponse": {
+            "type": "object",
+            "properties": {
+                "by_vehicle_class": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.VehicleClassCountResponse"
+                    }
+                },
+                "total_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.TotalRevenueResponse": {
+            "type": "object",
+            "properties": {
+                "by_vehicle_class": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.VehicleClassRevenueResponse"
+                    }
+                },
+                "currency": {
+                    "description": "e.g., \"TWD\", \"USD\"",
+                    "type": "string"
+                },
+                "forfeited_deposits": {
+                    "type": "number"
+                },
+                "gross_revenue": {
+                    "type": "number"
+                },
+                "refunded_amount": {
+                    "type": "number"
+                },
+                "total_revenue": {
+                    "type": "number"
+                }
+            }
+        },
+        "dtos.VehicleClassCountResponse": {
+            "type": "object",
+            "properties": {
+                "total_count": {
+                    "type": "integer"
+                },
+                "vehicle_class": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.VehicleClassRevenueResponse": {
+            "type": "object",
+            "properties": {
+                "forfeited_deposits": {
+                    "type": "number"
+                },
+                "gross_revenue": {
+                    "type": "number"
+                },
+                "refunded_amount": {
+                    "type": "number"
+                },
+                "total_revenue": {
+                    "type": "number"
+                },
+                "vehicle_class": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.VehicleClassSpotsResponse": {
+            "type": "object",
+            "properties": {
+                "available_spots": {
+                    "type": "integer"
+                },
+                "held_spots": {
+                    "type": "integer"
+                },
+                "is_full": {
+                    "type": "boolean"
+                },
+                "occupied_spots": {
+                    "type": "integer"
+                },
+                "public_available_spots": {
+                    "type": "integer"
+                },
+                "reserved_spots": {
+                    "type": "integer"
+                },
+                "total_capacity": {
+                    "type": "integer"
+                },
+                "vehicle_class": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.VehicleDetailsPayload": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string",
+                    "example": "White"
+                },
+                "make": {
+                    "type": "string",
+                    "example": "Toyota"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Faculty member, usually parks on B2"
+                }
+            }
+        },
+        "dtos.VehicleProfileResponse": {
+            "type": "object",
+            "properties": {
+                "activeParkingRecordId": {
+                    "type": "integer"
+                },
+                "averageDwellMinutes": {
+                    "type": "number"
+                },
+                "hasUnpaidBalance": {
+                    "type": "boolean"
+                },
+                "totalSpend": {
+                    "type": "number"
+                },
+                "unpaidVisitCount": {
+                    "type": "integer"
+                },
+                "vehicle": {
+                    "$ref": "#/definitions/models.Vehicle"
+                },
+                "visitCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.VerifyLicensePlatePayload": {
+            "type": "object",
+            "required": [
+                "licensePlate"
+            ],
+            "properties": {
+                "licensePlate": {
+                    "type": "string",
+                    "example": "XYZ-7890"
+                }
+            }
+        },
+        "dtos.WatchlistEntryPayload": {
+            "type": "object",
+            "required": [
+                "action",
+                "category",
+                "licensePlate"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "DenyEntry"
+                },
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "category": {
+                    "type": "string",
+                    "example": "Stolen"
+                },
+                "createdBy": {
+                    "type": "string",
+                    "example": "security-01"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2026-12-31T23:59:59+08:00"
+                },
+                "licensePlate": {
+                    "type": "string",
+                    "example": "ABC-1234"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Reported stolen on 2026-10-01"
+                }
+            }
+        },
+        "dtos.ZonePayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "地下二樓汽車位"
+                },
+                "level": {
+                    "type": "integer",
+                    "example": -2
+                },
+                "name": {
+                    "type": "string",
+                    "example": "B2"
+                }
+            }
+        },
+        "dtos.ZoneSpotsResponse": {
+            "type": "object",
+            "properties": {
+                "available_spots": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "occupied_spots": {
+                    "type": "integer"
+                },
+                "total_spots": {
+                    "type": "integer"
+                },
+                "zone_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ChargingMeterReading": {
+            "type": "object",
+            "properties": {
+                "chargingMeterReadingID": {
+                    "description": "ChargingMeterReadingID 作為主鍵",
+                    "type": "integer"
+                },
+                "chargingSessionID": {
+                    "description": "ChargingSessionID 所屬的充電",
+                    "type": "integer"
+                },
+                "meterWh": {
+                    "description": "MeterWh 電錶讀數",
+                    "type": "integer"
+                },
+                "readAt": {
+                    "description": "ReadAt 讀數時間",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChargingSession": {
+            "type": "object",
+            "properties": {
+                "chargerID": {
+                    "description": "ChargerID 充電樁識別碼",
+                    "type": "string"
+                },
+                "chargingSessionID": {
+                    "description": "ChargingSessionID 作為主鍵",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "lastMeterAt": {
+                    "description": "LastMeterAt 最後一次回報電錶讀數的時間",
+                    "type": "string"
+                },
+                "lastMeterWh": {
+                    "description": "LastMeterWh 最後一次回報的電錶讀數",
+                    "type": "integer"
+                },
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 充電車輛的停車記錄",
+                    "type": "integer"
+                },
+                "readings": {
+                    "description": "Readings 充電樁回報的電錶讀數",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChargingMeterReading"
+                    }
+                },
+                "startMeterWh": {
+                    "description": "StartMeterWh 開始充電時的電錶讀數",
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "description": "StartedAt 開始充電時間",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status 充電狀態：Active, Completed",
+                    "type": "string"
+                },
+                "stoppedAt": {
+                    "description": "StoppedAt 結束充電時間，結束後車輛仍佔用充電車位的時間計收佔用費，充電中則為 NULL",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Merchant": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active 商家是否仍可發放折抵碼",
+                    "type": "boolean"
+                },
+                "contactEmail": {
+                    "description": "ContactEmail 商家聯絡信箱",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "merchantID": {
+                    "description": "MerchantID 作為主鍵",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name 商家名稱",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ParkingLot": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active 停車場是否營業中，停止營業的停車場不接受車輛進場",
+                    "type": "boolean"
+                },
+                "address": {
+                    "description": "Address 停車場地址",
+                    "type": "string"
+                },
+                "capacity": {
+                    "description": "Capacity 停車場的汽車車位數",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name 停車場名稱",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 作為主鍵",
+                    "type": "integer"
+                },
+                "rateCalendar": {
+                    "description": "RateCalendar 停車場汽車使用的費率行事曆名稱，對應 configs.RateCalendars",
+                    "type": "string"
+                },
+                "reservedSpots": {
+                    "description": "ReservedSpots 保留給定期停車證持有者的汽車車位數，一般車輛在空位不超過此數時不得進場",
+                    "type": "integer"
+                },
+                "sensors": {
+                    "description": "Sensors 停車場出入口的感應器",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ParkingLotSensor"
+                    }
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                },
+                "vehicleClasses": {
+                    "description": "VehicleClasses 停車場開放的汽車以外車種，未列出的車種不得進場",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ParkingLotVehicleClass"
+                    }
+                }
+            }
+        },
+        "models.ParkingLotSensor": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "direction": {
+                    "description": "Direction 感應器方向：Entry, Exit",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 感應器所在的停車場",
+                    "type": "integer"
+                },
+                "sensorID": {
+                    "description": "SensorID 感應器識別碼，與停車記錄的 SensorEntryID / SensorExitID 相同",
+                    "type": "string"
+                },
+                "vehicleClass": {
+                    "description": "VehicleClass 專用車道的車種，例如機車道的入口感應器；空字串表示不限車種",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ParkingLotVehicleClass": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "description": "Capacity 此車種的車位數",
+                    "type": "integer"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 所屬的停車場",
+                    "type": "integer"
+                },
+                "rateCalendar": {
+                    "description": "RateCalendar 此車種使用的費率行事曆名稱，對應 configs.RateCalendars",
+                    "type": "string"
+                },
+                "vehicleClass": {
+                    "description": "VehicleClass 車種：Motorcycle, Large",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ParkingRecord": {
+            "type": "object",
+            "properties": {
+                "abandonReason": {
+                    "description": "AbandonReason 停車記錄被結束為 Abandoned 的原因",
+                    "type": "string"
+                },
+                "actualDurationMinutes": {
+                    "description": "ActualDurationMinutes 實際停車時長（分鐘）",
+                    "type": "integer"
+                },
                 "calculatedAmount": {
+                    "description": "CalculatedAmount 應付停車費用",
+                    "type": "number"
+                },
+                "capacityOverrideBy": {
+                    "description": "CapacityOverrideBy 停車場客滿時強制放行的操作人員，正常進場則為 NULL",
+                    "type": "string"
+                },
+                "capacityOverrideReason": {
+                    "description": "CapacityOverrideReason 停車場客滿時強制放行的原因",
+                    "type": "string"
+                },
+                "entryTime": {
+                    "description": "EntryTime 進場時間",
+                    "type": "string"
+                },
+                "exitMatch": {
+                    "description": "ExitMatch 出場時找到此停車記錄的方式：Exact, Fuzzy, Operator，尚未出場則為空字串",
+                    "type": "string"
+                },
+                "exitRawLicensePlate": {
+                    "description": "ExitRawLicensePlate 出場時 OCR 回傳的原始車牌字串，尚未出場則為 NULL",
+                    "type": "string"
+                },
+                "exitTime": {
+                    "description": "ExitTime 出場時間，如果尚未出場則為 NULL；Abandoned 的停車記錄為結束記錄的時間",
+                    "type": "string"
+                },
+                "feeCalculatedAt": {
+                    "description": "FeeCalculatedAt 應付停車費用的計算時間，付款明細依此時間的費用列出；付款後出場寬限期由此起算 (付款時間較早時由付款時間起算)",
+                    "type": "string"
+                },
+                "flagReason": {
+                    "description": "FlagReason 車牌符合監控名單 (處理方式為 Flag) 時標記的原因，供現場人員處理，未標記則為 NULL",
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "description": "ImageURL 與 ThumbnailURL 為取得進場影像與縮圖的 API 路徑，讀取或寫入後依 ImageKey 設定，不存放在資料庫",
+                    "type": "string",
+                    "example": "/api/v1/parking-records/12/images/original"
+                },
+                "licensePlate": {
+                    "description": "LicensePlate 標準格式的車牌號碼 (通常來自 OCR)，例如 ABC-1234",
+                    "type": "string"
+                },
+                "ocrConfidence": {
+                    "description": "OcrConfidence 進場時 OCR 的信心分數 (0.0 到 1.0 之間)，未提供則為 NULL",
                     "type": "number"
                 },
-                "entryTime": {
-                    "description": "Assuming models.ParkingRecord.EntryTime is time.Time",
+                "paidAt": {
+                    "description": "PaidAt 最近一次付款成功的時間，尚未付款則為 NULL",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 停車記錄所屬的停車場",
+                    "type": "integer"
+                },
+                "paymentStatus": {
+                    "description": "PaymentStatus 支付狀態：Pending, Paid, Refunded",
+                    "type": "string"
+                },
+                "permitID": {
+                    "description": "PermitID 進場時適用的定期停車證，非定期停車則為 NULL",
+                    "type": "integer"
+                },
+                "rawLicensePlate": {
+                    "description": "RawLicensePlate 進場時 OCR 回傳的原始車牌字串，保留供稽核",
+                    "type": "string"
+                },
+                "recordID": {
+                    "description": "RecordID 作為主鍵",
+                    "type": "integer"
+                },
+                "reservationID": {
+                    "description": "ReservationID 車輛於預約抵達時段內進場時所連結的預約，非預約進場則為 NULL",
+                    "type": "integer"
+                },
+                "sensorEntryID": {
+                    "description": "SensorEntryID 入場感應器記錄ID",
+                    "type": "string"
+                },
+                "sensorExitID": {
+                    "description": "SensorExitID 出場感應器記錄ID",
+                    "type": "string"
+                },
+                "sessionStatus": {
+                    "description": "SessionStatus 停車狀態：Open, Exited, Abandoned",
+                    "type": "string"
+                },
+                "spotID": {
+                    "description": "SpotID 車輛停放的車位，由車位感應器回報時連結，未知則為 NULL",
+                    "type": "integer"
+                },
+                "staleFlaggedAt": {
+                    "description": "StaleFlaggedAt 尚未出場的時間超過上限，被標記為疑似遺漏出場的時間，未標記則為 NULL",
+                    "type": "string"
+                },
+                "supersededByRecordID": {
+                    "description": "SupersededByRecordID 同一車牌再次進場而結束此停車記錄時，新的停車記錄 ID",
+                    "type": "integer"
+                },
+                "thumbnailUrl": {
+                    "type": "string",
+                    "example": "/api/v1/parking-records/12/images/thumbnail"
+                },
+                "transaction": {
+                    "description": "GORM 模型關聯定義",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    ]
+                },
+                "transactionID": {
+                    "description": "TransactionID 關聯到 Transactions 表的外鍵，如果尚未支付或無交易則為 NULL",
+                    "type": "integer"
+                },
+                "userVerifiedLicensePlate": {
+                    "description": "UserVerifiedLicensePlate 使用者驗證/修正後的標準格式車牌號碼，可以為 NULL",
+                    "type": "string"
+                },
+                "vehicleClass": {
+                    "description": "VehicleClass 車種：Car, Motorcycle, Large，決定車位數與費率",
+                    "type": "string"
+                },
+                "vehicleID": {
+                    "description": "VehicleID 停車記錄所屬的車輛資料，以車牌 (有驗證車牌時為驗證車牌) 對應",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ParkingValidation": {
+            "type": "object",
+            "properties": {
+                "appliedAt": {
+                    "description": "AppliedAt 套用時間",
+                    "type": "string"
+                },
+                "code": {
+                    "description": "Code 套用時的折抵碼",
+                    "type": "string"
+                },
+                "discountAmount": {
+                    "description": "DiscountAmount 最近一次計算費用時此折抵實際折抵的金額，即商家補貼的金額",
+                    "type": "number"
+                },
+                "merchantID": {
+                    "description": "MerchantID 負擔折抵金額的商家",
+                    "type": "integer"
+                },
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 套用折抵的停車記錄",
+                    "type": "integer"
+                },
+                "parkingValidationID": {
+                    "description": "ParkingValidationID 作為主鍵",
+                    "type": "integer"
+                },
+                "validationCode": {
+                    "$ref": "#/definitions/models.ValidationCode"
+                },
+                "validationCodeID": {
+                    "description": "ValidationCodeID 套用的折抵碼",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaymentIntent": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount 付款金額",
+                    "type": "number"
+                },
+                "clientReference": {
+                    "description": "ClientReference 客戶端提供的付款參考資訊",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency 幣別",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt 付款意圖的到期時間，到期仍未請款則轉為 Expired",
+                    "type": "string"
+                },
+                "failureReason": {
+                    "description": "FailureReason 付款失敗或過期的原因",
+                    "type": "string"
+                },
+                "lineItems": {
+                    "description": "LineItems 付款明細",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransactionLineItem"
+                    }
+                },
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 關聯到 ParkingRecords 表的外鍵，預約訂金則為 0",
+                    "type": "integer"
+                },
+                "paymentIntentID": {
+                    "description": "PaymentIntentID 作為主鍵",
+                    "type": "integer"
+                },
+                "paymentMethod": {
+                    "description": "PaymentMethod 付款方式，例如 \"Cash\", \"LinePay\"",
+                    "type": "string"
+                },
+                "paymentProvider": {
+                    "description": "PaymentProvider 處理此付款的支付供應商",
+                    "type": "string"
+                },
+                "providerReference": {
+                    "description": "ProviderReference 支付供應商端的付款參考編號",
+                    "type": "string"
+                },
+                "reservationID": {
+                    "description": "ReservationID 預約訂金所屬的預約，停車費付款則為 NULL",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status 付款意圖狀態：Pending, Authorized, Captured, Failed, Expired",
+                    "type": "string"
+                },
+                "transactionID": {
+                    "description": "TransactionID 請款完成後建立的交易，尚未完成則為 NULL",
+                    "type": "integer"
+                },
+                "transactionType": {
+                    "description": "TransactionType 完成後建立的交易類型：Payment, Overstay, Deposit",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Permit": {
+            "type": "object",
+            "properties": {
+                "allowedEndMinute": {
+                    "description": "AllowedEndMinute 每日允許時段的結束時間 (不包含)",
+                    "type": "integer"
+                },
+                "allowedStartMinute": {
+                    "description": "AllowedStartMinute 每日允許時段的開始時間",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "holderName": {
+                    "description": "HolderName 停車證持有人",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 適用的停車場，NULL 表示所有停車場皆適用",
+                    "type": "integer"
+                },
+                "permitID": {
+                    "description": "PermitID 作為主鍵",
+                    "type": "integer"
+                },
+                "permitType": {
+                    "description": "PermitType 停車證類型，例如 \"Monthly\", \"Season\"",
+                    "type": "string"
+                },
+                "plates": {
+                    "description": "Plates 此停車證登記的車牌",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PermitPlate"
+                    }
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                },
+                "validFrom": {
+                    "description": "ValidFrom 生效時間",
+                    "type": "string"
+                },
+                "validUntil": {
+                    "description": "ValidUntil 到期時間 (不包含)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PermitPlate": {
+            "type": "object",
+            "properties": {
+                "licensePlate": {
+                    "description": "LicensePlate 標準格式的車牌號碼",
+                    "type": "string"
+                },
+                "permitID": {
+                    "description": "PermitID 所屬的停車證",
+                    "type": "integer"
+                },
+                "permitPlateID": {
+                    "description": "PermitPlateID 作為主鍵",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlateReview": {
+            "type": "object",
+            "properties": {
+                "alternatives": {
+                    "description": "Alternatives OCR 提供的其他可能車牌，依 OCR 回傳的順序排列",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlateReviewAlternative"
+                    }
+                },
+                "createdAt": {
+                    "description": "CreatedAt 排入審核的時間",
+                    "type": "string"
+                },
+                "licensePlate": {
+                    "description": "LicensePlate OCR 辨識出的標準格式車牌",
+                    "type": "string"
+                },
+                "ocrConfidence": {
+                    "description": "OcrConfidence OCR 的信心分數 (0.0 到 1.0 之間)",
+                    "type": "number"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 停車記錄所屬的停車場",
+                    "type": "integer"
+                },
+                "parkingRecord": {
+                    "description": "ParkingRecord 待審核的停車記錄，列出審核項目時一併載入以顯示進場時間與圖片",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ParkingRecord"
+                        }
+                    ]
+                },
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 待審核的停車記錄，每筆停車記錄最多一個審核項目",
+                    "type": "integer"
+                },
+                "plateReviewID": {
+                    "description": "PlateReviewID 作為主鍵",
+                    "type": "integer"
+                },
+                "rawLicensePlate": {
+                    "description": "RawLicensePlate OCR 回傳的原始車牌字串",
+                    "type": "string"
+                },
+                "reviewedAt": {
+                    "description": "ReviewedAt 審核時間，尚未審核則為 NULL",
+                    "type": "string"
+                },
+                "reviewedBy": {
+                    "description": "ReviewedBy 審核人員，尚未審核則為 NULL",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status 審核狀態：Pending, Approved, Corrected",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 更新時間",
+                    "type": "string"
+                },
+                "verifiedLicensePlate": {
+                    "description": "VerifiedLicensePlate 審核確認的標準格式車牌，尚未審核則為 NULL",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PlateReviewAlternative": {
+            "type": "object",
+            "properties": {
+                "licensePlate": {
+                    "description": "LicensePlate 標準格式的車牌",
+                    "type": "string"
+                },
+                "plateReviewAlternativeID": {
+                    "description": "PlateReviewAlternativeID 作為主鍵",
+                    "type": "integer"
+                },
+                "plateReviewID": {
+                    "description": "PlateReviewID 所屬的審核項目",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Reservation": {
+            "type": "object",
+            "properties": {
+                "cancelledAt": {
+                    "description": "CancelledAt 取消時間",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "depositAmount": {
+                    "description": "DepositAmount 訂金金額，未付訂金則為 0",
+                    "type": "number"
+                },
+                "depositStatus": {
+                    "description": "DepositStatus 訂金狀態：None (未付訂金), Pending, Paid, Failed, Refunded, Forfeited (逾時未到沒收)",
+                    "type": "string"
+                },
+                "depositTransactionID": {
+                    "description": "DepositTransactionID 訂金的交易記錄，尚未付款則為 NULL",
+                    "type": "integer"
+                },
+                "endTime": {
+                    "description": "EndTime 預約結束時間 (不包含)",
                     "type": "string"
                 },
-                "error": {
+                "licensePlate": {
+                    "description": "LicensePlate 預約的標準格式車牌號碼",
+                    "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 預約的停車場",
+                    "type": "integer"
+                },
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 預約車輛進場後的停車記錄，尚未進場則為 NULL",
+                    "type": "integer"
+                },
+                "reservationID": {
+                    "description": "ReservationID 作為主鍵",
+                    "type": "integer"
+                },
+                "startTime": {
+                    "description": "StartTime 預約開始時間",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status 預約狀態：Confirmed, Cancelled, Fulfilled, Expired",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                },
+                "vehicleClass": {
+                    "description": "VehicleClass 預約的車種：Car, Motorcycle, Large",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Spot": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code 車位編號，同一分區內不可重複 (例如 \"B2-014\")",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "occupied": {
+                    "description": "Occupied 車位感應器最近一次回報是否有車",
+                    "type": "boolean"
+                },
+                "sensorID": {
+                    "description": "SensorID 車位感應器識別碼，在所有車位中不可重複",
+                    "type": "string"
+                },
+                "spotID": {
+                    "description": "SpotID 作為主鍵",
+                    "type": "integer"
+                },
+                "statusChangedAt": {
+                    "description": "StatusChangedAt 車位最近一次由空轉為有車或由有車轉為空的時間，尚未回報則為 NULL",
                     "type": "string"
                 },
-                "licensePlate": {
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
                     "type": "string"
                 },
-                "parkingRecordID": {
+                "zoneID": {
+                    "description": "ZoneID 車位所屬的分區",
                     "type": "integer"
-                },
-                "paymentStatus": {
-                    "type": "string"
                 }
             }
         },
-        "dtos.ImageAttachmentRateResponse": {
+        "models.Transaction": {
             "type": "object",
             "properties": {
-                "attachment_rate": {
-                    "description": "Value between 0.0 and 1.0",
+                "amount": {
+                    "description": "Amount 交易金額",
                     "type": "number"
                 },
-                "entries_with_image": {
+                "lineItems": {
+                    "description": "LineItems 付款明細，退款與舊交易沒有明細",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransactionLineItem"
+                    }
+                },
+                "operator": {
+                    "description": "Operator 執行退款的操作人員",
+                    "type": "string"
+                },
+                "originalTransactionID": {
+                    "description": "OriginalTransactionID 退款交易所對應的原始交易，非退款交易則為 NULL",
                     "type": "integer"
                 },
-                "total_entries": {
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 關聯到 ParkingRecords 表的外鍵，預約車輛尚未進場時的訂金交易為 0",
+                    "type": "integer"
+                },
+                "paymentGatewayResponse": {
+                    "description": "PaymentGatewayResponse 支付閘道回傳的詳細資訊 (JSON或TEXT)",
+                    "type": "string"
+                },
+                "paymentMethod": {
+                    "description": "PaymentMethod 付款方式，例如 \"CreditCard\", \"MobilePay\", \"Cash\"",
+                    "type": "string"
+                },
+                "paymentProvider": {
+                    "description": "PaymentProvider 處理此交易的支付供應商，例如 \"Cash\", \"Simulator\"",
+                    "type": "string"
+                },
+                "providerReference": {
+                    "description": "ProviderReference 支付供應商端的交易參考編號，退款時使用",
+                    "type": "string"
+                },
+                "refundReason": {
+                    "description": "RefundReason 退款原因",
+                    "type": "string"
+                },
+                "reservationID": {
+                    "description": "ReservationID 預約訂金及其退款所屬的預約，其他交易則為 NULL",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status 交易狀態：Success, Failed, Refunded (原始交易已全額退款), Pending (退款等待支付供應商處理)",
+                    "type": "string"
+                },
+                "transactionID": {
+                    "description": "TransactionID 作為主鍵",
                     "type": "integer"
+                },
+                "transactionTime": {
+                    "description": "TransactionTime 交易時間",
+                    "type": "string"
+                },
+                "transactionType": {
+                    "description": "TransactionType 交易類型：Payment (停車費), Overstay (超過出場寬限期的補繳), Deposit (預約訂金，進場後折抵停車費), Refund (退款，金額為負數)",
+                    "type": "string"
                 }
             }
         },
-        "dtos.ParkingPaymentPayload": {
+        "models.TransactionLineItem": {
             "type": "object",
-            "required": [
-                "amountPaid",
-                "paymentMethod"
-            ],
             "properties": {
-                "amountPaid": {
-                    "type": "number",
-                    "example": 50
+                "amount": {
+                    "description": "Amount 明細金額，折抵為負數",
+                    "type": "number"
                 },
-                "paymentMethod": {
-                    "type": "string",
-                    "example": "MobilePay"
+                "chargingSessionID": {
+                    "description": "ChargingSessionID 電費與佔用費明細所屬的充電，其他明細則為 NULL",
+                    "type": "integer"
                 },
-                "paymentReference": {
-                    "description": "可選，如果前端有來自支付閘道的參考ID或備註",
-                    "type": "string",
-                    "example": "TXN_REF_123XYZ"
+                "description": {
+                    "description": "Description 明細說明",
+                    "type": "string"
+                },
+                "itemType": {
+                    "description": "ItemType 明細類型：Parking, Energy, IdleFee, Adjustment (預約訂金等先前付款的折抵)",
+                    "type": "string"
+                },
+                "lineItemID": {
+                    "description": "LineItemID 作為主鍵",
+                    "type": "integer"
+                },
+                "paymentIntentID": {
+                    "description": "PaymentIntentID 明細所屬的付款意圖",
+                    "type": "integer"
+                },
+                "transactionID": {
+                    "description": "TransactionID 請款完成後建立的交易，尚未完成則為 NULL",
+                    "type": "integer"
                 }
             }
         },
-        "dtos.ParkingRecordWithTransactionResponse": {
+        "models.ValidationCode": {
             "type": "object",
             "properties": {
-                "actualDurationMinutes": {
-                    "description": "ActualDurationMinutes 實際停車時長（分鐘）",
-                    "type": "integer"
+                "active": {
+                    "description": "Active 折抵碼是否可使用",
+                    "type": "boolean"
                 },
-                "calculatedAmount": {
-                    "description": "CalculatedAmount 應付停車費用",
+                "amount": {
+                    "description": "Amount FixedAmount 的折抵金額，或 FlatFee 的固定停車費用",
                     "type": "number"
                 },
-                "entryTime": {
-                    "description": "EntryTime 進場時間",
-                    "type": "string"
-                },
-                "exitTime": {
-                    "description": "ExitTime 出場時間，如果尚未出場則為 NULL",
+                "code": {
+                    "description": "Code 折抵碼，不分大小寫且不可重複",
                     "type": "string"
                 },
-                "image": {
-                    "description": "New fields",
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
                     "type": "string"
                 },
-                "licensePlate": {
-                    "description": "LicensePlate 車牌號碼 (通常來自 OCR)",
+                "discountType": {
+                    "description": "DiscountType 折抵方式：FixedAmount, Percentage, FreeMinutes, FlatFee",
                     "type": "string"
                 },
-                "paymentStatus": {
-                    "description": "PaymentStatus 支付狀態：Pending, Paid, Refunded",
+                "expiresAt": {
+                    "description": "ExpiresAt 到期時間，NULL 表示不會到期",
                     "type": "string"
                 },
-                "recordID": {
-                    "description": "RecordID 作為主鍵",
+                "freeMinutes": {
+                    "description": "FreeMinutes FreeMinutes 的免費分鐘數",
                     "type": "integer"
                 },
-                "sensorEntryID": {
-                    "description": "SensorEntryID 入場感應器記錄ID",
-                    "type": "string"
+                "maxUses": {
+                    "description": "MaxUses 可使用次數上限，0 表示不限",
+                    "type": "integer"
                 },
-                "sensorExitID": {
-                    "description": "SensorExitID 出場感應器記錄ID",
-                    "type": "string"
+                "merchant": {
+                    "$ref": "#/definitions/models.Merchant"
                 },
-                "transaction": {
-                    "$ref": "#/definitions/models.Transaction"
+                "merchantID": {
+                    "description": "MerchantID 發放折抵碼的商家",
+                    "type": "integer"
                 },
-                "transactionID": {
-                    "description": "TransactionID 關聯到 Transactions 表的外鍵，如果尚未支付或無交易則為 NULL",
+                "percent": {
+                    "description": "Percent Percentage 的折抵百分比 (1-100)",
                     "type": "integer"
                 },
-                "userVerifiedLicensePlate": {
-                    "description": "UserVerifiedLicensePlate 使用者驗證/修正後的車牌號碼，可以為 NULL",
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
                     "type": "string"
+                },
+                "usedCount": {
+                    "description": "UsedCount 已使用次數",
+                    "type": "integer"
+                },
+                "validationCodeID": {
+                    "description": "ValidationCodeID 作為主鍵",
+                    "type": "integer"
                 }
             }
         },
-        "dtos.SimpleEntryPayload": {
+        "models.Vehicle": {
             "type": "object",
-            "required": [
-                "licensePlate"
-            ],
             "properties": {
+                "color": {
+                    "description": "Color 車身顏色，由現場人員填寫，未知則為 NULL",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "firstSeenAt": {
+                    "description": "FirstSeenAt 第一次進場的時間",
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "description": "LastSeenAt 最近一次進場的時間",
+                    "type": "string"
+                },
                 "licensePlate": {
-                    "type": "string",
-                    "example": "ABC-1234"
-                }
-            }
-        },
-        "dtos.SuccessResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "Operation successful"
-                }
-            }
-        },
-        "dtos.SuccessResponseWithData": {
-            "type": "object",
-            "properties": {
-                "data": {},
-                "message": {
+                    "description": "LicensePlate 標準格式的車牌號碼，每個車牌只有一筆車輛資料",
                     "type": "string"
-                }
-            }
-        },
-        "dtos.TotalParkingCountResponse": {
-            "type": "object",
-            "properties": {
-                "total_count": {
+                },
+                "make": {
+                    "description": "Make 廠牌，由現場人員填寫，未知則為 NULL",
+                    "type": "string"
+                },
+                "notes": {
+                    "description": "Notes 現場人員的備註",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                },
+                "vehicleID": {
+                    "description": "VehicleID 作為主鍵",
                     "type": "integer"
                 }
             }
         },
-        "dtos.TotalRevenueResponse": {
+        "models.WatchlistAlert": {
             "type": "object",
             "properties": {
-                "currency": {
-                    "description": "e.g., \"TWD\", \"USD\"",
+                "acknowledgeNote": {
+                    "description": "AcknowledgeNote 確認時的處理說明",
                     "type": "string"
                 },
-                "total_revenue": {
-                    "type": "number"
-                }
-            }
-        },
-        "dtos.VerifyLicensePlatePayload": {
-            "type": "object",
-            "required": [
-                "licensePlate"
-            ],
-            "properties": {
-                "licensePlate": {
-                    "type": "string",
-                    "example": "XYZ-7890"
-                }
-            }
-        },
-        "models.ParkingRecord": {
-            "type": "object",
-            "properties": {
-                "actualDurationMinutes": {
-                    "description": "ActualDurationMinutes 實際停車時長（分鐘）",
-                    "type": "integer"
+                "acknowledgedAt": {
+                    "description": "AcknowledgedAt 確認警示的時間，尚未確認則為 NULL",
+                    "type": "string"
                 },
-                "calculatedAmount": {
-                    "description": "CalculatedAmount 應付停車費用",
-                    "type": "number"
+                "acknowledgedBy": {
+                    "description": "AcknowledgedBy 確認警示的人員，尚未確認則為 NULL",
+                    "type": "string"
                 },
-                "entryTime": {
-                    "description": "EntryTime 進場時間",
+                "action": {
+                    "description": "Action 監控名單的處理方式",
                     "type": "string"
                 },
-                "exitTime": {
-                    "description": "ExitTime 出場時間，如果尚未出場則為 NULL",
+                "category": {
+                    "description": "Category 監控名單類別",
                     "type": "string"
                 },
-                "image": {
-                    "description": "New fields",
+                "createdAt": {
+                    "description": "CreatedAt 發生警示的時間",
+                    "type": "string"
+                },
+                "event": {
+                    "description": "Event 發生警示的時機：Entry, EntryDenied, Exit",
                     "type": "string"
                 },
                 "licensePlate": {
-                    "description": "LicensePlate 車牌號碼 (通常來自 OCR)",
+                    "description": "LicensePlate 進出場時辨識的標準格式車牌",
                     "type": "string"
                 },
-                "paymentStatus": {
-                    "description": "PaymentStatus 支付狀態：Pending, Paid, Refunded",
+                "matchType": {
+                    "description": "MatchType 比對方式：Exact, Variant, Fuzzy",
                     "type": "string"
                 },
-                "recordID": {
-                    "description": "RecordID 作為主鍵",
+                "parkingLotID": {
+                    "description": "ParkingLotID 發生警示的停車場",
                     "type": "integer"
                 },
-                "sensorEntryID": {
-                    "description": "SensorEntryID 入場感應器記錄ID",
-                    "type": "string"
+                "parkingRecordID": {
+                    "description": "ParkingRecordID 相關的停車記錄，拒絕進場時為 NULL",
+                    "type": "integer"
                 },
-                "sensorExitID": {
-                    "description": "SensorExitID 出場感應器記錄ID",
+                "reason": {
+                    "description": "Reason 列入監控名單的原因",
                     "type": "string"
                 },
-                "transaction": {
-                    "description": "GORM 模型關聯定義\nVehicle     Vehicle     ` + "`" + `gorm:\"foreignKey:VehicleID\"` + "`" + ` // 移除 Vehicle 關聯",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.Transaction"
-                        }
-                    ]
+                "similarity": {
+                    "description": "Similarity 車牌相似度 (0.0 到 1.0 之間)，Exact 為 1",
+                    "type": "number"
                 },
-                "transactionID": {
-                    "description": "TransactionID 關聯到 Transactions 表的外鍵，如果尚未支付或無交易則為 NULL",
+                "watchlistAlertID": {
+                    "description": "WatchlistAlertID 作為主鍵",
                     "type": "integer"
                 },
-                "userVerifiedLicensePlate": {
-                    "description": "UserVerifiedLicensePlate 使用者驗證/修正後的車牌號碼，可以為 NULL",
+                "watchlistEntryID": {
+                    "description": "WatchlistEntryID 比對到的監控名單項目",
+                    "type": "integer"
+                },
+                "watchlistLicensePlate": {
+                    "description": "WatchlistLicensePlate 監控名單上的車牌",
                     "type": "string"
                 }
             }
         },
-        "models.Transaction": {
+        "models.WatchlistEntry": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "Amount 交易金額",
-                    "type": "number"
+                "action": {
+                    "description": "Action 車輛出現時的處理方式：Alert, DenyEntry, Flag",
+                    "type": "string"
                 },
-                "parkingRecordID": {
-                    "description": "ParkingRecordID 關聯到 ParkingRecords 表的外鍵",
-                    "type": "integer"
+                "active": {
+                    "description": "Active 是否啟用",
+                    "type": "boolean"
                 },
-                "paymentGatewayResponse": {
-                    "description": "PaymentGatewayResponse 支付閘道回傳的詳細資訊 (JSON或TEXT)",
+                "category": {
+                    "description": "Category 類別：Stolen, Banned, VIP, Other",
                     "type": "string"
                 },
-                "paymentMethod": {
-                    "description": "PaymentMethod 付款方式，例如 \"CreditCard\", \"MobilePay\", \"Cash\"",
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
                     "type": "string"
                 },
-                "status": {
-                    "description": "Status 交易狀態：Success, Failed, Refunded",
+                "createdBy": {
+                    "description": "CreatedBy 列入監控名單的人員",
                     "type": "string"
                 },
-                "transactionID": {
-                    "description": "TransactionID 作為主鍵",
+                "expiresAt": {
+                    "description": "ExpiresAt 自動失效的時間，NULL 表示不會失效",
+                    "type": "string"
+                },
+                "licensePlate": {
+                    "description": "LicensePlate 標準格式的車牌號碼",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason 列入監控名單的原因，會顯示在警示中",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                },
+                "watchlistEntryID": {
+                    "description": "WatchlistEntryID 作為主鍵",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Zone": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "CreatedAt 建立時間",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description 分區說明，例如 \"機車專用\"",
+                    "type": "string"
+                },
+                "level": {
+                    "description": "Level 分區所在樓層，地下樓層為負數，看板依樓層排序",
                     "type": "integer"
                 },
-                "transactionTime": {
-                    "description": "TransactionTime 交易時間",
+                "name": {
+                    "description": "Name 分區名稱，同一停車場內不可重複，會顯示在看板上 (例如 \"B2\")",
                     "type": "string"
+                },
+                "parkingLotID": {
+                    "description": "ParkingLotID 分區所屬的停車場",
+                    "type": "integer"
+                },
+                "spots": {
+                    "description": "Spots 分區內的車位",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Spot"
+                    }
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt 最後更新時間",
+                    "type": "string"
+                },
+                "zoneID": {
+                    "description": "ZoneID 作為主鍵",
+                    "type": "integer"
                 }
             }
         }
@@ -0,0 +1,38 @@
+package dtos
+
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
+// StartChargingPayload defines the JSON structure a charger sends when it starts charging a parked vehicle.
+// The vehicle is identified by ParkingRecordID or, when omitted, by the LicensePlate of a vehicle currently in the lot.
+// Meter readings are the charger's cumulative energy meter in watt-hours.
+type StartChargingPayload struct {
+	ChargerID       string `json:"chargerId" binding:"required" example:"LOT1-EV-01"`
+	ParkingRecordID uint   `json:"parkingRecordId,omitempty" example:"1"`
+	LicensePlate    string `json:"licensePlate,omitempty" example:"ABC-1234"`
+	MeterWh         *int64 `json:"meterWh" binding:"required,min=0" example:"1520000"`
+}
+
+// MeterReadingPayload defines the JSON structure of a meter reading sent by a charger during or at the end of charging.
+// ReadAt defaults to the time the reading is received.
+type MeterReadingPayload struct {
+	MeterWh *int64     `json:"meterWh" binding:"required,min=0" example:"1532345"`
+	ReadAt  *time.Time `json:"readAt,omitempty" example:"2026-10-18T10:30:00+08:00"`
+}
+
+// ChargingCharge is the charging line of a fee quote for a single charging session.
+// Energy is billed per kWh delivered; once charging stops, IdleMinutes past the idle grace period are billed per minute.
+type ChargingCharge struct {
+	ChargingSessionID uint         `json:"chargingSessionID" example:"1"`
+	ChargerID         string       `json:"chargerID" example:"LOT1-EV-01"`
+	Status            string       `json:"status" example:"Completed"`
+	EnergyKWh         float64      `json:"energyKWh" example:"12.345"`
+	EnergyRate        models.Money `json:"energyRate" example:"8.00"`
+	EnergyAmount      models.Money `json:"energyAmount" example:"98.76"`
+	IdleMinutes       int          `json:"idleMinutes" example:"20"`
+	IdleFeeRate       models.Money `json:"idleFeeRate" example:"2.00"`
+	IdleFeeAmount     models.Money `json:"idleFeeAmount" example:"40.00"`
+	Amount            models.Money `json:"amount" example:"138.76"`
+}
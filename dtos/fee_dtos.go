@@ -0,0 +1,78 @@
+package dtos
+
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
+// FeeBreakdown describes how a parking fee was calculated by the tariff.
+type FeeBreakdown struct {
+	DurationMinutes int          `json:"durationMinutes" example:"95"`
+	BillableUnits   int          `json:"billableUnits" example:"95"`
+	Segments        []FeeSegment `json:"segments"`
+	Days            []DailyFee   `json:"days"`
+	TotalAmount     models.Money `json:"totalAmount" example:"500.00"`
+}
+
+// FeeSegment is the part of a stay that falls into a single rate band on a single calendar day.
+type FeeSegment struct {
+	Date           string       `json:"date" example:"2025-06-06"`
+	DayType        string       `json:"dayType" example:"Weekday"`
+	Band           string       `json:"band" example:"Night"`
+	StartTime      time.Time    `json:"startTime"`
+	EndTime        time.Time    `json:"endTime"`
+	BillableUnits  int          `json:"billableUnits" example:"30"`
+	RatePerUnit    models.Money `json:"ratePerUnit" example:"5.00"`
+	UncappedAmount models.Money `json:"uncappedAmount" example:"150.00"`
+	Amount         models.Money `json:"amount" example:"100.00"`
+	CapApplied     bool         `json:"capApplied" example:"true"`
+}
+
+// DailyFee is the portion of a fee charged for a single calendar day, before and after the daily cap.
+type DailyFee struct {
+	Date           string       `json:"date" example:"2025-06-01"`
+	DayType        string       `json:"dayType" example:"Holiday"`
+	HolidayName    string       `json:"holidayName,omitempty" example:"端午節"`
+	BillableUnits  int          `json:"billableUnits" example:"95"`
+	UncappedAmount models.Money `json:"uncappedAmount" example:"950.00"`
+	Amount         models.Money `json:"amount" example:"500.00"`
+	CapApplied     bool         `json:"capApplied" example:"true"`
+}
+
+// FeeQuote is a read-only, itemized quote of the current fee for a parking record.
+// ExpiresAt is omitted once the vehicle has exited and the fee is final. GracePeriodEndsAt is set once
+// the record is paid; leaving after it requires an overstay payment of AmountDue.
+// TotalAmount is the parking Subtotal less the discounts, plus the EV ChargingTotal, which discounts do not apply to.
+type FeeQuote struct {
+	ParkingRecordID   uint             `json:"parkingRecordID" example:"1"`
+	ParkingLotID      uint             `json:"parkingLotID" example:"1"`
+	LicensePlate      string           `json:"licensePlate" example:"ABC-1234"`
+	PaymentStatus     string           `json:"paymentStatus" example:"Pending"`
+	EntryTime         time.Time        `json:"entryTime"`
+	QuotedAt          time.Time        `json:"quotedAt"`
+	ExpiresAt         *time.Time       `json:"expiresAt,omitempty"`
+	GracePeriodEndsAt *time.Time       `json:"gracePeriodEndsAt,omitempty"`
+	DurationMinutes   int              `json:"durationMinutes" example:"95"`
+	BillableUnits     int              `json:"billableUnits" example:"95"`
+	Segments          []FeeSegment     `json:"segments"`
+	Days              []DailyFee       `json:"days"`
+	Subtotal          models.Money     `json:"subtotal" example:"500.00"`
+	Discounts         []FeeDiscount    `json:"discounts"`
+	DiscountTotal     models.Money     `json:"discountTotal" example:"0.00"`
+	ChargingCharges   []ChargingCharge `json:"chargingCharges"`
+	ChargingTotal     models.Money     `json:"chargingTotal" example:"0.00"`
+	TotalAmount       models.Money     `json:"totalAmount" example:"500.00"`
+	AmountPaid        models.Money     `json:"amountPaid" example:"0.00"`
+	AmountDue         models.Money     `json:"amountDue" example:"500.00"`
+	Currency          string           `json:"currency" example:"TWD"`
+}
+
+// FeeDiscount is a single discount line applied to a fee quote.
+// Merchant validations carry the IDs of the applied validation and of the merchant that subsidises it.
+type FeeDiscount struct {
+	Code                string       `json:"code" example:"SHOP10"`
+	Description         string       `json:"description" example:"10% off"`
+	Amount              models.Money `json:"amount" example:"50.00"`
+	ParkingValidationID uint         `json:"parkingValidationID,omitempty" example:"1"`
+	MerchantID          uint         `json:"merchantID,omitempty" example:"1"`
+}
@@ -0,0 +1,30 @@
+package dtos
+
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
+// CreateMerchantPayload defines the JSON structure for registering a merchant.
+type CreateMerchantPayload struct {
+	Name         string `json:"name" binding:"required" example:"Campus Coffee"`
+	ContactEmail string `json:"contactEmail,omitempty" example:"owner@campuscoffee.tw"`
+}
+
+// CreateValidationCodePayload defines the JSON structure for issuing a validation code.
+// Amount is used by FixedAmount (amount off) and FlatFee (the flat fee charged); Percent by Percentage;
+// FreeMinutes by FreeMinutes. A missing Code is generated. MaxUses of 0 means unlimited.
+type CreateValidationCodePayload struct {
+	Code         string       `json:"code,omitempty" example:"COFFEE-30"`
+	DiscountType string       `json:"discountType" binding:"required,oneof=FixedAmount Percentage FreeMinutes FlatFee" example:"FreeMinutes"`
+	Amount       models.Money `json:"amount,omitzero" example:"30.00"`
+	Percent      int          `json:"percent,omitempty" example:"50"`
+	FreeMinutes  int          `json:"freeMinutes,omitempty" example:"60"`
+	MaxUses      int          `json:"maxUses,omitempty" example:"100"`
+	ExpiresAt    *time.Time   `json:"expiresAt,omitempty"`
+}
+
+// ApplyValidationPayload defines the JSON structure for applying a validation code to a parking record.
+type ApplyValidationPayload struct {
+	Code string `json:"code" binding:"required" example:"COFFEE-30"`
+}
@@ -0,0 +1,66 @@
+package dtos
+
+// ParkingLotPayload defines the JSON structure for creating or updating a parking lot.
+// Capacity, ReservedSpots and RateCalendar apply to cars; VehicleClasses opens the lot to motorcycles and large vehicles.
+// RateCalendar names one of the configured rate calendars and defaults to "Default".
+// ReservedSpots are held back for permit holders: other cars are refused once only that many spots are free.
+// Omitting Active keeps the current state (new lots are active), and omitting VehicleClasses keeps the current classes.
+type ParkingLotPayload struct {
+	Name          string `json:"name" binding:"required" example:"教授，你好停車場"`
+	Address       string `json:"address,omitempty" example:"高雄市燕巢區深中路58號"`
+	Capacity      int    `json:"capacity" binding:"required,min=1" example:"100"`
+	ReservedSpots int    `json:"reservedSpots,omitempty" example:"10"`
+	RateCalendar  string `json:"rateCalendar,omitempty" example:"Default"`
+	Active        *bool  `json:"active,omitempty" example:"true"`
+
+	VehicleClasses []ParkingLotVehicleClassPayload `json:"vehicleClasses,omitempty" binding:"omitempty,dive"`
+}
+
+// ParkingLotVehicleClassPayload defines the capacity and rate calendar of a vehicle class other than cars.
+// RateCalendar defaults to the configured calendar of the vehicle class.
+type ParkingLotVehicleClassPayload struct {
+	VehicleClass string `json:"vehicleClass" binding:"required,oneof=Motorcycle Large" example:"Motorcycle"`
+	Capacity     int    `json:"capacity" binding:"min=0" example:"50"`
+	RateCalendar string `json:"rateCalendar,omitempty" example:"Motorcycle"`
+}
+
+// ParkingLotSensorPayload defines the JSON structure for registering an entry or exit sensor of a parking lot.
+// VehicleClass marks the sensor of a lane for a single vehicle class, such as a motorcycle lane; vehicles entering there get that class.
+type ParkingLotSensorPayload struct {
+	SensorID     string `json:"sensorId" binding:"required" example:"LOT1-GATE-A-IN"`
+	Direction    string `json:"direction" binding:"required,oneof=Entry Exit" example:"Entry"`
+	VehicleClass string `json:"vehicleClass,omitempty" binding:"omitempty,oneof=Car Motorcycle Large" example:"Motorcycle"`
+}
+
+// ZonePayload defines the JSON structure for creating or updating a zone of a parking lot, such as a floor or a motorcycle area.
+// Level orders zones on the signboard; basement levels are negative.
+type ZonePayload struct {
+	Name        string `json:"name" binding:"required" example:"B2"`
+	Level       int    `json:"level,omitempty" example:"-2"`
+	Description string `json:"description,omitempty" example:"地下二樓汽車位"`
+}
+
+// SpotPayload defines the JSON structure for adding a spot to a zone.
+// SensorID identifies the spot sensor that reports whether the spot is occupied.
+type SpotPayload struct {
+	Code     string `json:"code" binding:"required" example:"B2-014"`
+	SensorID string `json:"sensorId" binding:"required" example:"LOT1-B2-014"`
+}
+
+// SpotStatusPayload defines the JSON structure a spot sensor uses to report whether its spot is occupied.
+// When LicensePlate is given on an occupied report, the vehicle's active parking record is linked to the spot.
+type SpotStatusPayload struct {
+	Occupied     *bool  `json:"occupied" binding:"required" example:"true"`
+	LicensePlate string `json:"licensePlate,omitempty" example:"ABC-1234"`
+}
+
+// SpotStatusResponse defines the response to a spot sensor report.
+// ParkingRecordID is set when the report linked a vehicle's active parking record to the spot.
+type SpotStatusResponse struct {
+	SpotID          uint   `json:"spotId" example:"14"`
+	Code            string `json:"code" example:"B2-014"`
+	ZoneID          uint   `json:"zoneId" example:"2"`
+	ZoneName        string `json:"zoneName" example:"B2"`
+	Occupied        bool   `json:"occupied" example:"true"`
+	ParkingRecordID *uint  `json:"parkingRecordId,omitempty" example:"42"`
+}
@@ -1,9 +1,12 @@
 package dtos
 
+import "hello-professor_backend/models"
+
 // ParkingPaymentPayload defines the JSON structure for paying a parking record.
+// PaymentMethod selects the payment provider, e.g. "Cash" or a gateway method such as "MobilePay".
 type ParkingPaymentPayload struct {
-	PaymentMethod string  `json:"paymentMethod" binding:"required" example:"MobilePay"`
-	AmountPaid    float64 `json:"amountPaid" binding:"required" example:"50.00"`
-	// 可選，如果前端有來自支付閘道的參考ID或備註
+	PaymentMethod string       `json:"paymentMethod" binding:"required" example:"MobilePay"`
+	AmountPaid    models.Money `json:"amountPaid" binding:"required" example:"50.00"`
+	// 可選，如果前端有來自支付閘道的參考ID或備註 (模擬支付閘道：含 DECLINE 會被拒絕，含 TIMEOUT 會逾時)
 	PaymentReference string `json:"paymentReference,omitempty" example:"TXN_REF_123XYZ"`
 }
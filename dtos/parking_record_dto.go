@@ -15,16 +15,65 @@ type VehicleEntryExitPayload struct {
 // Used for responses where both are relevant, e.g., after a payment.
 type ParkingRecordWithTransactionResponse struct {
 	models.ParkingRecord
-	Transaction models.Transaction `json:"transaction"`
+	Transaction   models.Transaction    `json:"transaction"`
+	PaymentIntent *models.PaymentIntent `json:"paymentIntent,omitempty"`
+}
+
+// ParkingRecordWithFeeBreakdownResponse combines a ParkingRecord with the breakdown of its calculated fee
+// and the merchant validations deducted from it, plus the EV charging charges billed on top.
+// AmountPaid is what the record has already been credited, such as a reservation deposit, and AmountDue is what remains to pay.
+// Used when preparing a parking record for payment.
+type ParkingRecordWithFeeBreakdownResponse struct {
+	models.ParkingRecord
+	FeeBreakdown    FeeBreakdown     `json:"feeBreakdown"`
+	Discounts       []FeeDiscount    `json:"discounts"`
+	ChargingCharges []ChargingCharge `json:"chargingCharges"`
+	AmountPaid      models.Money     `json:"amountPaid"`
+	AmountDue       models.Money     `json:"amountDue"`
 }
 
 // ErrorResponseWithRecord defines the JSON structure for an error response that includes parking record details.
-// Typically used for 402 Payment Required errors during vehicle exit.
+// Typically used for 402 Payment Required errors during vehicle exit, including overstays after the exit grace period.
 type ErrorResponseWithRecord struct {
-	Error            string    `json:"error"`
-	ParkingRecordID  uint      `json:"parkingRecordID,omitempty"`
-	LicensePlate     string    `json:"licensePlate,omitempty"`
-	CalculatedAmount float64   `json:"calculatedAmount,omitempty"`
-	PaymentStatus    string    `json:"paymentStatus,omitempty"`
-	EntryTime        time.Time `json:"entryTime,omitempty"` // Assuming models.ParkingRecord.EntryTime is time.Time
+	Error             string       `json:"error"`
+	ParkingRecordID   uint         `json:"parkingRecordID,omitempty"`
+	LicensePlate      string       `json:"licensePlate,omitempty"`
+	CalculatedAmount  models.Money `json:"calculatedAmount,omitzero"`
+	AmountDue         models.Money `json:"amountDue,omitzero"`
+	PaymentStatus     string       `json:"paymentStatus,omitempty"`
+	EntryTime         time.Time    `json:"entryTime,omitempty"` // Assuming models.ParkingRecord.EntryTime is time.Time
+	GracePeriodEndsAt *time.Time   `json:"gracePeriodEndsAt,omitempty"`
+}
+
+// ExitMatchOptions controls how an exiting license plate is matched to an active parking record.
+// ParkingRecordID, when set, is the record confirmed by the gate operator and takes precedence over the plate.
+type ExitMatchOptions struct {
+	FuzzyMatch      bool
+	ParkingRecordID uint
+}
+
+// ExitCandidate is an active parking record whose license plate is similar to the plate read at the exit.
+// Similarity ranges from 0.0 to 1.0.
+type ExitCandidate struct {
+	ParkingRecordID          uint      `json:"parkingRecordID" example:"12"`
+	LicensePlate             string    `json:"licensePlate" example:"ABC-1234"`
+	UserVerifiedLicensePlate *string   `json:"userVerifiedLicensePlate,omitempty" example:"ABC-1234"`
+	EntryTime                time.Time `json:"entryTime"`
+	ImageURL                 *string   `json:"imageUrl,omitempty" example:"/api/v1/parking-records/12/images/original"`
+	ThumbnailURL             *string   `json:"thumbnailUrl,omitempty" example:"/api/v1/parking-records/12/images/thumbnail"`
+	Similarity               float64   `json:"similarity" example:"0.64"`
+}
+
+// ExitCandidatesResponse is returned with 300 Multiple Choices when a fuzzy exit match is ambiguous.
+// The gate operator confirms one candidate by repeating the exit request with its parkingRecordId.
+type ExitCandidatesResponse struct {
+	Error        string          `json:"error"`
+	LicensePlate string          `json:"licensePlate" example:"ABC-1Z34"`
+	Candidates   []ExitCandidate `json:"candidates"`
+}
+
+// AbandonParkingRecordPayload defines the JSON structure for closing a parking record whose vehicle left without passing an exit.
+type AbandonParkingRecordPayload struct {
+	ClosedBy string `json:"closedBy" binding:"required" example:"operator-01"`
+	Reason   string `json:"reason" binding:"required" example:"Vehicle not found on site during patrol"`
 }
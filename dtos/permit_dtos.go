@@ -0,0 +1,18 @@
+package dtos
+
+import "time"
+
+// PermitPayload defines the JSON structure for creating or replacing a parking permit.
+// AllowedStartTime and AllowedEndTime are "HH:MM" in the parking lot's local time; an end before the start
+// spans midnight, and omitting both (or giving the same time) allows the whole day.
+// Omitting ParkingLotID makes the permit valid in every lot.
+type PermitPayload struct {
+	HolderName       string    `json:"holderName" binding:"required" example:"王教授"`
+	PermitType       string    `json:"permitType,omitempty" example:"Monthly"`
+	ParkingLotID     *uint     `json:"parkingLotId,omitempty" example:"1"`
+	LicensePlates    []string  `json:"licensePlates" binding:"required,min=1,dive,required" example:"ABC-1234"`
+	ValidFrom        time.Time `json:"validFrom" binding:"required" example:"2026-10-01T00:00:00+08:00"`
+	ValidUntil       time.Time `json:"validUntil" binding:"required" example:"2026-11-01T00:00:00+08:00"`
+	AllowedStartTime string    `json:"allowedStartTime,omitempty" example:"07:00"`
+	AllowedEndTime   string    `json:"allowedEndTime,omitempty" example:"19:00"`
+}
@@ -0,0 +1,47 @@
+package dtos
+
+import "time"
+
+// OcrReading is the confidence of the OCR read of a license plate at entry, with the other plates it considered.
+type OcrReading struct {
+	Confidence   float64
+	Alternatives []string
+}
+
+// ApprovePlateReviewPayload defines the JSON structure for confirming that the OCR read of a plate is correct.
+type ApprovePlateReviewPayload struct {
+	ReviewedBy string `json:"reviewedBy" binding:"required" example:"reviewer-01"`
+}
+
+// CorrectPlateReviewPayload defines the JSON structure for correcting the OCR read of a plate.
+// LicensePlate is stored in the canonical format as the record's UserVerifiedLicensePlate.
+type CorrectPlateReviewPayload struct {
+	ReviewedBy   string `json:"reviewedBy" binding:"required" example:"reviewer-01"`
+	LicensePlate string `json:"licensePlate" binding:"required" example:"ABC-1284"`
+}
+
+// PlateReviewThroughputResponse defines the structure for the plate review throughput report.
+// Queued counts the reviews queued in the time range and Reviewed those completed in it; Pending is the current backlog.
+// ReviewsPerHour is only given when both ends of the time range are set.
+type PlateReviewThroughputResponse struct {
+	QueuedCount        int64                        `json:"queued_count"`
+	ReviewedCount      int64                        `json:"reviewed_count"`
+	ApprovedCount      int64                        `json:"approved_count"`
+	CorrectedCount     int64                        `json:"corrected_count"`
+	CorrectionRate     float64                      `json:"correction_rate"` // Value between 0.0 and 1.0
+	AverageWaitSeconds float64                      `json:"average_wait_seconds"`
+	ReviewsPerHour     *float64                     `json:"reviews_per_hour,omitempty"`
+	PendingCount       int64                        `json:"pending_count"`
+	OldestPendingAt    *time.Time                   `json:"oldest_pending_at,omitempty"`
+	ByReviewer         []ReviewerThroughputResponse `json:"by_reviewer"`
+}
+
+// ReviewerThroughputResponse defines the reviews completed by a single reviewer in the report's time range.
+type ReviewerThroughputResponse struct {
+	ReviewedBy         string   `json:"reviewed_by"`
+	ReviewedCount      int64    `json:"reviewed_count"`
+	ApprovedCount      int64    `json:"approved_count"`
+	CorrectedCount     int64    `json:"corrected_count"`
+	AverageWaitSeconds float64  `json:"average_wait_seconds"`
+	ReviewsPerHour     *float64 `json:"reviews_per_hour,omitempty"`
+}
@@ -0,0 +1,11 @@
+package dtos
+
+import "hello-professor_backend/models"
+
+// RefundTransactionPayload defines the JSON structure for refunding a transaction.
+// Omitting Amount refunds the whole remaining refundable amount.
+type RefundTransactionPayload struct {
+	Amount   *models.Money `json:"amount,omitempty" example:"20.00"`
+	Reason   string        `json:"reason" binding:"required" example:"Gate malfunction, customer charged twice"`
+	Operator string        `json:"operator" binding:"required" example:"staff-007"`
+}
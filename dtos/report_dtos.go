@@ -1,14 +1,41 @@
 package dtos
 
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
 // TotalParkingCountResponse defines the structure for total parking count response
 type TotalParkingCountResponse struct {
-	TotalCount int64 `json:"total_count"`
+	TotalCount     int64                       `json:"total_count"`
+	ByVehicleClass []VehicleClassCountResponse `json:"by_vehicle_class"`
+}
+
+// VehicleClassCountResponse defines the parking count of a single vehicle class.
+type VehicleClassCountResponse struct {
+	VehicleClass string `json:"vehicle_class"`
+	TotalCount   int64  `json:"total_count"`
 }
 
-// TotalRevenueResponse defines the structure for total revenue response
+// TotalRevenueResponse defines the structure for total revenue response.
+// TotalRevenue is net of refunds; GrossRevenue is what was collected before refunds.
+// Both include ForfeitedDeposits, the deposits kept from no-show reservations.
 type TotalRevenueResponse struct {
-	TotalRevenue float64 `json:"total_revenue"`
-	Currency     string  `json:"currency"` // e.g., "TWD", "USD"
+	TotalRevenue      models.Money                  `json:"total_revenue"`
+	GrossRevenue      models.Money                  `json:"gross_revenue"`
+	RefundedAmount    models.Money                  `json:"refunded_amount"`
+	ForfeitedDeposits models.Money                  `json:"forfeited_deposits"`
+	Currency          string                        `json:"currency"` // e.g., "TWD", "USD"
+	ByVehicleClass    []VehicleClassRevenueResponse `json:"by_vehicle_class"`
+}
+
+// VehicleClassRevenueResponse defines the revenue of a single vehicle class.
+type VehicleClassRevenueResponse struct {
+	VehicleClass      string       `json:"vehicle_class"`
+	TotalRevenue      models.Money `json:"total_revenue"`
+	GrossRevenue      models.Money `json:"gross_revenue"`
+	RefundedAmount    models.Money `json:"refunded_amount"`
+	ForfeitedDeposits models.Money `json:"forfeited_deposits"`
 }
 
 // ImageAttachmentRateResponse defines the structure for image attachment rate
@@ -18,9 +45,108 @@ type ImageAttachmentRateResponse struct {
 	AttachmentRate   float64 `json:"attachment_rate"` // Value between 0.0 and 1.0
 }
 
-// AvailableSpotsResponse defines the structure for available parking spots response
+// AvailableSpotsResponse defines the structure for available parking spots response.
+// The totals are summed over the parking lots listed in ParkingLots, and ByVehicleClass sums them per vehicle class.
+// HeldSpots counts the spots held for reservations whose window is open; they are not available to other vehicles.
+// PublicAvailableSpots excludes the spots reserved for permit holders.
 type AvailableSpotsResponse struct {
-	TotalCapacity  int   `json:"total_capacity"`
-	OccupiedSpots  int64 `json:"occupied_spots"`
-	AvailableSpots int64 `json:"available_spots"`
+	TotalCapacity        int                         `json:"total_capacity"`
+	OccupiedSpots        int64                       `json:"occupied_spots"`
+	HeldSpots            int64                       `json:"held_spots"`
+	AvailableSpots       int64                       `json:"available_spots"`
+	ReservedSpots        int                         `json:"reserved_spots"`
+	PublicAvailableSpots int64                       `json:"public_available_spots"`
+	ParkingLots          []ParkingLotSpotsResponse   `json:"parking_lots"`
+	ByVehicleClass       []VehicleClassSpotsResponse `json:"by_vehicle_class"`
+}
+
+// ParkingLotSpotsResponse defines the available parking spots of a single parking lot.
+// OccupiedSpots can exceed TotalCapacity when operators admitted vehicles into a full lot.
+// The lot totals are summed over VehicleClasses, which lists cars and every other class the lot accepts or holds.
+// Zones are counted from spot sensors, while the lot totals are counted from parking records.
+type ParkingLotSpotsResponse struct {
+	ParkingLotID         uint                        `json:"parking_lot_id"`
+	Name                 string                      `json:"name"`
+	TotalCapacity        int                         `json:"total_capacity"`
+	OccupiedSpots        int64                       `json:"occupied_spots"`
+	HeldSpots            int64                       `json:"held_spots"`
+	AvailableSpots       int64                       `json:"available_spots"`
+	ReservedSpots        int                         `json:"reserved_spots"`
+	PublicAvailableSpots int64                       `json:"public_available_spots"`
+	IsFull               bool                        `json:"is_full"`
+	VehicleClasses       []VehicleClassSpotsResponse `json:"vehicle_classes"`
+	Zones                []ZoneSpotsResponse         `json:"zones"`
+}
+
+// VehicleClassSpotsResponse defines the available spots of a single vehicle class.
+// Only car spots are reserved for permit holders.
+type VehicleClassSpotsResponse struct {
+	VehicleClass         string `json:"vehicle_class"`
+	TotalCapacity        int    `json:"total_capacity"`
+	OccupiedSpots        int64  `json:"occupied_spots"`
+	HeldSpots            int64  `json:"held_spots"`
+	AvailableSpots       int64  `json:"available_spots"`
+	ReservedSpots        int    `json:"reserved_spots"`
+	PublicAvailableSpots int64  `json:"public_available_spots"`
+	IsFull               bool   `json:"is_full"`
+}
+
+// ZoneSpotsResponse defines the available spots of a single zone as reported by its spot sensors.
+type ZoneSpotsResponse struct {
+	ZoneID         uint   `json:"zone_id"`
+	Name           string `json:"name"`
+	Level          int    `json:"level"`
+	TotalSpots     int    `json:"total_spots"`
+	OccupiedSpots  int    `json:"occupied_spots"`
+	AvailableSpots int    `json:"available_spots"`
+}
+
+// SignboardResponse defines the lines shown on the entrance signboard of a parking lot, one per zone,
+// such as "B2: 14 free". A lot without zones shows a single line with the spots free for vehicles without a permit.
+type SignboardResponse struct {
+	ParkingLotID uint            `json:"parking_lot_id"`
+	Name         string          `json:"name"`
+	Lines        []SignboardLine `json:"lines"`
+}
+
+// SignboardLine defines a single line of the signboard.
+type SignboardLine struct {
+	Label          string `json:"label"`
+	AvailableSpots int64  `json:"available_spots"`
+	Text           string `json:"text"`
+}
+
+// MerchantSubsidyResponse defines how much a single merchant subsidised through validations.
+type MerchantSubsidyResponse struct {
+	MerchantID      uint         `json:"merchant_id"`
+	MerchantName    string       `json:"merchant_name"`
+	ValidationCount int64        `json:"validation_count"`
+	SubsidyAmount   models.Money `json:"subsidy_amount"`
+}
+
+// MerchantSubsidyReportResponse defines the structure for the merchant subsidy report.
+// Only validations on paid parking records are counted.
+type MerchantSubsidyReportResponse struct {
+	Merchants    []MerchantSubsidyResponse `json:"merchants"`
+	TotalSubsidy models.Money              `json:"total_subsidy"`
+	Currency     string                    `json:"currency"`
+}
+
+// PermitExpiryResponse defines a single permit in the permit expiry report.
+type PermitExpiryResponse struct {
+	PermitID      uint      `json:"permit_id"`
+	HolderName    string    `json:"holder_name"`
+	PermitType    string    `json:"permit_type"`
+	LicensePlates []string  `json:"license_plates"`
+	ValidUntil    time.Time `json:"valid_until"`
+	DaysRemaining int       `json:"days_remaining"` // Negative for permits that already expired
+}
+
+// PermitExpiryReportResponse defines the structure for the permit expiry report.
+// Expiring lists permits ending within WithinDays; RecentlyExpired those that ended in the past WithinDays.
+type PermitExpiryReportResponse struct {
+	WithinDays      int                    `json:"within_days"`
+	GeneratedAt     time.Time              `json:"generated_at"`
+	Expiring        []PermitExpiryResponse `json:"expiring"`
+	RecentlyExpired []PermitExpiryResponse `json:"recently_expired"`
 }
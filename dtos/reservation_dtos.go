@@ -0,0 +1,32 @@
+package dtos
+
+import (
+	"hello-professor_backend/models"
+	"time"
+)
+
+// ReservationPayload defines the JSON structure for reserving a spot for a time window.
+// Omitting ParkingLotID is allowed when only one lot exists, and VehicleClass defaults to Car.
+// Giving PaymentMethod prepays the reservation deposit, which is credited against the parking fee on arrival.
+type ReservationPayload struct {
+	ParkingLotID     uint      `json:"parkingLotId,omitempty" example:"1"`
+	LicensePlate     string    `json:"licensePlate" binding:"required" example:"ABC-1234"`
+	VehicleClass     string    `json:"vehicleClass,omitempty" binding:"omitempty,oneof=Car Motorcycle Large" example:"Car"`
+	StartTime        time.Time `json:"startTime" binding:"required" example:"2026-10-18T09:00:00+08:00"`
+	EndTime          time.Time `json:"endTime" binding:"required" example:"2026-10-18T12:00:00+08:00"`
+	PaymentMethod    string    `json:"paymentMethod,omitempty" example:"CreditCard"`
+	PaymentReference string    `json:"paymentReference,omitempty" example:"txn_123abc"`
+}
+
+// ReservationResponse combines a Reservation with the payment intent of its deposit.
+// PaymentIntent is set when a deposit payment was started and may still be pending provider confirmation.
+type ReservationResponse struct {
+	models.Reservation
+	PaymentIntent *models.PaymentIntent `json:"paymentIntent,omitempty"`
+}
+
+// CancelReservationResponse combines a cancelled Reservation with the refund of its deposit, if any.
+type CancelReservationResponse struct {
+	models.Reservation
+	DepositRefund *models.Transaction `json:"depositRefund,omitempty"`
+}
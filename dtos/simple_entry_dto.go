@@ -3,7 +3,29 @@ package dtos
 import "mime/multipart"
 
 // SimpleEntryPayload defines the JSON structure for simple vehicle entry requests using multipart/form-data.
+// ParkingLotID or a registered SensorID selects the parking lot; both may be omitted when only one lot exists.
+// On entry, VehicleClass (Car, Motorcycle or Large) defaults to the vehicle class of the entry sensor, then to Car.
+// On entry, OverrideCapacity lets an operator admit a vehicle into a full lot; OverrideBy and OverrideReason are then required.
+// On entry, OcrConfidence (0.0 to 1.0) and OcrAlternatives describe the OCR read; reads below the review threshold are queued for manual review.
+// On exit, FuzzyMatch falls back to the most similar license plates when no active record matches exactly,
+// and ParkingRecordID is the candidate the gate operator confirmed after an ambiguous match.
 type SimpleEntryPayload struct {
-	LicensePlate string                `form:"licensePlate" binding:"required" example:"ABC-1234"`
-	Image        *multipart.FileHeader `form:"image" swaggerignore:"true"` // Image file upload
+	LicensePlate     string                `form:"licensePlate" binding:"required" example:"ABC-1234"`
+	ParkingLotID     uint                  `form:"parkingLotId" json:"parkingLotId,omitempty" example:"1"`
+	SensorID         string                `form:"sensorId" json:"sensorId,omitempty" example:"LOT1-GATE-A-IN"`
+	VehicleClass     string                `form:"vehicleClass" json:"vehicleClass,omitempty" binding:"omitempty,oneof=Car Motorcycle Large" example:"Car"`
+	OverrideCapacity bool                  `form:"overrideCapacity" json:"overrideCapacity,omitempty" example:"false"`
+	OverrideBy       string                `form:"overrideBy" json:"overrideBy,omitempty" example:"operator-01"`
+	OverrideReason   string                `form:"overrideReason" json:"overrideReason,omitempty" example:"Disabled driver with appointment"`
+	Image            *multipart.FileHeader `form:"image" swaggerignore:"true"` // Image file upload
+	OcrConfidence    *float64              `form:"ocrConfidence" json:"ocrConfidence,omitempty" binding:"omitempty,min=0,max=1" example:"0.72"`
+	OcrAlternatives  []string              `form:"ocrAlternatives" json:"ocrAlternatives,omitempty" example:"ABC-1284"`
+	FuzzyMatch       bool                  `form:"fuzzyMatch" json:"fuzzyMatch,omitempty" example:"true"`
+	ParkingRecordID  uint                  `form:"parkingRecordId" json:"parkingRecordId,omitempty" example:"12"`
+}
+
+// CapacityOverride identifies the operator who admits a vehicle into a full parking lot, and why.
+type CapacityOverride struct {
+	OverriddenBy string
+	Reason       string
 }
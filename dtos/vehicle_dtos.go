@@ -0,0 +1,24 @@
+package dtos
+
+import "hello-professor_backend/models"
+
+// VehicleDetailsPayload defines the JSON structure for the details staff keep about a vehicle.
+// The details are replaced as a whole; omitted fields are cleared.
+type VehicleDetailsPayload struct {
+	Make  *string `json:"make,omitempty" example:"Toyota"`
+	Color *string `json:"color,omitempty" example:"White"`
+	Notes *string `json:"notes,omitempty" example:"Faculty member, usually parks on B2"`
+}
+
+// VehicleProfileResponse defines the JSON structure for a vehicle's profile.
+// AverageDwellMinutes only counts visits that have exited; an unpaid visit is one that exited without payment.
+// ActiveParkingRecordID is set while the vehicle is parked.
+type VehicleProfileResponse struct {
+	Vehicle               models.Vehicle `json:"vehicle"`
+	VisitCount            int64          `json:"visitCount"`
+	TotalSpend            models.Money   `json:"totalSpend"`
+	AverageDwellMinutes   float64        `json:"averageDwellMinutes"`
+	UnpaidVisitCount      int64          `json:"unpaidVisitCount"`
+	HasUnpaidBalance      bool           `json:"hasUnpaidBalance"`
+	ActiveParkingRecordID *uint          `json:"activeParkingRecordId,omitempty"`
+}
@@ -0,0 +1,22 @@
+package dtos
+
+import "time"
+
+// WatchlistEntryPayload defines the JSON structure for creating or replacing a watchlist entry.
+// Category is one of Stolen, Banned, VIP, Other and Action one of Alert, DenyEntry, Flag.
+// Omitting Active creates an active entry; omitting ExpiresAt keeps the entry until it is removed.
+type WatchlistEntryPayload struct {
+	LicensePlate string     `json:"licensePlate" binding:"required" example:"ABC-1234"`
+	Category     string     `json:"category" binding:"required" example:"Stolen"`
+	Action       string     `json:"action" binding:"required" example:"DenyEntry"`
+	Reason       string     `json:"reason,omitempty" example:"Reported stolen on 2026-10-01"`
+	CreatedBy    string     `json:"createdBy,omitempty" example:"security-01"`
+	Active       *bool      `json:"active,omitempty" example:"true"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty" example:"2026-12-31T23:59:59+08:00"`
+}
+
+// AcknowledgeWatchlistAlertPayload defines the JSON structure for acknowledging a watchlist alert.
+type AcknowledgeWatchlistAlertPayload struct {
+	AcknowledgedBy string `json:"acknowledgedBy" binding:"required" example:"operator-01"`
+	Note           string `json:"note,omitempty" example:"Police notified"`
+}
@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// Every 在背景以固定間隔重複執行 task，task 回傳的錯誤只會被記錄，不會中斷排程
+func Every(name string, interval time.Duration, task func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := task(); err != nil {
+				log.Printf("[Job:%s] 執行失敗: %v", name, err)
+			}
+		}
+	}()
+}
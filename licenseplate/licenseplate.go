@@ -0,0 +1,150 @@
+package licenseplate
+
+import (
+	"strings"
+	"unicode"
+)
+
+// pattern 描述一種台灣車牌格式，L 代表英文字母、D 代表數字，例如 "LLL-DDDD"
+type pattern string
+
+// patterns 依優先順序列出常見的台灣車牌格式，同樣可行時較前面的格式優先
+var patterns = []pattern{
+	"LLL-DDDD", // 2012 年後的自用小客車、電動車與大型重機
+	"LL-DDDD",  // 舊式自用小客車
+	"DDDD-LL",  // 舊式自用小客車
+	"LLL-DDD",  // 普通重型機車
+	"DDD-LLL",  // 舊式普通重型機車
+	"LL-DDD",   // 舊式營業用車輛
+	"DDD-LL",   // 舊式輕型機車
+}
+
+// asDigit 是出現在數字位置時應讀成數字的易混淆字母；台灣車牌不使用字母 I 與 O
+var asDigit = map[rune]rune{'O': '0', 'I': '1', 'B': '8'}
+
+// asLetter 是出現在字母位置時應讀成字母的易混淆數字
+var asLetter = map[rune]rune{'8': 'B'}
+
+// Normalize 將 OCR 或人工輸入的車牌轉為標準格式，例如 "abc1234"、"ABC 1234"、"AB C-I234" 都會成為 "ABC-1234"
+// 會轉為大寫、移除空白與分隔符號，並依台灣車牌格式校正 O/0、I/1、B/8 等易混淆字元
+// 原本有分隔符號時優先採用分隔位置相符的格式，其次是校正字元較少的格式，同分時取 patterns 中較前面的格式
+// 字母位置只會將 8 校正為 B，0 與 1 不會被讀成字母；不符合任何格式的車牌只轉為大寫並移除分隔符號
+func Normalize(raw string) string {
+	compact, split := compactPlate(raw)
+	if compact == "" {
+		return ""
+	}
+
+	best, bestScore := "", -1
+	for _, p := range patterns {
+		candidate, substitutions, ok := p.match(compact)
+		if !ok {
+			continue
+		}
+		// 分隔位置不符視為比任何字元校正都不可信
+		score := substitutions
+		if split > 0 && split != p.split() {
+			score += len(compact) + 1
+		}
+		if bestScore < 0 || score < bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if bestScore < 0 {
+		return compact
+	}
+	return best
+}
+
+// SearchKey 回傳用於模糊比對的車牌鍵值：大寫、移除分隔符號，並將 O、I 與 8 統一為 0、1 與 B
+// 適用於不完整的車牌片段，SQL 端可用 TRANSLATE(UPPER(license_plate), '8OI- ', 'B01') 得到相同的鍵值
+func SearchKey(raw string) string {
+	compact, _ := compactPlate(raw)
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case 'O':
+			return '0'
+		case 'I':
+			return '1'
+		case '8':
+			return 'B'
+		}
+		return r
+	}, compact)
+}
+
+// SearchKeySQL 回傳將車牌欄位 column 轉為 SearchKey 鍵值的 SQL 表示式
+func SearchKeySQL(column string) string {
+	return "TRANSLATE(UPPER(" + column + "), '8OI- ', 'B01')"
+}
+
+// compactPlate 將車牌轉為只含大寫英數字的字串，全形字元會先轉為半形
+// split 為第一個分隔符號前的字元數，沒有分隔符號時為 0
+func compactPlate(raw string) (compact string, split int) {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(raw) {
+		// 全形英數字轉為半形
+		if r >= '！' && r <= '～' {
+			r -= '！' - '!'
+		}
+		r = unicode.ToUpper(r)
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			continue
+		}
+		if split == 0 && b.Len() > 0 {
+			split = b.Len()
+		}
+	}
+	compact = b.String()
+	if split >= len(compact) {
+		split = 0
+	}
+	return compact, split
+}
+
+// split 回傳格式中分隔符號前的字元數
+func (p pattern) split() int {
+	return strings.IndexByte(string(p), '-')
+}
+
+// match 檢查 compact 是否能套用此格式，回傳加上分隔符號的車牌與校正的字元數
+func (p pattern) match(compact string) (string, int, bool) {
+	layout := strings.ReplaceAll(string(p), "-", "")
+	if len(compact) != len(layout) {
+		return "", 0, false
+	}
+
+	var b strings.Builder
+	substitutions := 0
+	for i, r := range compact {
+		if i == p.split() {
+			b.WriteByte('-')
+		}
+		isDigit := r >= '0' && r <= '9'
+		switch layout[i] {
+		case 'D':
+			if !isDigit {
+				digit, ok := asDigit[r]
+				if !ok {
+					return "", 0, false
+				}
+				r = digit
+				substitutions++
+			}
+		case 'L':
+			if isDigit {
+				letter, ok := asLetter[r]
+				if !ok {
+					return "", 0, false
+				}
+				r = letter
+				substitutions++
+			} else if r == 'I' || r == 'O' {
+				return "", 0, false
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), substitutions, true
+}
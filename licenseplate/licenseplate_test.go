@@ -0,0 +1,125 @@
+package licenseplate
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		// 各種車牌格式
+		{name: "LLL-DDDD", input: "ABC1234", want: "ABC-1234"},
+		{name: "LL-DDDD", input: "AB1234", want: "AB-1234"},
+		{name: "DDDD-LL", input: "1234AB", want: "1234-AB"},
+		{name: "LLL-DDD", input: "ABC123", want: "ABC-123"},
+		{name: "DDD-LLL", input: "123ABC", want: "123-ABC"},
+		{name: "LL-DDD", input: "AB123", want: "AB-123"},
+		{name: "DDD-LL", input: "123AB", want: "123-AB"},
+
+		// 大小寫與分隔符號
+		{name: "lower case", input: "abc1234", want: "ABC-1234"},
+		{name: "space separator", input: "ABC 1234", want: "ABC-1234"},
+		{name: "dot separator", input: "ABC.1234", want: "ABC-1234"},
+		{name: "surrounding spaces", input: "  abc-1234 ", want: "ABC-1234"},
+		{name: "full-width", input: "ＡＢＣ－１２３４", want: "ABC-1234"},
+		{name: "full-width lower case", input: "ａｂｃ１２３４", want: "ABC-1234"},
+
+		// 易混淆字元的校正
+		{name: "O in digit position", input: "ABC-O234", want: "ABC-0234"},
+		{name: "I in digit position", input: "ABC-I234", want: "ABC-1234"},
+		{name: "B in digit position", input: "ABC-123B", want: "ABC-1238"},
+		{name: "8 in letter position", input: "8BC-1234", want: "BBC-1234"},
+		{name: "misplaced separator", input: "AB C-I234", want: "ABC-1234"},
+		// 0 不會被讀成字母，不符合任何格式時只移除分隔符號
+		{name: "0 in letter position", input: "0AB-1234", want: "0AB1234"},
+		{name: "I in letter position", input: "IAB-1234", want: "IAB1234"},
+
+		// 多種格式可行時，校正字元較少者優先，分隔位置不符比任何校正都不可信，同分時取較前面的格式
+		{name: "fewest substitutions", input: "B8B888", want: "BBB-888"},
+		{name: "separator position wins", input: "B8-B888", want: "BB-8888"},
+		{name: "earlier pattern on tie", input: "888888", want: "BB-8888"},
+		{name: "earlier short pattern on tie", input: "88888", want: "BB-888"},
+		{name: "separator picks later pattern", input: "1234-88", want: "1234-BB"},
+
+		// 不符合任何格式
+		{name: "too long", input: "abcd-12345", want: "ABCD12345"},
+		{name: "empty", input: "", want: ""},
+		{name: "separators only", input: " - ", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("%s: Normalize(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeIsIdempotent(t *testing.T) {
+	for _, input := range []string{"ABC-1234", "AB-1234", "1234-AB", "ABC-123", "123-ABC", "AB-123", "123-AB", "BB-8888", "0AB1234"} {
+		if got := Normalize(input); got != input {
+			t.Errorf("Normalize(%q) = %q, want it unchanged", input, got)
+		}
+	}
+}
+
+func TestSearchKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "ABC-1234", want: "ABC1234"},
+		{input: "abc 1234", want: "ABC1234"},
+		{input: "ＡＢＣ－１２３４", want: "ABC1234"},
+		{input: "O1-8I", want: "01B1"},
+		{input: "BBC-1234", want: "BBC1234"},
+		{input: "8BC-1234", want: "BBC1234"},
+		{input: "ABC-O234", want: "ABC0234"},
+		{input: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := SearchKey(tt.input); got != tt.want {
+			t.Errorf("SearchKey(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestSearchKeySQLMatchesSearchKey 以 PostgreSQL TRANSLATE 的規則套用 SearchKeySQL 的對應字元，
+// 確認資料庫端對已存放的標準格式車牌算出的鍵值與 SearchKey 相同
+func TestSearchKeySQLMatchesSearchKey(t *testing.T) {
+	expression := SearchKeySQL("license_plate")
+	m := regexp.MustCompile(`^TRANSLATE\(UPPER\(license_plate\), '([^']*)', '([^']*)'\)$`).FindStringSubmatch(expression)
+	if m == nil {
+		t.Fatalf("SearchKeySQL returned an unexpected expression: %s", expression)
+	}
+	from, to := []rune(m[1]), []rune(m[2])
+
+	// TRANSLATE 將 from 的字元換成 to 中相同位置的字元，to 較短時多出的字元會被刪除
+	translate := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			for i, f := range from {
+				if f != r {
+					continue
+				}
+				if i < len(to) {
+					return to[i]
+				}
+				return -1
+			}
+			return r
+		}, strings.ToUpper(s))
+	}
+
+	for _, input := range []string{"abc1234", "AB1234", "1234AB", "ABC123", "123ABC", "AB123", "123AB", "8BC-1234", "ABC-O234", "0AB-1234", "B8-B888", "ab c 1234"} {
+		stored := Normalize(input)
+		if got, want := translate(stored), SearchKey(stored); got != want {
+			t.Errorf("SQL key of %q = %q, SearchKey = %q", stored, got, want)
+		}
+		if got, want := translate(stored), SearchKey(input); got != want {
+			t.Errorf("SQL key of %q = %q, SearchKey(%q) = %q", stored, got, input, want)
+		}
+	}
+}
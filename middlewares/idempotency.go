@@ -0,0 +1,101 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/services"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseRecorder 在寫出回應的同時保留一份回應內容
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 依 Idempotency-Key 標頭確保重送的請求只會被處理一次
+// 未帶標頭的請求照常處理；相同 key 與相同請求內容重送時，原樣回傳第一次的回應；
+// 相同 key 用於不同請求內容時回傳 409 (multipart/form-data 以欄位內容比對，不受 boundary 影響)。伺服器錯誤 (5xx) 不會被保存，客戶端可用相同 key 重試
+// 請求內容超過 configs.IdempotencyMaxBodyBytes 時回傳 413
+func Idempotency(idempotencyService services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(configs.IdempotencyKeyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, configs.IdempotencyMaxBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				dtos.SendErrorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds %d bytes", maxBytesErr.Limit))
+				return
+			}
+			dtos.SendErrorResponse(c, http.StatusBadRequest, "Cannot read request body: "+err.Error())
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, lease, err := idempotencyService.AcquireIdempotencyKey(key, c.Request.Method, c.Request.URL.Path, c.GetHeader("Content-Type"), body)
+		if err != nil {
+			errMsg := err.Error()
+			if strings.HasPrefix(errMsg, "invalid_idempotency_key:") {
+				dtos.SendErrorResponse(c, http.StatusBadRequest, errMsg)
+			} else if strings.HasPrefix(errMsg, "idempotency_key_reused:") || strings.HasPrefix(errMsg, "idempotency_request_in_progress:") {
+				dtos.SendErrorResponse(c, http.StatusConflict, errMsg)
+			} else {
+				dtos.SendErrorResponse(c, http.StatusInternalServerError, "Failed to process idempotency key: "+errMsg)
+			}
+			return
+		}
+		if record != nil {
+			c.Header(configs.IdempotencyReplayedHeader, "true")
+			c.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// 處理過程 panic (由外層的 Recovery 轉為 500) 或回應為伺服器錯誤時釋放 key，讓客戶端可以重試
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := idempotencyService.ReleaseIdempotencyKey(lease); err != nil {
+				log.Printf("[Idempotency] 無法釋放 key %s: %v", key, err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		// 回應已送出，保存失敗時不釋放 key，處理期限過後才允許重送的請求接手
+		completed = true
+		if err := idempotencyService.CompleteIdempotencyKey(lease, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			log.Printf("[Idempotency] 無法保存 key %s 的回應: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+const (
+	// 充電狀態
+	ChargingSessionStatusActive    = "Active"
+	ChargingSessionStatusCompleted = "Completed"
+)
+
+// ChargingSession 電動車在停車期間的一次充電，由充電樁回報開始、電錶讀數與結束
+// 電錶讀數以瓦時 (Wh) 為單位，充電電量為最後一次讀數減去開始時的讀數
+// 對應 PostgreSQL 的 'charging_sessions' 表
+type ChargingSession struct {
+	// ChargingSessionID 作為主鍵
+	ChargingSessionID uint `gorm:"primaryKey"`
+	// ParkingRecordID 充電車輛的停車記錄
+	ParkingRecordID uint `gorm:"not null;index"`
+	// ChargerID 充電樁識別碼
+	ChargerID string `gorm:"type:varchar(100);not null;index"`
+	// Status 充電狀態：Active, Completed
+	Status string `gorm:"type:varchar(20);not null;default:'Active';index"`
+	// StartedAt 開始充電時間
+	StartedAt time.Time `gorm:"not null"`
+	// StoppedAt 結束充電時間，結束後車輛仍佔用充電車位的時間計收佔用費，充電中則為 NULL
+	StoppedAt *time.Time
+	// StartMeterWh 開始充電時的電錶讀數
+	StartMeterWh int64 `gorm:"not null"`
+	// LastMeterWh 最後一次回報的電錶讀數
+	LastMeterWh int64 `gorm:"not null"`
+	// LastMeterAt 最後一次回報電錶讀數的時間
+	LastMeterAt time.Time `gorm:"not null"`
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+
+	// Readings 充電樁回報的電錶讀數
+	Readings []ChargingMeterReading `gorm:"foreignKey:ChargingSessionID;constraint:OnDelete:CASCADE"`
+}
+
+// EnergyWh 回傳目前為止的充電電量 (Wh)
+func (s ChargingSession) EnergyWh() int64 {
+	return s.LastMeterWh - s.StartMeterWh
+}
+
+// ChargingMeterReading 充電樁回報的一筆電錶讀數
+// 對應 PostgreSQL 的 'charging_meter_readings' 表
+type ChargingMeterReading struct {
+	// ChargingMeterReadingID 作為主鍵
+	ChargingMeterReadingID uint `gorm:"primaryKey"`
+	// ChargingSessionID 所屬的充電
+	ChargingSessionID uint `gorm:"not null;index"`
+	// MeterWh 電錶讀數
+	MeterWh int64 `gorm:"not null"`
+	// ReadAt 讀數時間
+	ReadAt time.Time `gorm:"not null"`
+}
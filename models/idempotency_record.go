@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// IdempotencyRecord 以 Idempotency-Key 保存的第一次請求回應，用於重送請求時原樣回傳
+// 對應 PostgreSQL 的 'idempotency_records' 表
+type IdempotencyRecord struct {
+	// IdempotencyKey 客戶端提供的 Idempotency-Key，作為主鍵
+	IdempotencyKey string `gorm:"primaryKey;type:varchar(255)"`
+	// Method 第一次請求的 HTTP 方法
+	Method string `gorm:"type:varchar(10);not null"`
+	// Path 第一次請求的路徑
+	Path string `gorm:"type:varchar(255);not null"`
+	// RequestHash 第一次請求方法、路徑與內容的 SHA-256，用於辨識以相同 key 送出的不同請求
+	RequestHash string `gorm:"type:varchar(64);not null"`
+	// Completed 第一次請求是否已處理完成，未完成表示仍在處理中
+	Completed bool `gorm:"not null;default:false"`
+	// LeaseExpiresAt 處理中的請求保留 key 的期限，期限過後仍未完成時，相同請求的重送可接手處理；NULL 視為已過期
+	LeaseExpiresAt *time.Time
+	// StatusCode 第一次回應的 HTTP 狀態碼
+	StatusCode int
+	// ContentType 第一次回應的 Content-Type
+	ContentType string `gorm:"type:varchar(100)"`
+	// ResponseBody 第一次回應的原始內容
+	ResponseBody []byte `gorm:"type:bytea"`
+	// CreatedAt 記錄建立時間
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	// ExpiresAt 記錄到期時間，到期後相同 key 視為新的請求
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
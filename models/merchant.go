@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// 商家折抵碼的折抵方式
+const (
+	// ValidationTypeFixedAmount 折抵固定金額
+	ValidationTypeFixedAmount = "FixedAmount"
+	// ValidationTypePercentage 依百分比折抵
+	ValidationTypePercentage = "Percentage"
+	// ValidationTypeFreeMinutes 前 N 分鐘免費
+	ValidationTypeFreeMinutes = "FreeMinutes"
+	// ValidationTypeFlatFee 停車費用以固定金額計
+	ValidationTypeFlatFee = "FlatFee"
+)
+
+// Merchant 停車場周邊提供停車折抵的商家
+// 對應 PostgreSQL 的 'merchants' 表
+type Merchant struct {
+	// MerchantID 作為主鍵
+	MerchantID uint `gorm:"primaryKey"`
+	// Name 商家名稱
+	Name string `gorm:"type:varchar(100);not null"`
+	// ContactEmail 商家聯絡信箱
+	ContactEmail string `gorm:"type:varchar(255)"`
+	// Active 商家是否仍可發放折抵碼
+	Active bool `gorm:"not null;default:true"`
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+}
+
+// ValidationCode 商家發放的停車折抵碼
+// 對應 PostgreSQL 的 'validation_codes' 表
+type ValidationCode struct {
+	// ValidationCodeID 作為主鍵
+	ValidationCodeID uint `gorm:"primaryKey"`
+	// MerchantID 發放折抵碼的商家
+	MerchantID uint `gorm:"not null;index"`
+	// Code 折抵碼，不分大小寫且不可重複
+	Code string `gorm:"type:varchar(50);not null;uniqueIndex"`
+	// DiscountType 折抵方式：FixedAmount, Percentage, FreeMinutes, FlatFee
+	DiscountType string `gorm:"type:varchar(20);not null"`
+	// Amount FixedAmount 的折抵金額，或 FlatFee 的固定停車費用
+	Amount Money `gorm:"type:decimal(10,2);not null;default:0.00"`
+	// Percent Percentage 的折抵百分比 (1-100)
+	Percent int `gorm:"not null;default:0"`
+	// FreeMinutes FreeMinutes 的免費分鐘數
+	FreeMinutes int `gorm:"not null;default:0"`
+	// MaxUses 可使用次數上限，0 表示不限
+	MaxUses int `gorm:"not null;default:0"`
+	// UsedCount 已使用次數
+	UsedCount int `gorm:"not null;default:0"`
+	// ExpiresAt 到期時間，NULL 表示不會到期
+	ExpiresAt *time.Time
+	// Active 折抵碼是否可使用
+	Active bool `gorm:"not null;default:true"`
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+
+	Merchant Merchant `gorm:"foreignKey:MerchantID"`
+}
+
+// ParkingValidation 套用在停車記錄上的折抵碼
+// 對應 PostgreSQL 的 'parking_validations' 表
+type ParkingValidation struct {
+	// ParkingValidationID 作為主鍵
+	ParkingValidationID uint `gorm:"primaryKey"`
+	// ParkingRecordID 套用折抵的停車記錄
+	ParkingRecordID uint `gorm:"not null;uniqueIndex:idx_parking_validations_record_code"`
+	// ValidationCodeID 套用的折抵碼
+	ValidationCodeID uint `gorm:"not null;uniqueIndex:idx_parking_validations_record_code"`
+	// MerchantID 負擔折抵金額的商家
+	MerchantID uint `gorm:"not null;index"`
+	// Code 套用時的折抵碼
+	Code string `gorm:"type:varchar(50);not null"`
+	// DiscountAmount 最近一次計算費用時此折抵實際折抵的金額，即商家補貼的金額
+	DiscountAmount Money `gorm:"type:decimal(10,2);not null;default:0.00"`
+	// AppliedAt 套用時間
+	AppliedAt time.Time `gorm:"not null"`
+
+	ValidationCode ValidationCode `gorm:"foreignKey:ValidationCodeID"`
+}
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency 未指定幣別時使用的幣別
+const DefaultCurrency = "TWD"
+
+// minorUnitsPerMajor 每一元的最小貨幣單位數，對應資料庫的 decimal(10,2)
+const minorUnitsPerMajor = 100
+
+const (
+	// maxAmountMajor decimal(10,2) 欄位可存放的最大整數元
+	maxAmountMajor = 99_999_999
+	// maxScanMajor 以最小貨幣單位表示時不會溢位的最大整數元，供讀取加總等超過 decimal(10,2) 範圍的查詢結果
+	maxScanMajor = math.MaxInt64/minorUnitsPerMajor - 1
+)
+
+// Money 以整數最小貨幣單位 (分) 加上幣別表示的金額，所有加總與比較皆為精確運算
+// JSON 編碼為小數點後兩位的數字 (例如 10.50)；資料庫中對應 decimal(10,2) 欄位，幣別不儲存於欄位中
+// 零值為 0 元的 DefaultCurrency
+type Money struct {
+	minor    int64
+	currency string
+}
+
+// NewMoney 以最小貨幣單位建立金額
+func NewMoney(minorUnits int64, currency string) Money {
+	return Money{minor: minorUnits, currency: currency}
+}
+
+// MoneyFromMajor 以整數元建立金額
+func MoneyFromMajor(major int64, currency string) Money {
+	return Money{minor: major * minorUnitsPerMajor, currency: currency}
+}
+
+// ParseMoney 解析十進位金額字串 (例如 "10"、"-3.5"、"120.25")，小數超過兩位或超出 decimal(10,2) 的範圍時回傳錯誤
+func ParseMoney(s string, currency string) (Money, error) {
+	return parseMoney(s, currency, maxAmountMajor)
+}
+
+// parseMoney 解析十進位金額字串，整數部分的絕對值超過 maxMajor 時回傳錯誤
+func parseMoney(s string, currency string, maxMajor int64) (Money, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+
+	integerPart, fractionPart, hasFraction := strings.Cut(text, ".")
+	if integerPart == "" && (!hasFraction || fractionPart == "") {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	fractionPart = strings.TrimRight(fractionPart, "0")
+	if len(fractionPart) > 2 {
+		return Money{}, fmt.Errorf("invalid amount %q: at most 2 decimal places are allowed", s)
+	}
+	if !isDigits(integerPart) || !isDigits(fractionPart) {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	var major int64
+	if integerPart != "" {
+		var err error
+		if major, err = strconv.ParseInt(integerPart, 10, 64); err != nil || major > maxMajor {
+			return Money{}, fmt.Errorf("invalid amount %q: must not exceed %d", s, maxMajor)
+		}
+	}
+	var minor int64
+	if fractionPart != "" {
+		minor, _ = strconv.ParseInt((fractionPart + "0")[:2], 10, 64)
+	}
+
+	amount := major*minorUnitsPerMajor + minor
+	if negative {
+		amount = -amount
+	}
+	return Money{minor: amount, currency: currency}, nil
+}
+
+// MinorUnits 回傳以最小貨幣單位表示的金額
+func (m Money) MinorUnits() int64 {
+	return m.minor
+}
+
+// Currency 回傳幣別，未指定時為 DefaultCurrency
+func (m Money) Currency() string {
+	if m.currency == "" {
+		return DefaultCurrency
+	}
+	return m.currency
+}
+
+// Add 回傳兩個金額的和，幣別不同時會 panic
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return Money{minor: m.minor + other.minor, currency: m.Currency()}
+}
+
+// Sub 回傳兩個金額的差，幣別不同時會 panic
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return Money{minor: m.minor - other.minor, currency: m.Currency()}
+}
+
+// Mul 回傳金額乘以整數倍
+func (m Money) Mul(n int64) Money {
+	return Money{minor: m.minor * n, currency: m.currency}
+}
+
+// Percent 回傳金額的 percent%，不足一分的部分四捨五入
+func (m Money) Percent(percent int64) Money {
+	product := m.minor * percent
+	half := int64(50)
+	if product < 0 {
+		half = -half
+	}
+	return Money{minor: (product + half) / 100, currency: m.currency}
+}
+
+// MulFraction 回傳金額乘以 numerator/denominator，不足一分的部分四捨五入，例如以每度電價計算瓦時數的電費
+func (m Money) MulFraction(numerator, denominator int64) Money {
+	product := m.minor * numerator
+	half := denominator / 2
+	if product < 0 {
+		half = -half
+	}
+	return Money{minor: (product + half) / denominator, currency: m.currency}
+}
+
+// Neg 回傳金額的相反數
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// Cmp 比較兩個金額，m 小於、等於、大於 other 時分別回傳 -1、0、1，幣別不同時會 panic
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+	switch {
+	case m.minor < other.minor:
+		return -1
+	case m.minor > other.minor:
+		return 1
+	}
+	return 0
+}
+
+// Equal 判斷兩個金額與幣別是否相同
+func (m Money) Equal(other Money) bool {
+	return m.minor == other.minor && m.Currency() == other.Currency()
+}
+
+// GreaterThan 判斷 m 是否大於 other
+func (m Money) GreaterThan(other Money) bool {
+	return m.Cmp(other) > 0
+}
+
+// LessThan 判斷 m 是否小於 other
+func (m Money) LessThan(other Money) bool {
+	return m.Cmp(other) < 0
+}
+
+// IsZero 判斷金額是否為 0
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsPositive 判斷金額是否大於 0
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+// IsNegative 判斷金額是否小於 0
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// MinMoney 回傳兩個金額中較小者
+func MinMoney(a, b Money) Money {
+	if b.LessThan(a) {
+		return b
+	}
+	return a
+}
+
+// MaxMoney 回傳兩個金額中較大者
+func MaxMoney(a, b Money) Money {
+	if b.GreaterThan(a) {
+		return b
+	}
+	return a
+}
+
+// String 以小數點後兩位的十進位格式回傳金額，例如 "10.50"
+func (m Money) String() string {
+	sign := ""
+	minor := m.minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/minorUnitsPerMajor, minor%minorUnitsPerMajor)
+}
+
+// MarshalJSON 將金額編碼為小數點後兩位的 JSON 數字
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON 接受 JSON 數字或字串格式的金額，幣別為 DefaultCurrency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "null" {
+		return nil
+	}
+	parsed, err := ParseMoney(text, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value 實作 driver.Valuer，以十進位字串寫入 decimal 欄位
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan 實作 sql.Scanner，從 decimal 欄位讀取金額，幣別為 DefaultCurrency
+func (m *Money) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case int64:
+		*m = MoneyFromMajor(v, DefaultCurrency)
+		return nil
+	case float64:
+		text = strconv.FormatFloat(v, 'f', 2, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	// 加總等查詢結果可能超出 decimal(10,2) 的範圍，只限制在不會溢位的範圍內
+	parsed, err := parseMoney(text, DefaultCurrency, maxScanMajor)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// mustMatch 確認兩個金額的幣別相同
+func (m Money) mustMatch(other Money) {
+	if m.Currency() != other.Currency() {
+		panic(errors.New("money: currency mismatch between " + m.Currency() + " and " + other.Currency()))
+	}
+}
+
+// isDigits 判斷字串是否只包含 0-9
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
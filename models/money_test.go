@@ -0,0 +1,168 @@
+package models
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "10", want: 1000},
+		{input: "10.5", want: 1050},
+		{input: "120.25", want: 12025},
+		{input: "-3.5", want: -350},
+		{input: "+7.01", want: 701},
+		{input: ".75", want: 75},
+		{input: "5.", want: 500},
+		{input: " 12.30 ", want: 1230},
+		{input: "1.2500", want: 125},
+		{input: "0", want: 0},
+		{input: "99999999.99", want: 9999999999},
+		{input: "-99999999.99", want: -9999999999},
+		{input: "100000000", wantErr: true},
+		{input: "9223372036854775807", wantErr: true},
+		{input: "-9223372036854775808", wantErr: true},
+		{input: "1.005", wantErr: true},
+		{input: "", wantErr: true},
+		{input: ".", wantErr: true},
+		{input: "-", wantErr: true},
+		{input: "1e3", wantErr: true},
+		{input: "1,000", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "--1", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input, DefaultCurrency)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q) = %s, want error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMoney(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got.MinorUnits() != tt.want {
+			t.Errorf("ParseMoney(%q) = %d minor units, want %d", tt.input, got.MinorUnits(), tt.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		minor int64
+		want  string
+	}{
+		{minor: 0, want: "0.00"},
+		{minor: 5, want: "0.05"},
+		{minor: 1050, want: "10.50"},
+		{minor: -350, want: "-3.50"},
+		{minor: -5, want: "-0.05"},
+	}
+	for _, tt := range tests {
+		if got := NewMoney(tt.minor, DefaultCurrency).String(); got != tt.want {
+			t.Errorf("NewMoney(%d).String() = %q, want %q", tt.minor, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyPercent(t *testing.T) {
+	tests := []struct {
+		minor   int64
+		percent int64
+		want    int64
+	}{
+		{minor: 1000, percent: 10, want: 100},
+		{minor: 1005, percent: 10, want: 101},   // 100.5 分四捨五入為 101
+		{minor: 1004, percent: 10, want: 100},   // 100.4 分捨去為 100
+		{minor: -1005, percent: 10, want: -101}, // 負數以絕對值四捨五入
+		{minor: 333, percent: 50, want: 167},
+		{minor: 1000, percent: 0, want: 0},
+		{minor: 1000, percent: 100, want: 1000},
+	}
+	for _, tt := range tests {
+		if got := NewMoney(tt.minor, DefaultCurrency).Percent(tt.percent).MinorUnits(); got != tt.want {
+			t.Errorf("NewMoney(%d).Percent(%d) = %d, want %d", tt.minor, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulFraction(t *testing.T) {
+	tests := []struct {
+		minor       int64
+		numerator   int64
+		denominator int64
+		want        int64
+	}{
+		{minor: 650, numerator: 12345, denominator: 1000, want: 8024}, // 6.50/kWh x 12.345 kWh = 80.2425
+		{minor: 650, numerator: 1, denominator: 1000, want: 1},        // 0.65 分四捨五入為 1
+		{minor: 100, numerator: 1, denominator: 3, want: 33},
+		{minor: 200, numerator: 1, denominator: 3, want: 67},
+		{minor: 100, numerator: 1, denominator: 2, want: 50},
+		{minor: 1, numerator: 1, denominator: 2, want: 1},   // 0.5 分進位
+		{minor: -1, numerator: 1, denominator: 2, want: -1}, // 負數以絕對值四捨五入
+		{minor: -200, numerator: 1, denominator: 3, want: -67},
+	}
+	for _, tt := range tests {
+		if got := NewMoney(tt.minor, DefaultCurrency).MulFraction(tt.numerator, tt.denominator).MinorUnits(); got != tt.want {
+			t.Errorf("NewMoney(%d).MulFraction(%d, %d) = %d, want %d", tt.minor, tt.numerator, tt.denominator, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		want    int64
+		wantErr bool
+	}{
+		{src: nil, want: 0},
+		{src: []byte("12.34"), want: 1234},
+		{src: "0", want: 0},
+		{src: "-5.10", want: -510},
+		{src: int64(7), want: 700},
+		{src: float64(3.5), want: 350},
+		// 加總的查詢結果可超出 decimal(10,2) 的範圍
+		{src: []byte("123456789012.34"), want: 12345678901234},
+		{src: []byte("92233720368547758.07"), wantErr: true},
+		{src: []byte("1.234"), wantErr: true},
+		{src: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		var m Money
+		err := m.Scan(tt.src)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Scan(%#v) = %s, want error", tt.src, m)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Scan(%#v) returned error: %v", tt.src, err)
+			continue
+		}
+		if m.MinorUnits() != tt.want || m.Currency() != DefaultCurrency {
+			t.Errorf("Scan(%#v) = %d %s, want %d %s", tt.src, m.MinorUnits(), m.Currency(), tt.want, DefaultCurrency)
+		}
+	}
+}
+
+func TestMoneyJSONRoundTrip(t *testing.T) {
+	m := NewMoney(1050, DefaultCurrency)
+	data, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON returned error: %v", err)
+	}
+	var decoded Money
+	if err := decoded.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON(%s) returned error: %v", data, err)
+	}
+	if !decoded.Equal(m) {
+		t.Errorf("round trip = %s, want %s", decoded, m)
+	}
+	if err := decoded.UnmarshalJSON([]byte(`"9223372036854775807"`)); err == nil {
+		t.Errorf("UnmarshalJSON accepted an amount outside decimal(10,2)")
+	}
+}
@@ -0,0 +1,117 @@
+package models
+
+import "time"
+
+// 感應器的方向
+const (
+	// SensorDirectionEntry 入口感應器
+	SensorDirectionEntry = "Entry"
+	// SensorDirectionExit 出口感應器
+	SensorDirectionExit = "Exit"
+)
+
+// 車種
+const (
+	// VehicleClassCar 一般汽車，未指定車種的車輛皆視為汽車
+	VehicleClassCar = "Car"
+	// VehicleClassMotorcycle 機車
+	VehicleClassMotorcycle = "Motorcycle"
+	// VehicleClassLarge 大型車，例如遊覽車、貨車
+	VehicleClassLarge = "Large"
+)
+
+// VehicleClasses 所有車種，報表依此順序分組
+var VehicleClasses = []string{VehicleClassCar, VehicleClassMotorcycle, VehicleClassLarge}
+
+// IsValidVehicleClass 檢查是否為已定義的車種
+func IsValidVehicleClass(vehicleClass string) bool {
+	for _, class := range VehicleClasses {
+		if class == vehicleClass {
+			return true
+		}
+	}
+	return false
+}
+
+// ParkingLot 停車場，每個停車場有各自的容量、費率與感應器
+// Capacity、ReservedSpots 與 RateCalendar 適用於汽車，其他車種的車位數與費率記錄在 VehicleClasses
+// 對應 PostgreSQL 的 'parking_lots' 表
+type ParkingLot struct {
+	// ParkingLotID 作為主鍵
+	ParkingLotID uint `gorm:"primaryKey"`
+	// Name 停車場名稱
+	Name string `gorm:"type:varchar(100);not null;uniqueIndex"`
+	// Address 停車場地址
+	Address string `gorm:"type:varchar(255)"`
+	// Capacity 停車場的汽車車位數
+	Capacity int `gorm:"not null"`
+	// ReservedSpots 保留給定期停車證持有者的汽車車位數，一般車輛在空位不超過此數時不得進場
+	ReservedSpots int `gorm:"not null;default:0"`
+	// RateCalendar 停車場汽車使用的費率行事曆名稱，對應 configs.RateCalendars
+	RateCalendar string `gorm:"type:varchar(50);not null;default:'Default'"`
+	// Active 停車場是否營業中，停止營業的停車場不接受車輛進場
+	Active bool `gorm:"not null;default:true"`
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+
+	// Sensors 停車場出入口的感應器
+	Sensors []ParkingLotSensor `gorm:"foreignKey:ParkingLotID;constraint:OnDelete:CASCADE"`
+	// VehicleClasses 停車場開放的汽車以外車種，未列出的車種不得進場
+	VehicleClasses []ParkingLotVehicleClass `gorm:"foreignKey:ParkingLotID;constraint:OnDelete:CASCADE"`
+}
+
+// CapacityFor 回傳停車場該車種的車位數，未開放的車種為 0
+func (p ParkingLot) CapacityFor(vehicleClass string) int {
+	if vehicleClass == VehicleClassCar {
+		return p.Capacity
+	}
+	for _, class := range p.VehicleClasses {
+		if class.VehicleClass == vehicleClass {
+			return class.Capacity
+		}
+	}
+	return 0
+}
+
+// RateCalendarFor 回傳停車場該車種使用的費率行事曆名稱，未開放的車種回傳空字串
+func (p ParkingLot) RateCalendarFor(vehicleClass string) string {
+	if vehicleClass == VehicleClassCar {
+		return p.RateCalendar
+	}
+	for _, class := range p.VehicleClasses {
+		if class.VehicleClass == vehicleClass {
+			return class.RateCalendar
+		}
+	}
+	return ""
+}
+
+// ParkingLotVehicleClass 停車場汽車以外車種的車位數與費率
+// 對應 PostgreSQL 的 'parking_lot_vehicle_classes' 表
+type ParkingLotVehicleClass struct {
+	// ParkingLotID 所屬的停車場
+	ParkingLotID uint `gorm:"primaryKey;autoIncrement:false"`
+	// VehicleClass 車種：Motorcycle, Large
+	VehicleClass string `gorm:"type:varchar(20);primaryKey"`
+	// Capacity 此車種的車位數
+	Capacity int `gorm:"not null"`
+	// RateCalendar 此車種使用的費率行事曆名稱，對應 configs.RateCalendars
+	RateCalendar string `gorm:"type:varchar(50);not null"`
+}
+
+// ParkingLotSensor 停車場出入口的感應器
+// 對應 PostgreSQL 的 'parking_lot_sensors' 表
+type ParkingLotSensor struct {
+	// SensorID 感應器識別碼，與停車記錄的 SensorEntryID / SensorExitID 相同
+	SensorID string `gorm:"type:varchar(100);primaryKey"`
+	// ParkingLotID 感應器所在的停車場
+	ParkingLotID uint `gorm:"not null;index"`
+	// Direction 感應器方向：Entry, Exit
+	Direction string `gorm:"type:varchar(10);not null"`
+	// VehicleClass 專用車道的車種，例如機車道的入口感應器；空字串表示不限車種
+	VehicleClass string `gorm:"type:varchar(20);not null;default:''"`
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+}
@@ -1,37 +1,138 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ExitMatchExact 出場車牌與停車記錄的車牌完全相符
+	ExitMatchExact = "Exact"
+	// ExitMatchFuzzy 出場車牌與停車記錄的車牌不完全相符，由相似度自動比對
+	ExitMatchFuzzy = "Fuzzy"
+	// ExitMatchOperator 由出口操作人員從候選記錄中確認
+	ExitMatchOperator = "Operator"
+)
+
+const (
+	// ImageKindOriginal 上傳的原始影像
+	ImageKindOriginal = "original"
+	// ImageKindThumbnail 上傳時產生的縮圖，無法產生縮圖時為原始影像
+	ImageKindThumbnail = "thumbnail"
+)
+
+const (
+	// SessionStatusOpen 車輛仍在場內
+	SessionStatusOpen = "Open"
+	// SessionStatusExited 車輛已由出口出場
+	SessionStatusExited = "Exited"
+	// SessionStatusAbandoned 車輛未經出口離場 (例如未觸發出口感應器)，停車記錄於同一車牌再次進場或由現場人員結束
+	SessionStatusAbandoned = "Abandoned"
+)
 
 // ParkingRecord 停車紀錄
 // 對應 PostgreSQL 的 'parking_records' 表
 type ParkingRecord struct {
 	// RecordID 作為主鍵
 	RecordID uint `gorm:"primaryKey"`
-	// LicensePlate 車牌號碼 (通常來自 OCR)
+	// ParkingLotID 停車記錄所屬的停車場
+	ParkingLotID uint `gorm:"not null;index"`
+	// LicensePlate 標準格式的車牌號碼 (通常來自 OCR)，例如 ABC-1234
 	LicensePlate string `gorm:"type:varchar(20);not null;index"`
-	// UserVerifiedLicensePlate 使用者驗證/修正後的車牌號碼，可以為 NULL
+	// RawLicensePlate 進場時 OCR 回傳的原始車牌字串，保留供稽核
+	RawLicensePlate string `gorm:"type:varchar(50)"`
+	// OcrConfidence 進場時 OCR 的信心分數 (0.0 到 1.0 之間)，未提供則為 NULL
+	OcrConfidence *float64
+	// ExitRawLicensePlate 出場時 OCR 回傳的原始車牌字串，尚未出場則為 NULL
+	ExitRawLicensePlate *string `gorm:"type:varchar(50)"`
+	// ExitMatch 出場時找到此停車記錄的方式：Exact, Fuzzy, Operator，尚未出場則為空字串
+	ExitMatch string `gorm:"type:varchar(20)"`
+	// VehicleClass 車種：Car, Motorcycle, Large，決定車位數與費率
+	VehicleClass string `gorm:"type:varchar(20);not null;default:'Car';index"`
+	// UserVerifiedLicensePlate 使用者驗證/修正後的標準格式車牌號碼，可以為 NULL
 	UserVerifiedLicensePlate *string `gorm:"type:varchar(20)"`
 	// EntryTime 進場時間
 	EntryTime time.Time `gorm:"not null"`
-	// ExitTime 出場時間，如果尚未出場則為 NULL
+	// ExitTime 出場時間，如果尚未出場則為 NULL；Abandoned 的停車記錄為結束記錄的時間
 	ExitTime *time.Time
+	// SessionStatus 停車狀態：Open, Exited, Abandoned
+	SessionStatus string `gorm:"type:varchar(20);not null;default:'Open';index"`
+	// StaleFlaggedAt 尚未出場的時間超過上限，被標記為疑似遺漏出場的時間，未標記則為 NULL
+	StaleFlaggedAt *time.Time `gorm:"index"`
+	// AbandonReason 停車記錄被結束為 Abandoned 的原因
+	AbandonReason *string `gorm:"type:varchar(255)"`
+	// SupersededByRecordID 同一車牌再次進場而結束此停車記錄時，新的停車記錄 ID
+	SupersededByRecordID *uint
 	// ActualDurationMinutes 實際停車時長（分鐘）
 	ActualDurationMinutes int `gorm:"default:0"` // 預設值為 0
 	// CalculatedAmount 應付停車費用
-	CalculatedAmount float64 `gorm:"type:decimal(10,2);default:0.00"`
+	CalculatedAmount Money `gorm:"type:decimal(10,2);default:0.00"`
+	// FeeCalculatedAt 應付停車費用的計算時間，付款明細依此時間的費用列出；付款後出場寬限期由此起算 (付款時間較早時由付款時間起算)
+	FeeCalculatedAt *time.Time
 	// PaymentStatus 支付狀態：Pending, Paid, Refunded
 	PaymentStatus string `gorm:"type:varchar(20);not null;default:'Pending'"`
+	// PaidAt 最近一次付款成功的時間，尚未付款則為 NULL
+	PaidAt *time.Time
 	// TransactionID 關聯到 Transactions 表的外鍵，如果尚未支付或無交易則為 NULL
 	TransactionID *uint // 使用指針表示可為 NULL
+	// PermitID 進場時適用的定期停車證，非定期停車則為 NULL
+	PermitID *uint `gorm:"index"`
+	// VehicleID 停車記錄所屬的車輛資料，以車牌 (有驗證車牌時為驗證車牌) 對應
+	VehicleID *uint `gorm:"index"`
+	// ReservationID 車輛於預約抵達時段內進場時所連結的預約，非預約進場則為 NULL
+	ReservationID *uint `gorm:"index"`
+	// SpotID 車輛停放的車位，由車位感應器回報時連結，未知則為 NULL
+	SpotID *uint `gorm:"index"`
+	// CapacityOverrideBy 停車場客滿時強制放行的操作人員，正常進場則為 NULL
+	CapacityOverrideBy *string `gorm:"type:varchar(100)"`
+	// CapacityOverrideReason 停車場客滿時強制放行的原因
+	CapacityOverrideReason *string `gorm:"type:varchar(255)"`
+	// FlagReason 車牌符合監控名單 (處理方式為 Flag) 時標記的原因，供現場人員處理，未標記則為 NULL
+	FlagReason *string `gorm:"type:varchar(255)"`
 	// SensorEntryID 入場感應器記錄ID
 	SensorEntryID string `gorm:"type:varchar(100)"`
 	// SensorExitID 出場感應器記錄ID
 	SensorExitID string `gorm:"type:varchar(100)"`
 
+	// ImageKey 進場影像在影像儲存空間中的 key，沒有影像則為 NULL
+	// 舊版以 Base64 存放在 image 欄位的影像由 make migrate-images 搬移至影像儲存空間
+	ImageKey *string `json:"-" gorm:"type:varchar(255)"`
+	// ThumbnailKey 進場影像縮圖的 key，無法產生縮圖 (例如 WebP) 或沒有影像則為 NULL
+	ThumbnailKey *string `json:"-" gorm:"type:varchar(255)"`
+	// ImageURL 與 ThumbnailURL 為取得進場影像與縮圖的 API 路徑，讀取或寫入後依 ImageKey 設定，不存放在資料庫
+	ImageURL     *string `json:"imageUrl,omitempty" gorm:"-" example:"/api/v1/parking-records/12/images/original"`
+	ThumbnailURL *string `json:"thumbnailUrl,omitempty" gorm:"-" example:"/api/v1/parking-records/12/images/thumbnail"`
+
 	// GORM 模型關聯定義
-	// Vehicle     Vehicle     `gorm:"foreignKey:VehicleID"` // 移除 Vehicle 關聯
 	Transaction Transaction `gorm:"foreignKey:TransactionID"`
+}
+
+// ParkingRecordImageURL 取得停車記錄影像的 API 路徑，kind 為 ImageKindOriginal 或 ImageKindThumbnail
+func ParkingRecordImageURL(recordID uint, kind string) string {
+	return fmt.Sprintf("/api/v1/parking-records/%d/images/%s", recordID, kind)
+}
+
+// AfterFind 讀取停車記錄後設定影像的 API 路徑
+func (r *ParkingRecord) AfterFind(tx *gorm.DB) error {
+	r.setImageURLs()
+	return nil
+}
+
+// AfterSave 寫入停車記錄後設定影像的 API 路徑
+func (r *ParkingRecord) AfterSave(tx *gorm.DB) error {
+	r.setImageURLs()
+	return nil
+}
 
-	// New fields
-	Image *string `json:"image,omitempty" gorm:"type:text"` // 新增欄位: 圖片 URL 或 Base64
+// setImageURLs 有進場影像時設定原始影像與縮圖的 API 路徑
+func (r *ParkingRecord) setImageURLs() {
+	r.ImageURL, r.ThumbnailURL = nil, nil
+	if r.ImageKey == nil || r.RecordID == 0 {
+		return
+	}
+	imageURL := ParkingRecordImageURL(r.RecordID, ImageKindOriginal)
+	thumbnailURL := ParkingRecordImageURL(r.RecordID, ImageKindThumbnail)
+	r.ImageURL, r.ThumbnailURL = &imageURL, &thumbnailURL
 }
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// PaymentIntent 付款意圖，記錄一次付款從建立到請款完成的生命週期
+// 狀態流程：Pending -> Authorized -> Captured，或轉為 Failed / Expired
+// 對應 PostgreSQL 的 'payment_intents' 表
+type PaymentIntent struct {
+	// PaymentIntentID 作為主鍵
+	PaymentIntentID uint `gorm:"primaryKey"`
+	// ParkingRecordID 關聯到 ParkingRecords 表的外鍵，預約訂金則為 0
+	ParkingRecordID uint `gorm:"not null;index"`
+	// ReservationID 預約訂金所屬的預約，停車費付款則為 NULL
+	ReservationID *uint `gorm:"index"`
+	// Amount 付款金額
+	Amount Money `gorm:"type:decimal(10,2);not null"`
+	// Currency 幣別
+	Currency string `gorm:"type:varchar(3);not null"`
+	// PaymentMethod 付款方式，例如 "Cash", "LinePay"
+	PaymentMethod string `gorm:"type:varchar(50);not null"`
+	// PaymentProvider 處理此付款的支付供應商
+	PaymentProvider string `gorm:"type:varchar(50);not null;uniqueIndex:idx_payment_intents_provider_reference"`
+	// ProviderReference 支付供應商端的付款參考編號
+	ProviderReference string `gorm:"type:varchar(100);not null;uniqueIndex:idx_payment_intents_provider_reference"`
+	// ClientReference 客戶端提供的付款參考資訊
+	ClientReference string `gorm:"type:varchar(100)"`
+	// TransactionType 完成後建立的交易類型：Payment, Overstay, Deposit
+	TransactionType string `gorm:"type:varchar(20);not null;default:'Payment'"`
+	// Status 付款意圖狀態：Pending, Authorized, Captured, Failed, Expired
+	Status string `gorm:"type:varchar(20);not null;default:'Pending';index"`
+	// FailureReason 付款失敗或過期的原因
+	FailureReason string `gorm:"type:varchar(255)"`
+	// ExpiresAt 付款意圖的到期時間，到期仍未請款則轉為 Expired
+	ExpiresAt time.Time `gorm:"not null"`
+	// TransactionID 請款完成後建立的交易，尚未完成則為 NULL
+	TransactionID *uint
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+
+	// LineItems 付款明細
+	LineItems []TransactionLineItem `json:",omitempty" gorm:"foreignKey:PaymentIntentID"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// PaymentWebhookEvent 已處理的支付供應商通知，用於辨識重送的通知以確保冪等
+// 對應 PostgreSQL 的 'payment_webhook_events' 表
+type PaymentWebhookEvent struct {
+	// PaymentWebhookEventID 作為主鍵
+	PaymentWebhookEventID uint `gorm:"primaryKey"`
+	// PaymentProvider 送出通知的支付供應商
+	PaymentProvider string `gorm:"type:varchar(50);not null;uniqueIndex:idx_payment_webhook_events_provider_event"`
+	// EventID 支付供應商的通知編號
+	EventID string `gorm:"type:varchar(100);not null;uniqueIndex:idx_payment_webhook_events_provider_event"`
+	// ProviderReference 通知所屬的付款參考編號
+	ProviderReference string `gorm:"type:varchar(100);not null"`
+	// Status 通知中的付款狀態
+	Status string `gorm:"type:varchar(20);not null"`
+	// Payload 通知原始內容
+	Payload string `gorm:"type:text"`
+	// ReceivedAt 收到通知的時間
+	ReceivedAt time.Time `gorm:"not null"`
+}
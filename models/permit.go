@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Permit 月租或季租等定期停車證，在有效期間與允許時段內停車免收費
+// 允許時段以當日 00:00 起算的分鐘數表示，結束早於開始表示跨夜，開始等於結束表示全天
+// 對應 PostgreSQL 的 'permits' 表
+type Permit struct {
+	// PermitID 作為主鍵
+	PermitID uint `gorm:"primaryKey"`
+	// HolderName 停車證持有人
+	HolderName string `gorm:"type:varchar(100);not null"`
+	// PermitType 停車證類型，例如 "Monthly", "Season"
+	PermitType string `gorm:"type:varchar(20);not null;default:'Monthly'"`
+	// ParkingLotID 適用的停車場，NULL 表示所有停車場皆適用
+	ParkingLotID *uint `gorm:"index"`
+	// ValidFrom 生效時間
+	ValidFrom time.Time `gorm:"not null"`
+	// ValidUntil 到期時間 (不包含)
+	ValidUntil time.Time `gorm:"not null;index"`
+	// AllowedStartMinute 每日允許時段的開始時間
+	AllowedStartMinute int `gorm:"not null;default:0"`
+	// AllowedEndMinute 每日允許時段的結束時間 (不包含)
+	AllowedEndMinute int `gorm:"not null;default:0"`
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+
+	// Plates 此停車證登記的車牌
+	Plates []PermitPlate `gorm:"foreignKey:PermitID;constraint:OnDelete:CASCADE"`
+}
+
+// PermitPlate 停車證登記的車牌
+// 對應 PostgreSQL 的 'permit_plates' 表
+type PermitPlate struct {
+	// PermitPlateID 作為主鍵
+	PermitPlateID uint `gorm:"primaryKey"`
+	// PermitID 所屬的停車證
+	PermitID uint `gorm:"not null;index"`
+	// LicensePlate 標準格式的車牌號碼
+	LicensePlate string `gorm:"type:varchar(20);not null;index"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	// 車牌人工審核狀態
+	PlateReviewStatusPending   = "Pending"
+	PlateReviewStatusApproved  = "Approved"
+	PlateReviewStatusCorrected = "Corrected"
+)
+
+// PlateReview 車牌人工審核項目，進場時 OCR 信心分數低於門檻的停車記錄會排入審核
+// 狀態流程：Pending -> Approved (確認 OCR 車牌正確) 或 Corrected (修正為其他車牌)，審核結果寫入停車記錄的 UserVerifiedLicensePlate
+// 對應 PostgreSQL 的 'plate_reviews' 表
+type PlateReview struct {
+	// PlateReviewID 作為主鍵
+	PlateReviewID uint `gorm:"primaryKey"`
+	// ParkingRecordID 待審核的停車記錄，每筆停車記錄最多一個審核項目
+	ParkingRecordID uint `gorm:"not null;uniqueIndex"`
+	// ParkingRecord 待審核的停車記錄，列出審核項目時一併載入以顯示進場時間與圖片
+	ParkingRecord *ParkingRecord `json:",omitempty" gorm:"foreignKey:ParkingRecordID"`
+	// ParkingLotID 停車記錄所屬的停車場
+	ParkingLotID uint `gorm:"not null;index"`
+	// LicensePlate OCR 辨識出的標準格式車牌
+	LicensePlate string `gorm:"type:varchar(20);not null"`
+	// RawLicensePlate OCR 回傳的原始車牌字串
+	RawLicensePlate string `gorm:"type:varchar(50)"`
+	// OcrConfidence OCR 的信心分數 (0.0 到 1.0 之間)
+	OcrConfidence float64 `gorm:"not null"`
+	// Alternatives OCR 提供的其他可能車牌，依 OCR 回傳的順序排列
+	Alternatives []PlateReviewAlternative `gorm:"foreignKey:PlateReviewID;constraint:OnDelete:CASCADE"`
+	// Status 審核狀態：Pending, Approved, Corrected
+	Status string `gorm:"type:varchar(20);not null;default:'Pending';index"`
+	// VerifiedLicensePlate 審核確認的標準格式車牌，尚未審核則為 NULL
+	VerifiedLicensePlate *string `gorm:"type:varchar(20)"`
+	// ReviewedBy 審核人員，尚未審核則為 NULL
+	ReviewedBy *string `gorm:"type:varchar(100);index"`
+	// ReviewedAt 審核時間，尚未審核則為 NULL
+	ReviewedAt *time.Time `gorm:"index"`
+	// CreatedAt 排入審核的時間
+	CreatedAt time.Time `gorm:"index"`
+	// UpdatedAt 更新時間
+	UpdatedAt time.Time
+}
+
+// PlateReviewAlternative OCR 提供的其他可能車牌
+type PlateReviewAlternative struct {
+	// PlateReviewAlternativeID 作為主鍵
+	PlateReviewAlternativeID uint `gorm:"primaryKey"`
+	// PlateReviewID 所屬的審核項目
+	PlateReviewID uint `gorm:"not null;index"`
+	// LicensePlate 標準格式的車牌
+	LicensePlate string `gorm:"type:varchar(20);not null"`
+}
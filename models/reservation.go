@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+const (
+	// 預約狀態
+	ReservationStatusConfirmed = "Confirmed"
+	ReservationStatusCancelled = "Cancelled"
+	ReservationStatusFulfilled = "Fulfilled"
+	ReservationStatusExpired   = "Expired"
+
+	// 預約訂金狀態
+	DepositStatusNone      = "None"
+	DepositStatusPending   = "Pending"
+	DepositStatusPaid      = "Paid"
+	DepositStatusFailed    = "Failed"
+	DepositStatusRefunded  = "Refunded"
+	DepositStatusForfeited = "Forfeited"
+)
+
+// Reservation 車位預約，Confirmed 的預約在時段內為該車牌保留一個車位
+// 狀態流程：Confirmed -> Fulfilled (預約車輛於抵達時段內進場)，或轉為 Cancelled / Expired (逾時未到)
+// 對應 PostgreSQL 的 'reservations' 表
+type Reservation struct {
+	// ReservationID 作為主鍵
+	ReservationID uint `gorm:"primaryKey"`
+	// ParkingLotID 預約的停車場
+	ParkingLotID uint `gorm:"not null;index"`
+	// VehicleClass 預約的車種：Car, Motorcycle, Large
+	VehicleClass string `gorm:"type:varchar(20);not null;default:'Car'"`
+	// LicensePlate 預約的標準格式車牌號碼
+	LicensePlate string `gorm:"type:varchar(20);not null;index"`
+	// StartTime 預約開始時間
+	StartTime time.Time `gorm:"not null;index"`
+	// EndTime 預約結束時間 (不包含)
+	EndTime time.Time `gorm:"not null"`
+	// Status 預約狀態：Confirmed, Cancelled, Fulfilled, Expired
+	Status string `gorm:"type:varchar(20);not null;default:'Confirmed';index"`
+	// DepositStatus 訂金狀態：None (未付訂金), Pending, Paid, Failed, Refunded, Forfeited (逾時未到沒收)
+	DepositStatus string `gorm:"type:varchar(20);not null;default:'None'"`
+	// DepositAmount 訂金金額，未付訂金則為 0
+	DepositAmount Money `gorm:"type:decimal(10,2);default:0.00"`
+	// DepositTransactionID 訂金的交易記錄，尚未付款則為 NULL
+	DepositTransactionID *uint
+	// ParkingRecordID 預約車輛進場後的停車記錄，尚未進場則為 NULL
+	ParkingRecordID *uint `gorm:"index"`
+	// CancelledAt 取消時間
+	CancelledAt *time.Time
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+}
@@ -7,16 +7,33 @@ import "time"
 type Transaction struct {
 	// TransactionID 作為主鍵
 	TransactionID uint `gorm:"primaryKey"`
-	// ParkingRecordID 關聯到 ParkingRecords 表的外鍵
+	// ParkingRecordID 關聯到 ParkingRecords 表的外鍵，預約車輛尚未進場時的訂金交易為 0
 	ParkingRecordID uint `gorm:"not null"`
+	// ReservationID 預約訂金及其退款所屬的預約，其他交易則為 NULL
+	ReservationID *uint `gorm:"index"`
 	// Amount 交易金額
-	Amount float64 `gorm:"type:decimal(10,2);not null"`
+	Amount Money `gorm:"type:decimal(10,2);not null"`
 	// TransactionTime 交易時間
 	TransactionTime time.Time `gorm:"not null"`
 	// PaymentMethod 付款方式，例如 "CreditCard", "MobilePay", "Cash"
 	PaymentMethod string `gorm:"type:varchar(50);not null"`
-	// Status 交易狀態：Success, Failed, Refunded
+	// TransactionType 交易類型：Payment (停車費), Overstay (超過出場寬限期的補繳), Deposit (預約訂金，進場後折抵停車費), Refund (退款，金額為負數)
+	TransactionType string `gorm:"type:varchar(20);not null;default:'Payment'"`
+	// Status 交易狀態：Success, Failed, Refunded (原始交易已全額退款), Pending (退款等待支付供應商處理)
 	Status string `gorm:"type:varchar(20);not null;default:'Success'"`
+	// PaymentProvider 處理此交易的支付供應商，例如 "Cash", "Simulator"
+	PaymentProvider string `gorm:"type:varchar(50)"`
+	// ProviderReference 支付供應商端的交易參考編號，退款時使用
+	ProviderReference string `gorm:"type:varchar(100);index"`
 	// PaymentGatewayResponse 支付閘道回傳的詳細資訊 (JSON或TEXT)
 	PaymentGatewayResponse string `gorm:"type:text"`
+	// OriginalTransactionID 退款交易所對應的原始交易，非退款交易則為 NULL
+	OriginalTransactionID *uint `gorm:"index"`
+	// RefundReason 退款原因
+	RefundReason string `gorm:"type:varchar(255)"`
+	// Operator 執行退款的操作人員
+	Operator string `gorm:"type:varchar(100)"`
+
+	// LineItems 付款明細，退款與舊交易沒有明細
+	LineItems []TransactionLineItem `json:",omitempty" gorm:"foreignKey:TransactionID"`
 }
@@ -0,0 +1,29 @@
+package models
+
+const (
+	// 交易明細類型
+	LineItemTypeParking    = "Parking"
+	LineItemTypeEnergy     = "Energy"
+	LineItemTypeIdleFee    = "IdleFee"
+	LineItemTypeAdjustment = "Adjustment"
+)
+
+// TransactionLineItem 付款的明細項目，例如停車費、充電電費與充電佔用費，同一筆付款的明細加總等於付款金額
+// 明細於建立付款意圖時寫入，請款完成建立交易後連結到該交易
+// 對應 PostgreSQL 的 'transaction_line_items' 表
+type TransactionLineItem struct {
+	// LineItemID 作為主鍵
+	LineItemID uint `gorm:"primaryKey"`
+	// PaymentIntentID 明細所屬的付款意圖
+	PaymentIntentID *uint `gorm:"index"`
+	// TransactionID 請款完成後建立的交易，尚未完成則為 NULL
+	TransactionID *uint `gorm:"index"`
+	// ChargingSessionID 電費與佔用費明細所屬的充電，其他明細則為 NULL
+	ChargingSessionID *uint `gorm:"index"`
+	// ItemType 明細類型：Parking, Energy, IdleFee, Adjustment (預約訂金等先前付款的折抵)
+	ItemType string `gorm:"type:varchar(20);not null"`
+	// Description 明細說明
+	Description string `gorm:"type:varchar(255)"`
+	// Amount 明細金額，折抵為負數
+	Amount Money `gorm:"type:decimal(10,2);not null"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Vehicle 車輛資料，以標準格式車牌識別，車輛第一次進場時自動建立
+// 到訪次數、消費總額與平均停留時間由所屬的停車記錄統計，不另外儲存
+// 對應 PostgreSQL 的 'vehicles' 表
+type Vehicle struct {
+	// VehicleID 作為主鍵
+	VehicleID uint `gorm:"primaryKey"`
+	// LicensePlate 標準格式的車牌號碼，每個車牌只有一筆車輛資料
+	LicensePlate string `gorm:"type:varchar(20);not null;uniqueIndex"`
+	// Make 廠牌，由現場人員填寫，未知則為 NULL
+	Make *string `gorm:"type:varchar(50)"`
+	// Color 車身顏色，由現場人員填寫，未知則為 NULL
+	Color *string `gorm:"type:varchar(30)"`
+	// Notes 現場人員的備註
+	Notes *string `gorm:"type:text"`
+	// FirstSeenAt 第一次進場的時間
+	FirstSeenAt time.Time `gorm:"not null"`
+	// LastSeenAt 最近一次進場的時間
+	LastSeenAt time.Time `gorm:"not null;index"`
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+}
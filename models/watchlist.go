@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+const (
+	// 監控名單類別
+	WatchlistCategoryStolen = "Stolen"
+	WatchlistCategoryBanned = "Banned"
+	WatchlistCategoryVIP    = "VIP"
+	WatchlistCategoryOther  = "Other"
+
+	// 監控名單車輛出現時的處理方式
+	WatchlistActionAlert     = "Alert"     // 只發出警示
+	WatchlistActionDenyEntry = "DenyEntry" // 拒絕進場並發出警示
+	WatchlistActionFlag      = "Flag"      // 標記停車記錄供現場人員處理並發出警示
+
+	// 警示發生的時機
+	WatchlistEventEntry       = "Entry"
+	WatchlistEventEntryDenied = "EntryDenied"
+	WatchlistEventExit        = "Exit"
+
+	// 車牌與監控名單的比對方式
+	WatchlistMatchExact   = "Exact"   // 標準格式車牌相同
+	WatchlistMatchVariant = "Variant" // 只差在 O/0、I/1、B/8 等易混淆字元
+	WatchlistMatchFuzzy   = "Fuzzy"   // 車牌相似度達門檻
+)
+
+// WatchlistEntry 監控名單上的車牌，例如失竊車輛、禁止進入的車輛或貴賓車輛
+// 對應 PostgreSQL 的 'watchlist_entries' 表
+type WatchlistEntry struct {
+	// WatchlistEntryID 作為主鍵
+	WatchlistEntryID uint `gorm:"primaryKey"`
+	// LicensePlate 標準格式的車牌號碼
+	LicensePlate string `gorm:"type:varchar(20);not null;index"`
+	// Category 類別：Stolen, Banned, VIP, Other
+	Category string `gorm:"type:varchar(20);not null"`
+	// Action 車輛出現時的處理方式：Alert, DenyEntry, Flag
+	Action string `gorm:"type:varchar(20);not null"`
+	// Reason 列入監控名單的原因，會顯示在警示中
+	Reason string `gorm:"type:varchar(255)"`
+	// CreatedBy 列入監控名單的人員
+	CreatedBy string `gorm:"type:varchar(100)"`
+	// Active 是否啟用
+	Active bool `gorm:"not null;default:true"`
+	// ExpiresAt 自動失效的時間，NULL 表示不會失效
+	ExpiresAt *time.Time
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+}
+
+// WatchlistAlert 監控名單車輛進出場時產生的警示，保留當時的名單內容以供稽核
+// 對應 PostgreSQL 的 'watchlist_alerts' 表
+type WatchlistAlert struct {
+	// WatchlistAlertID 作為主鍵
+	WatchlistAlertID uint `gorm:"primaryKey"`
+	// WatchlistEntryID 比對到的監控名單項目
+	WatchlistEntryID uint `gorm:"not null;index"`
+	// ParkingLotID 發生警示的停車場
+	ParkingLotID uint `gorm:"not null;index"`
+	// ParkingRecordID 相關的停車記錄，拒絕進場時為 NULL
+	ParkingRecordID *uint `gorm:"index"`
+	// Event 發生警示的時機：Entry, EntryDenied, Exit
+	Event string `gorm:"type:varchar(20);not null"`
+	// LicensePlate 進出場時辨識的標準格式車牌
+	LicensePlate string `gorm:"type:varchar(20);not null"`
+	// WatchlistLicensePlate 監控名單上的車牌
+	WatchlistLicensePlate string `gorm:"type:varchar(20);not null"`
+	// MatchType 比對方式：Exact, Variant, Fuzzy
+	MatchType string `gorm:"type:varchar(20);not null"`
+	// Similarity 車牌相似度 (0.0 到 1.0 之間)，Exact 為 1
+	Similarity float64 `gorm:"not null"`
+	// Category 監控名單類別
+	Category string `gorm:"type:varchar(20);not null;index"`
+	// Action 監控名單的處理方式
+	Action string `gorm:"type:varchar(20);not null"`
+	// Reason 列入監控名單的原因
+	Reason string `gorm:"type:varchar(255)"`
+	// AcknowledgedBy 確認警示的人員，尚未確認則為 NULL
+	AcknowledgedBy *string `gorm:"type:varchar(100)"`
+	// AcknowledgedAt 確認警示的時間，尚未確認則為 NULL
+	AcknowledgedAt *time.Time `gorm:"index"`
+	// AcknowledgeNote 確認時的處理說明
+	AcknowledgeNote *string `gorm:"type:varchar(255)"`
+	// CreatedAt 發生警示的時間
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// IsValidWatchlistCategory 檢查是否為支援的監控名單類別
+func IsValidWatchlistCategory(category string) bool {
+	switch category {
+	case WatchlistCategoryStolen, WatchlistCategoryBanned, WatchlistCategoryVIP, WatchlistCategoryOther:
+		return true
+	}
+	return false
+}
+
+// IsValidWatchlistAction 檢查是否為支援的監控名單處理方式
+func IsValidWatchlistAction(action string) bool {
+	switch action {
+	case WatchlistActionAlert, WatchlistActionDenyEntry, WatchlistActionFlag:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Zone 停車場內的分區，例如樓層 B2 或機車區
+// 對應 PostgreSQL 的 'zones' 表
+type Zone struct {
+	// ZoneID 作為主鍵
+	ZoneID uint `gorm:"primaryKey"`
+	// ParkingLotID 分區所屬的停車場
+	ParkingLotID uint `gorm:"not null;uniqueIndex:idx_zones_parking_lot_name"`
+	// Name 分區名稱，同一停車場內不可重複，會顯示在看板上 (例如 "B2")
+	Name string `gorm:"type:varchar(50);not null;uniqueIndex:idx_zones_parking_lot_name"`
+	// Level 分區所在樓層，地下樓層為負數，看板依樓層排序
+	Level int `gorm:"not null;default:0"`
+	// Description 分區說明，例如 "機車專用"
+	Description string `gorm:"type:varchar(255)"`
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+
+	// Spots 分區內的車位
+	Spots []Spot `gorm:"foreignKey:ZoneID;constraint:OnDelete:CASCADE"`
+}
+
+// Spot 分區內的單一車位，由車位感應器回報是否有車
+// 對應 PostgreSQL 的 'spots' 表
+type Spot struct {
+	// SpotID 作為主鍵
+	SpotID uint `gorm:"primaryKey"`
+	// ZoneID 車位所屬的分區
+	ZoneID uint `gorm:"not null;uniqueIndex:idx_spots_zone_code"`
+	// Code 車位編號，同一分區內不可重複 (例如 "B2-014")
+	Code string `gorm:"type:varchar(50);not null;uniqueIndex:idx_spots_zone_code"`
+	// SensorID 車位感應器識別碼，在所有車位中不可重複
+	SensorID string `gorm:"type:varchar(100);not null;uniqueIndex"`
+	// Occupied 車位感應器最近一次回報是否有車
+	Occupied bool `gorm:"not null;default:false"`
+	// StatusChangedAt 車位最近一次由空轉為有車或由有車轉為空的時間，尚未回報則為 NULL
+	StatusChangedAt *time.Time
+	// CreatedAt 建立時間
+	CreatedAt time.Time
+	// UpdatedAt 最後更新時間
+	UpdatedAt time.Time
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChargingSessionRepository 定義充電資料庫操作的介面
+type ChargingSessionRepository interface {
+	CreateChargingSession(tx *gorm.DB, session *models.ChargingSession) error
+	GetChargingSessionByID(id uint) (*models.ChargingSession, error)
+	GetChargingSessionByIDForUpdate(tx *gorm.DB, id uint) (*models.ChargingSession, error)
+	GetChargingSessionsByParkingRecordID(tx *gorm.DB, parkingRecordID uint) ([]models.ChargingSession, error)
+	GetActiveChargingSessionByChargerID(tx *gorm.DB, chargerID string) (*models.ChargingSession, error)
+	CountActiveChargingSessions(tx *gorm.DB, parkingRecordID uint) (int64, error)
+	UpdateChargingSession(tx *gorm.DB, session *models.ChargingSession) error
+	CreateMeterReading(tx *gorm.DB, reading *models.ChargingMeterReading) error
+	StopActiveChargingSessions(tx *gorm.DB, parkingRecordID uint, at time.Time) (int64, error)
+}
+
+// chargingSessionRepository 是 ChargingSessionRepository 的 GORM 實作
+type chargingSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewChargingSessionRepository 建立一個新的 ChargingSessionRepository 實例
+func NewChargingSessionRepository() ChargingSessionRepository {
+	return &chargingSessionRepository{db: database.GetDB()}
+}
+
+// CreateChargingSession 新增充電
+func (r *chargingSessionRepository) CreateChargingSession(tx *gorm.DB, session *models.ChargingSession) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Create(session).Error
+}
+
+// GetChargingSessionByID 透過 ID 取得充電及其電錶讀數
+func (r *chargingSessionRepository) GetChargingSessionByID(id uint) (*models.ChargingSession, error) {
+	var session models.ChargingSession
+	result := r.db.Preload("Readings", func(db *gorm.DB) *gorm.DB {
+		return db.Order("read_at")
+	}).First(&session, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// GetChargingSessionByIDForUpdate 在資料庫交易中透過 ID 取得充電並鎖定該列
+func (r *chargingSessionRepository) GetChargingSessionByIDForUpdate(tx *gorm.DB, id uint) (*models.ChargingSession, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var session models.ChargingSession
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// GetChargingSessionsByParkingRecordID 取得停車記錄的所有充電，依開始時間排序
+func (r *chargingSessionRepository) GetChargingSessionsByParkingRecordID(tx *gorm.DB, parkingRecordID uint) ([]models.ChargingSession, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var sessions []models.ChargingSession
+	result := dbToUse.Where("parking_record_id = ?", parkingRecordID).Order("started_at, charging_session_id").Find(&sessions)
+	return sessions, result.Error
+}
+
+// GetActiveChargingSessionByChargerID 取得充電樁目前進行中的充電，沒有時回傳 nil
+func (r *chargingSessionRepository) GetActiveChargingSessionByChargerID(tx *gorm.DB, chargerID string) (*models.ChargingSession, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var session models.ChargingSession
+	result := dbToUse.Where("charger_id = ? AND status = ?", chargerID, models.ChargingSessionStatusActive).First(&session)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// CountActiveChargingSessions 計算停車記錄進行中的充電數量
+func (r *chargingSessionRepository) CountActiveChargingSessions(tx *gorm.DB, parkingRecordID uint) (int64, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var count int64
+	err := dbToUse.Model(&models.ChargingSession{}).
+		Where("parking_record_id = ? AND status = ?", parkingRecordID, models.ChargingSessionStatusActive).
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateChargingSession 更新充電
+func (r *chargingSessionRepository) UpdateChargingSession(tx *gorm.DB, session *models.ChargingSession) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Omit("Readings").Save(session).Error
+}
+
+// CreateMeterReading 新增電錶讀數
+func (r *chargingSessionRepository) CreateMeterReading(tx *gorm.DB, reading *models.ChargingMeterReading) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Create(reading).Error
+}
+
+// StopActiveChargingSessions 將停車記錄進行中的充電以最後一次的電錶讀數結束
+func (r *chargingSessionRepository) StopActiveChargingSessions(tx *gorm.DB, parkingRecordID uint, at time.Time) (int64, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	result := dbToUse.Model(&models.ChargingSession{}).
+		Where("parking_record_id = ? AND status = ?", parkingRecordID, models.ChargingSessionStatusActive).
+		Updates(map[string]interface{}{"status": models.ChargingSessionStatusCompleted, "stopped_at": at})
+	return result.RowsAffected, result.Error
+}
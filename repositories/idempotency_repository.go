@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository 定義 Idempotency 記錄資料庫操作的介面
+type IdempotencyRepository interface {
+	CreateIdempotencyRecordIfNotExists(record *models.IdempotencyRecord) (bool, error)
+	GetIdempotencyRecordByKey(key string) (*models.IdempotencyRecord, error)
+	TakeOverIdempotencyRecord(key string, requestHash string, now time.Time, leaseExpiresAt time.Time) (bool, error)
+	CompleteIdempotencyRecord(key string, requestHash string, leaseExpiresAt time.Time, statusCode int, contentType string, responseBody []byte) (bool, error)
+	DeleteIdempotencyRecord(key string, requestHash string, leaseExpiresAt time.Time) (bool, error)
+	DeleteExpiredIdempotencyRecords(now time.Time) (int64, error)
+}
+
+// idempotencyRepository 是 IdempotencyRepository 的 GORM 實作
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository 建立一個新的 IdempotencyRepository 實例
+func NewIdempotencyRepository() IdempotencyRepository {
+	return &idempotencyRepository{db: database.GetDB()}
+}
+
+// CreateIdempotencyRecordIfNotExists 新增 Idempotency 記錄，若相同 key 已存在則不新增並回傳 false
+// 已過期的舊記錄會先被移除
+func (r *idempotencyRepository) CreateIdempotencyRecordIfNotExists(record *models.IdempotencyRecord) (bool, error) {
+	if err := r.db.Where("idempotency_key = ? AND expires_at < ?", record.IdempotencyKey, time.Now()).
+		Delete(&models.IdempotencyRecord{}).Error; err != nil {
+		return false, err
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetIdempotencyRecordByKey 透過 key 取得 Idempotency 記錄
+func (r *idempotencyRepository) GetIdempotencyRecordByKey(key string) (*models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	result := r.db.Where("idempotency_key = ?", key).First(&record)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &record, nil
+}
+
+// TakeOverIdempotencyRecord 接手處理期限已過仍未完成的 Idempotency 記錄，並延長處理期限
+// 記錄已完成、請求內容不同或處理期限未過時不接手並回傳 false
+func (r *idempotencyRepository) TakeOverIdempotencyRecord(key string, requestHash string, now time.Time, leaseExpiresAt time.Time) (bool, error) {
+	result := r.db.Model(&models.IdempotencyRecord{}).
+		Where("idempotency_key = ? AND request_hash = ? AND completed = ?", key, requestHash, false).
+		Where("lease_expires_at IS NULL OR lease_expires_at < ?", now).
+		Update("lease_expires_at", leaseExpiresAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CompleteIdempotencyRecord 保存第一次請求的回應並標記為已完成
+// 只更新仍由 leaseExpiresAt 這次處理期限持有的記錄，記錄已被重送的請求接手時不更新並回傳 false
+func (r *idempotencyRepository) CompleteIdempotencyRecord(key string, requestHash string, leaseExpiresAt time.Time, statusCode int, contentType string, responseBody []byte) (bool, error) {
+	result := r.db.Model(&models.IdempotencyRecord{}).
+		Where("idempotency_key = ? AND request_hash = ? AND lease_expires_at = ? AND completed = ?", key, requestHash, leaseExpiresAt, false).
+		Updates(map[string]interface{}{
+			"completed":     true,
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": responseBody,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteIdempotencyRecord 刪除仍由 leaseExpiresAt 這次處理期限持有的 Idempotency 記錄，讓相同 key 可以重新處理
+// 記錄已被重送的請求接手時不刪除並回傳 false
+func (r *idempotencyRepository) DeleteIdempotencyRecord(key string, requestHash string, leaseExpiresAt time.Time) (bool, error) {
+	result := r.db.Where("idempotency_key = ? AND request_hash = ? AND lease_expires_at = ? AND completed = ?", key, requestHash, leaseExpiresAt, false).
+		Delete(&models.IdempotencyRecord{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpiredIdempotencyRecords 刪除已過期的 Idempotency 記錄，回傳刪除的筆數
+func (r *idempotencyRepository) DeleteExpiredIdempotencyRecords(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", now).Delete(&models.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
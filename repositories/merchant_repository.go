@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MerchantSubsidy 單一商家在期間內的折抵統計
+type MerchantSubsidy struct {
+	MerchantID      uint
+	MerchantName    string
+	ValidationCount int64
+	SubsidyAmount   models.Money
+}
+
+// MerchantRepository 定義商家、折抵碼與停車折抵資料庫操作的介面
+type MerchantRepository interface {
+	CreateMerchant(merchant *models.Merchant) error
+	GetMerchantByID(id uint) (*models.Merchant, error)
+	GetAllMerchants() ([]models.Merchant, error)
+
+	CreateValidationCode(code *models.ValidationCode) error
+	GetValidationCodesByMerchantID(merchantID uint) ([]models.ValidationCode, error)
+	GetValidationCodeByCodeForUpdate(tx *gorm.DB, code string) (*models.ValidationCode, error)
+	UpdateValidationCode(tx *gorm.DB, code *models.ValidationCode) error
+
+	CreateParkingValidation(tx *gorm.DB, validation *models.ParkingValidation) error
+	GetParkingValidationsByParkingRecordID(tx *gorm.DB, parkingRecordID uint) ([]models.ParkingValidation, error)
+	UpdateParkingValidationDiscount(tx *gorm.DB, parkingValidationID uint, discount models.Money) error
+
+	// --- 報表相關方法 ---
+	SumMerchantSubsidies(parkingLotID *uint, startTime, endTime *time.Time) ([]MerchantSubsidy, error)
+}
+
+// merchantRepository 是 MerchantRepository 的 GORM 實作
+type merchantRepository struct {
+	db *gorm.DB
+}
+
+// NewMerchantRepository 建立一個新的 MerchantRepository 實例
+func NewMerchantRepository() MerchantRepository {
+	return &merchantRepository{db: database.GetDB()}
+}
+
+// CreateMerchant 新增商家
+func (r *merchantRepository) CreateMerchant(merchant *models.Merchant) error {
+	return r.db.Create(merchant).Error
+}
+
+// GetMerchantByID 透過 ID 取得商家
+func (r *merchantRepository) GetMerchantByID(id uint) (*models.Merchant, error) {
+	var merchant models.Merchant
+	result := r.db.First(&merchant, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &merchant, nil
+}
+
+// GetAllMerchants 取得所有商家
+func (r *merchantRepository) GetAllMerchants() ([]models.Merchant, error) {
+	var merchants []models.Merchant
+	result := r.db.Order("merchant_id").Find(&merchants)
+	return merchants, result.Error
+}
+
+// CreateValidationCode 新增折抵碼
+func (r *merchantRepository) CreateValidationCode(code *models.ValidationCode) error {
+	return r.db.Create(code).Error
+}
+
+// GetValidationCodesByMerchantID 取得商家發放的所有折抵碼
+func (r *merchantRepository) GetValidationCodesByMerchantID(merchantID uint) ([]models.ValidationCode, error) {
+	var codes []models.ValidationCode
+	result := r.db.Where("merchant_id = ?", merchantID).Order("created_at DESC").Find(&codes)
+	return codes, result.Error
+}
+
+// GetValidationCodeByCodeForUpdate 在資料庫交易中透過折抵碼取得折抵碼與其商家，並鎖定折抵碼該列
+func (r *merchantRepository) GetValidationCodeByCodeForUpdate(tx *gorm.DB, code string) (*models.ValidationCode, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var validationCode models.ValidationCode
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: clause.CurrentTable}}).
+		Preload("Merchant").
+		Where("code = ?", code).
+		First(&validationCode)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &validationCode, nil
+}
+
+// UpdateValidationCode 更新折抵碼
+func (r *merchantRepository) UpdateValidationCode(tx *gorm.DB, code *models.ValidationCode) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Omit("Merchant").Save(code).Error
+}
+
+// CreateParkingValidation 新增停車折抵
+func (r *merchantRepository) CreateParkingValidation(tx *gorm.DB, validation *models.ParkingValidation) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Omit("ValidationCode").Create(validation).Error
+}
+
+// GetParkingValidationsByParkingRecordID 依套用順序取得停車記錄的所有折抵與其折抵碼
+func (r *merchantRepository) GetParkingValidationsByParkingRecordID(tx *gorm.DB, parkingRecordID uint) ([]models.ParkingValidation, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var validations []models.ParkingValidation
+	result := dbToUse.Preload("ValidationCode").
+		Where("parking_record_id = ?", parkingRecordID).
+		Order("applied_at, parking_validation_id").
+		Find(&validations)
+	return validations, result.Error
+}
+
+// UpdateParkingValidationDiscount 更新停車折抵實際折抵的金額
+func (r *merchantRepository) UpdateParkingValidationDiscount(tx *gorm.DB, parkingValidationID uint, discount models.Money) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Model(&models.ParkingValidation{}).
+		Where("parking_validation_id = ?", parkingValidationID).
+		Update("discount_amount", discount).Error
+}
+
+// SumMerchantSubsidies 依商家統計在指定時間範圍內進場且已付款的停車記錄所獲得的折抵總額，parkingLotID 為 nil 時統計所有停車場
+func (r *merchantRepository) SumMerchantSubsidies(parkingLotID *uint, startTime, endTime *time.Time) ([]MerchantSubsidy, error) {
+	dbQuery := r.db.Model(&models.ParkingValidation{}).
+		Joins("JOIN parking_records ON parking_records.record_id = parking_validations.parking_record_id").
+		Joins("JOIN merchants ON merchants.merchant_id = parking_validations.merchant_id").
+		Where("parking_records.payment_status = ?", "Paid")
+
+	if parkingLotID != nil {
+		dbQuery = dbQuery.Where("parking_records.parking_lot_id = ?", *parkingLotID)
+	}
+	if startTime != nil {
+		dbQuery = dbQuery.Where("parking_records.entry_time >= ?", *startTime)
+	}
+	if endTime != nil {
+		dbQuery = dbQuery.Where("parking_records.entry_time <= ?", *endTime)
+	}
+
+	rows, err := dbQuery.
+		Select("merchants.merchant_id, merchants.name, COUNT(*), COALESCE(SUM(parking_validations.discount_amount), 0)").
+		Group("merchants.merchant_id, merchants.name").
+		Order("merchants.merchant_id").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subsidies := []MerchantSubsidy{}
+	for rows.Next() {
+		var subsidy MerchantSubsidy
+		if err := rows.Scan(&subsidy.MerchantID, &subsidy.MerchantName, &subsidy.ValidationCount, &subsidy.SubsidyAmount); err != nil {
+			return nil, err
+		}
+		subsidies = append(subsidies, subsidy)
+	}
+	return subsidies, rows.Err()
+}
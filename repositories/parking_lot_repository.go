@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ParkingLotRepository 定義停車場與感應器資料庫操作的介面
+type ParkingLotRepository interface {
+	CreateParkingLot(parkingLot *models.ParkingLot) error
+	GetParkingLotByID(id uint) (*models.ParkingLot, error)
+	GetParkingLotByIDForUpdate(tx *gorm.DB, id uint) (*models.ParkingLot, error)
+	GetParkingLotByName(name string) (*models.ParkingLot, error)
+	GetAllParkingLots() ([]models.ParkingLot, error)
+	UpdateParkingLot(parkingLot *models.ParkingLot) error
+
+	CreateParkingLotSensor(sensor *models.ParkingLotSensor) error
+	GetParkingLotSensorByID(sensorID string) (*models.ParkingLotSensor, error)
+	DeleteParkingLotSensor(sensorID string) error
+
+	CreateZone(zone *models.Zone) error
+	GetZoneByID(zoneID uint) (*models.Zone, error)
+	GetZoneByName(parkingLotID uint, name string) (*models.Zone, error)
+	GetZonesByParkingLotID(parkingLotID uint) ([]models.Zone, error)
+	GetAllZones() ([]models.Zone, error)
+	UpdateZone(zone *models.Zone) error
+	DeleteZone(zoneID uint) error
+
+	CreateSpot(spot *models.Spot) error
+	GetSpotByID(spotID uint) (*models.Spot, error)
+	GetSpotByCode(zoneID uint, code string) (*models.Spot, error)
+	GetSpotBySensorID(sensorID string) (*models.Spot, error)
+	UpdateSpot(spot *models.Spot) error
+	DeleteSpot(spotID uint) error
+}
+
+// parkingLotRepository 是 ParkingLotRepository 的 GORM 實作
+type parkingLotRepository struct {
+	db *gorm.DB
+}
+
+// NewParkingLotRepository 建立一個新的 ParkingLotRepository 實例
+func NewParkingLotRepository() ParkingLotRepository {
+	return &parkingLotRepository{db: database.GetDB()}
+}
+
+// CreateParkingLot 新增停車場
+func (r *parkingLotRepository) CreateParkingLot(parkingLot *models.ParkingLot) error {
+	return r.db.Create(parkingLot).Error
+}
+
+// GetParkingLotByID 透過 ID 取得停車場與其感應器、開放的車種
+func (r *parkingLotRepository) GetParkingLotByID(id uint) (*models.ParkingLot, error) {
+	var parkingLot models.ParkingLot
+	result := r.db.Preload("Sensors").Preload("VehicleClasses").First(&parkingLot, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &parkingLot, nil
+}
+
+// GetParkingLotByIDForUpdate 在資料庫交易中透過 ID 取得停車場並鎖定該列，用於序列化同一停車場的進場
+func (r *parkingLotRepository) GetParkingLotByIDForUpdate(tx *gorm.DB, id uint) (*models.ParkingLot, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var parkingLot models.ParkingLot
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("VehicleClasses").First(&parkingLot, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &parkingLot, nil
+}
+
+// GetParkingLotByName 透過名稱取得停車場
+func (r *parkingLotRepository) GetParkingLotByName(name string) (*models.ParkingLot, error) {
+	var parkingLot models.ParkingLot
+	result := r.db.Where("name = ?", name).First(&parkingLot)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &parkingLot, nil
+}
+
+// GetAllParkingLots 取得所有停車場與其感應器、開放的車種
+func (r *parkingLotRepository) GetAllParkingLots() ([]models.ParkingLot, error) {
+	var parkingLots []models.ParkingLot
+	result := r.db.Preload("Sensors").Preload("VehicleClasses").Order("parking_lot_id").Find(&parkingLots)
+	return parkingLots, result.Error
+}
+
+// UpdateParkingLot 更新停車場並以 parkingLot.VehicleClasses 取代原本開放的車種，不會變更其感應器
+func (r *parkingLotRepository) UpdateParkingLot(parkingLot *models.ParkingLot) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("parking_lot_id = ?", parkingLot.ParkingLotID).Delete(&models.ParkingLotVehicleClass{}).Error; err != nil {
+			return err
+		}
+		for i := range parkingLot.VehicleClasses {
+			parkingLot.VehicleClasses[i].ParkingLotID = parkingLot.ParkingLotID
+		}
+		return tx.Omit("Sensors").Session(&gorm.Session{FullSaveAssociations: true}).Save(parkingLot).Error
+	})
+}
+
+// CreateParkingLotSensor 新增停車場感應器
+func (r *parkingLotRepository) CreateParkingLotSensor(sensor *models.ParkingLotSensor) error {
+	return r.db.Create(sensor).Error
+}
+
+// GetParkingLotSensorByID 透過感應器識別碼取得感應器
+func (r *parkingLotRepository) GetParkingLotSensorByID(sensorID string) (*models.ParkingLotSensor, error) {
+	var sensor models.ParkingLotSensor
+	result := r.db.Where("sensor_id = ?", sensorID).First(&sensor)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &sensor, nil
+}
+
+// DeleteParkingLotSensor 刪除停車場感應器
+func (r *parkingLotRepository) DeleteParkingLotSensor(sensorID string) error {
+	return r.db.Where("sensor_id = ?", sensorID).Delete(&models.ParkingLotSensor{}).Error
+}
+
+// CreateZone 新增停車場分區
+func (r *parkingLotRepository) CreateZone(zone *models.Zone) error {
+	return r.db.Create(zone).Error
+}
+
+// GetZoneByID 透過 ID 取得分區與其車位
+func (r *parkingLotRepository) GetZoneByID(zoneID uint) (*models.Zone, error) {
+	var zone models.Zone
+	result := r.db.Preload("Spots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("code")
+	}).First(&zone, zoneID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &zone, nil
+}
+
+// GetZoneByName 透過名稱取得停車場內的分區
+func (r *parkingLotRepository) GetZoneByName(parkingLotID uint, name string) (*models.Zone, error) {
+	var zone models.Zone
+	result := r.db.Where("parking_lot_id = ? AND name = ?", parkingLotID, name).First(&zone)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &zone, nil
+}
+
+// GetZonesByParkingLotID 取得停車場的所有分區與其車位，依樓層與名稱排序
+func (r *parkingLotRepository) GetZonesByParkingLotID(parkingLotID uint) ([]models.Zone, error) {
+	var zones []models.Zone
+	result := r.db.Preload("Spots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("code")
+	}).Where("parking_lot_id = ?", parkingLotID).Order("level, name").Find(&zones)
+	return zones, result.Error
+}
+
+// GetAllZones 取得所有停車場的分區與其車位，依停車場、樓層與名稱排序
+func (r *parkingLotRepository) GetAllZones() ([]models.Zone, error) {
+	var zones []models.Zone
+	result := r.db.Preload("Spots").Order("parking_lot_id, level, name").Find(&zones)
+	return zones, result.Error
+}
+
+// UpdateZone 更新分區，不會變更其車位
+func (r *parkingLotRepository) UpdateZone(zone *models.Zone) error {
+	return r.db.Omit("Spots").Save(zone).Error
+}
+
+// DeleteZone 刪除分區與其車位
+func (r *parkingLotRepository) DeleteZone(zoneID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("zone_id = ?", zoneID).Delete(&models.Spot{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Zone{}, zoneID).Error
+	})
+}
+
+// CreateSpot 新增車位
+func (r *parkingLotRepository) CreateSpot(spot *models.Spot) error {
+	return r.db.Create(spot).Error
+}
+
+// GetSpotByID 透過 ID 取得車位
+func (r *parkingLotRepository) GetSpotByID(spotID uint) (*models.Spot, error) {
+	var spot models.Spot
+	result := r.db.First(&spot, spotID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &spot, nil
+}
+
+// GetSpotByCode 透過車位編號取得分區內的車位
+func (r *parkingLotRepository) GetSpotByCode(zoneID uint, code string) (*models.Spot, error) {
+	var spot models.Spot
+	result := r.db.Where("zone_id = ? AND code = ?", zoneID, code).First(&spot)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &spot, nil
+}
+
+// GetSpotBySensorID 透過車位感應器識別碼取得車位
+func (r *parkingLotRepository) GetSpotBySensorID(sensorID string) (*models.Spot, error) {
+	var spot models.Spot
+	result := r.db.Where("sensor_id = ?", sensorID).First(&spot)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &spot, nil
+}
+
+// UpdateSpot 更新車位
+func (r *parkingLotRepository) UpdateSpot(spot *models.Spot) error {
+	return r.db.Save(spot).Error
+}
+
+// DeleteSpot 刪除車位
+func (r *parkingLotRepository) DeleteSpot(spotID uint) error {
+	return r.db.Delete(&models.Spot{}, spotID).Error
+}
@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"database/sql"
 	"hello-professor_backend/database"
+	"hello-professor_backend/licenseplate"
 	"hello-professor_backend/models"
 
-	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ParkingRecordRepository 定義停車記錄資料庫操作的介面
 type ParkingRecordRepository interface {
-	CreateParkingRecord(parkingRecord *models.ParkingRecord) error
+	CreateParkingRecord(tx *gorm.DB, parkingRecord *models.ParkingRecord) error
 	GetParkingRecordByID(id uint) (*models.ParkingRecord, error)
 	GetParkingRecordsByLicensePlate(licensePlate string) ([]models.ParkingRecord, error)
 	SearchParkingRecordsByLicensePlate(licensePlateQuery string) ([]models.ParkingRecord, error)
@@ -20,12 +22,26 @@ type ParkingRecordRepository interface {
 	DeleteParkingRecord(id uint) error
 	GetAllParkingRecords(limit int, offset int) ([]models.ParkingRecord, error)
 	GetLatestParkingRecordByLicensePlate(licensePlate string) (*models.ParkingRecord, error)
+	GetLatestParkingRecordByLicensePlateForUpdate(tx *gorm.DB, licensePlate string) (*models.ParkingRecord, error)
+	FindSimilarActiveParkingRecords(parkingLotID uint, licensePlate string, minSimilarity float64, limit int) ([]ParkingRecordMatch, error)
+	FlagStaleParkingRecords(enteredBefore time.Time, flaggedAt time.Time) (int64, error)
+	GetStaleParkingRecords(parkingLotID *uint, limit int, offset int) ([]models.ParkingRecord, error)
 
 	// --- 報表相關方法 ---
-	CountParkingRecords(startTime, endTime *time.Time) (int64, error)
-	SumPaidParkingFees(startTime, endTime *time.Time) (float64, error)
-	CountParkingRecordsWithImage(startTime, endTime *time.Time) (int64, error)
-	CountActiveParkingRecords() (int64, error)
+	// parkingLotID 為 nil 時統計所有停車場
+	CountParkingRecords(parkingLotID *uint, startTime, endTime *time.Time) (int64, error)
+	CountParkingRecordsByVehicleClass(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]int64, error)
+	SumPaidParkingFeesByVehicleClass(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]models.Money, error)
+	SumRefundedParkingFeesByVehicleClass(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]models.Money, error)
+	CountParkingRecordsWithImage(parkingLotID *uint, startTime, endTime *time.Time) (int64, error)
+	CountActiveParkingRecords(tx *gorm.DB, parkingLotID uint, vehicleClass string) (int64, error)
+	CountActiveParkingRecordsByParkingLot() (map[uint]map[string]int64, error)
+}
+
+// ParkingRecordMatch 是相似車牌查詢找到的停車記錄與其車牌相似度 (0.0 到 1.0 之間)
+type ParkingRecordMatch struct {
+	Record     models.ParkingRecord
+	Similarity float64
 }
 
 // parkingRecordRepository 是 ParkingRecordRepository 的 GORM 實作
@@ -39,8 +55,12 @@ func NewParkingRecordRepository() ParkingRecordRepository {
 }
 
 // CreateParkingRecord 新增停車記錄
-func (r *parkingRecordRepository) CreateParkingRecord(parkingRecord *models.ParkingRecord) error {
-	result := r.db.Create(parkingRecord)
+func (r *parkingRecordRepository) CreateParkingRecord(tx *gorm.DB, parkingRecord *models.ParkingRecord) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	result := dbToUse.Create(parkingRecord)
 	return result.Error
 }
 
@@ -59,9 +79,10 @@ func (r *parkingRecordRepository) GetParkingRecordByID(id uint) (*models.Parking
 }
 
 // GetParkingRecordsByLicensePlate 透過 LicensePlate 取得相關的所有停車記錄
-// 會同時比對 LicensePlate 和 UserVerifiedLicensePlate 欄位
+// 會同時比對 LicensePlate 和 UserVerifiedLicensePlate 欄位，車牌會先轉為標準格式
 func (r *parkingRecordRepository) GetParkingRecordsByLicensePlate(licensePlate string) ([]models.ParkingRecord, error) {
 	var records []models.ParkingRecord
+	licensePlate = licenseplate.Normalize(licensePlate)
 	result := r.db.Preload("Transaction").
 		Where("license_plate = ? OR user_verified_license_plate = ?", licensePlate, licensePlate).
 		Order("entry_time DESC").
@@ -70,29 +91,37 @@ func (r *parkingRecordRepository) GetParkingRecordsByLicensePlate(licensePlate s
 }
 
 // SearchParkingRecordsByLicensePlate 透過 LicensePlate 模糊搜尋相關的所有停車記錄
-// 會同時比對 LicensePlate 和 UserVerifiedLicensePlate 欄位，不區分大小寫
+// 會同時比對 LicensePlate 和 UserVerifiedLicensePlate 欄位，不區分大小寫與分隔符號，且 O/0、I/1、B/8 視為相同
+// 查詢字串為車牌片段時，包含該片段的車牌也會列出
 func (r *parkingRecordRepository) SearchParkingRecordsByLicensePlate(licensePlateQuery string) ([]models.ParkingRecord, error) {
 	var records []models.ParkingRecord
 	// 定義相似度閾值，您可以根據需求調整這個值（0.0 到 1.0 之間）
 	const similarityThreshold = 0.3 // 例如，0.3 表示 30% 的相似度
 
-	// 將查詢字串轉換為小寫，以進行不區分大小寫的相似度比較
-	lowerLicensePlateQuery := strings.ToLower(licensePlateQuery)
+	// 將查詢字串與車牌都轉為比對用的鍵值，以進行不區分格式的相似度比較
+	searchKey := licenseplate.SearchKey(licensePlateQuery)
+	if searchKey == "" {
+		return records, nil
+	}
+	plateKey := licenseplate.SearchKeySQL("license_plate")
+	verifiedPlateKey := licenseplate.SearchKeySQL("user_verified_license_plate")
+	pattern := "%" + searchKey + "%"
 
 	result := r.db.Preload("Transaction").
-		Where("similarity(LOWER(license_plate), ?) > ? OR similarity(LOWER(user_verified_license_plate), ?) > ?", lowerLicensePlateQuery, similarityThreshold, lowerLicensePlateQuery, similarityThreshold).
+		Where("similarity("+plateKey+", ?) > ? OR similarity("+verifiedPlateKey+", ?) > ? OR "+plateKey+" LIKE ? OR "+verifiedPlateKey+" LIKE ?", searchKey, similarityThreshold, searchKey, similarityThreshold, pattern, pattern).
 		Order("entry_time DESC").
 		Find(&records)
 	return records, result.Error
 }
 
 // UpdateParkingRecord 更新停車記錄
+// 影像的 key 只在進場時寫入，不會被更新 (API 不會回傳 key，整筆更新時無法帶回)
 func (r *parkingRecordRepository) UpdateParkingRecord(tx *gorm.DB, parkingRecord *models.ParkingRecord) error {
 	dbToUse := r.db
 	if tx != nil {
 		dbToUse = tx
 	}
-	result := dbToUse.Save(parkingRecord)
+	result := dbToUse.Omit("ImageKey", "ThumbnailKey").Save(parkingRecord)
 	return result.Error
 }
 
@@ -117,9 +146,10 @@ func (r *parkingRecordRepository) GetAllParkingRecords(limit int, offset int) ([
 }
 
 // GetLatestParkingRecordByLicensePlate 透過 LicensePlate 取得最新的停車記錄（基於 EntryTime 降序）
-// 會同時比對 LicensePlate 和 UserVerifiedLicensePlate 欄位
+// 會同時比對 LicensePlate 和 UserVerifiedLicensePlate 欄位，車牌會先轉為標準格式
 func (r *parkingRecordRepository) GetLatestParkingRecordByLicensePlate(licensePlate string) (*models.ParkingRecord, error) {
 	var record models.ParkingRecord
+	licensePlate = licenseplate.Normalize(licensePlate)
 	// 查詢條件修改為同時檢查 LicensePlate 或 UserVerifiedLicensePlate，並且 ExitTime 為 NULL (表示仍在場內)
 	result := r.db.Preload("Transaction").
 		Where("(license_plate = ? OR user_verified_license_plate = ?) AND exit_time IS NULL", licensePlate, licensePlate).
@@ -133,13 +163,109 @@ func (r *parkingRecordRepository) GetLatestParkingRecordByLicensePlate(licensePl
 	return &record, nil
 }
 
+// GetLatestParkingRecordByLicensePlateForUpdate 在資料庫交易中取得車牌最新一筆尚未出場的停車記錄並鎖定該列
+// 與 GetLatestParkingRecordByLicensePlate 相同，會同時比對 LicensePlate 和 UserVerifiedLicensePlate 欄位
+func (r *parkingRecordRepository) GetLatestParkingRecordByLicensePlateForUpdate(tx *gorm.DB, licensePlate string) (*models.ParkingRecord, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var record models.ParkingRecord
+	licensePlate = licenseplate.Normalize(licensePlate)
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("(license_plate = ? OR user_verified_license_plate = ?) AND exit_time IS NULL", licensePlate, licensePlate).
+		Order("entry_time DESC").First(&record)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &record, nil
+}
+
+// FindSimilarActiveParkingRecords 以 pg_trgm 相似度尋找停車場內尚未出場、車牌與 licensePlate 相似的停車記錄
+// 會同時比對 LicensePlate 和 UserVerifiedLicensePlate 欄位，取較高的相似度；結果依相似度由高到低排列，最多回傳 limit 筆
+func (r *parkingRecordRepository) FindSimilarActiveParkingRecords(parkingLotID uint, licensePlate string, minSimilarity float64, limit int) ([]ParkingRecordMatch, error) {
+	searchKey := licenseplate.SearchKey(licensePlate)
+	if searchKey == "" {
+		return nil, nil
+	}
+	similarity := "GREATEST(similarity(" + licenseplate.SearchKeySQL("license_plate") + ", @key), COALESCE(similarity(" + licenseplate.SearchKeySQL("user_verified_license_plate") + ", @key), 0))"
+
+	var rows []struct {
+		RecordID   uint
+		Similarity float64
+	}
+	result := r.db.Model(&models.ParkingRecord{}).
+		Select("record_id, "+similarity+" AS similarity", sql.Named("key", searchKey)).
+		Where("parking_lot_id = ? AND exit_time IS NULL", parkingLotID).
+		Where(similarity+" >= @min", sql.Named("key", searchKey), sql.Named("min", minSimilarity)).
+		Order("similarity DESC, entry_time DESC").
+		Limit(limit).
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(rows) == 0 {
+		return []ParkingRecordMatch{}, nil
+	}
+
+	// 以單一查詢載入所有候選的停車記錄，再依相似度的順序組合結果
+	recordIDs := make([]uint, len(rows))
+	for i, row := range rows {
+		recordIDs[i] = row.RecordID
+	}
+	var records []models.ParkingRecord
+	if err := r.db.Find(&records, recordIDs).Error; err != nil {
+		return nil, err
+	}
+	recordsByID := make(map[uint]models.ParkingRecord, len(records))
+	for _, record := range records {
+		recordsByID[record.RecordID] = record
+	}
+
+	matches := make([]ParkingRecordMatch, 0, len(rows))
+	for _, row := range rows {
+		record, ok := recordsByID[row.RecordID]
+		if !ok {
+			continue // 兩次查詢之間被刪除的停車記錄
+		}
+		matches = append(matches, ParkingRecordMatch{Record: record, Similarity: row.Similarity})
+	}
+	return matches, nil
+}
+
+// FlagStaleParkingRecords 將 enteredBefore 之前進場且尚未出場、尚未標記的停車記錄標記為疑似遺漏出場，回傳標記的筆數
+func (r *parkingRecordRepository) FlagStaleParkingRecords(enteredBefore time.Time, flaggedAt time.Time) (int64, error) {
+	result := r.db.Model(&models.ParkingRecord{}).
+		Where("exit_time IS NULL AND stale_flagged_at IS NULL AND entry_time < ?", enteredBefore).
+		Update("stale_flagged_at", flaggedAt)
+	return result.RowsAffected, result.Error
+}
+
+// GetStaleParkingRecords 取得被標記為疑似遺漏出場且仍未出場的停車記錄 (分頁)，依進場時間由舊到新排序；parkingLotID 為 nil 時包含所有停車場
+func (r *parkingRecordRepository) GetStaleParkingRecords(parkingLotID *uint, limit int, offset int) ([]models.ParkingRecord, error) {
+	query := r.db.Where("exit_time IS NULL AND stale_flagged_at IS NOT NULL")
+	if parkingLotID != nil {
+		query = query.Where("parking_lot_id = ?", *parkingLotID)
+	}
+	var records []models.ParkingRecord
+	result := query.Order("entry_time, record_id").Limit(limit).Offset(offset).Find(&records)
+	return records, result.Error
+}
+
 // --- 報表相關方法的實作 ---
 
 // CountParkingRecords 計算在指定時間範圍內的停車記錄總數。
-func (r *parkingRecordRepository) CountParkingRecords(startTime, endTime *time.Time) (int64, error) {
+func (r *parkingRecordRepository) CountParkingRecords(parkingLotID *uint, startTime, endTime *time.Time) (int64, error) {
 	var count int64
 	dbQuery := r.db.Model(&models.ParkingRecord{})
 
+	if parkingLotID != nil {
+		dbQuery = dbQuery.Where("parking_lot_id = ?", *parkingLotID)
+	}
 	if startTime != nil {
 		dbQuery = dbQuery.Where("entry_time >= ?", *startTime)
 	}
@@ -151,30 +277,105 @@ func (r *parkingRecordRepository) CountParkingRecords(startTime, endTime *time.T
 	return count, err
 }
 
-// SumPaidParkingFees 計算在指定時間範圍內，狀態為 "Paid" 的停車記錄的總金額。
-func (r *parkingRecordRepository) SumPaidParkingFees(startTime, endTime *time.Time) (float64, error) {
-	var totalRevenue float64
-	dbQuery := r.db.Model(&models.ParkingRecord{}).Where("payment_status = ?", "Paid")
+// CountParkingRecordsByVehicleClass 依車種計算在指定時間範圍內的停車記錄數，沒有記錄的車種不會出現在結果中。
+func (r *parkingRecordRepository) CountParkingRecordsByVehicleClass(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]int64, error) {
+	dbQuery := r.db.Model(&models.ParkingRecord{})
 
+	if parkingLotID != nil {
+		dbQuery = dbQuery.Where("parking_lot_id = ?", *parkingLotID)
+	}
+	if vehicleClass != nil {
+		dbQuery = dbQuery.Where("vehicle_class = ?", *vehicleClass)
+	}
 	if startTime != nil {
-		dbQuery = dbQuery.Where("entry_time >= ?", *startTime) // 假設基於進場時間統計收入
+		dbQuery = dbQuery.Where("entry_time >= ?", *startTime)
 	}
 	if endTime != nil {
 		dbQuery = dbQuery.Where("entry_time <= ?", *endTime)
 	}
 
-	err := dbQuery.Select("COALESCE(SUM(calculated_amount), 0)").Row().Scan(&totalRevenue)
+	rows, err := dbQuery.Select("vehicle_class, COUNT(*)").Group("vehicle_class").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{}
+	for rows.Next() {
+		var class string
+		var count int64
+		if err := rows.Scan(&class, &count); err != nil {
+			return nil, err
+		}
+		counts[class] = count
+	}
+	return counts, rows.Err()
+}
+
+// SumPaidParkingFeesByVehicleClass 依車種計算在指定時間範圍內進場的停車記錄，扣除退款後的實收停車費總額。
+func (r *parkingRecordRepository) SumPaidParkingFeesByVehicleClass(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]models.Money, error) {
+	dbQuery := r.transactionsOfParkingRecords(parkingLotID, vehicleClass, startTime, endTime).
+		Where("transactions.status IN ?", []string{"Success", "Refunded"})
+	return sumTransactionAmountsByVehicleClass(dbQuery, "COALESCE(SUM(transactions.amount), 0)")
+}
+
+// SumRefundedParkingFeesByVehicleClass 依車種計算在指定時間範圍內進場的停車記錄的退款總額 (以正數表示)。
+func (r *parkingRecordRepository) SumRefundedParkingFeesByVehicleClass(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]models.Money, error) {
+	dbQuery := r.transactionsOfParkingRecords(parkingLotID, vehicleClass, startTime, endTime).
+		Where("transactions.transaction_type = ? AND transactions.status = ?", "Refund", "Success")
+	return sumTransactionAmountsByVehicleClass(dbQuery, "COALESCE(-SUM(transactions.amount), 0)")
+}
+
+// transactionsOfParkingRecords 建立查詢在指定時間範圍內進場的停車記錄所屬交易的基礎查詢
+func (r *parkingRecordRepository) transactionsOfParkingRecords(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) *gorm.DB {
+	dbQuery := r.db.Model(&models.Transaction{}).
+		Joins("JOIN parking_records ON parking_records.record_id = transactions.parking_record_id")
+
+	if parkingLotID != nil {
+		dbQuery = dbQuery.Where("parking_records.parking_lot_id = ?", *parkingLotID)
+	}
+	if vehicleClass != nil {
+		dbQuery = dbQuery.Where("parking_records.vehicle_class = ?", *vehicleClass)
+	}
+	if startTime != nil {
+		dbQuery = dbQuery.Where("parking_records.entry_time >= ?", *startTime) // 假設基於進場時間統計收入
+	}
+	if endTime != nil {
+		dbQuery = dbQuery.Where("parking_records.entry_time <= ?", *endTime)
+	}
+	return dbQuery
+}
+
+// sumTransactionAmountsByVehicleClass 依停車記錄的車種加總交易金額，sumExpression 為加總的 SQL 運算式
+func sumTransactionAmountsByVehicleClass(dbQuery *gorm.DB, sumExpression string) (map[string]models.Money, error) {
+	rows, err := dbQuery.Select("parking_records.vehicle_class, " + sumExpression).
+		Group("parking_records.vehicle_class").
+		Rows()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return totalRevenue, nil
+	defer rows.Close()
+
+	sums := map[string]models.Money{}
+	for rows.Next() {
+		var class string
+		var sum models.Money
+		if err := rows.Scan(&class, &sum); err != nil {
+			return nil, err
+		}
+		sums[class] = sum
+	}
+	return sums, rows.Err()
 }
 
-// CountParkingRecordsWithImage 計算在指定時間範圍內，Image 欄位不為 NULL 的停車記錄數量。
-func (r *parkingRecordRepository) CountParkingRecordsWithImage(startTime, endTime *time.Time) (int64, error) {
+// CountParkingRecordsWithImage 計算在指定時間範圍內，ImageKey 欄位不為 NULL 的停車記錄數量。
+func (r *parkingRecordRepository) CountParkingRecordsWithImage(parkingLotID *uint, startTime, endTime *time.Time) (int64, error) {
 	var count int64
-	dbQuery := r.db.Model(&models.ParkingRecord{}).Where("image IS NOT NULL AND image != ''")
+	dbQuery := r.db.Model(&models.ParkingRecord{}).Where("image_key IS NOT NULL")
 
+	if parkingLotID != nil {
+		dbQuery = dbQuery.Where("parking_lot_id = ?", *parkingLotID)
+	}
 	if startTime != nil {
 		dbQuery = dbQuery.Where("entry_time >= ?", *startTime)
 	}
@@ -186,10 +387,43 @@ func (r *parkingRecordRepository) CountParkingRecordsWithImage(startTime, endTim
 	return count, err
 }
 
-// CountActiveParkingRecords 計算當前仍在場內的車輛總數 (exit_time IS NULL)。
-func (r *parkingRecordRepository) CountActiveParkingRecords() (int64, error) {
+// CountActiveParkingRecords 計算停車場中該車種當前仍在場內的車輛數 (exit_time IS NULL)。
+func (r *parkingRecordRepository) CountActiveParkingRecords(tx *gorm.DB, parkingLotID uint, vehicleClass string) (int64, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
 	var count int64
-	dbQuery := r.db.Model(&models.ParkingRecord{}).Where("exit_time IS NULL")
-	err := dbQuery.Count(&count).Error
+	err := dbToUse.Model(&models.ParkingRecord{}).
+		Where("parking_lot_id = ? AND vehicle_class = ? AND exit_time IS NULL", parkingLotID, vehicleClass).
+		Count(&count).Error
 	return count, err
 }
+
+// CountActiveParkingRecordsByParkingLot 依停車場與車種計算當前仍在場內的車輛數 (exit_time IS NULL)，沒有車輛的停車場與車種不會出現在結果中。
+func (r *parkingRecordRepository) CountActiveParkingRecordsByParkingLot() (map[uint]map[string]int64, error) {
+	rows, err := r.db.Model(&models.ParkingRecord{}).
+		Select("parking_lot_id, vehicle_class, COUNT(*)").
+		Where("exit_time IS NULL").
+		Group("parking_lot_id, vehicle_class").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[uint]map[string]int64{}
+	for rows.Next() {
+		var parkingLotID uint
+		var class string
+		var count int64
+		if err := rows.Scan(&parkingLotID, &class, &count); err != nil {
+			return nil, err
+		}
+		if counts[parkingLotID] == nil {
+			counts[parkingLotID] = map[string]int64{}
+		}
+		counts[parkingLotID][class] = count
+	}
+	return counts, rows.Err()
+}
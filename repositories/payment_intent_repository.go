@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentIntentRepository 定義付款意圖資料庫操作的介面
+type PaymentIntentRepository interface {
+	CreatePaymentIntent(tx *gorm.DB, intent *models.PaymentIntent) error
+	GetPaymentIntentByID(id uint) (*models.PaymentIntent, error)
+	GetPaymentIntentsByParkingRecordID(parkingRecordID uint) ([]models.PaymentIntent, error)
+	GetPaymentIntentByProviderReferenceForUpdate(tx *gorm.DB, provider string, providerReference string) (*models.PaymentIntent, error)
+	UpdatePaymentIntent(tx *gorm.DB, intent *models.PaymentIntent) error
+	ExpirePaymentIntents(tx *gorm.DB, now time.Time) ([]models.PaymentIntent, error)
+	CreateWebhookEventIfNotExists(tx *gorm.DB, event *models.PaymentWebhookEvent) (bool, error)
+	AssignLineItemsToTransaction(tx *gorm.DB, paymentIntentID uint, transactionID uint) error
+}
+
+// paymentIntentRepository 是 PaymentIntentRepository 的 GORM 實作
+type paymentIntentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentIntentRepository 建立一個新的 PaymentIntentRepository 實例
+func NewPaymentIntentRepository() PaymentIntentRepository {
+	return &paymentIntentRepository{db: database.GetDB()}
+}
+
+// CreatePaymentIntent 新增付款意圖
+func (r *paymentIntentRepository) CreatePaymentIntent(tx *gorm.DB, intent *models.PaymentIntent) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Create(intent).Error
+}
+
+// GetPaymentIntentByID 透過 ID 取得付款意圖
+func (r *paymentIntentRepository) GetPaymentIntentByID(id uint) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	result := r.db.Preload("LineItems").First(&intent, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &intent, nil
+}
+
+// GetPaymentIntentsByParkingRecordID 取得停車記錄的所有付款意圖
+func (r *paymentIntentRepository) GetPaymentIntentsByParkingRecordID(parkingRecordID uint) ([]models.PaymentIntent, error) {
+	var intents []models.PaymentIntent
+	result := r.db.Preload("LineItems").Where("parking_record_id = ?", parkingRecordID).Order("created_at DESC").Find(&intents)
+	return intents, result.Error
+}
+
+// GetPaymentIntentByProviderReferenceForUpdate 在資料庫交易中透過支付供應商參考編號取得付款意圖並鎖定該列
+func (r *paymentIntentRepository) GetPaymentIntentByProviderReferenceForUpdate(tx *gorm.DB, provider string, providerReference string) (*models.PaymentIntent, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var intent models.PaymentIntent
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("payment_provider = ? AND provider_reference = ?", provider, providerReference).
+		First(&intent)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &intent, nil
+}
+
+// UpdatePaymentIntent 更新付款意圖
+func (r *paymentIntentRepository) UpdatePaymentIntent(tx *gorm.DB, intent *models.PaymentIntent) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Save(intent).Error
+}
+
+// ExpirePaymentIntents 鎖定已到期但尚未完成的付款意圖並標記為 Expired，回傳被標記的付款意圖
+func (r *paymentIntentRepository) ExpirePaymentIntents(tx *gorm.DB, now time.Time) ([]models.PaymentIntent, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var intents []models.PaymentIntent
+	err := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("status IN ? AND expires_at < ?", []string{"Pending", "Authorized"}, now).
+		Find(&intents).Error
+	if err != nil || len(intents) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, len(intents))
+	for i := range intents {
+		intents[i].Status = "Expired"
+		intents[i].FailureReason = "Payment was not confirmed before the intent expired"
+		ids[i] = intents[i].PaymentIntentID
+	}
+	err = dbToUse.Model(&models.PaymentIntent{}).
+		Where("payment_intent_id IN ?", ids).
+		Updates(map[string]interface{}{"status": "Expired", "failure_reason": "Payment was not confirmed before the intent expired"}).Error
+	if err != nil {
+		return nil, err
+	}
+	return intents, nil
+}
+
+// CreateWebhookEventIfNotExists 新增支付供應商通知記錄，若相同通知已處理過則不新增並回傳 false
+func (r *paymentIntentRepository) CreateWebhookEventIfNotExists(tx *gorm.DB, event *models.PaymentWebhookEvent) (bool, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	result := dbToUse.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// AssignLineItemsToTransaction 將付款意圖的明細連結到請款完成後建立的交易
+func (r *paymentIntentRepository) AssignLineItemsToTransaction(tx *gorm.DB, paymentIntentID uint, transactionID uint) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Model(&models.TransactionLineItem{}).
+		Where("payment_intent_id = ?", paymentIntentID).
+		Update("transaction_id", transactionID).Error
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/licenseplate"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PermitRepository 定義定期停車證資料庫操作的介面
+type PermitRepository interface {
+	CreatePermit(permit *models.Permit) error
+	GetPermitByID(id uint) (*models.Permit, error)
+	GetAllPermits(limit int, offset int) ([]models.Permit, error)
+	UpdatePermit(permit *models.Permit) error
+	DeletePermit(id uint) error
+	FindActivePermitByLicensePlate(licensePlate string, parkingLotID uint, at time.Time) (*models.Permit, error)
+	GetPermitsExpiringBetween(start, end time.Time) ([]models.Permit, error)
+}
+
+// permitRepository 是 PermitRepository 的 GORM 實作
+type permitRepository struct {
+	db *gorm.DB
+}
+
+// NewPermitRepository 建立一個新的 PermitRepository 實例
+func NewPermitRepository() PermitRepository {
+	return &permitRepository{db: database.GetDB()}
+}
+
+// CreatePermit 新增定期停車證與其登記的車牌
+func (r *permitRepository) CreatePermit(permit *models.Permit) error {
+	return r.db.Create(permit).Error
+}
+
+// GetPermitByID 透過 ID 取得定期停車證與其登記的車牌
+func (r *permitRepository) GetPermitByID(id uint) (*models.Permit, error) {
+	var permit models.Permit
+	result := r.db.Preload("Plates").First(&permit, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &permit, nil
+}
+
+// GetAllPermits 取得所有定期停車證 (分頁)
+func (r *permitRepository) GetAllPermits(limit int, offset int) ([]models.Permit, error) {
+	var permits []models.Permit
+	result := r.db.Preload("Plates").Order("permit_id").Limit(limit).Offset(offset).Find(&permits)
+	return permits, result.Error
+}
+
+// UpdatePermit 更新定期停車證，並以 permit.Plates 取代原本登記的車牌
+func (r *permitRepository) UpdatePermit(permit *models.Permit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("permit_id = ?", permit.PermitID).Delete(&models.PermitPlate{}).Error; err != nil {
+			return err
+		}
+		for i := range permit.Plates {
+			permit.Plates[i].PermitPlateID = 0
+			permit.Plates[i].PermitID = permit.PermitID
+		}
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(permit).Error
+	})
+}
+
+// DeletePermit 刪除定期停車證與其登記的車牌
+func (r *permitRepository) DeletePermit(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("permit_id = ?", id).Delete(&models.PermitPlate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Permit{}, id).Error
+	})
+}
+
+// FindActivePermitByLicensePlate 取得車牌在指定時間有效且適用於該停車場的定期停車證，有多張時回傳最晚到期者
+func (r *permitRepository) FindActivePermitByLicensePlate(licensePlate string, parkingLotID uint, at time.Time) (*models.Permit, error) {
+	var permit models.Permit
+	result := r.db.Preload("Plates").
+		Joins("JOIN permit_plates ON permit_plates.permit_id = permits.permit_id").
+		Where("permit_plates.license_plate = ?", licenseplate.Normalize(licensePlate)).
+		Where("permits.valid_from <= ? AND permits.valid_until > ?", at, at).
+		Where("permits.parking_lot_id IS NULL OR permits.parking_lot_id = ?", parkingLotID).
+		Order("permits.valid_until DESC").
+		First(&permit)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &permit, nil
+}
+
+// GetPermitsExpiringBetween 取得到期時間在指定範圍內的定期停車證，依到期時間排序
+func (r *permitRepository) GetPermitsExpiringBetween(start, end time.Time) ([]models.Permit, error) {
+	var permits []models.Permit
+	result := r.db.Preload("Plates").
+		Where("valid_until >= ? AND valid_until < ?", start, end).
+		Order("valid_until").
+		Find(&permits)
+	return permits, result.Error
+}
@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlateReviewFilter 查詢車牌審核項目的篩選條件，零值的欄位不篩選
+type PlateReviewFilter struct {
+	ParkingLotID *uint
+	Status       string
+	ReviewedBy   string
+}
+
+// PlateReviewerStats 審核人員在一段期間內完成的審核數量與平均等候時間
+// AverageWaitSeconds 為項目排入審核到審核完成的平均秒數
+type PlateReviewerStats struct {
+	ReviewedBy         string
+	ApprovedCount      int64
+	CorrectedCount     int64
+	AverageWaitSeconds float64
+}
+
+// PlateReviewRepository 定義車牌審核資料庫操作的介面
+type PlateReviewRepository interface {
+	CreatePlateReview(tx *gorm.DB, review *models.PlateReview) error
+	GetPlateReviewByID(id uint) (*models.PlateReview, error)
+	GetPlateReviewByIDForUpdate(tx *gorm.DB, id uint) (*models.PlateReview, error)
+	GetPlateReviews(filter PlateReviewFilter, limit int, offset int) ([]models.PlateReview, error)
+	UpdatePlateReview(tx *gorm.DB, review *models.PlateReview) error
+	CountPlateReviewsCreated(parkingLotID *uint, startTime, endTime *time.Time) (int64, error)
+	CountPendingPlateReviews(parkingLotID *uint) (int64, *time.Time, error)
+	GetReviewerStats(parkingLotID *uint, startTime, endTime *time.Time) ([]PlateReviewerStats, error)
+}
+
+// plateReviewRepository 是 PlateReviewRepository 的 GORM 實作
+type plateReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewPlateReviewRepository 建立一個新的 PlateReviewRepository 實例
+func NewPlateReviewRepository() PlateReviewRepository {
+	return &plateReviewRepository{db: database.GetDB()}
+}
+
+// CreatePlateReview 新增車牌審核項目及其他可能車牌
+func (r *plateReviewRepository) CreatePlateReview(tx *gorm.DB, review *models.PlateReview) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Create(review).Error
+}
+
+// GetPlateReviewByID 透過 ID 取得車牌審核項目及其他可能車牌與停車記錄
+func (r *plateReviewRepository) GetPlateReviewByID(id uint) (*models.PlateReview, error) {
+	var review models.PlateReview
+	result := r.db.Preload("Alternatives").Preload("ParkingRecord").First(&review, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &review, nil
+}
+
+// GetPlateReviewByIDForUpdate 在資料庫交易中透過 ID 取得車牌審核項目並鎖定該列
+func (r *plateReviewRepository) GetPlateReviewByIDForUpdate(tx *gorm.DB, id uint) (*models.PlateReview, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var review models.PlateReview
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &review, nil
+}
+
+// GetPlateReviews 依篩選條件取得車牌審核項目 (分頁)，依排入審核的時間由舊到新排序
+func (r *plateReviewRepository) GetPlateReviews(filter PlateReviewFilter, limit int, offset int) ([]models.PlateReview, error) {
+	query := r.db.Model(&models.PlateReview{}).Preload("Alternatives").Preload("ParkingRecord")
+	if filter.ParkingLotID != nil {
+		query = query.Where("parking_lot_id = ?", *filter.ParkingLotID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ReviewedBy != "" {
+		query = query.Where("reviewed_by = ?", filter.ReviewedBy)
+	}
+	var reviews []models.PlateReview
+	result := query.Order("created_at, plate_review_id").Limit(limit).Offset(offset).Find(&reviews)
+	return reviews, result.Error
+}
+
+// UpdatePlateReview 更新車牌審核項目，不會更新其他可能車牌與停車記錄
+func (r *plateReviewRepository) UpdatePlateReview(tx *gorm.DB, review *models.PlateReview) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Omit("Alternatives", "ParkingRecord").Save(review).Error
+}
+
+// CountPlateReviewsCreated 計算指定時間範圍內排入審核的項目數量
+func (r *plateReviewRepository) CountPlateReviewsCreated(parkingLotID *uint, startTime, endTime *time.Time) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.PlateReview{})
+	if parkingLotID != nil {
+		query = query.Where("parking_lot_id = ?", *parkingLotID)
+	}
+	if startTime != nil {
+		query = query.Where("created_at >= ?", *startTime)
+	}
+	if endTime != nil {
+		query = query.Where("created_at <= ?", *endTime)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// CountPendingPlateReviews 計算目前待審核的項目數量，並回傳最早排入審核的時間，沒有待審核項目時為 nil
+func (r *plateReviewRepository) CountPendingPlateReviews(parkingLotID *uint) (int64, *time.Time, error) {
+	var result struct {
+		Count    int64
+		OldestAt *time.Time
+	}
+	query := r.db.Model(&models.PlateReview{}).
+		Select("COUNT(*) AS count, MIN(created_at) AS oldest_at").
+		Where("status = ?", models.PlateReviewStatusPending)
+	if parkingLotID != nil {
+		query = query.Where("parking_lot_id = ?", *parkingLotID)
+	}
+	if err := query.Scan(&result).Error; err != nil {
+		return 0, nil, err
+	}
+	return result.Count, result.OldestAt, nil
+}
+
+// GetReviewerStats 依審核人員統計指定時間範圍內完成的審核數量與平均等候時間，依完成數量由多到少排序
+func (r *plateReviewRepository) GetReviewerStats(parkingLotID *uint, startTime, endTime *time.Time) ([]PlateReviewerStats, error) {
+	query := r.db.Model(&models.PlateReview{}).
+		Select("reviewed_by, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS approved_count, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS corrected_count, "+
+			"COALESCE(AVG(EXTRACT(EPOCH FROM reviewed_at - created_at)), 0) AS average_wait_seconds",
+			models.PlateReviewStatusApproved, models.PlateReviewStatusCorrected).
+		Where("reviewed_at IS NOT NULL")
+	if parkingLotID != nil {
+		query = query.Where("parking_lot_id = ?", *parkingLotID)
+	}
+	if startTime != nil {
+		query = query.Where("reviewed_at >= ?", *startTime)
+	}
+	if endTime != nil {
+		query = query.Where("reviewed_at <= ?", *endTime)
+	}
+	var stats []PlateReviewerStats
+	err := query.Group("reviewed_by").Order("COUNT(*) DESC, reviewed_by").Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/licenseplate"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReservationFilter 查詢預約的篩選條件，零值的欄位不篩選
+type ReservationFilter struct {
+	ParkingLotID *uint
+	LicensePlate string
+	Status       string
+}
+
+// ReservationRepository 定義預約資料庫操作的介面
+type ReservationRepository interface {
+	CreateReservation(tx *gorm.DB, reservation *models.Reservation) error
+	GetReservationByID(id uint) (*models.Reservation, error)
+	GetReservationByIDForUpdate(tx *gorm.DB, id uint) (*models.Reservation, error)
+	GetReservations(filter ReservationFilter, limit int, offset int) ([]models.Reservation, error)
+	UpdateReservation(tx *gorm.DB, reservation *models.Reservation) error
+	CountConfirmedReservations(tx *gorm.DB, parkingLotID uint, vehicleClass string, startsBefore, endsAfter time.Time, excludeLicensePlate string) (int64, error)
+	CountConfirmedReservationsByParkingLot(startsBefore, endsAfter time.Time) (map[uint]map[string]int64, error)
+	FindArrivingReservationForUpdate(tx *gorm.DB, parkingLotID uint, licensePlate string, vehicleClass string, startsFrom, startsUntil time.Time) (*models.Reservation, error)
+	ExpireReservations(startedBefore time.Time) (int64, error)
+	SumForfeitedDepositsByVehicleClass(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]models.Money, error)
+}
+
+// reservationRepository 是 ReservationRepository 的 GORM 實作
+type reservationRepository struct {
+	db *gorm.DB
+}
+
+// NewReservationRepository 建立一個新的 ReservationRepository 實例
+func NewReservationRepository() ReservationRepository {
+	return &reservationRepository{db: database.GetDB()}
+}
+
+// CreateReservation 新增預約
+func (r *reservationRepository) CreateReservation(tx *gorm.DB, reservation *models.Reservation) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Create(reservation).Error
+}
+
+// GetReservationByID 透過 ID 取得預約
+func (r *reservationRepository) GetReservationByID(id uint) (*models.Reservation, error) {
+	var reservation models.Reservation
+	result := r.db.First(&reservation, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &reservation, nil
+}
+
+// GetReservationByIDForUpdate 在資料庫交易中透過 ID 取得預約並鎖定該列
+func (r *reservationRepository) GetReservationByIDForUpdate(tx *gorm.DB, id uint) (*models.Reservation, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var reservation models.Reservation
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &reservation, nil
+}
+
+// GetReservations 依篩選條件取得預約 (分頁)，依預約開始時間排序
+func (r *reservationRepository) GetReservations(filter ReservationFilter, limit int, offset int) ([]models.Reservation, error) {
+	query := r.db.Model(&models.Reservation{})
+	if filter.ParkingLotID != nil {
+		query = query.Where("parking_lot_id = ?", *filter.ParkingLotID)
+	}
+	if filter.LicensePlate != "" {
+		query = query.Where("license_plate = ?", licenseplate.Normalize(filter.LicensePlate))
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var reservations []models.Reservation
+	result := query.Order("start_time, reservation_id").Limit(limit).Offset(offset).Find(&reservations)
+	return reservations, result.Error
+}
+
+// UpdateReservation 更新預約
+func (r *reservationRepository) UpdateReservation(tx *gorm.DB, reservation *models.Reservation) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Save(reservation).Error
+}
+
+// CountConfirmedReservations 計算停車場該車種在 startsBefore 之前開始、endsAfter 之後結束的 Confirmed 預約數量
+// excludeLicensePlate 不為空字串時不計入該車牌的預約
+func (r *reservationRepository) CountConfirmedReservations(tx *gorm.DB, parkingLotID uint, vehicleClass string, startsBefore, endsAfter time.Time, excludeLicensePlate string) (int64, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	query := dbToUse.Model(&models.Reservation{}).
+		Where("parking_lot_id = ? AND vehicle_class = ? AND status = ?", parkingLotID, vehicleClass, models.ReservationStatusConfirmed).
+		Where("start_time <= ? AND end_time > ?", startsBefore, endsAfter)
+	if excludeLicensePlate != "" {
+		query = query.Where("license_plate <> ?", licenseplate.Normalize(excludeLicensePlate))
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// CountConfirmedReservationsByParkingLot 依停車場與車種計算在 startsBefore 之前開始、endsAfter 之後結束的 Confirmed 預約數量
+func (r *reservationRepository) CountConfirmedReservationsByParkingLot(startsBefore, endsAfter time.Time) (map[uint]map[string]int64, error) {
+	rows, err := r.db.Model(&models.Reservation{}).
+		Select("parking_lot_id, vehicle_class, COUNT(*)").
+		Where("status = ?", models.ReservationStatusConfirmed).
+		Where("start_time <= ? AND end_time > ?", startsBefore, endsAfter).
+		Group("parking_lot_id, vehicle_class").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[uint]map[string]int64{}
+	for rows.Next() {
+		var parkingLotID uint
+		var class string
+		var count int64
+		if err := rows.Scan(&parkingLotID, &class, &count); err != nil {
+			return nil, err
+		}
+		if counts[parkingLotID] == nil {
+			counts[parkingLotID] = map[string]int64{}
+		}
+		counts[parkingLotID][class] = count
+	}
+	return counts, rows.Err()
+}
+
+// FindArrivingReservationForUpdate 在資料庫交易中取得車牌在停車場開始時間介於 startsFrom 與 startsUntil 之間的 Confirmed 預約並鎖定該列
+// 有多筆時取開始時間最早的一筆，找不到時回傳 nil
+func (r *reservationRepository) FindArrivingReservationForUpdate(tx *gorm.DB, parkingLotID uint, licensePlate string, vehicleClass string, startsFrom, startsUntil time.Time) (*models.Reservation, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var reservation models.Reservation
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("parking_lot_id = ? AND license_plate = ? AND vehicle_class = ? AND status = ?", parkingLotID, licenseplate.Normalize(licensePlate), vehicleClass, models.ReservationStatusConfirmed).
+		Where("start_time >= ? AND start_time <= ?", startsFrom, startsUntil).
+		Order("start_time").
+		First(&reservation)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &reservation, nil
+}
+
+// ExpireReservations 將在 startedBefore 之前開始但仍未進場的 Confirmed 預約標記為 Expired，已付的訂金標記為沒收
+func (r *reservationRepository) ExpireReservations(startedBefore time.Time) (int64, error) {
+	result := r.db.Model(&models.Reservation{}).
+		Where("status = ? AND start_time < ?", models.ReservationStatusConfirmed, startedBefore).
+		Updates(map[string]interface{}{
+			"status":         models.ReservationStatusExpired,
+			"deposit_status": gorm.Expr("CASE WHEN deposit_status = ? THEN ? ELSE deposit_status END", models.DepositStatusPaid, models.DepositStatusForfeited),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// SumForfeitedDepositsByVehicleClass 依車種計算在指定時間範圍內開始且逾時未到而沒收的訂金總額
+func (r *reservationRepository) SumForfeitedDepositsByVehicleClass(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]models.Money, error) {
+	dbQuery := r.db.Model(&models.Reservation{}).Where("deposit_status = ?", models.DepositStatusForfeited)
+	if parkingLotID != nil {
+		dbQuery = dbQuery.Where("parking_lot_id = ?", *parkingLotID)
+	}
+	if vehicleClass != nil {
+		dbQuery = dbQuery.Where("vehicle_class = ?", *vehicleClass)
+	}
+	if startTime != nil {
+		dbQuery = dbQuery.Where("start_time >= ?", *startTime)
+	}
+	if endTime != nil {
+		dbQuery = dbQuery.Where("start_time <= ?", *endTime)
+	}
+
+	rows, err := dbQuery.Select("vehicle_class, COALESCE(SUM(deposit_amount), 0)").
+		Group("vehicle_class").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sums := map[string]models.Money{}
+	for rows.Next() {
+		var class string
+		var sum models.Money
+		if err := rows.Scan(&class, &sum); err != nil {
+			return nil, err
+		}
+		sums[class] = sum
+	}
+	return sums, rows.Err()
+}
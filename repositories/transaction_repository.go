@@ -3,8 +3,10 @@ package repositories
 import (
 	"hello-professor_backend/database"
 	"hello-professor_backend/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionRepository 定義交易資料庫操作的介面
@@ -15,6 +17,13 @@ type TransactionRepository interface {
 	UpdateTransaction(transaction *models.Transaction) error
 	DeleteTransaction(id uint) error
 	GetAllTransactions(limit int, offset int) ([]models.Transaction, error)
+	GetTransactionByIDForUpdate(tx *gorm.DB, id uint) (*models.Transaction, error)
+	SumRefundedAmount(tx *gorm.DB, originalTransactionID uint) (models.Money, error)
+	SumPendingRefundAmount(tx *gorm.DB, originalTransactionID uint) (models.Money, error)
+	GetPendingRefunds(createdBefore time.Time) ([]models.Transaction, error)
+	SumNetPaidAmountByParkingRecordID(tx *gorm.DB, parkingRecordID uint) (models.Money, error)
+	AssignReservationTransactions(tx *gorm.DB, reservationID uint, parkingRecordID uint) error
+	SumLineItemAmountsByParkingRecordID(tx *gorm.DB, parkingRecordID uint) ([]models.TransactionLineItem, error)
 }
 
 // transactionRepository 是 TransactionRepository 的 GORM 實作
@@ -40,7 +49,7 @@ func (r *transactionRepository) CreateTransaction(tx *gorm.DB, transaction *mode
 // GetTransactionByID 透過 ID 取得交易記錄
 func (r *transactionRepository) GetTransactionByID(id uint) (*models.Transaction, error) {
 	var transaction models.Transaction
-	result := r.db.First(&transaction, id)
+	result := r.db.Preload("LineItems").First(&transaction, id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil // 或者回傳一個特定的 not found 錯誤
@@ -53,7 +62,7 @@ func (r *transactionRepository) GetTransactionByID(id uint) (*models.Transaction
 // GetTransactionsByParkingRecordID 透過 ParkingRecordID 取得相關的所有交易記錄
 func (r *transactionRepository) GetTransactionsByParkingRecordID(parkingRecordID uint) ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	result := r.db.Where("parking_record_id = ?", parkingRecordID).Find(&transactions)
+	result := r.db.Preload("LineItems").Where("parking_record_id = ?", parkingRecordID).Find(&transactions)
 	return transactions, result.Error
 }
 
@@ -79,6 +88,112 @@ func (r *transactionRepository) GetAllTransactions(limit int, offset int) ([]mod
 	if offset > 0 {
 		dbQuery = dbQuery.Offset(offset)
 	}
-	result := dbQuery.Find(&transactions)
+	result := dbQuery.Preload("LineItems").Find(&transactions)
 	return transactions, result.Error
 }
+
+// GetTransactionByIDForUpdate 在資料庫交易中透過 ID 取得交易記錄並鎖定該列
+func (r *transactionRepository) GetTransactionByIDForUpdate(tx *gorm.DB, id uint) (*models.Transaction, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var transaction models.Transaction
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &transaction, nil
+}
+
+// SumRefundedAmount 計算某筆原始交易已退款的總金額 (以正數表示)
+func (r *transactionRepository) SumRefundedAmount(tx *gorm.DB, originalTransactionID uint) (models.Money, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var refunded models.Money
+	err := dbToUse.Model(&models.Transaction{}).
+		Where("original_transaction_id = ? AND transaction_type = ? AND status = ?", originalTransactionID, "Refund", "Success").
+		Select("COALESCE(-SUM(amount), 0)").Row().Scan(&refunded)
+	return refunded, err
+}
+
+// SumPendingRefundAmount 計算某筆原始交易仍在等待支付供應商處理的退款總金額 (以正數表示)
+func (r *transactionRepository) SumPendingRefundAmount(tx *gorm.DB, originalTransactionID uint) (models.Money, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var pending models.Money
+	err := dbToUse.Model(&models.Transaction{}).
+		Where("original_transaction_id = ? AND transaction_type = ? AND status = ?", originalTransactionID, "Refund", "Pending").
+		Select("COALESCE(-SUM(amount), 0)").Row().Scan(&pending)
+	return pending, err
+}
+
+// GetPendingRefunds 取得在 createdBefore 之前建立、仍在等待支付供應商處理的退款交易
+func (r *transactionRepository) GetPendingRefunds(createdBefore time.Time) ([]models.Transaction, error) {
+	var refunds []models.Transaction
+	result := r.db.Where("transaction_type = ? AND status = ? AND transaction_time < ?", "Refund", "Pending", createdBefore).
+		Order("transaction_id").
+		Find(&refunds)
+	return refunds, result.Error
+}
+
+// SumNetPaidAmountByParkingRecordID 計算停車記錄扣除退款後的實收金額
+// 已全額退款的原始交易 (Refunded) 與其負數的退款交易會互相抵銷
+func (r *transactionRepository) SumNetPaidAmountByParkingRecordID(tx *gorm.DB, parkingRecordID uint) (models.Money, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var netPaid models.Money
+	err := dbToUse.Model(&models.Transaction{}).
+		Where("parking_record_id = ? AND status IN ?", parkingRecordID, []string{"Success", "Refunded"}).
+		Select("COALESCE(SUM(amount), 0)").Row().Scan(&netPaid)
+	return netPaid, err
+}
+
+// AssignReservationTransactions 將預約的訂金及其退款交易連結到預約車輛進場後的停車記錄
+func (r *transactionRepository) AssignReservationTransactions(tx *gorm.DB, reservationID uint, parkingRecordID uint) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Model(&models.Transaction{}).
+		Where("reservation_id = ?", reservationID).
+		Update("parking_record_id", parkingRecordID).Error
+}
+
+// SumLineItemAmountsByParkingRecordID 加總停車記錄已付款交易的明細金額，依明細類型與充電分組
+// 回傳的每一筆只有 ItemType、ChargingSessionID 與 Amount 有值
+func (r *transactionRepository) SumLineItemAmountsByParkingRecordID(tx *gorm.DB, parkingRecordID uint) ([]models.TransactionLineItem, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	rows, err := dbToUse.Model(&models.TransactionLineItem{}).
+		Select("transaction_line_items.item_type, transaction_line_items.charging_session_id, COALESCE(SUM(transaction_line_items.amount), 0)").
+		Joins("JOIN transactions ON transactions.transaction_id = transaction_line_items.transaction_id").
+		Where("transactions.parking_record_id = ? AND transactions.status IN ?", parkingRecordID, []string{"Success", "Refunded"}).
+		Group("transaction_line_items.item_type, transaction_line_items.charging_session_id").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sums []models.TransactionLineItem
+	for rows.Next() {
+		var sum models.TransactionLineItem
+		if err := rows.Scan(&sum.ItemType, &sum.ChargingSessionID, &sum.Amount); err != nil {
+			return nil, err
+		}
+		sums = append(sums, sum)
+	}
+	return sums, rows.Err()
+}
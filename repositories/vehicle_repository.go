@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"hello-professor_backend/database"
+	"hello-professor_backend/licenseplate"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VehicleVisitStats 車輛所有停車記錄的統計
+// AverageDwellMinutes 只計算已出場的停車記錄；UnpaidVisitCount 為已出場但未付款的停車記錄數
+type VehicleVisitStats struct {
+	VisitCount            int64
+	AverageDwellMinutes   float64
+	UnpaidVisitCount      int64
+	ActiveParkingRecordID *uint
+}
+
+// VehicleRepository 定義車輛資料庫操作的介面
+type VehicleRepository interface {
+	UpsertVehicle(tx *gorm.DB, licensePlate string, seenAt time.Time) (*models.Vehicle, error)
+	GetVehicleByID(id uint) (*models.Vehicle, error)
+	GetVehicleByLicensePlate(licensePlate string) (*models.Vehicle, error)
+	GetVehicles(licensePlateQuery string, limit int, offset int) ([]models.Vehicle, error)
+	UpdateVehicle(vehicle *models.Vehicle) error
+	GetVehicleVisitStats(vehicleID uint) (*VehicleVisitStats, error)
+	SumVehicleSpend(vehicleID uint) (models.Money, error)
+	GetVehicleVisits(vehicleID uint, limit int, offset int) ([]models.ParkingRecord, error)
+}
+
+// vehicleRepository 是 VehicleRepository 的 GORM 實作
+type vehicleRepository struct {
+	db *gorm.DB
+}
+
+// NewVehicleRepository 建立一個新的 VehicleRepository 實例
+func NewVehicleRepository() VehicleRepository {
+	return &vehicleRepository{db: database.GetDB()}
+}
+
+// UpsertVehicle 以單一 INSERT ... ON CONFLICT 取得車牌的車輛資料，不存在時建立，並更新第一次與最近一次進場的時間
+// 以車牌的唯一索引處理，同一車牌同時進場也只會有一筆車輛資料
+func (r *vehicleRepository) UpsertVehicle(tx *gorm.DB, licensePlate string, seenAt time.Time) (*models.Vehicle, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	vehicle := &models.Vehicle{
+		LicensePlate: licensePlate,
+		FirstSeenAt:  seenAt,
+		LastSeenAt:   seenAt,
+	}
+	result := dbToUse.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "license_plate"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"first_seen_at": gorm.Expr("LEAST(vehicles.first_seen_at, ?)", seenAt),
+			"last_seen_at":  gorm.Expr("GREATEST(vehicles.last_seen_at, ?)", seenAt),
+			"updated_at":    time.Now(),
+		}),
+	}).Create(vehicle)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return vehicle, nil
+}
+
+// GetVehicleByID 透過 ID 取得車輛資料
+func (r *vehicleRepository) GetVehicleByID(id uint) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	result := r.db.First(&vehicle, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &vehicle, nil
+}
+
+// GetVehicleByLicensePlate 透過車牌取得車輛資料，車牌會轉為標準格式
+func (r *vehicleRepository) GetVehicleByLicensePlate(licensePlate string) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	result := r.db.Where("license_plate = ?", licenseplate.Normalize(licensePlate)).First(&vehicle)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &vehicle, nil
+}
+
+// GetVehicles 取得車輛資料 (分頁)，依最近一次進場的時間由新到舊排序
+// licensePlateQuery 不為空字串時只列出車牌包含該片段者，不區分格式且 O/0、I/1、B/8 視為相同
+func (r *vehicleRepository) GetVehicles(licensePlateQuery string, limit int, offset int) ([]models.Vehicle, error) {
+	query := r.db.Model(&models.Vehicle{})
+	if licensePlateQuery != "" {
+		query = query.Where(licenseplate.SearchKeySQL("license_plate")+" LIKE ?", "%"+licenseplate.SearchKey(licensePlateQuery)+"%")
+	}
+	var vehicles []models.Vehicle
+	result := query.Order("last_seen_at DESC, vehicle_id DESC").Limit(limit).Offset(offset).Find(&vehicles)
+	return vehicles, result.Error
+}
+
+// UpdateVehicle 更新車輛資料
+func (r *vehicleRepository) UpdateVehicle(vehicle *models.Vehicle) error {
+	return r.db.Save(vehicle).Error
+}
+
+// GetVehicleVisitStats 統計車輛的停車記錄數、平均停留時間、未付款的停車記錄數，以及目前尚未出場的停車記錄
+func (r *vehicleRepository) GetVehicleVisitStats(vehicleID uint) (*VehicleVisitStats, error) {
+	var stats VehicleVisitStats
+	result := r.db.Model(&models.ParkingRecord{}).
+		Select("COUNT(*) AS visit_count, "+
+			"COALESCE(AVG(actual_duration_minutes) FILTER (WHERE exit_time IS NOT NULL), 0) AS average_dwell_minutes, "+
+			"COUNT(*) FILTER (WHERE exit_time IS NOT NULL AND payment_status = ?) AS unpaid_visit_count, "+
+			"MAX(record_id) FILTER (WHERE exit_time IS NULL) AS active_parking_record_id", "Pending").
+		Where("vehicle_id = ?", vehicleID).
+		Scan(&stats)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &stats, nil
+}
+
+// SumVehicleSpend 計算車輛所有停車記錄的實收金額 (付款扣除退款)
+func (r *vehicleRepository) SumVehicleSpend(vehicleID uint) (models.Money, error) {
+	var spend models.Money
+	row := r.db.Model(&models.Transaction{}).
+		Joins("JOIN parking_records ON parking_records.record_id = transactions.parking_record_id").
+		Where("parking_records.vehicle_id = ? AND transactions.status IN ?", vehicleID, []string{"Success", "Refunded"}).
+		Select("COALESCE(SUM(transactions.amount), 0)").
+		Row()
+	err := row.Scan(&spend)
+	return spend, err
+}
+
+// GetVehicleVisits 取得車輛的停車記錄 (分頁)，依進場時間由新到舊排序
+func (r *vehicleRepository) GetVehicleVisits(vehicleID uint, limit int, offset int) ([]models.ParkingRecord, error) {
+	var records []models.ParkingRecord
+	result := r.db.Preload("Transaction").
+		Where("vehicle_id = ?", vehicleID).
+		Order("entry_time DESC").
+		Limit(limit).Offset(offset).
+		Find(&records)
+	return records, result.Error
+}
@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"database/sql"
+	"hello-professor_backend/database"
+	"hello-professor_backend/licenseplate"
+	"hello-professor_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WatchlistEntryFilter 查詢監控名單的篩選條件，零值的欄位不篩選
+type WatchlistEntryFilter struct {
+	Category     string
+	Action       string
+	LicensePlate string
+	ActiveOnly   bool
+}
+
+// WatchlistAlertFilter 查詢監控名單警示的篩選條件，零值的欄位不篩選
+type WatchlistAlertFilter struct {
+	ParkingLotID *uint
+	Category     string
+	Acknowledged *bool
+}
+
+// WatchlistMatch 是監控名單查詢找到的項目與其車牌相似度 (0.0 到 1.0 之間)
+type WatchlistMatch struct {
+	Entry      models.WatchlistEntry
+	Similarity float64
+}
+
+// WatchlistRepository 定義監控名單與警示資料庫操作的介面
+type WatchlistRepository interface {
+	CreateWatchlistEntry(entry *models.WatchlistEntry) error
+	GetWatchlistEntryByID(id uint) (*models.WatchlistEntry, error)
+	GetWatchlistEntries(filter WatchlistEntryFilter, limit int, offset int) ([]models.WatchlistEntry, error)
+	UpdateWatchlistEntry(entry *models.WatchlistEntry) error
+	DeleteWatchlistEntry(id uint) error
+	FindWatchlistMatches(licensePlate string, at time.Time, minSimilarity float64) ([]WatchlistMatch, error)
+
+	CreateWatchlistAlert(tx *gorm.DB, alert *models.WatchlistAlert) error
+	GetWatchlistAlertByID(id uint) (*models.WatchlistAlert, error)
+	GetWatchlistAlertByIDForUpdate(tx *gorm.DB, id uint) (*models.WatchlistAlert, error)
+	GetWatchlistAlerts(filter WatchlistAlertFilter, limit int, offset int) ([]models.WatchlistAlert, error)
+	UpdateWatchlistAlert(tx *gorm.DB, alert *models.WatchlistAlert) error
+	WatchlistAlertExists(entryID uint, parkingRecordID uint, event string) (bool, error)
+}
+
+// watchlistRepository 是 WatchlistRepository 的 GORM 實作
+type watchlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWatchlistRepository 建立一個新的 WatchlistRepository 實例
+func NewWatchlistRepository() WatchlistRepository {
+	return &watchlistRepository{db: database.GetDB()}
+}
+
+// CreateWatchlistEntry 新增監控名單項目
+func (r *watchlistRepository) CreateWatchlistEntry(entry *models.WatchlistEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// GetWatchlistEntryByID 透過 ID 取得監控名單項目
+func (r *watchlistRepository) GetWatchlistEntryByID(id uint) (*models.WatchlistEntry, error) {
+	var entry models.WatchlistEntry
+	result := r.db.First(&entry, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &entry, nil
+}
+
+// GetWatchlistEntries 依篩選條件取得監控名單項目 (分頁)，ActiveOnly 為 true 時排除停用與已失效的項目
+func (r *watchlistRepository) GetWatchlistEntries(filter WatchlistEntryFilter, limit int, offset int) ([]models.WatchlistEntry, error) {
+	query := r.db.Model(&models.WatchlistEntry{})
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.LicensePlate != "" {
+		query = query.Where("license_plate = ?", filter.LicensePlate)
+	}
+	if filter.ActiveOnly {
+		query = query.Where("active AND (expires_at IS NULL OR expires_at > ?)", time.Now())
+	}
+	var entries []models.WatchlistEntry
+	result := query.Order("watchlist_entry_id").Limit(limit).Offset(offset).Find(&entries)
+	return entries, result.Error
+}
+
+// UpdateWatchlistEntry 更新監控名單項目
+func (r *watchlistRepository) UpdateWatchlistEntry(entry *models.WatchlistEntry) error {
+	return r.db.Save(entry).Error
+}
+
+// DeleteWatchlistEntry 刪除監控名單項目，已產生的警示會保留
+func (r *watchlistRepository) DeleteWatchlistEntry(id uint) error {
+	return r.db.Delete(&models.WatchlistEntry{}, id).Error
+}
+
+// FindWatchlistMatches 以 pg_trgm 相似度尋找在指定時間有效、車牌與 licensePlate 相符或相似的監控名單項目
+// 車牌以 licenseplate.SearchKey 比對，只差在易混淆字元的車牌相似度為 1；結果依相似度由高到低排列
+func (r *watchlistRepository) FindWatchlistMatches(licensePlate string, at time.Time, minSimilarity float64) ([]WatchlistMatch, error) {
+	searchKey := licenseplate.SearchKey(licensePlate)
+	if searchKey == "" {
+		return nil, nil
+	}
+	similarity := "similarity(" + licenseplate.SearchKeySQL("license_plate") + ", @key)"
+
+	var rows []struct {
+		WatchlistEntryID uint
+		Similarity       float64
+	}
+	result := r.db.Model(&models.WatchlistEntry{}).
+		Select("watchlist_entry_id, CASE WHEN "+licenseplate.SearchKeySQL("license_plate")+" = @key THEN 1 ELSE "+similarity+" END AS similarity", sql.Named("key", searchKey)).
+		Where("active AND (expires_at IS NULL OR expires_at > @at)", sql.Named("at", at)).
+		Where("("+licenseplate.SearchKeySQL("license_plate")+" = @key OR "+similarity+" >= @min)", sql.Named("key", searchKey), sql.Named("min", minSimilarity)).
+		Order("similarity DESC, watchlist_entry_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	matches := make([]WatchlistMatch, 0, len(rows))
+	for _, row := range rows {
+		var entry models.WatchlistEntry
+		if err := r.db.First(&entry, row.WatchlistEntryID).Error; err != nil {
+			return nil, err
+		}
+		matches = append(matches, WatchlistMatch{Entry: entry, Similarity: row.Similarity})
+	}
+	return matches, nil
+}
+
+// CreateWatchlistAlert 新增監控名單警示
+func (r *watchlistRepository) CreateWatchlistAlert(tx *gorm.DB, alert *models.WatchlistAlert) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Create(alert).Error
+}
+
+// GetWatchlistAlertByID 透過 ID 取得監控名單警示
+func (r *watchlistRepository) GetWatchlistAlertByID(id uint) (*models.WatchlistAlert, error) {
+	var alert models.WatchlistAlert
+	result := r.db.First(&alert, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &alert, nil
+}
+
+// GetWatchlistAlertByIDForUpdate 在資料庫交易中透過 ID 取得監控名單警示並鎖定該列
+func (r *watchlistRepository) GetWatchlistAlertByIDForUpdate(tx *gorm.DB, id uint) (*models.WatchlistAlert, error) {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	var alert models.WatchlistAlert
+	result := dbToUse.Clauses(clause.Locking{Strength: "UPDATE"}).First(&alert, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &alert, nil
+}
+
+// GetWatchlistAlerts 依篩選條件取得監控名單警示 (分頁)，依發生時間由新到舊排序
+func (r *watchlistRepository) GetWatchlistAlerts(filter WatchlistAlertFilter, limit int, offset int) ([]models.WatchlistAlert, error) {
+	query := r.db.Model(&models.WatchlistAlert{})
+	if filter.ParkingLotID != nil {
+		query = query.Where("parking_lot_id = ?", *filter.ParkingLotID)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Acknowledged != nil {
+		if *filter.Acknowledged {
+			query = query.Where("acknowledged_at IS NOT NULL")
+		} else {
+			query = query.Where("acknowledged_at IS NULL")
+		}
+	}
+	var alerts []models.WatchlistAlert
+	result := query.Order("created_at DESC, watchlist_alert_id DESC").Limit(limit).Offset(offset).Find(&alerts)
+	return alerts, result.Error
+}
+
+// UpdateWatchlistAlert 更新監控名單警示
+func (r *watchlistRepository) UpdateWatchlistAlert(tx *gorm.DB, alert *models.WatchlistAlert) error {
+	dbToUse := r.db
+	if tx != nil {
+		dbToUse = tx
+	}
+	return dbToUse.Save(alert).Error
+}
+
+// WatchlistAlertExists 檢查停車記錄是否已因同一監控名單項目在同一時機產生過警示
+func (r *watchlistRepository) WatchlistAlertExists(entryID uint, parkingRecordID uint, event string) (bool, error) {
+	var count int64
+	result := r.db.Model(&models.WatchlistAlert{}).
+		Where("watchlist_entry_id = ? AND parking_record_id = ? AND event = ?", entryID, parkingRecordID, event).
+		Count(&count)
+	return count > 0, result.Error
+}
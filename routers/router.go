@@ -1,11 +1,15 @@
 package routers
 
 import (
+	"hello-professor_backend/configs"
 	"hello-professor_backend/controllers"
 	"hello-professor_backend/database"
 	"hello-professor_backend/docs" // 匯入 swag 產生的 docs
+	"hello-professor_backend/jobs"
+	"hello-professor_backend/middlewares"
 	"hello-professor_backend/repositories"
 	"hello-professor_backend/services"
+	"log"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -26,21 +30,79 @@ func SetupRouter() *gin.Engine {
 	router.Use(cors.New(config))
 
 	// 初始化 Repositories
-	// vehicleRepo := repositories.NewVehicleRepository() // 移除
+	vehicleRepo := repositories.NewVehicleRepository()
 	transactionRepo := repositories.NewTransactionRepository()
 	parkingRecordRepo := repositories.NewParkingRecordRepository()
+	paymentIntentRepo := repositories.NewPaymentIntentRepository()
+	idempotencyRepo := repositories.NewIdempotencyRepository()
+	merchantRepo := repositories.NewMerchantRepository()
+	permitRepo := repositories.NewPermitRepository()
+	parkingLotRepo := repositories.NewParkingLotRepository()
+	reservationRepo := repositories.NewReservationRepository()
+	chargingRepo := repositories.NewChargingSessionRepository()
+	plateReviewRepo := repositories.NewPlateReviewRepository()
+	watchlistRepo := repositories.NewWatchlistRepository()
+
+	// 初始化支付供應商，依付款方式選擇
+	// 模擬支付閘道以簽章通知付款結果，未設定通知密鑰時不啟用，也不開放通知路由，避免接受以公開的預設密鑰偽造的通知
+	paymentProviderList := []services.PaymentProvider{services.NewCashPaymentProvider()}
+	webhookSecret := configs.PaymentWebhookSecret()
+	if webhookSecret != "" {
+		paymentProviderList = append(paymentProviderList, services.NewSimulatorPaymentProvider(configs.SimulatorConfirmDelay, configs.SimulatorDeclineRate, configs.PaymentCallbackBaseURL(), webhookSecret))
+	} else {
+		log.Println("PAYMENT_WEBHOOK_SECRET 未設定，停用模擬支付閘道與付款通知路由 (開發環境可設定 PAYMENT_DEV_MODE=true)")
+	}
+	paymentProviders := services.NewPaymentProviderRegistry(configs.PaymentMethodProviders, paymentProviderList...)
 
 	// 初始化 Services
-	// vehicleService := services.NewVehicleService(vehicleRepo, parkingRecordRepo) // 移除
-	transactionService := services.NewTransactionService(transactionRepo)
-	// ParkingRecordService 不再需要 vehicleRepo
+	vehicleService := services.NewVehicleService(vehicleRepo)
+	transactionService := services.NewTransactionService(transactionRepo, parkingRecordRepo, paymentProviders, database.GetDB())
+	paymentIntentService := services.NewPaymentIntentService(paymentIntentRepo, parkingRecordRepo, reservationRepo, transactionService, paymentProviders, database.GetDB())
+	// 停車費用依各停車場選用的費率行事曆計算
+	parkingLotService := services.NewParkingLotService(parkingLotRepo, configs.RateCalendars)
+	validationService := services.NewValidationService(merchantRepo, parkingLotService, database.GetDB())
+	permitService := services.NewPermitService(permitRepo, parkingLotService, configs.ParkingLotLocation)
+	reservationService := services.NewReservationService(reservationRepo, parkingRecordRepo, parkingLotService, paymentIntentService, transactionService, database.GetDB())
+	// 電費依每度電計價，充電結束超過寬限時間後另收佔用費
+	chargingService := services.NewChargingService(chargingRepo, parkingRecordRepo, configs.EnergyPricePerKWh, configs.IdleFeePerMinute, configs.ChargingIdleGracePeriod, database.GetDB())
+	// 進場 OCR 信心分數低於門檻的車牌排入人工審核
+	plateReviewService := services.NewPlateReviewService(plateReviewRepo, vehicleService, configs.OcrReviewConfidenceThreshold, database.GetDB())
+	watchlistService := services.NewWatchlistService(watchlistRepo, database.GetDB())
 	// 修改此處以傳入 TransactionService 和 DB 實例
-	parkingRecordService := services.NewParkingRecordService(parkingRecordRepo, transactionService, database.GetDB())
+	imageStore, err := configs.ImageStore()
+	if err != nil {
+		log.Fatalf("無法建立影像儲存空間: %v", err)
+	}
+	imageService := services.NewImageService(imageStore, configs.MaxImagePixels, configs.ThumbnailMaxSize, configs.ThumbnailJPEGQuality)
+
+	parkingRecordService := services.NewParkingRecordService(parkingRecordRepo, transactionService, parkingLotService, paymentIntentService, validationService, permitService, reservationService, chargingService, plateReviewService, watchlistService, vehicleService, imageService, database.GetDB())
+
+	// 背景工作：定期將逾期未確認的付款意圖標記為 Expired
+	jobs.Every("expire-payment-intents", configs.PaymentIntentExpiryCheckInterval, paymentIntentService.ExpireStalePaymentIntents)
+	// 背景工作：定期重送支付供應商未確認結果的退款
+	jobs.Every("retry-pending-refunds", configs.RefundRetryInterval, transactionService.RetryPendingRefunds)
+	// 背景工作：定期將逾時未到的預約標記為 Expired，釋出保留的車位
+	jobs.Every("expire-no-show-reservations", configs.ReservationExpiryCheckInterval, reservationService.ExpireNoShowReservations)
+	// 背景工作：定期標記進場過久仍未出場 (疑似未觸發出口感應器) 的停車記錄
+	jobs.Every("flag-stale-parking-records", configs.StaleSessionCheckInterval, parkingRecordService.FlagStaleParkingRecords)
+
+	// 重送的請求依 Idempotency-Key 回傳第一次的回應
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, configs.IdempotencyKeyTTL, configs.IdempotencyLeaseDuration)
+	idempotent := middlewares.Idempotency(idempotencyService)
+	jobs.Every("delete-expired-idempotency-records", configs.IdempotencyCleanupInterval, idempotencyService.DeleteExpiredIdempotencyRecords)
 
 	// 初始化 Controllers
-	// vehicleController := controllers.NewVehicleController(vehicleService) // 移除
+	vehicleController := controllers.NewVehicleController(vehicleService)
 	transactionController := controllers.NewTransactionController(transactionService)
 	parkingRecordController := controllers.NewParkingRecordController(parkingRecordService)
+	paymentIntentController := controllers.NewPaymentIntentController(paymentIntentService)
+	merchantController := controllers.NewMerchantController(validationService)
+	permitController := controllers.NewPermitController(permitService)
+	parkingLotController := controllers.NewParkingLotController(parkingLotService)
+	reservationController := controllers.NewReservationController(reservationService)
+	chargingController := controllers.NewChargingController(chargingService)
+	plateReviewController := controllers.NewPlateReviewController(plateReviewService)
+	watchlistController := controllers.NewWatchlistController(watchlistService)
 
 	// Swagger 文件的基本路徑，對應 main.go 中的 @BasePath
 	docs.SwaggerInfo.BasePath = "/api/v1"
@@ -48,43 +110,143 @@ func SetupRouter() *gin.Engine {
 	// API v1 路由群組
 	apiV1 := router.Group("/api/v1")
 	{
-		// 車輛路由 (移除)
-		// vehicleRoutes := apiV1.Group("/vehicles")
-		// {
-		// 	vehicleRoutes.POST("", vehicleController.CreateVehicleHandler)
-		// 	vehicleRoutes.GET("/search", vehicleController.SearchVehiclesHandler)
-		// 	vehicleRoutes.GET("/:id", vehicleController.GetVehicleByIDHandler)
-		// 	vehicleRoutes.GET("/license/:plate", vehicleController.GetVehicleByLicensePlateHandler)
-		// 	vehicleRoutes.PUT("/:id", vehicleController.UpdateVehicleHandler)
-		// 	vehicleRoutes.DELETE("/:id", vehicleController.DeleteVehicleHandler)
-		// 	vehicleRoutes.GET("", vehicleController.GetAllVehiclesHandler)
-		// }
+		// 車輛資料路由，車輛於第一次進場時自動建立
+		vehicleRoutes := apiV1.Group("/vehicles")
+		{
+			vehicleRoutes.GET("", vehicleController.GetVehiclesHandler)
+			vehicleRoutes.GET("/:id", vehicleController.GetVehicleByIDHandler)
+			vehicleRoutes.GET("/license/:plate", vehicleController.GetVehicleByLicensePlateHandler)
+			vehicleRoutes.PUT("/:id", vehicleController.UpdateVehicleHandler)
+			vehicleRoutes.GET("/:id/visits", vehicleController.GetVehicleVisitsHandler)
+		}
 
 		// 交易路由
 		transactionRoutes := apiV1.Group("/transactions")
 		{
-			transactionRoutes.POST("", transactionController.CreateTransactionHandler)
+			transactionRoutes.POST("", idempotent, transactionController.CreateTransactionHandler)
 			transactionRoutes.GET("/:id", transactionController.GetTransactionByIDHandler)
 			transactionRoutes.GET("/parking/:parkingRecordID", transactionController.GetTransactionsByParkingRecordIDHandler)
+			transactionRoutes.POST("/:id/refund", idempotent, transactionController.RefundTransactionHandler)
 			transactionRoutes.PUT("/:id", transactionController.UpdateTransactionHandler)
 			transactionRoutes.DELETE("/:id", transactionController.DeleteTransactionHandler)
 			transactionRoutes.GET("", transactionController.GetAllTransactionsHandler)
 		}
 
+		// 付款意圖與支付供應商通知路由
+		apiV1.GET("/payment-intents/:id", paymentIntentController.GetPaymentIntentByIDHandler)
+		if webhookSecret != "" {
+			apiV1.POST("/payments/callbacks/:provider", paymentIntentController.PaymentCallbackHandler)
+		}
+
+		// 商家與折抵碼路由
+		merchantRoutes := apiV1.Group("/merchants")
+		{
+			merchantRoutes.POST("", merchantController.CreateMerchantHandler)
+			merchantRoutes.GET("", merchantController.GetAllMerchantsHandler)
+			merchantRoutes.GET("/:id", merchantController.GetMerchantByIDHandler)
+			merchantRoutes.POST("/:id/validation-codes", merchantController.CreateValidationCodeHandler)
+			merchantRoutes.GET("/:id/validation-codes", merchantController.GetValidationCodesByMerchantIDHandler)
+		}
+
+		// 停車場路由
+		parkingLotRoutes := apiV1.Group("/parking-lots")
+		{
+			parkingLotRoutes.POST("", parkingLotController.CreateParkingLotHandler)
+			parkingLotRoutes.GET("", parkingLotController.GetAllParkingLotsHandler)
+			parkingLotRoutes.GET("/:id", parkingLotController.GetParkingLotByIDHandler)
+			parkingLotRoutes.PUT("/:id", parkingLotController.UpdateParkingLotHandler)
+			parkingLotRoutes.POST("/:id/sensors", parkingLotController.AddParkingLotSensorHandler)
+			parkingLotRoutes.DELETE("/:id/sensors/:sensorId", parkingLotController.RemoveParkingLotSensorHandler)
+			parkingLotRoutes.POST("/:id/zones", parkingLotController.CreateZoneHandler)
+			parkingLotRoutes.GET("/:id/zones", parkingLotController.GetZonesHandler)
+			parkingLotRoutes.PUT("/:id/zones/:zoneId", parkingLotController.UpdateZoneHandler)
+			parkingLotRoutes.DELETE("/:id/zones/:zoneId", parkingLotController.DeleteZoneHandler)
+			parkingLotRoutes.POST("/:id/zones/:zoneId/spots", parkingLotController.AddSpotHandler)
+			parkingLotRoutes.DELETE("/:id/zones/:zoneId/spots/:spotId", parkingLotController.RemoveSpotHandler)
+		}
+
+		// 車位感應器回報車位狀態
+		apiV1.POST("/spot-sensors/:sensorId/status", parkingRecordController.RecordSpotStatusHandler)
+
+		// 定期停車證路由
+		permitRoutes := apiV1.Group("/permits")
+		{
+			permitRoutes.POST("", permitController.CreatePermitHandler)
+			permitRoutes.GET("", permitController.GetAllPermitsHandler)
+			permitRoutes.GET("/:id", permitController.GetPermitByIDHandler)
+			permitRoutes.PUT("/:id", permitController.UpdatePermitHandler)
+			permitRoutes.DELETE("/:id", permitController.DeletePermitHandler)
+		}
+
+		// 車位預約路由
+		reservationRoutes := apiV1.Group("/reservations")
+		{
+			reservationRoutes.POST("", idempotent, reservationController.CreateReservationHandler)
+			reservationRoutes.GET("", reservationController.GetReservationsHandler)
+			reservationRoutes.GET("/:id", reservationController.GetReservationByIDHandler)
+			reservationRoutes.POST("/:id/cancel", reservationController.CancelReservationHandler)
+		}
+
+		// 電動車充電路由，由充電樁回報開始、電錶讀數與結束
+		chargingRoutes := apiV1.Group("/charging-sessions")
+		{
+			chargingRoutes.POST("", idempotent, chargingController.StartChargingSessionHandler)
+			chargingRoutes.GET("/:id", chargingController.GetChargingSessionByIDHandler)
+			chargingRoutes.POST("/:id/meter-readings", chargingController.RecordMeterReadingHandler)
+			chargingRoutes.POST("/:id/stop", chargingController.StopChargingSessionHandler)
+		}
+
+		// 車牌人工審核路由
+		plateReviewRoutes := apiV1.Group("/plate-reviews")
+		{
+			plateReviewRoutes.GET("", plateReviewController.GetPlateReviewsHandler)
+			plateReviewRoutes.GET("/:id", plateReviewController.GetPlateReviewByIDHandler)
+			plateReviewRoutes.POST("/:id/approve", plateReviewController.ApprovePlateReviewHandler)
+			plateReviewRoutes.POST("/:id/correct", plateReviewController.CorrectPlateReviewHandler)
+		}
+
+		// 監控名單路由，車牌符合時於進出場產生警示
+		watchlistEntryRoutes := apiV1.Group("/watchlist-entries")
+		{
+			watchlistEntryRoutes.POST("", watchlistController.CreateWatchlistEntryHandler)
+			watchlistEntryRoutes.GET("", watchlistController.GetWatchlistEntriesHandler)
+			watchlistEntryRoutes.GET("/:id", watchlistController.GetWatchlistEntryByIDHandler)
+			watchlistEntryRoutes.PUT("/:id", watchlistController.UpdateWatchlistEntryHandler)
+			watchlistEntryRoutes.DELETE("/:id", watchlistController.DeleteWatchlistEntryHandler)
+		}
+
+		// 監控名單警示路由，操作台透過 /stream 即時接收警示
+		watchlistAlertRoutes := apiV1.Group("/watchlist-alerts")
+		{
+			watchlistAlertRoutes.GET("", watchlistController.GetWatchlistAlertsHandler)
+			watchlistAlertRoutes.GET("/stream", watchlistController.StreamWatchlistAlertsHandler)
+			watchlistAlertRoutes.GET("/:id", watchlistController.GetWatchlistAlertByIDHandler)
+			watchlistAlertRoutes.POST("/:id/acknowledge", watchlistController.AcknowledgeWatchlistAlertHandler)
+		}
+
 		// 停車記錄路由
 		parkingRecordRoutes := apiV1.Group("/parking-records")
 		{
-			parkingRecordRoutes.POST("/entry", parkingRecordController.RecordVehicleEntryHandler)
-			parkingRecordRoutes.POST("/exit", parkingRecordController.RecordVehicleExitHandler)
+			parkingRecordRoutes.POST("/entry", idempotent, parkingRecordController.RecordVehicleEntryHandler)
+			parkingRecordRoutes.POST("/exit", idempotent, parkingRecordController.RecordVehicleExitHandler)
 			parkingRecordRoutes.POST("", parkingRecordController.CreateParkingRecordHandler) // 通用建立
 			parkingRecordRoutes.GET("/search/license", parkingRecordController.SearchParkingRecordsByLicensePlateHandler)
+			parkingRecordRoutes.GET("/stale", parkingRecordController.GetStaleParkingRecordsHandler)
 			parkingRecordRoutes.GET("/:id", parkingRecordController.GetParkingRecordByIDHandler)
 			// 修改路由以使用 licensePlate 而非 vehicleID
 			parkingRecordRoutes.GET("/license/:licensePlate", parkingRecordController.GetParkingRecordsByLicensePlateHandler)
 			parkingRecordRoutes.GET("/license/:licensePlate/latest", parkingRecordController.GetLatestParkingRecordByLicensePlateHandler)
+			parkingRecordRoutes.GET("/license/:licensePlate/quote", parkingRecordController.GetParkingFeeQuoteByLicensePlateHandler)
 			parkingRecordRoutes.PATCH("/:id/verify-license-plate", parkingRecordController.UpdateUserVerifiedLicensePlateHandler)
+			parkingRecordRoutes.GET("/:id/quote", parkingRecordController.GetParkingFeeQuoteHandler)
 			parkingRecordRoutes.POST("/:id/prepare-payment", parkingRecordController.PrepareParkingRecordForPaymentHandler)
-			parkingRecordRoutes.POST("/:id/pay", parkingRecordController.PayForParkingRecordHandler)
+			parkingRecordRoutes.POST("/:id/pay", idempotent, parkingRecordController.PayForParkingRecordHandler)
+			parkingRecordRoutes.POST("/:id/abandon", parkingRecordController.AbandonParkingRecordHandler)
+			parkingRecordRoutes.GET("/:id/images/:kind", parkingRecordController.GetParkingRecordImageHandler)
+			parkingRecordRoutes.GET("/:id/payment-intents", paymentIntentController.GetPaymentIntentsByParkingRecordIDHandler)
+			parkingRecordRoutes.POST("/:id/validations", merchantController.ApplyValidationHandler)
+			parkingRecordRoutes.GET("/:id/validations", merchantController.GetValidationsByParkingRecordIDHandler)
+			parkingRecordRoutes.GET("/:id/charging-sessions", chargingController.GetChargingSessionsByParkingRecordIDHandler)
 			parkingRecordRoutes.PUT("/:id", parkingRecordController.UpdateParkingRecordHandler)
 			parkingRecordRoutes.DELETE("/:id", parkingRecordController.DeleteParkingRecordHandler)
 			parkingRecordRoutes.GET("", parkingRecordController.GetAllParkingRecordsHandler)
@@ -95,7 +257,11 @@ func SetupRouter() *gin.Engine {
 				reportRoutes.GET("/traffic/total-count", parkingRecordController.GetTotalParkingCountHandler)
 				reportRoutes.GET("/revenue/total", parkingRecordController.GetTotalRevenueHandler)
 				reportRoutes.GET("/operations/image-attachment-rate", parkingRecordController.GetImageAttachmentRateHandler)
+				reportRoutes.GET("/operations/plate-review-throughput", plateReviewController.GetPlateReviewThroughputHandler)
 				reportRoutes.GET("/parking-lot/available-spots", parkingRecordController.GetAvailableParkingSpotsHandler)
+				reportRoutes.GET("/parking-lot/signboard", parkingRecordController.GetSignboardHandler)
+				reportRoutes.GET("/merchants/subsidies", merchantController.GetMerchantSubsidyReportHandler)
+				reportRoutes.GET("/permits/expiring", permitController.GetPermitExpiryReportHandler)
 			}
 		}
 	}
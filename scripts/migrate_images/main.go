@@ -0,0 +1,192 @@
+// migrate_images 將舊版以 Base64 data URL 存放在 parking_records.image 欄位的影像搬移到影像儲存空間
+// 影像儲存空間依 configs.ImageStore 的環境變數決定；搬移後停車記錄改存 image_key 與 thumbnail_key，image 欄位設為 NULL
+// 已搬移但沒有縮圖的影像會補產生縮圖
+// 需先執行 make setup-db 建立 image_key 與 thumbnail_key 欄位，重複執行只會處理尚未搬移的記錄
+//
+// 用法: go run ./scripts/migrate_images [-batch-size 100] [-dry-run] [-drop-column]
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"hello-professor_backend/configs"
+	"hello-professor_backend/database"
+	"hello-professor_backend/models"
+	"hello-professor_backend/services"
+
+	"github.com/joho/godotenv"
+)
+
+// legacyImage 尚未搬移的停車記錄影像
+type legacyImage struct {
+	RecordID  uint
+	EntryTime time.Time
+	Image     string
+}
+
+func main() {
+	batchSize := flag.Int("batch-size", 100, "每批讀取的停車記錄數量")
+	dryRun := flag.Bool("dry-run", false, "只檢查可搬移的影像，不寫入儲存空間與資料庫")
+	dropColumn := flag.Bool("drop-column", false, "所有影像搬移完成後刪除 parking_records.image 欄位")
+	flag.Parse()
+	if *batchSize <= 0 {
+		log.Fatalf("batch-size 必須大於 0")
+	}
+
+	godotenv.Overload()
+	if err := database.InitDB(); err != nil {
+		log.Fatalf("資料庫連接失敗: %v", err)
+	}
+	db := database.GetDB()
+	if !db.Migrator().HasColumn(&models.ParkingRecord{}, "ImageKey") || !db.Migrator().HasColumn(&models.ParkingRecord{}, "ThumbnailKey") {
+		log.Fatalf("parking_records.image_key 或 thumbnail_key 欄位不存在，請先執行 make setup-db")
+	}
+
+	store, err := configs.ImageStore()
+	if err != nil {
+		log.Fatalf("無法建立影像儲存空間: %v", err)
+	}
+	imageService := services.NewImageService(store, configs.MaxImagePixels, configs.ThumbnailMaxSize, configs.ThumbnailJPEGQuality)
+
+	createMissingThumbnails(imageService, *batchSize, *dryRun)
+	if !db.Migrator().HasColumn(&models.ParkingRecord{}, "image") {
+		fmt.Println("parking_records.image 欄位不存在，沒有需要搬移的影像")
+		return
+	}
+
+	var migrated, skipped int
+	var lastRecordID uint
+	for {
+		var batch []legacyImage
+		if err := db.Raw(`SELECT record_id, entry_time, image FROM parking_records
+			WHERE record_id > ? AND image_key IS NULL AND image IS NOT NULL AND image <> ''
+			ORDER BY record_id LIMIT ?`, lastRecordID, *batchSize).Scan(&batch).Error; err != nil {
+			log.Fatalf("讀取停車記錄失敗: %v", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, legacy := range batch {
+			lastRecordID = legacy.RecordID
+			data, err := decodeDataURL(legacy.Image)
+			if err != nil {
+				log.Printf("略過停車記錄 %d: %v", legacy.RecordID, err)
+				skipped++
+				continue
+			}
+			if *dryRun {
+				migrated++
+				continue
+			}
+
+			stored, err := imageService.StoreParkingRecordImage(data, legacy.EntryTime)
+			if err != nil {
+				log.Printf("略過停車記錄 %d: %v", legacy.RecordID, err)
+				skipped++
+				continue
+			}
+			result := db.Exec("UPDATE parking_records SET image_key = ?, thumbnail_key = ?, image = NULL WHERE record_id = ? AND image_key IS NULL", stored.Key, stored.ThumbnailKey, legacy.RecordID)
+			if result.Error != nil || result.RowsAffected == 0 {
+				// 停車記錄已被其他程序處理或更新失敗，刪除剛寫入的影像避免留下孤兒物件
+				keys := []string{stored.Key}
+				if stored.ThumbnailKey != nil {
+					keys = append(keys, *stored.ThumbnailKey)
+				}
+				for _, key := range keys {
+					if deleteErr := imageService.DeleteImage(key); deleteErr != nil {
+						log.Printf("刪除影像 %s 失敗: %v", key, deleteErr)
+					}
+				}
+				if result.Error != nil {
+					log.Fatalf("更新停車記錄 %d 失敗: %v", legacy.RecordID, result.Error)
+				}
+				continue
+			}
+			migrated++
+		}
+		log.Printf("已處理至停車記錄 %d：搬移 %d 筆，略過 %d 筆", lastRecordID, migrated, skipped)
+	}
+
+	if *dryRun {
+		fmt.Printf("試跑完成：可搬移 %d 筆，無法搬移 %d 筆\n", migrated, skipped)
+		return
+	}
+	fmt.Printf("影像搬移完成：搬移 %d 筆，略過 %d 筆\n", migrated, skipped)
+
+	if *dropColumn {
+		var remaining int64
+		if err := db.Raw("SELECT COUNT(*) FROM parking_records WHERE image IS NOT NULL AND image <> ''").Scan(&remaining).Error; err != nil {
+			log.Fatalf("計算未搬移的影像失敗: %v", err)
+		}
+		if remaining > 0 {
+			log.Fatalf("仍有 %d 筆停車記錄的影像未搬移，不刪除 image 欄位", remaining)
+		}
+		if err := db.Migrator().DropColumn(&models.ParkingRecord{}, "image"); err != nil {
+			log.Fatalf("刪除 image 欄位失敗: %v", err)
+		}
+		fmt.Println("已刪除 parking_records.image 欄位")
+	}
+}
+
+// createMissingThumbnails 為已搬移到影像儲存空間但沒有縮圖的停車記錄產生縮圖
+// 無法產生縮圖的格式 (例如 WebP) 會維持沒有縮圖，每次執行都會再檢查
+func createMissingThumbnails(imageService services.ImageService, batchSize int, dryRun bool) {
+	db := database.GetDB()
+	var created, skipped int
+	var lastRecordID uint
+	for {
+		var records []models.ParkingRecord
+		if err := db.Select("record_id", "image_key").
+			Where("record_id > ? AND image_key IS NOT NULL AND thumbnail_key IS NULL", lastRecordID).
+			Order("record_id").Limit(batchSize).Find(&records).Error; err != nil {
+			log.Fatalf("讀取停車記錄失敗: %v", err)
+		}
+		if len(records) == 0 {
+			break
+		}
+
+		for _, record := range records {
+			lastRecordID = record.RecordID
+			if dryRun {
+				created++
+				continue
+			}
+			thumbnailKey, err := imageService.CreateThumbnail(*record.ImageKey)
+			if err != nil || thumbnailKey == nil {
+				if err != nil {
+					log.Printf("略過停車記錄 %d 的縮圖: %v", record.RecordID, err)
+				}
+				skipped++
+				continue
+			}
+			if err := db.Model(&models.ParkingRecord{}).Where("record_id = ?", record.RecordID).Update("thumbnail_key", *thumbnailKey).Error; err != nil {
+				log.Fatalf("更新停車記錄 %d 失敗: %v", record.RecordID, err)
+			}
+			created++
+		}
+	}
+	if created > 0 || skipped > 0 {
+		fmt.Printf("縮圖補齊完成：產生 %d 筆，無法產生 %d 筆\n", created, skipped)
+	}
+}
+
+// decodeDataURL 解析 data:<mime>;base64,<data> 格式的影像
+// 影像格式由 ImageService 依內容判斷，因此忽略 data URL 宣告的 MIME 類型
+func decodeDataURL(value string) ([]byte, error) {
+	header, encoded, found := strings.Cut(value, ",")
+	if !found || !strings.HasPrefix(header, "data:") || !strings.HasSuffix(header, ";base64") {
+		return nil, errors.New("image 欄位不是 Base64 data URL")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("Base64 解碼失敗: %w", err)
+	}
+	return data, nil
+}
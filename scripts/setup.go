@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"log"
 
+	"hello-professor_backend/configs"
 	"hello-professor_backend/database"
+	"hello-professor_backend/licenseplate"
 	"hello-professor_backend/models"
 
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -18,15 +21,144 @@ func main() {
 		log.Fatalf("資料庫連接失敗: %v", err)
 	}
 
-	// 創建資料表（AutoMigrate 內部會處理順序和依賴）
+	// 先建立停車場，既有的停車記錄需歸屬到預設停車場後才能加上 NOT NULL 的 parking_lot_id
 	log.Println("開始進行資料庫遷移...")
+	if err := database.AutoMigrate(&models.ParkingLot{}, &models.ParkingLotSensor{}, &models.ParkingLotVehicleClass{}, &models.Zone{}, &models.Spot{}); err != nil {
+		log.Fatalf("資料庫遷移失敗: %v", err)
+	}
+	if err := seedDefaultParkingLot(); err != nil {
+		log.Fatalf("建立預設停車場失敗: %v", err)
+	}
+
+	// 創建資料表（AutoMigrate 內部會處理順序和依賴）
 	if err := database.AutoMigrate(
-		// &models.Vehicle{}, // 移除 Vehicle 模型
+		&models.Vehicle{},
 		&models.ParkingRecord{},
 		&models.Transaction{},
+		&models.PaymentIntent{},
+		&models.PaymentWebhookEvent{},
+		&models.IdempotencyRecord{},
+		&models.Merchant{},
+		&models.ValidationCode{},
+		&models.ParkingValidation{},
+		&models.Permit{},
+		&models.PermitPlate{},
+		&models.Reservation{},
+		&models.ChargingSession{},
+		&models.ChargingMeterReading{},
+		&models.TransactionLineItem{},
+		&models.PlateReview{},
+		&models.PlateReviewAlternative{},
+		&models.WatchlistEntry{},
+		&models.WatchlistAlert{},
 	); err != nil {
 		log.Fatalf("資料庫遷移失敗: %v", err)
 	}
+	// 車牌模糊搜尋與出場模糊比對使用 pg_trgm 的 similarity
+	if err := database.GetDB().Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Fatalf("建立 pg_trgm 擴充套件失敗: %v", err)
+	}
+	if err := normalizeLicensePlates(); err != nil {
+		log.Fatalf("車牌格式轉換失敗: %v", err)
+	}
+	if err := linkParkingRecordsToVehicles(); err != nil {
+		log.Fatalf("建立車輛資料失敗: %v", err)
+	}
+	// 既有已出場的停車記錄狀態為 Exited
+	if err := database.GetDB().Model(&models.ParkingRecord{}).
+		Where("exit_time IS NOT NULL AND session_status = ?", models.SessionStatusOpen).
+		Update("session_status", models.SessionStatusExited).Error; err != nil {
+		log.Fatalf("停車狀態轉換失敗: %v", err)
+	}
 
 	fmt.Println("資料庫設置完成！")
 }
+
+// seedDefaultParkingLot 建立 configs 中的預設停車場，並將尚未指定停車場的既有停車記錄歸屬到該停車場
+func seedDefaultParkingLot() error {
+	db := database.GetDB()
+	defaultParkingLot := models.ParkingLot{
+		Name:         configs.DefaultParkingLotName,
+		Address:      configs.DefaultParkingLotAddress,
+		Capacity:     configs.DefaultParkingLotCapacity,
+		RateCalendar: configs.DefaultRateCalendarName,
+		Active:       true,
+	}
+	if err := db.Where("name = ?", defaultParkingLot.Name).FirstOrCreate(&defaultParkingLot).Error; err != nil {
+		return err
+	}
+
+	if !db.Migrator().HasTable(&models.ParkingRecord{}) {
+		return nil
+	}
+	if !db.Migrator().HasColumn(&models.ParkingRecord{}, "ParkingLotID") {
+		if err := db.Exec("ALTER TABLE parking_records ADD COLUMN parking_lot_id bigint").Error; err != nil {
+			return err
+		}
+	}
+	return db.Exec("UPDATE parking_records SET parking_lot_id = ? WHERE parking_lot_id IS NULL", defaultParkingLot.ParkingLotID).Error
+}
+
+// normalizeLicensePlates 將既有停車記錄、停車證與預約的車牌轉為標準格式，停車記錄原本的車牌保留為 OCR 原始字串
+// 已有原始字串的停車記錄視為已轉換，重複執行不會有影響
+func normalizeLicensePlates() error {
+	db := database.GetDB()
+	var permitPlates []models.PermitPlate
+	if err := db.Find(&permitPlates).Error; err != nil {
+		return err
+	}
+	for _, plate := range permitPlates {
+		if normalized := licenseplate.Normalize(plate.LicensePlate); normalized != plate.LicensePlate {
+			if err := db.Model(&plate).Update("license_plate", normalized).Error; err != nil {
+				return err
+			}
+		}
+	}
+	var reservations []models.Reservation
+	if err := db.Where("status = ?", models.ReservationStatusConfirmed).Find(&reservations).Error; err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		if normalized := licenseplate.Normalize(reservation.LicensePlate); normalized != reservation.LicensePlate {
+			if err := db.Model(&reservation).Update("license_plate", normalized).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	var records []models.ParkingRecord
+	return db.Select("record_id", "license_plate", "user_verified_license_plate").
+		Where("raw_license_plate IS NULL OR raw_license_plate = ''").
+		FindInBatches(&records, 500, func(batch *gorm.DB, _ int) error {
+			for _, record := range records {
+				updates := map[string]interface{}{
+					"raw_license_plate": record.LicensePlate,
+					"license_plate":     licenseplate.Normalize(record.LicensePlate),
+				}
+				if record.UserVerifiedLicensePlate != nil {
+					updates["user_verified_license_plate"] = licenseplate.Normalize(*record.UserVerifiedLicensePlate)
+				}
+				if err := db.Model(&models.ParkingRecord{}).Where("record_id = ?", record.RecordID).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// linkParkingRecordsToVehicles 為既有停車記錄的車牌 (有驗證車牌時為驗證車牌) 建立車輛資料，並連結尚未連結的停車記錄
+// 需在車牌轉為標準格式後執行，重複執行不會有影響
+func linkParkingRecordsToVehicles() error {
+	db := database.GetDB()
+	plate := "COALESCE(NULLIF(parking_records.user_verified_license_plate, ''), parking_records.license_plate)"
+	if err := db.Exec(`INSERT INTO vehicles (license_plate, first_seen_at, last_seen_at, created_at, updated_at)
+		SELECT ` + plate + `, MIN(entry_time), MAX(entry_time), NOW(), NOW()
+		FROM parking_records WHERE vehicle_id IS NULL GROUP BY ` + plate + `
+		ON CONFLICT (license_plate) DO UPDATE SET
+			first_seen_at = LEAST(vehicles.first_seen_at, EXCLUDED.first_seen_at),
+			last_seen_at = GREATEST(vehicles.last_seen_at, EXCLUDED.last_seen_at)`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE parking_records SET vehicle_id = vehicles.vehicle_id
+		FROM vehicles WHERE parking_records.vehicle_id IS NULL AND vehicles.license_plate = ` + plate).Error
+}
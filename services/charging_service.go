@@ -0,0 +1,271 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChargingService 定義電動車充電服務的介面
+// 充電樁在開始、充電中與結束時回報電錶讀數，電費與充電結束後的佔用費另外列入停車記錄的費用
+type ChargingService interface {
+	StartChargingSession(payload dtos.StartChargingPayload) (*models.ChargingSession, error)
+	RecordMeterReading(id uint, payload dtos.MeterReadingPayload) (*models.ChargingSession, error)
+	StopChargingSession(id uint, payload dtos.MeterReadingPayload) (*models.ChargingSession, error)
+	GetChargingSessionByID(id uint) (*models.ChargingSession, error)
+	GetChargingSessionsByParkingRecordID(parkingRecordID uint) ([]models.ChargingSession, error)
+	CalculateCharges(parkingRecordID uint, calculateUntil time.Time) ([]dtos.ChargingCharge, error)
+	HasActiveChargingSession(tx *gorm.DB, parkingRecordID uint) (bool, error)
+	StopChargingSessions(tx *gorm.DB, parkingRecordID uint, at time.Time) error
+}
+
+// chargingService 是 ChargingService 的實作
+type chargingService struct {
+	chargingRepo      repositories.ChargingSessionRepository
+	parkingRecordRepo repositories.ParkingRecordRepository
+	energyRate        models.Money
+	idleFeeRate       models.Money
+	idleGracePeriod   time.Duration
+	db                *gorm.DB
+}
+
+// NewChargingService 建立一個新的 ChargingService 實例，energyRate 為每度電的電費，idleFeeRate 為充電結束超過 idleGracePeriod 後每分鐘的佔用費
+func NewChargingService(chargingRepo repositories.ChargingSessionRepository, prRepo repositories.ParkingRecordRepository, energyRate, idleFeeRate models.Money, idleGracePeriod time.Duration, db *gorm.DB) ChargingService {
+	return &chargingService{
+		chargingRepo:      chargingRepo,
+		parkingRecordRepo: prRepo,
+		energyRate:        energyRate,
+		idleFeeRate:       idleFeeRate,
+		idleGracePeriod:   idleGracePeriod,
+		db:                db,
+	}
+}
+
+// StartChargingSession 開始為場內車輛充電
+// 同一充電樁與同一停車記錄同時只能有一筆進行中的充電；已出場或已付款的停車記錄不可開始充電
+func (s *chargingService) StartChargingSession(payload dtos.StartChargingPayload) (session *models.ChargingSession, err error) {
+	chargerID := strings.TrimSpace(payload.ChargerID)
+	if chargerID == "" {
+		return nil, errors.New("invalid_charging: Charger ID is required.")
+	}
+	parkingRecordID := payload.ParkingRecordID
+	if parkingRecordID == 0 {
+		if payload.LicensePlate == "" {
+			return nil, errors.New("invalid_charging: Either parkingRecordId or licensePlate is required.")
+		}
+		record, findErr := s.parkingRecordRepo.GetLatestParkingRecordByLicensePlate(payload.LicensePlate)
+		if findErr != nil {
+			return nil, fmt.Errorf("error finding active parking record for license plate %s: %w", payload.LicensePlate, findErr)
+		}
+		if record == nil || record.ExitTime != nil {
+			return nil, fmt.Errorf("no active parking record found for license plate %s", payload.LicensePlate)
+		}
+		parkingRecordID = record.RecordID
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+		}
+	}()
+
+	// 鎖定停車記錄，避免與付款或出場同時進行
+	var record models.ParkingRecord
+	if queryErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&record, parkingRecordID).Error; queryErr != nil {
+		if errors.Is(queryErr, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("parking record ID %d not found", parkingRecordID)
+		}
+		return nil, fmt.Errorf("error finding parking record ID %d: %w", parkingRecordID, queryErr)
+	}
+	if record.ExitTime != nil {
+		return nil, fmt.Errorf("vehicle_exited: Vehicle of parking record ID %d has already exited.", parkingRecordID)
+	}
+	if record.PaymentStatus == "Paid" {
+		return nil, fmt.Errorf("already_paid: Parking record ID %d is already paid; charging cannot start after payment.", parkingRecordID)
+	}
+
+	busy, err := s.chargingRepo.GetActiveChargingSessionByChargerID(tx, chargerID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking charger %s: %w", chargerID, err)
+	}
+	if busy != nil {
+		return nil, fmt.Errorf("charger_busy: Charger %s is already charging parking record ID %d in charging session ID %d.", chargerID, busy.ParkingRecordID, busy.ChargingSessionID)
+	}
+	active, err := s.chargingRepo.CountActiveChargingSessions(tx, parkingRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking charging sessions of parking record ID %d: %w", parkingRecordID, err)
+	}
+	if active > 0 {
+		return nil, fmt.Errorf("charging_in_progress: Parking record ID %d is already charging.", parkingRecordID)
+	}
+
+	now := time.Now()
+	session = &models.ChargingSession{
+		ParkingRecordID: parkingRecordID,
+		ChargerID:       chargerID,
+		Status:          models.ChargingSessionStatusActive,
+		StartedAt:       now,
+		StartMeterWh:    *payload.MeterWh,
+		LastMeterWh:     *payload.MeterWh,
+		LastMeterAt:     now,
+		Readings:        []models.ChargingMeterReading{{MeterWh: *payload.MeterWh, ReadAt: now}},
+	}
+	if err = s.chargingRepo.CreateChargingSession(tx, session); err != nil {
+		return nil, fmt.Errorf("error creating charging session: %w", err)
+	}
+	return session, nil
+}
+
+// RecordMeterReading 記錄充電中的電錶讀數，讀數不可小於上一次的讀數
+func (s *chargingService) RecordMeterReading(id uint, payload dtos.MeterReadingPayload) (*models.ChargingSession, error) {
+	return s.applyMeterReading(id, payload, false)
+}
+
+// StopChargingSession 以最後的電錶讀數結束充電，之後車輛仍佔用充電車位超過寬限時間即開始計收佔用費
+func (s *chargingService) StopChargingSession(id uint, payload dtos.MeterReadingPayload) (*models.ChargingSession, error) {
+	return s.applyMeterReading(id, payload, true)
+}
+
+// applyMeterReading 在資料庫交易中記錄電錶讀數並更新充電，stop 為 true 時一併結束充電
+func (s *chargingService) applyMeterReading(id uint, payload dtos.MeterReadingPayload, stop bool) (session *models.ChargingSession, err error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+		}
+	}()
+
+	session, err = s.chargingRepo.GetChargingSessionByIDForUpdate(tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding charging session ID %d: %w", id, err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("charging session ID %d not found", id)
+	}
+	if session.Status != models.ChargingSessionStatusActive {
+		return nil, fmt.Errorf("charging_stopped: Charging session ID %d has already stopped.", id)
+	}
+
+	now := time.Now()
+	readAt := now
+	if payload.ReadAt != nil {
+		readAt = *payload.ReadAt
+	}
+	if *payload.MeterWh < session.LastMeterWh {
+		return nil, fmt.Errorf("invalid_meter_reading: Meter reading %d Wh is lower than the previous reading %d Wh of charging session ID %d.", *payload.MeterWh, session.LastMeterWh, id)
+	}
+	if readAt.Before(session.LastMeterAt) || readAt.After(now) {
+		return nil, fmt.Errorf("invalid_meter_reading: Reading time %v must be between the previous reading at %v and now.", readAt, session.LastMeterAt)
+	}
+
+	reading := &models.ChargingMeterReading{
+		ChargingSessionID: session.ChargingSessionID,
+		MeterWh:           *payload.MeterWh,
+		ReadAt:            readAt,
+	}
+	if err = s.chargingRepo.CreateMeterReading(tx, reading); err != nil {
+		return nil, fmt.Errorf("error recording meter reading for charging session ID %d: %w", id, err)
+	}
+
+	session.LastMeterWh = *payload.MeterWh
+	session.LastMeterAt = readAt
+	if stop {
+		session.Status = models.ChargingSessionStatusCompleted
+		session.StoppedAt = &readAt
+	}
+	if err = s.chargingRepo.UpdateChargingSession(tx, session); err != nil {
+		return nil, fmt.Errorf("error updating charging session ID %d: %w", id, err)
+	}
+
+	// 已計算的應付金額未包含此讀數的電費，清除後需重新準備付款
+	if err = tx.Model(&models.ParkingRecord{}).
+		Where("record_id = ? AND payment_status <> ? AND calculated_amount > 0", session.ParkingRecordID, "Paid").
+		Update("calculated_amount", models.Money{}).Error; err != nil {
+		return nil, fmt.Errorf("error resetting calculated fee of parking record ID %d: %w", session.ParkingRecordID, err)
+	}
+	return session, nil
+}
+
+// GetChargingSessionByID 呼叫 repository 透過 ID 取得充電及其電錶讀數
+func (s *chargingService) GetChargingSessionByID(id uint) (*models.ChargingSession, error) {
+	return s.chargingRepo.GetChargingSessionByID(id)
+}
+
+// GetChargingSessionsByParkingRecordID 呼叫 repository 取得停車記錄的所有充電
+func (s *chargingService) GetChargingSessionsByParkingRecordID(parkingRecordID uint) ([]models.ChargingSession, error) {
+	return s.chargingRepo.GetChargingSessionsByParkingRecordID(nil, parkingRecordID)
+}
+
+// CalculateCharges 計算停車記錄每筆充電到 calculateUntil 為止的電費與佔用費
+// 電費依最後一次回報的電錶讀數計算；佔用費自充電結束加上寬限時間後起算，未滿一分鐘以一分鐘計
+func (s *chargingService) CalculateCharges(parkingRecordID uint, calculateUntil time.Time) ([]dtos.ChargingCharge, error) {
+	sessions, err := s.chargingRepo.GetChargingSessionsByParkingRecordID(nil, parkingRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting charging sessions of parking record ID %d: %w", parkingRecordID, err)
+	}
+
+	charges := []dtos.ChargingCharge{}
+	for _, session := range sessions {
+		energyWh := session.EnergyWh()
+		charge := dtos.ChargingCharge{
+			ChargingSessionID: session.ChargingSessionID,
+			ChargerID:         session.ChargerID,
+			Status:            session.Status,
+			EnergyKWh:         float64(energyWh) / 1000,
+			EnergyRate:        s.energyRate,
+			EnergyAmount:      s.energyRate.MulFraction(energyWh, 1000),
+			IdleFeeRate:       s.idleFeeRate,
+		}
+		if session.StoppedAt != nil {
+			idleFrom := session.StoppedAt.Add(s.idleGracePeriod)
+			if calculateUntil.After(idleFrom) {
+				charge.IdleMinutes = int((calculateUntil.Sub(idleFrom) + time.Minute - 1) / time.Minute)
+			}
+		}
+		charge.IdleFeeAmount = s.idleFeeRate.Mul(int64(charge.IdleMinutes))
+		charge.Amount = charge.EnergyAmount.Add(charge.IdleFeeAmount)
+		charges = append(charges, charge)
+	}
+	return charges, nil
+}
+
+// HasActiveChargingSession 檢查停車記錄是否有進行中的充電
+func (s *chargingService) HasActiveChargingSession(tx *gorm.DB, parkingRecordID uint) (bool, error) {
+	active, err := s.chargingRepo.CountActiveChargingSessions(tx, parkingRecordID)
+	if err != nil {
+		return false, fmt.Errorf("error checking charging sessions of parking record ID %d: %w", parkingRecordID, err)
+	}
+	return active > 0, nil
+}
+
+// StopChargingSessions 車輛出場時以最後一次的電錶讀數結束仍在進行中的充電
+func (s *chargingService) StopChargingSessions(tx *gorm.DB, parkingRecordID uint, at time.Time) error {
+	if _, err := s.chargingRepo.StopActiveChargingSessions(tx, parkingRecordID, at); err != nil {
+		return fmt.Errorf("error stopping charging sessions of parking record ID %d: %w", parkingRecordID, err)
+	}
+	return nil
+}
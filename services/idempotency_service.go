@@ -0,0 +1,187 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IdempotencyService 定義 Idempotency-Key 處理服務的介面
+type IdempotencyService interface {
+	AcquireIdempotencyKey(key, method, path, contentType string, body []byte) (*models.IdempotencyRecord, *IdempotencyLease, error)
+	CompleteIdempotencyKey(lease *IdempotencyLease, statusCode int, contentType string, responseBody []byte) error
+	ReleaseIdempotencyKey(lease *IdempotencyLease) error
+	DeleteExpiredIdempotencyRecords() error
+}
+
+// IdempotencyLease 代表一次請求取得的 key 處理權，完成或釋放 key 時只會影響仍由此處理權持有的記錄
+type IdempotencyLease struct {
+	Key         string
+	RequestHash string
+	ExpiresAt   time.Time
+}
+
+// idempotencyService 是 IdempotencyService 的實作
+type idempotencyService struct {
+	idempotencyRepo repositories.IdempotencyRepository
+	ttl             time.Duration
+	lease           time.Duration
+}
+
+// NewIdempotencyService 建立一個新的 IdempotencyService 實例，ttl 為保存第一次回應的時間，lease 為處理中的請求保留 key 的時間
+func NewIdempotencyService(repo repositories.IdempotencyRepository, ttl time.Duration, lease time.Duration) IdempotencyService {
+	return &idempotencyService{
+		idempotencyRepo: repo,
+		ttl:             ttl,
+		lease:           lease,
+	}
+}
+
+// AcquireIdempotencyKey 嘗試以 key 開始處理請求
+// 第一次使用的 key 會回傳處理權，呼叫端應處理請求後以處理權呼叫 CompleteIdempotencyKey 或 ReleaseIdempotencyKey；
+// 相同請求重送且已處理完成時，回傳保存的記錄供呼叫端原樣回傳；
+// key 被用於不同的請求時回傳 idempotency_key_reused 錯誤，第一次請求仍在處理期限內時回傳 idempotency_request_in_progress 錯誤，超過處理期限則由此請求接手
+// multipart/form-data 請求以欄位內容辨識，客戶端重送時產生的不同 boundary 不影響比對
+func (s *idempotencyService) AcquireIdempotencyKey(key, method, path, contentType string, body []byte) (*models.IdempotencyRecord, *IdempotencyLease, error) {
+	if len(key) > configs.IdempotencyKeyMaxLength {
+		return nil, nil, fmt.Errorf("invalid_idempotency_key: %s must not exceed %d characters.", configs.IdempotencyKeyHeader, configs.IdempotencyKeyMaxLength)
+	}
+
+	now := time.Now()
+	// 處理期限同時作為處理權的識別，截到資料庫時間欄位的精度 (微秒) 才能再以相等比對
+	lease := &IdempotencyLease{
+		Key:         key,
+		RequestHash: idempotencyRequestHash(method, path, contentType, body),
+		ExpiresAt:   now.Add(s.lease).Truncate(time.Microsecond),
+	}
+	created, err := s.idempotencyRepo.CreateIdempotencyRecordIfNotExists(&models.IdempotencyRecord{
+		IdempotencyKey: key,
+		Method:         method,
+		Path:           path,
+		RequestHash:    lease.RequestHash,
+		LeaseExpiresAt: &lease.ExpiresAt,
+		ExpiresAt:      now.Add(s.ttl),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error saving idempotency key: %w", err)
+	}
+	if created {
+		return nil, lease, nil
+	}
+
+	existing, err := s.idempotencyRepo.GetIdempotencyRecordByKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting idempotency key: %w", err)
+	}
+	if existing == nil {
+		// 第一次請求處理失敗後已釋放 key，視為新的請求重新嘗試
+		return s.AcquireIdempotencyKey(key, method, path, contentType, body)
+	}
+	if existing.RequestHash != lease.RequestHash {
+		return nil, nil, fmt.Errorf("idempotency_key_reused: %s %s was already used for a different request.", configs.IdempotencyKeyHeader, key)
+	}
+	if !existing.Completed {
+		// 第一次請求的處理程序當機等原因未釋放 key 時，處理期限過後由重送的請求接手
+		takenOver, err := s.idempotencyRepo.TakeOverIdempotencyRecord(key, lease.RequestHash, now, lease.ExpiresAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error taking over idempotency key: %w", err)
+		}
+		if takenOver {
+			log.Printf("[Idempotency] key %s 的處理期限已過，由重送的請求接手處理", key)
+			return nil, lease, nil
+		}
+		return nil, nil, fmt.Errorf("idempotency_request_in_progress: The request with %s %s is still being processed.", configs.IdempotencyKeyHeader, key)
+	}
+	return existing, nil, nil
+}
+
+// CompleteIdempotencyKey 保存第一次請求的回應；key 已被重送的請求接手時不覆寫其記錄並回傳錯誤
+func (s *idempotencyService) CompleteIdempotencyKey(lease *IdempotencyLease, statusCode int, contentType string, responseBody []byte) error {
+	completed, err := s.idempotencyRepo.CompleteIdempotencyRecord(lease.Key, lease.RequestHash, lease.ExpiresAt, statusCode, contentType, responseBody)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return fmt.Errorf("idempotency_lease_lost: %s %s was taken over by a retried request.", configs.IdempotencyKeyHeader, lease.Key)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey 放棄保存第一次請求的回應，讓相同 key 可以重新嘗試；key 已被重送的請求接手時不刪除其記錄
+func (s *idempotencyService) ReleaseIdempotencyKey(lease *IdempotencyLease) error {
+	released, err := s.idempotencyRepo.DeleteIdempotencyRecord(lease.Key, lease.RequestHash, lease.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	if !released {
+		log.Printf("[Idempotency] key %s 已由重送的請求接手，不釋放", lease.Key)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyRecords 刪除已過期的 Idempotency 記錄
+func (s *idempotencyService) DeleteExpiredIdempotencyRecords() error {
+	deleted, err := s.idempotencyRepo.DeleteExpiredIdempotencyRecords(time.Now())
+	if err != nil {
+		return fmt.Errorf("error deleting expired idempotency records: %w", err)
+	}
+	if deleted > 0 {
+		log.Printf("[Idempotency] 已刪除 %d 筆過期的 Idempotency 記錄", deleted)
+	}
+	return nil
+}
+
+// idempotencyRequestHash 計算請求方法、路徑與內容的 SHA-256
+// multipart/form-data 的內容以各欄位名稱與內容的摘要計算，其他內容以原始內容計算
+func idempotencyRequestHash(method, path, contentType string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	if digest, ok := multipartFormDigest(contentType, body); ok {
+		hash.Write(digest)
+	} else {
+		hash.Write(body)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// multipartFormDigest 列出 multipart/form-data 各欄位 (包含檔案) 的名稱與內容的 SHA-256，排序後組成摘要
+// 內容不是 multipart/form-data 或無法解析時回傳 false
+func multipartFormDigest(contentType string, body []byte) ([]byte, bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, false
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	var fields []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, false
+		}
+		sum := sha256.Sum256(content)
+		fields = append(fields, part.FormName()+"\x00"+hex.EncodeToString(sum[:]))
+	}
+	sort.Strings(fields)
+	return []byte("multipart\x00" + strings.Join(fields, "\n")), true
+}
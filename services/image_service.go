@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hello-professor_backend/blobstore"
+	"hello-professor_backend/thumbnail"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// imageExtensions 接受的影像格式與存放時使用的副檔名
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// StoredImage 已存放的影像與其縮圖在影像儲存空間中的 key
+// 無法產生縮圖的格式 (例如 WebP) ThumbnailKey 為 nil
+type StoredImage struct {
+	Key          string
+	ThumbnailKey *string
+}
+
+// ImageService 定義停車影像服務的介面
+// 影像存放在 blobstore.Store，停車記錄只保存物件的 key；縮圖在存放影像時產生一次
+type ImageService interface {
+	StoreParkingRecordImage(data []byte, takenAt time.Time) (*StoredImage, error)
+	CreateThumbnail(key string) (*string, error)
+	GetImage(key string) (*blobstore.Object, error)
+	DeleteImage(key string) error
+}
+
+// imageService 是 ImageService 的實作
+type imageService struct {
+	store            blobstore.Store
+	maxPixels        int
+	thumbnailMaxSize int
+	thumbnailQuality int
+}
+
+// NewImageService 建立一個新的 ImageService 實例，拒絕像素數超過 maxPixels 的影像
+// 縮圖的長邊不超過 thumbnailMaxSize 像素，以 thumbnailQuality 的 JPEG 品質存放
+func NewImageService(store blobstore.Store, maxPixels int, thumbnailMaxSize int, thumbnailQuality int) ImageService {
+	return &imageService{
+		store:            store,
+		maxPixels:        maxPixels,
+		thumbnailMaxSize: thumbnailMaxSize,
+		thumbnailQuality: thumbnailQuality,
+	}
+}
+
+// StoreParkingRecordImage 存放停車記錄的影像與縮圖，例如 parking-records/2026/10/17/3f9a….jpg 與 parking-records/2026/10/17/3f9a…_thumb.jpg
+// 影像格式依內容判斷 (不採用上傳時宣告的 Content-Type)，只接受 JPEG、PNG、GIF 與 WebP；像素數超過上限的影像不會存放
+// 無法產生縮圖時仍會存放影像
+func (s *imageService) StoreParkingRecordImage(data []byte, takenAt time.Time) (*StoredImage, error) {
+	if len(data) == 0 {
+		return nil, errors.New("invalid_image: Image is empty.")
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("invalid_image: Unsupported image type %s; must be JPEG, PNG, GIF or WebP.", contentType)
+	}
+
+	// 先產生縮圖，像素數超過上限的影像在存放前即被拒絕
+	thumbnailData, err := s.generateThumbnail(data)
+	if errors.Is(err, thumbnail.ErrTooLarge) {
+		return nil, fmt.Errorf("invalid_image: Image dimensions exceed %d pixels.", s.maxPixels)
+	}
+	if err != nil {
+		log.Printf("[StoreParkingRecordImage] No thumbnail for uploaded image: %v", err)
+	}
+
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return nil, fmt.Errorf("error generating image key: %w", err)
+	}
+	key := fmt.Sprintf("parking-records/%s/%s%s", takenAt.UTC().Format("2006/01/02"), hex.EncodeToString(name), extension)
+	if err := s.store.Put(context.Background(), key, data, contentType); err != nil {
+		return nil, fmt.Errorf("error storing image %s: %w", key, err)
+	}
+	if thumbnailData == nil {
+		return &StoredImage{Key: key}, nil
+	}
+
+	thumbnailKey, err := s.storeThumbnail(key, thumbnailData)
+	if err != nil {
+		if deleteErr := s.DeleteImage(key); deleteErr != nil {
+			log.Printf("[StoreParkingRecordImage] %v", deleteErr)
+		}
+		return nil, err
+	}
+	return &StoredImage{Key: key, ThumbnailKey: &thumbnailKey}, nil
+}
+
+// CreateThumbnail 為已存放的影像產生縮圖並回傳其 key，供補齊沒有縮圖的舊影像使用；無法產生縮圖時回傳 nil
+func (s *imageService) CreateThumbnail(key string) (*string, error) {
+	object, err := s.store.Get(context.Background(), key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading image %s: %w", key, err)
+	}
+	thumbnailData, err := s.generateThumbnail(object.Data)
+	if err != nil {
+		log.Printf("[CreateThumbnail] No thumbnail for image %s: %v", key, err)
+	}
+	if thumbnailData == nil {
+		return nil, nil
+	}
+	thumbnailKey, err := s.storeThumbnail(key, thumbnailData)
+	if err != nil {
+		return nil, err
+	}
+	return &thumbnailKey, nil
+}
+
+// generateThumbnail 產生影像的縮圖，格式無法產生縮圖 (例如 WebP) 時回傳 nil
+// 影像損毀或像素數超過上限時回傳錯誤
+func (s *imageService) generateThumbnail(data []byte) ([]byte, error) {
+	thumbnailData, err := thumbnail.Generate(data, s.thumbnailMaxSize, s.thumbnailQuality, s.maxPixels)
+	if errors.Is(err, thumbnail.ErrUnsupportedFormat) {
+		return nil, nil
+	}
+	return thumbnailData, err
+}
+
+// storeThumbnail 將縮圖存放在影像 key 加上 _thumb 的位置並回傳其 key
+func (s *imageService) storeThumbnail(key string, thumbnailData []byte) (string, error) {
+	thumbnailKey := strings.TrimSuffix(key, path.Ext(key)) + "_thumb.jpg"
+	if err := s.store.Put(context.Background(), thumbnailKey, thumbnailData, thumbnail.ContentType); err != nil {
+		return "", fmt.Errorf("error storing thumbnail %s: %w", thumbnailKey, err)
+	}
+	return thumbnailKey, nil
+}
+
+// GetImage 讀取影像，影像不存在時回傳 blobstore.ErrNotFound
+func (s *imageService) GetImage(key string) (*blobstore.Object, error) {
+	return s.store.Get(context.Background(), key)
+}
+
+// DeleteImage 刪除影像，影像不存在時不視為錯誤
+func (s *imageService) DeleteImage(key string) error {
+	if err := s.store.Delete(context.Background(), key); err != nil {
+		return fmt.Errorf("error deleting image %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,466 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ParkingLotService 定義停車場服務的介面
+type ParkingLotService interface {
+	ParkingLotTariffs
+	CreateParkingLot(payload dtos.ParkingLotPayload) (*models.ParkingLot, error)
+	GetParkingLotByID(id uint) (*models.ParkingLot, error)
+	LockParkingLot(tx *gorm.DB, id uint) (*models.ParkingLot, error)
+	GetAllParkingLots() ([]models.ParkingLot, error)
+	UpdateParkingLot(id uint, payload dtos.ParkingLotPayload) (*models.ParkingLot, error)
+	AddParkingLotSensor(parkingLotID uint, payload dtos.ParkingLotSensorPayload) (*models.ParkingLotSensor, error)
+	GetParkingLotSensor(sensorID string) (*models.ParkingLotSensor, error)
+	RemoveParkingLotSensor(parkingLotID uint, sensorID string) error
+	ResolveParkingLot(parkingLotID uint, sensorID string, direction string) (*models.ParkingLot, error)
+
+	CreateZone(parkingLotID uint, payload dtos.ZonePayload) (*models.Zone, error)
+	GetZonesByParkingLotID(parkingLotID uint) ([]models.Zone, error)
+	GetAllZones() ([]models.Zone, error)
+	UpdateZone(parkingLotID uint, zoneID uint, payload dtos.ZonePayload) (*models.Zone, error)
+	DeleteZone(parkingLotID uint, zoneID uint) error
+	AddSpot(parkingLotID uint, zoneID uint, payload dtos.SpotPayload) (*models.Spot, error)
+	RemoveSpot(parkingLotID uint, zoneID uint, spotID uint) error
+	UpdateSpotStatus(sensorID string, occupied bool) (*models.Spot, *models.Zone, error)
+}
+
+// parkingLotService 是 ParkingLotService 的實作
+type parkingLotService struct {
+	parkingLotRepo repositories.ParkingLotRepository
+	// tariffs 依費率行事曆名稱建立的 Tariff
+	tariffs map[string]Tariff
+}
+
+// NewParkingLotService 建立一個新的 ParkingLotService 實例，calendars 為停車場可選用的費率行事曆
+func NewParkingLotService(parkingLotRepo repositories.ParkingLotRepository, calendars map[string]configs.RateCalendar) ParkingLotService {
+	tariffs := make(map[string]Tariff, len(calendars))
+	for name, calendar := range calendars {
+		tariffs[name] = NewCalendarTariff(calendar)
+	}
+	return &parkingLotService{
+		parkingLotRepo: parkingLotRepo,
+		tariffs:        tariffs,
+	}
+}
+
+// CreateParkingLot 新增停車場
+func (s *parkingLotService) CreateParkingLot(payload dtos.ParkingLotPayload) (*models.ParkingLot, error) {
+	parkingLot := &models.ParkingLot{Active: true}
+	if err := s.applyParkingLotPayload(parkingLot, payload); err != nil {
+		return nil, err
+	}
+	if err := s.parkingLotRepo.CreateParkingLot(parkingLot); err != nil {
+		return nil, fmt.Errorf("error creating parking lot: %w", err)
+	}
+	return parkingLot, nil
+}
+
+// GetParkingLotByID 呼叫 repository 透過 ID 取得停車場
+func (s *parkingLotService) GetParkingLotByID(id uint) (*models.ParkingLot, error) {
+	return s.parkingLotRepo.GetParkingLotByID(id)
+}
+
+// LockParkingLot 在資料庫交易中取得並鎖定停車場，直到交易結束前其他進場請求會等待
+func (s *parkingLotService) LockParkingLot(tx *gorm.DB, id uint) (*models.ParkingLot, error) {
+	return s.parkingLotRepo.GetParkingLotByIDForUpdate(tx, id)
+}
+
+// GetAllParkingLots 呼叫 repository 取得所有停車場
+func (s *parkingLotService) GetAllParkingLots() ([]models.ParkingLot, error) {
+	return s.parkingLotRepo.GetAllParkingLots()
+}
+
+// UpdateParkingLot 更新停車場的名稱、地址、容量、費率、開放的車種與營業狀態
+func (s *parkingLotService) UpdateParkingLot(id uint, payload dtos.ParkingLotPayload) (*models.ParkingLot, error) {
+	parkingLot, err := s.parkingLotRepo.GetParkingLotByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parking lot ID %d: %w", id, err)
+	}
+	if parkingLot == nil {
+		return nil, fmt.Errorf("parking lot ID %d not found", id)
+	}
+	if err := s.applyParkingLotPayload(parkingLot, payload); err != nil {
+		return nil, err
+	}
+	if err := s.parkingLotRepo.UpdateParkingLot(parkingLot); err != nil {
+		return nil, fmt.Errorf("error updating parking lot ID %d: %w", id, err)
+	}
+	return parkingLot, nil
+}
+
+// AddParkingLotSensor 為停車場登記出入口感應器，感應器識別碼在所有停車場中不可重複
+func (s *parkingLotService) AddParkingLotSensor(parkingLotID uint, payload dtos.ParkingLotSensorPayload) (*models.ParkingLotSensor, error) {
+	parkingLot, err := s.parkingLotRepo.GetParkingLotByID(parkingLotID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parking lot ID %d: %w", parkingLotID, err)
+	}
+	if parkingLot == nil {
+		return nil, fmt.Errorf("parking lot ID %d not found", parkingLotID)
+	}
+
+	sensorID := strings.TrimSpace(payload.SensorID)
+	if sensorID == "" {
+		return nil, errors.New("invalid_sensor: sensorId must not be empty.")
+	}
+	existing, err := s.parkingLotRepo.GetParkingLotSensorByID(sensorID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking sensor %s: %w", sensorID, err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("sensor_exists: Sensor %s is already registered to parking lot ID %d.", sensorID, existing.ParkingLotID)
+	}
+
+	sensor := &models.ParkingLotSensor{
+		SensorID:     sensorID,
+		ParkingLotID: parkingLotID,
+		Direction:    payload.Direction,
+		VehicleClass: payload.VehicleClass,
+	}
+	if err := s.parkingLotRepo.CreateParkingLotSensor(sensor); err != nil {
+		return nil, fmt.Errorf("error creating sensor %s: %w", sensorID, err)
+	}
+	return sensor, nil
+}
+
+// GetParkingLotSensor 呼叫 repository 透過識別碼取得出入口感應器
+func (s *parkingLotService) GetParkingLotSensor(sensorID string) (*models.ParkingLotSensor, error) {
+	return s.parkingLotRepo.GetParkingLotSensorByID(sensorID)
+}
+
+// RemoveParkingLotSensor 移除停車場的感應器
+func (s *parkingLotService) RemoveParkingLotSensor(parkingLotID uint, sensorID string) error {
+	sensor, err := s.parkingLotRepo.GetParkingLotSensorByID(sensorID)
+	if err != nil {
+		return fmt.Errorf("error finding sensor %s: %w", sensorID, err)
+	}
+	if sensor == nil || sensor.ParkingLotID != parkingLotID {
+		return fmt.Errorf("sensor %s of parking lot ID %d not found", sensorID, parkingLotID)
+	}
+	return s.parkingLotRepo.DeleteParkingLotSensor(sensorID)
+}
+
+// ResolveParkingLot 決定車輛進出的停車場
+// 有指定感應器時以感應器所屬的停車場為準，並須與 parkingLotID (若有指定) 一致；
+// 都未指定時，僅有一個停車場的部署會直接使用該停車場
+func (s *parkingLotService) ResolveParkingLot(parkingLotID uint, sensorID string, direction string) (*models.ParkingLot, error) {
+	if sensorID != "" {
+		sensor, err := s.parkingLotRepo.GetParkingLotSensorByID(sensorID)
+		if err != nil {
+			return nil, fmt.Errorf("error finding sensor %s: %w", sensorID, err)
+		}
+		if sensor == nil {
+			return nil, fmt.Errorf("sensor %s not found", sensorID)
+		}
+		if sensor.Direction != direction {
+			return nil, fmt.Errorf("invalid_sensor: Sensor %s is an %s sensor, not an %s sensor.", sensorID, sensor.Direction, direction)
+		}
+		if parkingLotID != 0 && parkingLotID != sensor.ParkingLotID {
+			return nil, fmt.Errorf("invalid_sensor: Sensor %s belongs to parking lot ID %d, not parking lot ID %d.", sensorID, sensor.ParkingLotID, parkingLotID)
+		}
+		parkingLotID = sensor.ParkingLotID
+	}
+
+	if parkingLotID == 0 {
+		parkingLots, err := s.parkingLotRepo.GetAllParkingLots()
+		if err != nil {
+			return nil, fmt.Errorf("error getting parking lots: %w", err)
+		}
+		if len(parkingLots) != 1 {
+			return nil, fmt.Errorf("parking_lot_required: Specify parkingLotId or a registered sensorId; there are %d parking lots.", len(parkingLots))
+		}
+		return &parkingLots[0], nil
+	}
+
+	parkingLot, err := s.parkingLotRepo.GetParkingLotByID(parkingLotID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parking lot ID %d: %w", parkingLotID, err)
+	}
+	if parkingLot == nil {
+		return nil, fmt.Errorf("parking lot ID %d not found", parkingLotID)
+	}
+	return parkingLot, nil
+}
+
+// CreateZone 為停車場新增分區，分區名稱在同一停車場內不可重複
+func (s *parkingLotService) CreateZone(parkingLotID uint, payload dtos.ZonePayload) (*models.Zone, error) {
+	parkingLot, err := s.parkingLotRepo.GetParkingLotByID(parkingLotID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parking lot ID %d: %w", parkingLotID, err)
+	}
+	if parkingLot == nil {
+		return nil, fmt.Errorf("parking lot ID %d not found", parkingLotID)
+	}
+
+	zone := &models.Zone{ParkingLotID: parkingLotID}
+	if err := s.applyZonePayload(zone, payload); err != nil {
+		return nil, err
+	}
+	if err := s.parkingLotRepo.CreateZone(zone); err != nil {
+		return nil, fmt.Errorf("error creating zone: %w", err)
+	}
+	zone.Spots = []models.Spot{}
+	return zone, nil
+}
+
+// GetZonesByParkingLotID 取得停車場的所有分區與其車位
+func (s *parkingLotService) GetZonesByParkingLotID(parkingLotID uint) ([]models.Zone, error) {
+	parkingLot, err := s.parkingLotRepo.GetParkingLotByID(parkingLotID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parking lot ID %d: %w", parkingLotID, err)
+	}
+	if parkingLot == nil {
+		return nil, fmt.Errorf("parking lot ID %d not found", parkingLotID)
+	}
+	return s.parkingLotRepo.GetZonesByParkingLotID(parkingLotID)
+}
+
+// GetAllZones 呼叫 repository 取得所有停車場的分區與其車位
+func (s *parkingLotService) GetAllZones() ([]models.Zone, error) {
+	return s.parkingLotRepo.GetAllZones()
+}
+
+// UpdateZone 更新分區的名稱、樓層與說明
+func (s *parkingLotService) UpdateZone(parkingLotID uint, zoneID uint, payload dtos.ZonePayload) (*models.Zone, error) {
+	zone, err := s.findZone(parkingLotID, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyZonePayload(zone, payload); err != nil {
+		return nil, err
+	}
+	if err := s.parkingLotRepo.UpdateZone(zone); err != nil {
+		return nil, fmt.Errorf("error updating zone ID %d: %w", zoneID, err)
+	}
+	return zone, nil
+}
+
+// DeleteZone 刪除分區與其車位，曾停放於這些車位的停車記錄仍保留原車位 ID
+func (s *parkingLotService) DeleteZone(parkingLotID uint, zoneID uint) error {
+	if _, err := s.findZone(parkingLotID, zoneID); err != nil {
+		return err
+	}
+	return s.parkingLotRepo.DeleteZone(zoneID)
+}
+
+// AddSpot 為分區新增車位，車位感應器識別碼在所有車位中不可重複
+func (s *parkingLotService) AddSpot(parkingLotID uint, zoneID uint, payload dtos.SpotPayload) (*models.Spot, error) {
+	if _, err := s.findZone(parkingLotID, zoneID); err != nil {
+		return nil, err
+	}
+
+	code := strings.TrimSpace(payload.Code)
+	sensorID := strings.TrimSpace(payload.SensorID)
+	if code == "" || sensorID == "" {
+		return nil, errors.New("invalid_spot: code and sensorId must not be empty.")
+	}
+	existing, err := s.parkingLotRepo.GetSpotByCode(zoneID, code)
+	if err != nil {
+		return nil, fmt.Errorf("error checking spot %s: %w", code, err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("spot_exists: Spot %s already exists in zone ID %d.", code, zoneID)
+	}
+	existing, err = s.parkingLotRepo.GetSpotBySensorID(sensorID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking spot sensor %s: %w", sensorID, err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("sensor_exists: Spot sensor %s is already registered to spot ID %d.", sensorID, existing.SpotID)
+	}
+
+	spot := &models.Spot{
+		ZoneID:   zoneID,
+		Code:     code,
+		SensorID: sensorID,
+	}
+	if err := s.parkingLotRepo.CreateSpot(spot); err != nil {
+		return nil, fmt.Errorf("error creating spot %s: %w", code, err)
+	}
+	return spot, nil
+}
+
+// RemoveSpot 移除分區的車位
+func (s *parkingLotService) RemoveSpot(parkingLotID uint, zoneID uint, spotID uint) error {
+	if _, err := s.findZone(parkingLotID, zoneID); err != nil {
+		return err
+	}
+	spot, err := s.parkingLotRepo.GetSpotByID(spotID)
+	if err != nil {
+		return fmt.Errorf("error finding spot ID %d: %w", spotID, err)
+	}
+	if spot == nil || spot.ZoneID != zoneID {
+		return fmt.Errorf("spot ID %d of zone ID %d not found", spotID, zoneID)
+	}
+	return s.parkingLotRepo.DeleteSpot(spotID)
+}
+
+// UpdateSpotStatus 依車位感應器的回報更新車位是否有車，並回傳車位與其所屬分區
+// 狀態未改變時不更新 StatusChangedAt，感應器可重複回報相同狀態
+func (s *parkingLotService) UpdateSpotStatus(sensorID string, occupied bool) (*models.Spot, *models.Zone, error) {
+	spot, err := s.parkingLotRepo.GetSpotBySensorID(sensorID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding spot sensor %s: %w", sensorID, err)
+	}
+	if spot == nil {
+		return nil, nil, fmt.Errorf("spot sensor %s not found", sensorID)
+	}
+	zone, err := s.parkingLotRepo.GetZoneByID(spot.ZoneID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding zone ID %d: %w", spot.ZoneID, err)
+	}
+	if zone == nil {
+		return nil, nil, fmt.Errorf("zone ID %d of spot sensor %s not found", spot.ZoneID, sensorID)
+	}
+
+	if spot.Occupied != occupied || spot.StatusChangedAt == nil {
+		now := time.Now()
+		spot.Occupied = occupied
+		spot.StatusChangedAt = &now
+		if err := s.parkingLotRepo.UpdateSpot(spot); err != nil {
+			return nil, nil, fmt.Errorf("error updating spot ID %d: %w", spot.SpotID, err)
+		}
+	}
+	return spot, zone, nil
+}
+
+// TariffForParkingLot 回傳停車場該車種使用的費率行事曆所對應的 Tariff
+// 停車場已不再開放該車種時 (例如車輛進場後才調整設定)，改用該車種預設的費率行事曆
+func (s *parkingLotService) TariffForParkingLot(parkingLotID uint, vehicleClass string) (Tariff, error) {
+	parkingLot, err := s.parkingLotRepo.GetParkingLotByID(parkingLotID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parking lot ID %d: %w", parkingLotID, err)
+	}
+	if parkingLot == nil {
+		return nil, fmt.Errorf("parking lot ID %d not found", parkingLotID)
+	}
+	if vehicleClass == "" {
+		vehicleClass = models.VehicleClassCar
+	}
+	rateCalendar := parkingLot.RateCalendarFor(vehicleClass)
+	if rateCalendar == "" {
+		rateCalendar = configs.DefaultVehicleClassRateCalendars[vehicleClass]
+	}
+	tariff, ok := s.tariffs[rateCalendar]
+	if !ok {
+		return nil, fmt.Errorf("rate calendar %q for %s of parking lot ID %d is not configured", rateCalendar, vehicleClass, parkingLotID)
+	}
+	return tariff, nil
+}
+
+// applyParkingLotPayload 驗證並將 payload 的內容寫入 parkingLot
+func (s *parkingLotService) applyParkingLotPayload(parkingLot *models.ParkingLot, payload dtos.ParkingLotPayload) error {
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		return errors.New("invalid_parking_lot: name must not be empty.")
+	}
+	if payload.Capacity <= 0 {
+		return errors.New("invalid_parking_lot: capacity must be greater than 0.")
+	}
+	if payload.ReservedSpots < 0 || payload.ReservedSpots > payload.Capacity {
+		return errors.New("invalid_parking_lot: reservedSpots must be between 0 and capacity.")
+	}
+	rateCalendar := payload.RateCalendar
+	if rateCalendar == "" {
+		rateCalendar = configs.DefaultRateCalendarName
+	}
+	if _, ok := s.tariffs[rateCalendar]; !ok {
+		return fmt.Errorf("invalid_parking_lot: Unknown rate calendar %q.", rateCalendar)
+	}
+
+	var vehicleClasses []models.ParkingLotVehicleClass
+	if payload.VehicleClasses != nil {
+		vehicleClasses = make([]models.ParkingLotVehicleClass, 0, len(payload.VehicleClasses))
+		seen := make(map[string]bool, len(payload.VehicleClasses))
+		for _, class := range payload.VehicleClasses {
+			if class.VehicleClass == models.VehicleClassCar || !models.IsValidVehicleClass(class.VehicleClass) {
+				return fmt.Errorf("invalid_parking_lot: vehicleClasses only lists %s and %s; cars use capacity.", models.VehicleClassMotorcycle, models.VehicleClassLarge)
+			}
+			if seen[class.VehicleClass] {
+				return fmt.Errorf("invalid_parking_lot: Vehicle class %s is listed more than once.", class.VehicleClass)
+			}
+			seen[class.VehicleClass] = true
+			if class.Capacity < 0 {
+				return fmt.Errorf("invalid_parking_lot: capacity of %s must not be negative.", class.VehicleClass)
+			}
+			classRateCalendar := class.RateCalendar
+			if classRateCalendar == "" {
+				classRateCalendar = configs.DefaultVehicleClassRateCalendars[class.VehicleClass]
+			}
+			if _, ok := s.tariffs[classRateCalendar]; !ok {
+				return fmt.Errorf("invalid_parking_lot: Unknown rate calendar %q for %s.", classRateCalendar, class.VehicleClass)
+			}
+			vehicleClasses = append(vehicleClasses, models.ParkingLotVehicleClass{
+				ParkingLotID: parkingLot.ParkingLotID,
+				VehicleClass: class.VehicleClass,
+				Capacity:     class.Capacity,
+				RateCalendar: classRateCalendar,
+			})
+		}
+	}
+
+	existing, err := s.parkingLotRepo.GetParkingLotByName(name)
+	if err != nil {
+		return fmt.Errorf("error checking parking lot name %s: %w", name, err)
+	}
+	if existing != nil && existing.ParkingLotID != parkingLot.ParkingLotID {
+		return fmt.Errorf("parking_lot_exists: A parking lot named %s already exists.", name)
+	}
+
+	parkingLot.Name = name
+	parkingLot.Address = strings.TrimSpace(payload.Address)
+	parkingLot.Capacity = payload.Capacity
+	parkingLot.ReservedSpots = payload.ReservedSpots
+	parkingLot.RateCalendar = rateCalendar
+	if payload.Active != nil {
+		parkingLot.Active = *payload.Active
+	}
+	if vehicleClasses != nil {
+		parkingLot.VehicleClasses = vehicleClasses
+	}
+	return nil
+}
+
+// findZone 取得停車場內的分區，分區不屬於該停車場時視為不存在
+func (s *parkingLotService) findZone(parkingLotID uint, zoneID uint) (*models.Zone, error) {
+	zone, err := s.parkingLotRepo.GetZoneByID(zoneID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding zone ID %d: %w", zoneID, err)
+	}
+	if zone == nil || zone.ParkingLotID != parkingLotID {
+		return nil, fmt.Errorf("zone ID %d of parking lot ID %d not found", zoneID, parkingLotID)
+	}
+	return zone, nil
+}
+
+// applyZonePayload 驗證並將 payload 的內容寫入 zone
+func (s *parkingLotService) applyZonePayload(zone *models.Zone, payload dtos.ZonePayload) error {
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		return errors.New("invalid_zone: name must not be empty.")
+	}
+
+	existing, err := s.parkingLotRepo.GetZoneByName(zone.ParkingLotID, name)
+	if err != nil {
+		return fmt.Errorf("error checking zone name %s: %w", name, err)
+	}
+	if existing != nil && existing.ZoneID != zone.ZoneID {
+		return fmt.Errorf("zone_exists: Parking lot ID %d already has a zone named %s.", zone.ParkingLotID, name)
+	}
+
+	zone.Name = name
+	zone.Level = payload.Level
+	zone.Description = strings.TrimSpace(payload.Description)
+	return nil
+}
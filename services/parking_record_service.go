@@ -3,10 +3,14 @@ package services
 import (
 	"errors"
 	"fmt"
+	"hello-professor_backend/blobstore"
 	"hello-professor_backend/configs"
 	"hello-professor_backend/dtos"
+	"hello-professor_backend/licenseplate"
 	"hello-professor_backend/models"
 	"hello-professor_backend/repositories"
+	"log"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,43 +21,88 @@ import (
 type ParkingRecordService interface {
 	CreateParkingRecord(parkingRecord *models.ParkingRecord) error
 	GetParkingRecordByID(id uint) (*models.ParkingRecord, error)
+	GetParkingRecordImage(id uint, kind string) (*blobstore.Object, error)
 	GetParkingRecordsByLicensePlate(licensePlate string) ([]models.ParkingRecord, error)
 	SearchParkingRecordsByLicensePlate(licensePlateQuery string) ([]models.ParkingRecord, error)
 	UpdateParkingRecord(tx *gorm.DB, parkingRecord *models.ParkingRecord) error
 	DeleteParkingRecord(id uint) error
 	GetAllParkingRecords(limit int, offset int) ([]models.ParkingRecord, error)
 	GetLatestParkingRecordByLicensePlate(licensePlate string) (*models.ParkingRecord, error)
-	RecordVehicleEntry(licensePlate string, sensorEntryID string, image *string) (*models.ParkingRecord, error)
-	RecordSimpleVehicleEntry(licensePlate string, image *string) (*models.ParkingRecord, error)
-	RecordVehicleExit(licensePlate string) (*models.ParkingRecord, error)
+	RecordVehicleEntry(licensePlate string, parkingLotID uint, sensorEntryID string, vehicleClass string, image []byte, override *dtos.CapacityOverride, ocr *dtos.OcrReading) (*models.ParkingRecord, error)
+	RecordSimpleVehicleEntry(licensePlate string, parkingLotID uint, sensorID string, vehicleClass string, image []byte, override *dtos.CapacityOverride, ocr *dtos.OcrReading) (*models.ParkingRecord, error)
+	RecordVehicleExit(licensePlate string, parkingLotID uint, sensorID string, options dtos.ExitMatchOptions) (*models.ParkingRecord, *dtos.FeeQuote, []dtos.ExitCandidate, error)
 	UpdateUserVerifiedLicensePlate(recordID uint, verifiedLicensePlate string) (*models.ParkingRecord, error)
-	PrepareParkingRecordForPayment(recordID uint) (*models.ParkingRecord, error)
-	PayForParkingRecord(recordID uint, paymentPayload dtos.ParkingPaymentPayload) (*models.ParkingRecord, *models.Transaction, error)
-	GetTotalParkingCount(startTime, endTime *time.Time) (int64, error)
-	GetTotalRevenue(startTime, endTime *time.Time) (float64, error)
-	GetImageAttachmentRate(startTime, endTime *time.Time) (*dtos.ImageAttachmentRateResponse, error)
-	GetAvailableParkingSpots() (*dtos.AvailableSpotsResponse, error)
+	RecordSpotStatus(sensorID string, occupied bool, licensePlate string) (*dtos.SpotStatusResponse, error)
+	PrepareParkingRecordForPayment(recordID uint) (*dtos.ParkingRecordWithFeeBreakdownResponse, error)
+	GetParkingFeeQuote(recordID uint) (*dtos.FeeQuote, error)
+	GetParkingFeeQuoteByLicensePlate(licensePlate string) (*dtos.FeeQuote, error)
+	PayForParkingRecord(recordID uint, paymentPayload dtos.ParkingPaymentPayload) (*models.ParkingRecord, *models.Transaction, *models.PaymentIntent, error)
+	FlagStaleParkingRecords() error
+	GetStaleParkingRecords(parkingLotID *uint, limit int, offset int) ([]models.ParkingRecord, error)
+	AbandonParkingRecord(recordID uint, payload dtos.AbandonParkingRecordPayload) (*models.ParkingRecord, error)
+	// 報表方法的 parkingLotID 為 nil 時統計所有停車場
+	GetTotalParkingCount(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (*dtos.TotalParkingCountResponse, error)
+	GetTotalRevenue(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (*dtos.TotalRevenueResponse, error)
+	GetImageAttachmentRate(parkingLotID *uint, startTime, endTime *time.Time) (*dtos.ImageAttachmentRateResponse, error)
+	GetAvailableParkingSpots(parkingLotID *uint, vehicleClass *string) (*dtos.AvailableSpotsResponse, error)
+	GetSignboard(parkingLotID uint) (*dtos.SignboardResponse, error)
 }
 
 // parkingRecordService 是 ParkingRecordService 的實作
 type parkingRecordService struct {
-	parkingRecordRepo  repositories.ParkingRecordRepository
-	transactionService TransactionService
-	db                 *gorm.DB
+	parkingRecordRepo    repositories.ParkingRecordRepository
+	transactionService   TransactionService
+	parkingLotService    ParkingLotService
+	paymentIntentService PaymentIntentService
+	validationService    ValidationService
+	permitService        PermitService
+	reservationService   ReservationService
+	chargingService      ChargingService
+	plateReviewService   PlateReviewService
+	watchlistService     WatchlistService
+	vehicleService       VehicleService
+	imageService         ImageService
+	db                   *gorm.DB
 }
 
 // NewParkingRecordService 建立一個新的 ParkingRecordService 實例
-func NewParkingRecordService(prRepo repositories.ParkingRecordRepository, ts TransactionService, db *gorm.DB) ParkingRecordService {
+func NewParkingRecordService(prRepo repositories.ParkingRecordRepository, ts TransactionService, pls ParkingLotService, pis PaymentIntentService, vs ValidationService, ps PermitService, rs ReservationService, cs ChargingService, prs PlateReviewService, ws WatchlistService, vhs VehicleService, is ImageService, db *gorm.DB) ParkingRecordService {
 	return &parkingRecordService{
-		parkingRecordRepo:  prRepo,
-		transactionService: ts,
-		db:                 db,
+		parkingRecordRepo:    prRepo,
+		transactionService:   ts,
+		parkingLotService:    pls,
+		paymentIntentService: pis,
+		validationService:    vs,
+		permitService:        ps,
+		reservationService:   rs,
+		chargingService:      cs,
+		plateReviewService:   prs,
+		watchlistService:     ws,
+		vehicleService:       vhs,
+		imageService:         is,
+		db:                   db,
 	}
 }
 
-// CreateParkingRecord 呼叫 repository 來新增停車記錄
+// CreateParkingRecord 呼叫 repository 來新增停車記錄，未指定停車場時使用唯一的停車場
 func (s *parkingRecordService) CreateParkingRecord(parkingRecord *models.ParkingRecord) error {
-	return s.parkingRecordRepo.CreateParkingRecord(parkingRecord)
+	if parkingRecord.ParkingLotID == 0 {
+		parkingLot, err := s.parkingLotService.ResolveParkingLot(0, "", models.SensorDirectionEntry)
+		if err != nil {
+			return err
+		}
+		parkingRecord.ParkingLotID = parkingLot.ParkingLotID
+	}
+	if parkingRecord.VehicleClass == "" {
+		parkingRecord.VehicleClass = models.VehicleClassCar
+	}
+	if !models.IsValidVehicleClass(parkingRecord.VehicleClass) {
+		return fmt.Errorf("invalid_vehicle_class: Unknown vehicle class %q.", parkingRecord.VehicleClass)
+	}
+	if parkingRecord.ExitTime != nil {
+		parkingRecord.SessionStatus = models.SessionStatusExited
+	}
+	return s.parkingRecordRepo.CreateParkingRecord(nil, parkingRecord)
 }
 
 // GetParkingRecordByID 呼叫 repository 透過 ID 取得停車記錄
@@ -61,6 +110,37 @@ func (s *parkingRecordService) GetParkingRecordByID(id uint) (*models.ParkingRec
 	return s.parkingRecordRepo.GetParkingRecordByID(id)
 }
 
+// GetParkingRecordImage 讀取停車記錄的進場影像，kind 為 models.ImageKindOriginal 或 models.ImageKindThumbnail
+// 沒有縮圖 (例如 WebP 影像) 時，縮圖以原始影像代替
+func (s *parkingRecordService) GetParkingRecordImage(id uint, kind string) (*blobstore.Object, error) {
+	if kind != models.ImageKindOriginal && kind != models.ImageKindThumbnail {
+		return nil, fmt.Errorf("invalid_image_kind: Unknown image kind %q; must be %s or %s.", kind, models.ImageKindOriginal, models.ImageKindThumbnail)
+	}
+	record, err := s.parkingRecordRepo.GetParkingRecordByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parking record ID %d: %w", id, err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("parking record ID %d not found", id)
+	}
+	if record.ImageKey == nil {
+		return nil, fmt.Errorf("image of parking record ID %d not found", id)
+	}
+
+	key := *record.ImageKey
+	if kind == models.ImageKindThumbnail && record.ThumbnailKey != nil {
+		key = *record.ThumbnailKey
+	}
+	object, err := s.imageService.GetImage(key)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return nil, fmt.Errorf("%s image %s of parking record ID %d not found in the image store", kind, key, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s image of parking record ID %d: %w", kind, id, err)
+	}
+	return object, nil
+}
+
 // GetParkingRecordsByLicensePlate 呼叫 repository 透過 LicensePlate 取得相關的所有停車記錄
 func (s *parkingRecordService) GetParkingRecordsByLicensePlate(licensePlate string) ([]models.ParkingRecord, error) {
 	return s.parkingRecordRepo.GetParkingRecordsByLicensePlate(licensePlate)
@@ -91,87 +171,495 @@ func (s *parkingRecordService) GetLatestParkingRecordByLicensePlate(licensePlate
 	return s.parkingRecordRepo.GetLatestParkingRecordByLicensePlate(licensePlate)
 }
 
-// RecordVehicleEntry 記錄車輛進場
-func (s *parkingRecordService) RecordVehicleEntry(licensePlate string, sensorEntryID string, image *string) (*models.ParkingRecord, error) {
+// RecordVehicleEntry 記錄車輛進入指定的停車場，vehicleClass 為空字串時視為汽車
+// 車位依車種分別計算，停車場該車種客滿時拒絕進場；保留的汽車車位只供定期停車證持有者使用，預約時段內保留的車位只供預約車輛使用。override 不為 nil 時，客滿仍會放行並記錄操作人員與原因
+// 預約車輛於抵達時段內進場時，停車記錄會連結該預約，預約的車位即為此車輛的車位
+// 同一停車場的進場會鎖定停車場資料列依序處理，避免同時進場超出容量
+// 車牌會轉為標準格式後記錄，OCR 回傳的原始字串另外保留；ocr 不為 nil 且信心分數低於門檻時，停車記錄會排入車牌人工審核
+// 停車記錄會連結到車牌的車輛資料，第一次進場的車牌會自動建立車輛資料
+// 同一車牌尚有未出場的停車記錄時，視為前次未經出口離場，將其結束為 Abandoned 後再進場，兩筆停車記錄都保留；前次進場在 configs.DuplicateEntryWindow 內時視為入口重複辨識，回傳車輛已在場內
+// 同一車牌的進場以車輛資料列序列化，取得車輛資料後會在交易中重新讀取未出場的停車記錄，同時進場的車牌只會有一筆成立
+// 車牌符合監控名單時產生警示；處理方式為 DenyEntry 且車牌相同 (或只差在易混淆字元) 時拒絕進場，為 Flag 時標記停車記錄供現場人員處理
+// image 不為 nil 時，影像存放到影像儲存空間，停車記錄只保存其 key；進場失敗時會刪除已存放的影像
+func (s *parkingRecordService) RecordVehicleEntry(licensePlate string, parkingLotID uint, sensorEntryID string, vehicleClass string, image []byte, override *dtos.CapacityOverride, ocr *dtos.OcrReading) (newRecord *models.ParkingRecord, err error) {
+	rawLicensePlate := licensePlate
+	licensePlate = licenseplate.Normalize(licensePlate)
+	if licensePlate == "" {
+		return nil, errors.New("invalid_license_plate: License plate is required.")
+	}
+	if vehicleClass == "" {
+		vehicleClass = models.VehicleClassCar
+	}
+	if !models.IsValidVehicleClass(vehicleClass) {
+		return nil, fmt.Errorf("invalid_vehicle_class: Unknown vehicle class %q.", vehicleClass)
+	}
+	if override != nil && (override.OverriddenBy == "" || override.Reason == "") {
+		return nil, errors.New("invalid_override: Overriding capacity requires the operator and a reason.")
+	}
+
+	now := time.Now()
 	latestRecord, err := s.parkingRecordRepo.GetLatestParkingRecordByLicensePlate(licensePlate)
 	if err != nil {
 		return nil, fmt.Errorf("error checking for existing record: %w", err)
 	}
-	if latestRecord != nil && latestRecord.ExitTime == nil {
+	if latestRecord != nil && latestRecord.ExitTime == nil && now.Sub(latestRecord.EntryTime) < configs.DuplicateEntryWindow {
 		return latestRecord, errors.New("vehicle already in parking lot")
 	}
 
-	now := time.Now()
-	newRecord := &models.ParkingRecord{
-		LicensePlate:  licensePlate,
-		EntryTime:     now,
-		SensorEntryID: sensorEntryID,
-		PaymentStatus: "Pending",
-		Image:         image,
+	watchlistHits, err := s.watchlistService.CheckLicensePlates(now, licensePlate)
+	if err != nil {
+		return nil, err
 	}
+	for _, hit := range watchlistHits {
+		if !hit.DeniesEntry() {
+			continue
+		}
+		// 拒絕進場沒有停車記錄，警示直接寫入並推送
+		deniedAlerts, alertErr := s.watchlistService.RecordAlerts(nil, watchlistHits, models.WatchlistEventEntryDenied, parkingLotID, nil)
+		if alertErr != nil {
+			return nil, alertErr
+		}
+		s.watchlistService.PublishAlerts(deniedAlerts)
+		return nil, fmt.Errorf("entry_denied: License plate %s is on the watchlist (%s) and may not enter.", licensePlate, watchlistReasons([]WatchlistHit{hit}))
+	}
+
+	// 持有有效定期停車證的車輛，進場時即標記所使用的停車證
+	permit, err := s.permitService.FindActivePermit(licensePlate, parkingLotID, now)
+	if err != nil {
+		return nil, fmt.Errorf("error checking permit for license plate %s: %w", licensePlate, err)
+	}
+
+	// 影像在交易外存放，避免上傳到儲存空間的時間佔用停車場的鎖
+	var storedImage *StoredImage
+	if image != nil {
+		if storedImage, err = s.imageService.StoreParkingRecordImage(image, now); err != nil {
+			return nil, err
+		}
+	}
+	discardImage := func() {
+		if storedImage == nil {
+			return
+		}
+		keys := []string{storedImage.Key}
+		if storedImage.ThumbnailKey != nil {
+			keys = append(keys, *storedImage.ThumbnailKey)
+		}
+		for _, key := range keys {
+			if deleteErr := s.imageService.DeleteImage(key); deleteErr != nil {
+				log.Printf("[RecordVehicleEntry] Failed to delete image of rejected entry: %v", deleteErr)
+			}
+		}
+	}
+
+	var watchlistAlerts []models.WatchlistAlert
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		discardImage()
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			discardImage()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+			discardImage()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+			discardImage()
+		} else {
+			// 進場成功後才推送警示，避免操作台收到未成立的停車記錄
+			s.watchlistService.PublishAlerts(watchlistAlerts)
+		}
+	}()
 
-	err = s.parkingRecordRepo.CreateParkingRecord(newRecord)
+	parkingLot, err := s.parkingLotService.LockParkingLot(tx, parkingLotID)
 	if err != nil {
+		return nil, fmt.Errorf("error finding parking lot ID %d: %w", parkingLotID, err)
+	}
+	if parkingLot == nil {
+		return nil, fmt.Errorf("parking lot ID %d not found", parkingLotID)
+	}
+	if !parkingLot.Active {
+		return nil, fmt.Errorf("parking_lot_inactive: Parking lot ID %d is not accepting vehicles.", parkingLotID)
+	}
+	capacity := parkingLot.CapacityFor(vehicleClass)
+	if vehicleClass != models.VehicleClassCar && parkingLot.RateCalendarFor(vehicleClass) == "" {
+		return nil, fmt.Errorf("vehicle_class_not_accepted: Parking lot ID %d does not accept %s.", parkingLotID, vehicleClass)
+	}
+
+	// 車輛資料列在交易結束前保持鎖定，同一車牌在其他停車場同時進場時會在此等待
+	vehicle, err := s.vehicleService.FindOrCreateVehicle(tx, licensePlate, now)
+	if err != nil {
+		return nil, err
+	}
+	openRecord, err := s.parkingRecordRepo.GetLatestParkingRecordByLicensePlateForUpdate(tx, licensePlate)
+	if err != nil {
+		return nil, fmt.Errorf("error checking for existing record: %w", err)
+	}
+	if openRecord != nil && now.Sub(openRecord.EntryTime) < configs.DuplicateEntryWindow {
+		err = errors.New("vehicle already in parking lot")
+		return openRecord, err
+	}
+
+	var abandonedRecord *models.ParkingRecord
+	if openRecord != nil {
+		reason := fmt.Sprintf("Vehicle re-entered parking lot ID %d without exiting", parkingLotID)
+		if abandonedRecord, err = s.abandonOpenParkingRecord(tx, openRecord.RecordID, now, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	reservation, err := s.reservationService.FindArrivingReservation(tx, parkingLotID, licensePlate, vehicleClass, now)
+	if err != nil {
+		return nil, err
+	}
+
+	newRecord = &models.ParkingRecord{
+		ParkingLotID:    parkingLotID,
+		VehicleID:       &vehicle.VehicleID,
+		VehicleClass:    vehicleClass,
+		LicensePlate:    licensePlate,
+		RawLicensePlate: rawLicensePlate,
+		EntryTime:       now,
+		SensorEntryID:   sensorEntryID,
+		PaymentStatus:   "Pending",
+	}
+	if storedImage != nil {
+		newRecord.ImageKey = &storedImage.Key
+		newRecord.ThumbnailKey = storedImage.ThumbnailKey
+	}
+	if permit != nil {
+		newRecord.PermitID = &permit.PermitID
+	}
+	if ocr != nil {
+		newRecord.OcrConfidence = &ocr.Confidence
+	}
+	var flagHits []WatchlistHit
+	for _, hit := range watchlistHits {
+		if hit.Entry.Action == models.WatchlistActionFlag {
+			flagHits = append(flagHits, hit)
+		}
+	}
+	if len(flagHits) > 0 {
+		flagReason := watchlistReasons(flagHits)
+		newRecord.FlagReason = &flagReason
+	}
+	excludeLicensePlate := ""
+	if reservation != nil {
+		newRecord.ReservationID = &reservation.ReservationID
+		excludeLicensePlate = licensePlate
+	}
+
+	occupiedSpots, err := s.parkingRecordRepo.CountActiveParkingRecords(tx, parkingLotID, vehicleClass)
+	if err != nil {
+		return nil, fmt.Errorf("error counting vehicles in parking lot ID %d: %w", parkingLotID, err)
+	}
+	heldSpots, err := s.reservationService.HeldSpots(tx, parkingLotID, vehicleClass, now, excludeLicensePlate)
+	if err != nil {
+		return nil, err
+	}
+	reservedSpots := reservedSpotsFor(*parkingLot, vehicleClass)
+	admittableSpots := int64(capacity) - heldSpots
+	if permit == nil {
+		admittableSpots -= int64(reservedSpots)
+	}
+	if occupiedSpots >= admittableSpots {
+		if override == nil {
+			return nil, fmt.Errorf("parking_lot_full: Parking lot ID %d is full for %s: %d of %d spots occupied, %d reserved for permit holders, %d held for reservations.", parkingLotID, vehicleClass, occupiedSpots, capacity, reservedSpots, heldSpots)
+		}
+		log.Printf("[RecordVehicleEntry] Capacity override by %s: admitting %s %s into parking lot ID %d with %d of %d spots occupied. Reason: %s", override.OverriddenBy, vehicleClass, licensePlate, parkingLotID, occupiedSpots, capacity, override.Reason)
+		newRecord.CapacityOverrideBy = &override.OverriddenBy
+		newRecord.CapacityOverrideReason = &override.Reason
+	}
+
+	if err = s.parkingRecordRepo.CreateParkingRecord(tx, newRecord); err != nil {
 		return nil, fmt.Errorf("error creating parking record: %w", err)
 	}
+	if abandonedRecord != nil {
+		if err = tx.Model(abandonedRecord).Update("superseded_by_record_id", newRecord.RecordID).Error; err != nil {
+			return nil, fmt.Errorf("error linking abandoned parking record ID %d: %w", abandonedRecord.RecordID, err)
+		}
+		log.Printf("[RecordVehicleEntry] Parking record ID %d for %s closed as %s; superseded by parking record ID %d", abandonedRecord.RecordID, licensePlate, models.SessionStatusAbandoned, newRecord.RecordID)
+	}
+	if ocr != nil {
+		if _, err = s.plateReviewService.QueuePlateReview(tx, newRecord, *ocr); err != nil {
+			return nil, err
+		}
+	}
+	if watchlistAlerts, err = s.watchlistService.RecordAlerts(tx, watchlistHits, models.WatchlistEventEntry, parkingLotID, &newRecord.RecordID); err != nil {
+		return nil, err
+	}
+	if reservation != nil {
+		if err = s.reservationService.FulfillReservation(tx, reservation, newRecord.RecordID); err != nil {
+			return nil, err
+		}
+	}
 	return newRecord, nil
 }
 
-// RecordSimpleVehicleEntry 記錄車輛簡易進場 (自動使用預設 SensorID)
-func (s *parkingRecordService) RecordSimpleVehicleEntry(licensePlate string, image *string) (*models.ParkingRecord, error) {
+// RecordSimpleVehicleEntry 記錄車輛簡易進場，停車場由 parkingLotID 或已登記的入口感應器決定 (未指定感應器時使用預設 SensorID)
+// 未指定車種時使用入口感應器登記的車種，都沒有時視為汽車
+func (s *parkingRecordService) RecordSimpleVehicleEntry(licensePlate string, parkingLotID uint, sensorID string, vehicleClass string, image []byte, override *dtos.CapacityOverride, ocr *dtos.OcrReading) (*models.ParkingRecord, error) {
 	const simpleEntrySensorID = "SIMPLE_ENTRY_PORTAL"
-	return s.RecordVehicleEntry(licensePlate, simpleEntrySensorID, image)
+
+	parkingLot, err := s.parkingLotService.ResolveParkingLot(parkingLotID, sensorID, models.SensorDirectionEntry)
+	if err != nil {
+		return nil, err
+	}
+	if sensorID == "" {
+		sensorID = simpleEntrySensorID
+	} else if vehicleClass == "" {
+		sensor, err := s.parkingLotService.GetParkingLotSensor(sensorID)
+		if err != nil {
+			return nil, fmt.Errorf("error finding sensor %s: %w", sensorID, err)
+		}
+		if sensor != nil {
+			vehicleClass = sensor.VehicleClass
+		}
+	}
+	return s.RecordVehicleEntry(licensePlate, parkingLot.ParkingLotID, sensorID, vehicleClass, image, override, ocr)
 }
 
-// RecordVehicleExit 記錄車輛出場，並檢查付款狀態
-func (s *parkingRecordService) RecordVehicleExit(licensePlate string) (*models.ParkingRecord, error) {
+// RecordVehicleExit 記錄車輛從指定的停車場出場，並檢查付款狀態
+// 停車場由 parkingLotID 或已登記的出口感應器決定；尚未付款或付款後超過出場寬限期時，會回傳目前的費用試算供出口收費使用
+// 車牌以標準格式比對，出場時 OCR 回傳的原始字串會保留在停車記錄
+// options.FuzzyMatch 為 true 且沒有完全相符的記錄時，改以車牌相似度比對：只有一筆高度相似的記錄時自動採用，否則回傳依相似度排列的候選記錄與 exit_match_ambiguous 錯誤
+// options.ParkingRecordID 不為 0 時，直接採用出口操作人員確認的停車記錄
+// 出場車牌或停車記錄的車牌符合監控名單時產生警示，同一停車記錄重複通過出口不會重複警示
+func (s *parkingRecordService) RecordVehicleExit(licensePlate string, parkingLotID uint, sensorID string, options dtos.ExitMatchOptions) (*models.ParkingRecord, *dtos.FeeQuote, []dtos.ExitCandidate, error) {
 	const defaultExitSensorID = "DEFAULT_EXIT_SENSOR"
 
-	latestRecord, err := s.parkingRecordRepo.GetLatestParkingRecordByLicensePlate(licensePlate)
+	rawLicensePlate := licensePlate
+	licensePlate = licenseplate.Normalize(licensePlate)
+
+	parkingLot, err := s.parkingLotService.ResolveParkingLot(parkingLotID, sensorID, models.SensorDirectionExit)
 	if err != nil {
-		return nil, fmt.Errorf("error finding active parking record for license plate %s: %w", licensePlate, err)
+		return nil, nil, nil, err
 	}
-	if latestRecord == nil {
-		return nil, fmt.Errorf("no active parking record found for license plate %s", licensePlate)
+	if sensorID == "" {
+		sensorID = defaultExitSensorID
+	}
+
+	latestRecord, exitMatch, candidates, err := s.matchExitingVehicle(licensePlate, parkingLot.ParkingLotID, options)
+	if err != nil {
+		return nil, nil, candidates, err
+	}
+	if err := s.alertWatchlistOnExit(latestRecord, licensePlate); err != nil {
+		return nil, nil, nil, err
+	}
+	record, quote, err := s.exitParkingRecord(latestRecord, sensorID, rawLicensePlate, exitMatch)
+	return record, quote, nil, err
+}
+
+// matchExitingVehicle 找出出場車輛在停車場內尚未出場的停車記錄，並回傳比對方式
+func (s *parkingRecordService) matchExitingVehicle(licensePlate string, parkingLotID uint, options dtos.ExitMatchOptions) (*models.ParkingRecord, string, []dtos.ExitCandidate, error) {
+	if options.ParkingRecordID != 0 {
+		record, err := s.parkingRecordRepo.GetParkingRecordByID(options.ParkingRecordID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error finding parking record ID %d: %w", options.ParkingRecordID, err)
+		}
+		if record == nil || record.ExitTime != nil || record.ParkingLotID != parkingLotID {
+			return nil, "", nil, fmt.Errorf("no active parking record ID %d found in parking lot ID %d", options.ParkingRecordID, parkingLotID)
+		}
+		log.Printf("[RecordVehicleExit] Operator confirmed parking record ID %d (%s) for exit plate %s", record.RecordID, record.LicensePlate, licensePlate)
+		return record, models.ExitMatchOperator, nil, nil
+	}
+
+	record, err := s.parkingRecordRepo.GetLatestParkingRecordByLicensePlate(licensePlate)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error finding active parking record for license plate %s: %w", licensePlate, err)
+	}
+	if record != nil && record.ParkingLotID == parkingLotID {
+		return record, models.ExitMatchExact, nil, nil
+	}
+	if !options.FuzzyMatch {
+		return nil, "", nil, fmt.Errorf("no active parking record found for license plate %s in parking lot ID %d", licensePlate, parkingLotID)
+	}
+
+	matches, err := s.parkingRecordRepo.FindSimilarActiveParkingRecords(parkingLotID, licensePlate, configs.ExitMatchMinSimilarity, configs.ExitMatchMaxCandidates)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error finding similar parking records for license plate %s: %w", licensePlate, err)
+	}
+	if len(matches) == 0 {
+		return nil, "", nil, fmt.Errorf("no active parking record found for license plate %s or a similar plate in parking lot ID %d", licensePlate, parkingLotID)
+	}
+
+	// 只有最相似的記錄相似度夠高，且明顯領先其他候選記錄時才自動採用
+	best := matches[0]
+	if best.Similarity >= configs.ExitMatchAutoAcceptSimilarity && (len(matches) == 1 || best.Similarity-matches[1].Similarity >= configs.ExitMatchAutoAcceptMargin) {
+		log.Printf("[RecordVehicleExit] Fuzzy matched exit plate %s to parking record ID %d (%s) with similarity %.2f", licensePlate, best.Record.RecordID, best.Record.LicensePlate, best.Similarity)
+		return &best.Record, models.ExitMatchFuzzy, nil, nil
+	}
+
+	candidates := make([]dtos.ExitCandidate, 0, len(matches))
+	for _, match := range matches {
+		candidates = append(candidates, dtos.ExitCandidate{
+			ParkingRecordID:          match.Record.RecordID,
+			LicensePlate:             match.Record.LicensePlate,
+			UserVerifiedLicensePlate: match.Record.UserVerifiedLicensePlate,
+			EntryTime:                match.Record.EntryTime,
+			ImageURL:                 match.Record.ImageURL,
+			ThumbnailURL:             match.Record.ThumbnailURL,
+			Similarity:               match.Similarity,
+		})
+	}
+	return nil, "", candidates, fmt.Errorf("exit_match_ambiguous: License plate %s has no exact match in parking lot ID %d; %d similar active parking records need confirmation by the gate operator.", licensePlate, parkingLotID, len(candidates))
+}
+
+// alertWatchlistOnExit 比對出場車牌與停車記錄的車牌是否符合監控名單，符合時產生並推送警示
+func (s *parkingRecordService) alertWatchlistOnExit(record *models.ParkingRecord, licensePlate string) error {
+	licensePlates := []string{licensePlate}
+	if record.LicensePlate != licensePlate {
+		licensePlates = append(licensePlates, record.LicensePlate)
+	}
+	if record.UserVerifiedLicensePlate != nil && *record.UserVerifiedLicensePlate != licensePlate && *record.UserVerifiedLicensePlate != record.LicensePlate {
+		licensePlates = append(licensePlates, *record.UserVerifiedLicensePlate)
+	}
+	hits, err := s.watchlistService.CheckLicensePlates(time.Now(), licensePlates...)
+	if err != nil {
+		return err
+	}
+	alerts, err := s.watchlistService.RecordAlerts(nil, hits, models.WatchlistEventExit, record.ParkingLotID, &record.RecordID)
+	if err != nil {
+		return err
+	}
+	s.watchlistService.PublishAlerts(alerts)
+	return nil
+}
+
+// exitParkingRecord 檢查停車記錄的付款狀態，可出場時記錄出場時間
+func (s *parkingRecordService) exitParkingRecord(latestRecord *models.ParkingRecord, sensorID string, rawLicensePlate string, exitMatch string) (*models.ParkingRecord, *dtos.FeeQuote, error) {
+	now := time.Now()
+	quote, err := s.buildFeeQuote(latestRecord, now)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if latestRecord.PaymentStatus != "Paid" {
-		calculatedAmount := latestRecord.CalculatedAmount
-		if calculatedAmount == 0 && latestRecord.ExitTime == nil {
-			tempExitTimeForCalc := time.Now()
-			duration := tempExitTimeForCalc.Sub(latestRecord.EntryTime)
-			minutes := int(duration.Minutes())
-			if minutes < 0 {
-				minutes = 0
-			}
-			calculatedAmount = float64(minutes) * 0.5
+		if quote.AmountDue.IsPositive() {
+			return latestRecord, quote, fmt.Errorf("payment_required: Parking record ID %d for license plate %s requires payment. Amount due: %s", latestRecord.RecordID, latestRecord.LicensePlate, quote.AmountDue)
+		}
+		// 未滿計費單位，或定期停車證、商家折抵、預約訂金折抵後無須付費的記錄視為已付款，直接出場
+		if err := s.validationService.RecordDiscounts(nil, quote.Discounts); err != nil {
+			return nil, nil, err
 		}
-		return latestRecord, fmt.Errorf("payment_required: Parking record ID %d for license plate %s requires payment. Amount due: %.2f", latestRecord.RecordID, latestRecord.LicensePlate, calculatedAmount)
+		latestRecord.PaymentStatus = "Paid"
+		latestRecord.PaidAt = &now
+		latestRecord.FeeCalculatedAt = &now
+		latestRecord.CalculatedAmount = quote.TotalAmount
+	} else if quote.AmountDue.IsPositive() {
+		return latestRecord, quote, fmt.Errorf("overstay_payment_required: Parking record ID %d for license plate %s exceeded the exit grace period ending at %v. Overstay amount due: %s", latestRecord.RecordID, latestRecord.LicensePlate, *quote.GracePeriodEndsAt, quote.AmountDue)
 	}
 
 	if latestRecord.ExitTime == nil {
-		now := time.Now()
 		latestRecord.ExitTime = &now
-		latestRecord.SensorExitID = defaultExitSensorID
-
-		duration := now.Sub(latestRecord.EntryTime)
-		actualMinutes := int(duration.Minutes())
-		if actualMinutes < 0 {
-			actualMinutes = 0
-		}
-		latestRecord.ActualDurationMinutes = actualMinutes
+		latestRecord.SessionStatus = models.SessionStatusExited
+		latestRecord.ExitRawLicensePlate = &rawLicensePlate
+		latestRecord.ExitMatch = exitMatch
+		latestRecord.SensorExitID = sensorID
+		latestRecord.ActualDurationMinutes = parkingDurationMinutes(latestRecord.EntryTime, now)
 
 		err = s.parkingRecordRepo.UpdateParkingRecord(nil, latestRecord)
 		if err != nil {
-			return nil, fmt.Errorf("error updating parking record ID %d on exit: %w", latestRecord.RecordID, err)
+			return nil, nil, fmt.Errorf("error updating parking record ID %d on exit: %w", latestRecord.RecordID, err)
+		}
+		// 出場時仍在充電的車輛，以最後一次的電錶讀數結束充電
+		if err = s.chargingService.StopChargingSessions(nil, latestRecord.RecordID, now); err != nil {
+			return nil, nil, err
 		}
 	}
 
-	return latestRecord, nil
+	return latestRecord, nil, nil
 }
 
-// UpdateUserVerifiedLicensePlate 更新使用者驗證的車牌號碼
+// FlagStaleParkingRecords 將進場超過 configs.StaleSessionMaxAge 仍未出場的停車記錄標記為疑似遺漏出場，由背景工作定期執行
+func (s *parkingRecordService) FlagStaleParkingRecords() error {
+	now := time.Now()
+	maxAge := configs.StaleSessionMaxAge()
+	flagged, err := s.parkingRecordRepo.FlagStaleParkingRecords(now.Add(-maxAge), now)
+	if err != nil {
+		return fmt.Errorf("error flagging stale parking records: %w", err)
+	}
+	if flagged > 0 {
+		log.Printf("[FlagStaleParkingRecords] Flagged %d parking records open for more than %v", flagged, maxAge)
+	}
+	return nil
+}
+
+// GetStaleParkingRecords 呼叫 repository 取得被標記為疑似遺漏出場且仍未出場的停車記錄 (分頁)
+func (s *parkingRecordService) GetStaleParkingRecords(parkingLotID *uint, limit int, offset int) ([]models.ParkingRecord, error) {
+	return s.parkingRecordRepo.GetStaleParkingRecords(parkingLotID, limit, offset)
+}
+
+// AbandonParkingRecord 由現場人員結束車輛未經出口離場的停車記錄，記錄為 Abandoned 並釋出車位；未付的費用保留在停車記錄上
+func (s *parkingRecordService) AbandonParkingRecord(recordID uint, payload dtos.AbandonParkingRecordPayload) (record *models.ParkingRecord, err error) {
+	closedBy := strings.TrimSpace(payload.ClosedBy)
+	reason := strings.TrimSpace(payload.Reason)
+	if closedBy == "" || reason == "" {
+		return nil, errors.New("invalid_abandon: Closing a parking record requires the operator and a reason.")
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+		}
+	}()
+
+	record, err = s.abandonOpenParkingRecord(tx, recordID, time.Now(), fmt.Sprintf("Closed by %s: %s", closedBy, reason))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("session_closed: Parking record ID %d has already exited.", recordID)
+	}
+	log.Printf("[AbandonParkingRecord] Parking record ID %d for %s closed as %s by %s. Reason: %s", record.RecordID, record.LicensePlate, models.SessionStatusAbandoned, closedBy, reason)
+	return record, nil
+}
+
+// abandonOpenParkingRecord 在資料庫交易中鎖定並結束尚未出場的停車記錄，狀態記為 Abandoned，仍在充電的充電紀錄以最後一次的電錶讀數結束
+// 停車記錄已出場時不會變更，回傳 nil
+func (s *parkingRecordService) abandonOpenParkingRecord(tx *gorm.DB, recordID uint, at time.Time, reason string) (*models.ParkingRecord, error) {
+	var record models.ParkingRecord
+	if queryErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&record, recordID).Error; queryErr != nil {
+		if errors.Is(queryErr, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("parking record ID %d not found", recordID)
+		}
+		return nil, fmt.Errorf("error finding parking record ID %d: %w", recordID, queryErr)
+	}
+	if record.ExitTime != nil {
+		return nil, nil
+	}
+
+	record.ExitTime = &at
+	record.SessionStatus = models.SessionStatusAbandoned
+	record.AbandonReason = &reason
+	record.ActualDurationMinutes = parkingDurationMinutes(record.EntryTime, at)
+	if err := s.parkingRecordRepo.UpdateParkingRecord(tx, &record); err != nil {
+		return nil, fmt.Errorf("error closing parking record ID %d: %w", recordID, err)
+	}
+	if err := s.chargingService.StopChargingSessions(tx, record.RecordID, at); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// UpdateUserVerifiedLicensePlate 更新使用者驗證的車牌號碼，車牌會轉為標準格式，停車記錄改為連結驗證車牌的車輛資料
 func (s *parkingRecordService) UpdateUserVerifiedLicensePlate(recordID uint, verifiedLicensePlate string) (*models.ParkingRecord, error) {
+	verifiedLicensePlate = licenseplate.Normalize(verifiedLicensePlate)
+	if verifiedLicensePlate == "" {
+		return nil, errors.New("invalid_license_plate: License plate is required.")
+	}
 	record, err := s.parkingRecordRepo.GetParkingRecordByID(recordID)
 	if err != nil {
 		return nil, err
@@ -179,15 +667,58 @@ func (s *parkingRecordService) UpdateUserVerifiedLicensePlate(recordID uint, ver
 	if record == nil {
 		return nil, errors.New("parking record not found")
 	}
+	vehicle, err := s.vehicleService.FindOrCreateVehicle(nil, verifiedLicensePlate, record.EntryTime)
+	if err != nil {
+		return nil, err
+	}
 	record.UserVerifiedLicensePlate = &verifiedLicensePlate
+	record.VehicleID = &vehicle.VehicleID
 	if err := s.parkingRecordRepo.UpdateParkingRecord(nil, record); err != nil {
 		return nil, err
 	}
 	return record, nil
 }
 
-// PrepareParkingRecordForPayment 準備停車記錄以進行付款
-func (s *parkingRecordService) PrepareParkingRecordForPayment(recordID uint) (*models.ParkingRecord, error) {
+// RecordSpotStatus 記錄車位感應器回報的車位狀態
+// 回報有車且帶有車牌時，將該車牌在同一停車場內尚未出場的停車記錄連結到此車位；找不到記錄時只更新車位狀態
+func (s *parkingRecordService) RecordSpotStatus(sensorID string, occupied bool, licensePlate string) (*dtos.SpotStatusResponse, error) {
+	spot, zone, err := s.parkingLotService.UpdateSpotStatus(sensorID, occupied)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.SpotStatusResponse{
+		SpotID:   spot.SpotID,
+		Code:     spot.Code,
+		ZoneID:   zone.ZoneID,
+		ZoneName: zone.Name,
+		Occupied: spot.Occupied,
+	}
+	if !occupied || licensePlate == "" {
+		return response, nil
+	}
+
+	record, err := s.parkingRecordRepo.GetLatestParkingRecordByLicensePlate(licensePlate)
+	if err != nil {
+		return nil, fmt.Errorf("error finding active parking record for license plate %s: %w", licensePlate, err)
+	}
+	if record == nil || record.ParkingLotID != zone.ParkingLotID {
+		log.Printf("[RecordSpotStatus] No active parking record of license plate %s in parking lot ID %d for spot %s", licensePlate, zone.ParkingLotID, spot.Code)
+		return response, nil
+	}
+	if record.SpotID == nil || *record.SpotID != spot.SpotID {
+		record.SpotID = &spot.SpotID
+		if err := s.parkingRecordRepo.UpdateParkingRecord(nil, record); err != nil {
+			return nil, fmt.Errorf("error linking parking record ID %d to spot %s: %w", record.RecordID, spot.Code, err)
+		}
+	}
+	response.ParkingRecordID = &record.RecordID
+	return response, nil
+}
+
+// PrepareParkingRecordForPayment 準備停車記錄以進行付款，回傳計算後的記錄、費用明細、商家折抵、充電費用與尚需支付的金額
+// 記錄的應付金額為扣除商家折抵後的停車費加上充電費用，已付的預約訂金再從中扣除
+func (s *parkingRecordService) PrepareParkingRecordForPayment(recordID uint) (*dtos.ParkingRecordWithFeeBreakdownResponse, error) {
 	record, err := s.parkingRecordRepo.GetParkingRecordByID(recordID)
 	if err != nil {
 		return nil, fmt.Errorf("error finding parking record ID %d: %w", recordID, err)
@@ -197,35 +728,309 @@ func (s *parkingRecordService) PrepareParkingRecordForPayment(recordID uint) (*m
 	}
 
 	if record.ExitTime != nil {
-		return record, fmt.Errorf("vehicle_exited: Vehicle has already exited on %v. Fee is final at %.2f", *record.ExitTime, record.CalculatedAmount)
+		return nil, fmt.Errorf("vehicle_exited: Vehicle has already exited on %v. Fee is final at %s", *record.ExitTime, record.CalculatedAmount)
 	}
 
 	if record.PaymentStatus == "Paid" {
-		return record, fmt.Errorf("already_paid: Parking record is already paid. Amount was %.2f", record.CalculatedAmount)
+		return nil, fmt.Errorf("already_paid: Parking record is already paid. Amount was %s", record.CalculatedAmount)
+	}
+
+	tariff, err := s.parkingLotService.TariffForParkingLot(record.ParkingLotID, record.VehicleClass)
+	if err != nil {
+		return nil, err
 	}
 
-	effectiveCalculationTime := time.Now()
-	duration := effectiveCalculationTime.Sub(record.EntryTime)
-	actualMinutes := int(duration.Minutes())
-	if actualMinutes < 0 {
-		actualMinutes = 0
+	now := time.Now()
+	fee := tariff.Calculate(record.EntryTime, now)
+	discounts, err := s.calculateDiscounts(record, fee, now)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: Implement proper rate calculation based on configs.RatePerUnit and configs.UnitDurationHours
-	calculatedAmount := float64(actualMinutes)*configs.RatePerUnit + 10.0
+	chargingCharges, err := s.chargingService.CalculateCharges(record.RecordID, now)
+	if err != nil {
+		return nil, err
+	}
 
-	record.ActualDurationMinutes = actualMinutes
-	record.CalculatedAmount = calculatedAmount
+	record.ActualDurationMinutes = fee.DurationMinutes
+	record.CalculatedAmount = fee.TotalAmount.Sub(totalDiscount(discounts)).Add(totalChargingAmount(chargingCharges))
+	record.FeeCalculatedAt = &now
 
 	if err = s.parkingRecordRepo.UpdateParkingRecord(nil, record); err != nil {
 		return nil, fmt.Errorf("error updating parking record ID %d with calculated fee: %w", recordID, err)
 	}
+	if err = s.validationService.RecordDiscounts(nil, discounts); err != nil {
+		return nil, err
+	}
 
-	return record, nil
+	amountPaid, err := s.transactionService.GetNetPaidAmountByParkingRecordID(recordID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting paid amount for parking record ID %d: %w", recordID, err)
+	}
+
+	return &dtos.ParkingRecordWithFeeBreakdownResponse{
+		ParkingRecord:   *record,
+		FeeBreakdown:    fee,
+		Discounts:       discounts,
+		ChargingCharges: chargingCharges,
+		AmountPaid:      amountPaid,
+		AmountDue:       models.MaxMoney(record.CalculatedAmount.Sub(amountPaid), models.Money{}),
+	}, nil
+}
+
+// GetParkingFeeQuote 試算停車記錄目前的費用明細，不會寫入資料庫
+func (s *parkingRecordService) GetParkingFeeQuote(recordID uint) (*dtos.FeeQuote, error) {
+	record, err := s.parkingRecordRepo.GetParkingRecordByID(recordID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding parking record ID %d: %w", recordID, err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("parking record ID %d not found", recordID)
+	}
+	return s.buildFeeQuote(record, time.Now())
+}
+
+// GetParkingFeeQuoteByLicensePlate 試算指定車牌目前仍在場內的停車記錄費用，不會寫入資料庫
+func (s *parkingRecordService) GetParkingFeeQuoteByLicensePlate(licensePlate string) (*dtos.FeeQuote, error) {
+	record, err := s.parkingRecordRepo.GetLatestParkingRecordByLicensePlate(licensePlate)
+	if err != nil {
+		return nil, fmt.Errorf("error finding active parking record for license plate %s: %w", licensePlate, err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("no active parking record found for license plate %s", licensePlate)
+	}
+	return s.buildFeeQuote(record, time.Now())
+}
+
+// buildFeeQuote 依停車記錄與試算時間組出費用明細
+// 已出場的記錄以出場時間計算，費用固定且不設有效期限
+func (s *parkingRecordService) buildFeeQuote(record *models.ParkingRecord, quotedAt time.Time) (*dtos.FeeQuote, error) {
+	calculateUntil := quotedAt
+	if record.ExitTime != nil {
+		calculateUntil = *record.ExitTime
+	}
+	tariff, err := s.parkingLotService.TariffForParkingLot(record.ParkingLotID, record.VehicleClass)
+	if err != nil {
+		return nil, err
+	}
+	fee := tariff.Calculate(record.EntryTime, calculateUntil)
+
+	discounts, err := s.calculateDiscounts(record, fee, calculateUntil)
+	if err != nil {
+		return nil, err
+	}
+	discountTotal := totalDiscount(discounts)
+
+	chargingCharges, err := s.chargingService.CalculateCharges(record.RecordID, calculateUntil)
+	if err != nil {
+		return nil, err
+	}
+	chargingTotal := totalChargingAmount(chargingCharges)
+
+	amountPaid, err := s.transactionService.GetNetPaidAmountByParkingRecordID(record.RecordID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting paid amount for parking record ID %d: %w", record.RecordID, err)
+	}
+
+	quote := &dtos.FeeQuote{
+		ParkingRecordID: record.RecordID,
+		ParkingLotID:    record.ParkingLotID,
+		LicensePlate:    record.LicensePlate,
+		PaymentStatus:   record.PaymentStatus,
+		EntryTime:       record.EntryTime,
+		QuotedAt:        quotedAt,
+		DurationMinutes: fee.DurationMinutes,
+		BillableUnits:   fee.BillableUnits,
+		Segments:        fee.Segments,
+		Days:            fee.Days,
+		Subtotal:        fee.TotalAmount,
+		Discounts:       discounts,
+		DiscountTotal:   discountTotal,
+		ChargingCharges: chargingCharges,
+		ChargingTotal:   chargingTotal,
+		TotalAmount:     fee.TotalAmount.Sub(discountTotal).Add(chargingTotal),
+		AmountPaid:      amountPaid,
+		Currency:        configs.Currency,
+	}
+	if record.ExitTime == nil {
+		expiresAt := minTime(tariff.NextUnitStart(record.EntryTime, quotedAt), quotedAt.Add(configs.QuoteMaxValidity))
+		quote.ExpiresAt = &expiresAt
+	}
+
+	if record.PaymentStatus != "Paid" {
+		if due := quote.TotalAmount.Sub(quote.AmountPaid); due.IsPositive() {
+			quote.AmountDue = due
+		}
+		return quote, nil
+	}
+
+	// 已付款的記錄在寬限期內出場不需補繳，超過寬限期則需補繳已付費用計算時間之後增加的費用
+	// 寬限期由已付費用的計算時間起算，而非付款時間，準備付款後拖延付款的時間仍需計費；退款不影響補繳的金額
+	paidUntil, err := s.feePaidUntil(record)
+	if err != nil {
+		return nil, err
+	}
+	gracePeriodEndsAt := paidUntil.Add(configs.PaymentExitGracePeriod)
+	quote.GracePeriodEndsAt = &gracePeriodEndsAt
+	if !calculateUntil.After(gracePeriodEndsAt) {
+		return quote, nil
+	}
+	paidTotal, err := s.feeTotalAt(record, tariff, paidUntil)
+	if err != nil {
+		return nil, err
+	}
+	if overstay := quote.TotalAmount.Sub(paidTotal); overstay.IsPositive() {
+		quote.AmountDue = overstay
+	}
+	return quote, nil
+}
+
+// feeTotalAt 計算停車記錄到 at 為止扣除折抵並加上充電費用的總金額
+func (s *parkingRecordService) feeTotalAt(record *models.ParkingRecord, tariff Tariff, at time.Time) (models.Money, error) {
+	fee := tariff.Calculate(record.EntryTime, at)
+	discounts, err := s.calculateDiscounts(record, fee, at)
+	if err != nil {
+		return models.Money{}, err
+	}
+	chargingCharges, err := s.chargingService.CalculateCharges(record.RecordID, at)
+	if err != nil {
+		return models.Money{}, err
+	}
+	return fee.TotalAmount.Sub(totalDiscount(discounts)).Add(totalChargingAmount(chargingCharges)), nil
+}
+
+// calculateDiscounts 計算停車記錄的所有折抵，先折抵定期停車證涵蓋的時段，商家折抵再套用於剩餘的金額
+func (s *parkingRecordService) calculateDiscounts(record *models.ParkingRecord, fee dtos.FeeBreakdown, calculateUntil time.Time) ([]dtos.FeeDiscount, error) {
+	discounts := []dtos.FeeDiscount{}
+	permitDiscount, err := s.permitService.CalculatePermitDiscount(record, fee, calculateUntil)
+	if err != nil {
+		return nil, err
+	}
+	if permitDiscount != nil {
+		discounts = append(discounts, *permitDiscount)
+		fee.TotalAmount = fee.TotalAmount.Sub(permitDiscount.Amount)
+	}
+
+	validationDiscounts, err := s.validationService.CalculateDiscounts(nil, record, fee, calculateUntil)
+	if err != nil {
+		return nil, err
+	}
+	return append(discounts, validationDiscounts...), nil
+}
+
+// totalDiscount 加總所有折抵金額
+func totalDiscount(discounts []dtos.FeeDiscount) models.Money {
+	var total models.Money
+	for _, discount := range discounts {
+		total = total.Add(discount.Amount)
+	}
+	return total
+}
+
+// totalChargingAmount 加總所有充電的電費與佔用費
+func totalChargingAmount(charges []dtos.ChargingCharge) models.Money {
+	var total models.Money
+	for _, charge := range charges {
+		total = total.Add(charge.Amount)
+	}
+	return total
+}
+
+// paymentLineItems 將一筆付款拆成停車費、各充電的電費與佔用費明細，每項只收取先前付款尚未收取的差額
+// 預約訂金或退款造成的差額列為調整明細，明細加總等於 amountDue
+func (s *parkingRecordService) paymentLineItems(tx *gorm.DB, recordID uint, parkingAmount models.Money, charges []dtos.ChargingCharge, amountDue models.Money) ([]models.TransactionLineItem, error) {
+	billed, err := s.transactionService.GetBilledLineItemsByParkingRecordID(tx, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting billed line items of parking record ID %d: %w", recordID, err)
+	}
+	billedAmount := func(itemType string, chargingSessionID *uint) models.Money {
+		var total models.Money
+		for _, item := range billed {
+			if item.ItemType != itemType {
+				continue
+			}
+			if (item.ChargingSessionID == nil) != (chargingSessionID == nil) {
+				continue
+			}
+			if chargingSessionID != nil && *item.ChargingSessionID != *chargingSessionID {
+				continue
+			}
+			total = total.Add(item.Amount)
+		}
+		return total
+	}
+
+	lineItems := []models.TransactionLineItem{}
+	var itemsTotal models.Money
+	addItem := func(itemType string, chargingSessionID *uint, description string, amount models.Money) {
+		amount = amount.Sub(billedAmount(itemType, chargingSessionID))
+		if amount.IsZero() {
+			return
+		}
+		lineItems = append(lineItems, models.TransactionLineItem{
+			ChargingSessionID: chargingSessionID,
+			ItemType:          itemType,
+			Description:       description,
+			Amount:            amount,
+		})
+		itemsTotal = itemsTotal.Add(amount)
+	}
+
+	addItem(models.LineItemTypeParking, nil, fmt.Sprintf("Parking fee of parking record ID %d", recordID), parkingAmount)
+	for _, charge := range charges {
+		chargingSessionID := charge.ChargingSessionID
+		addItem(models.LineItemTypeEnergy, &chargingSessionID, fmt.Sprintf("Charger %s: %.3f kWh at %s/kWh", charge.ChargerID, charge.EnergyKWh, charge.EnergyRate), charge.EnergyAmount)
+		addItem(models.LineItemTypeIdleFee, &chargingSessionID, fmt.Sprintf("Charger %s: %d idle minutes at %s/min", charge.ChargerID, charge.IdleMinutes, charge.IdleFeeRate), charge.IdleFeeAmount)
+	}
+	if adjustment := amountDue.Sub(itemsTotal); !adjustment.IsZero() {
+		lineItems = append(lineItems, models.TransactionLineItem{
+			ItemType:    models.LineItemTypeAdjustment,
+			Description: "Deposits, earlier payments and refunds",
+			Amount:      adjustment,
+		})
+	}
+	return lineItems, nil
+}
+
+// feePaidUntil 回傳已付款的停車記錄費用計算到的時間，即已付費用的計算時間與付款時間中較早者
+// 舊資料沒有 PaidAt 時，改用關聯交易的交易時間
+func (s *parkingRecordService) feePaidUntil(record *models.ParkingRecord) (time.Time, error) {
+	paidAt, err := s.lastPaymentTime(record)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if record.FeeCalculatedAt != nil && record.FeeCalculatedAt.Before(paidAt) {
+		return *record.FeeCalculatedAt, nil
+	}
+	return paidAt, nil
+}
+
+// lastPaymentTime 回傳停車記錄最近一次付款成功的時間
+// 舊資料沒有 PaidAt 時，改用關聯交易的交易時間
+func (s *parkingRecordService) lastPaymentTime(record *models.ParkingRecord) (time.Time, error) {
+	if record.PaidAt != nil {
+		return *record.PaidAt, nil
+	}
+	if record.TransactionID == nil {
+		return time.Time{}, fmt.Errorf("parking record ID %d is marked as paid but has no transaction", record.RecordID)
+	}
+	if record.Transaction.TransactionID == *record.TransactionID {
+		return record.Transaction.TransactionTime, nil
+	}
+	transaction, err := s.transactionService.GetTransactionByID(*record.TransactionID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error getting transaction ID %d for parking record ID %d: %w", *record.TransactionID, record.RecordID, err)
+	}
+	if transaction == nil {
+		return time.Time{}, fmt.Errorf("transaction ID %d for parking record ID %d not found", *record.TransactionID, record.RecordID)
+	}
+	return transaction.TransactionTime, nil
 }
 
 // PayForParkingRecord 處理特定停車記錄的支付
-func (s *parkingRecordService) PayForParkingRecord(recordID uint, paymentPayload dtos.ParkingPaymentPayload) (pr *models.ParkingRecord, tr *models.Transaction, err error) {
+// 支付供應商立即授權時 (例如現金) 會直接完成付款並回傳交易；
+// 否則只回傳 Pending 的付款意圖，待支付供應商通知授權後才會請款並標記為已付款
+func (s *parkingRecordService) PayForParkingRecord(recordID uint, paymentPayload dtos.ParkingPaymentPayload) (pr *models.ParkingRecord, tr *models.Transaction, intent *models.PaymentIntent, err error) {
 	tx := s.db.Begin()
 	if tx.Error != nil {
 		err = fmt.Errorf("failed to begin transaction: %w", tx.Error)
@@ -245,7 +1050,7 @@ func (s *parkingRecordService) PayForParkingRecord(recordID uint, paymentPayload
 		}
 	}()
 
-	fmt.Printf("[PayForParkingRecord] Started for RecordID: %d, Amount: %.2f, Method: %s\n", recordID, paymentPayload.AmountPaid, paymentPayload.PaymentMethod)
+	fmt.Printf("[PayForParkingRecord] Started for RecordID: %d, Amount: %s, Method: %s\n", recordID, paymentPayload.AmountPaid, paymentPayload.PaymentMethod)
 
 	var currentParkingRecord models.ParkingRecord
 	queryErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&currentParkingRecord, recordID).Error
@@ -260,83 +1065,190 @@ func (s *parkingRecordService) PayForParkingRecord(recordID uint, paymentPayload
 		return
 	}
 	pr = &currentParkingRecord
-	fmt.Printf("[PayForParkingRecord] Successfully fetched ParkingRecord with ID %d. LicensePlate: %s, Status: %s, CalculatedAmount: %.2f\n", pr.RecordID, pr.LicensePlate, pr.PaymentStatus, pr.CalculatedAmount)
+	fmt.Printf("[PayForParkingRecord] Successfully fetched ParkingRecord with ID %d. LicensePlate: %s, Status: %s, CalculatedAmount: %s\n", pr.RecordID, pr.LicensePlate, pr.PaymentStatus, pr.CalculatedAmount)
 
 	if pr.ExitTime != nil {
-		err = fmt.Errorf("vehicle_exited: Cannot pay for an already exited record. Fee was %.2f", pr.CalculatedAmount)
+		err = fmt.Errorf("vehicle_exited: Cannot pay for an already exited record. Fee was %s", pr.CalculatedAmount)
 		fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
 		return
 	}
-	if pr.PaymentStatus == "Paid" {
-		err = fmt.Errorf("already_paid: Parking record ID %d is already paid.", recordID)
+	// 充電進行中時電費尚未確定，需先結束充電才能付款
+	charging, chargingErr := s.chargingService.HasActiveChargingSession(tx, recordID)
+	if chargingErr != nil {
+		err = chargingErr
 		fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
 		return
 	}
-
-	if pr.CalculatedAmount <= 0 {
-		err = fmt.Errorf("fee_not_calculated: Fee for parking record ID %d has not been calculated. Please call prepare-payment first.", recordID)
+	if charging {
+		err = fmt.Errorf("charging_in_progress: Parking record ID %d is still charging. Stop the charging session before paying.", recordID)
 		fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
 		return
 	}
 
-	if paymentPayload.AmountPaid != pr.CalculatedAmount {
-		err = fmt.Errorf("amount_mismatch: Amount paid (%.2f) does not match calculated amount (%.2f) for parking record ID %d.", paymentPayload.AmountPaid, pr.CalculatedAmount, recordID)
+	now := time.Now()
+	transactionType := "Payment"
+	amountDue := pr.CalculatedAmount
+	var parkingAmount models.Money
+	var chargingCharges []dtos.ChargingCharge
+	if pr.PaymentStatus == "Paid" {
+		// 已付款但超過出場寬限期的記錄，允許針對超時費用進行補繳
+		quote, quoteErr := s.buildFeeQuote(pr, now)
+		if quoteErr != nil {
+			err = fmt.Errorf("error calculating overstay fee for parking record ID %d: %w", recordID, quoteErr)
+			fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+			return
+		}
+		if !quote.AmountDue.IsPositive() {
+			err = fmt.Errorf("already_paid: Parking record ID %d is already paid.", recordID)
+			fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+			return
+		}
+		if err = s.validationService.RecordDiscounts(tx, quote.Discounts); err != nil {
+			fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+			return
+		}
+		transactionType = "Overstay"
+		amountDue = quote.AmountDue
+		parkingAmount = quote.Subtotal.Sub(quote.DiscountTotal)
+		chargingCharges = quote.ChargingCharges
+		fmt.Printf("[PayForParkingRecord] RecordID %d exceeded the exit grace period. Overstay amount due: %s\n", recordID, amountDue)
+	} else if !pr.CalculatedAmount.IsPositive() {
+		err = fmt.Errorf("fee_not_calculated: Fee for parking record ID %d has not been calculated or changed after a validation was applied. Please call prepare-payment first.", recordID)
 		fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
 		return
-	}
+	} else if pr.FeeCalculatedAt == nil || now.Sub(*pr.FeeCalculatedAt) > configs.QuoteMaxValidity {
+		// 準備付款時計算的費用只在試算有效期限內可付款，避免以舊的金額支付之後的停車時間
+		err = fmt.Errorf("fee_expired: Fee for parking record ID %d was calculated more than %v ago. Please call prepare-payment again.", recordID, configs.QuoteMaxValidity)
+		fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+		return
+	} else {
+		// 預約訂金已計入停車記錄的實收金額，只需支付差額
+		amountPaid, sumErr := s.transactionService.GetNetPaidAmountByParkingRecordID(recordID)
+		if sumErr != nil {
+			err = fmt.Errorf("error getting paid amount for parking record ID %d: %w", recordID, sumErr)
+			fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+			return
+		}
+		amountDue = pr.CalculatedAmount.Sub(amountPaid)
+		if !amountDue.IsPositive() {
+			err = fmt.Errorf("no_payment_due: Parking fee (%s) of parking record ID %d is covered by the amount already paid (%s). The vehicle may exit.", pr.CalculatedAmount, recordID, amountPaid)
+			fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+			return
+		}
 
-	newTransaction := &models.Transaction{
-		ParkingRecordID:        recordID, // 等同於 pr.RecordID
-		Amount:                 paymentPayload.AmountPaid,
-		TransactionTime:        time.Now(),
-		PaymentMethod:          paymentPayload.PaymentMethod,
-		Status:                 "Success",
-		PaymentGatewayResponse: paymentPayload.PaymentReference,
+		// 應付金額在準備付款時計算，充電費用也以當時的時間計算
+		chargingCharges, err = s.chargingService.CalculateCharges(recordID, *pr.FeeCalculatedAt)
+		if err != nil {
+			fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+			return
+		}
+		parkingAmount = pr.CalculatedAmount.Sub(totalChargingAmount(chargingCharges))
 	}
-	fmt.Printf("[PayForParkingRecord] Preparing to create transaction for ParkingRecordID: %d, Transaction ParkingRecordID: %d\n", pr.RecordID, newTransaction.ParkingRecordID)
 
-	if createtransactionErr := s.transactionService.CreateTransaction(tx, newTransaction); createtransactionErr != nil {
-		err = fmt.Errorf("failed to create transaction record: %w", createtransactionErr)
-		fmt.Printf("[PayForParkingRecord] Error creating transaction: %v. ParkingRecordID was %d\n", err, newTransaction.ParkingRecordID)
-		return // defer 將會 rollback
+	if !paymentPayload.AmountPaid.Equal(amountDue) {
+		err = fmt.Errorf("amount_mismatch: Amount paid (%s) does not match amount due (%s) for parking record ID %d.", paymentPayload.AmountPaid, amountDue, recordID)
+		fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+		return
 	}
-	fmt.Printf("[PayForParkingRecord] Successfully created transaction with ID: %d for ParkingRecordID: %d\n", newTransaction.TransactionID, newTransaction.ParkingRecordID)
 
-	pr.PaymentStatus = "Paid"
-	pr.TransactionID = &newTransaction.TransactionID
+	lineItems, err := s.paymentLineItems(tx, recordID, parkingAmount, chargingCharges, amountDue)
+	if err != nil {
+		fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+		return
+	}
 
-	if updateRecordErr := s.parkingRecordRepo.UpdateParkingRecord(tx, pr); updateRecordErr != nil {
-		err = fmt.Errorf("failed to update parking record status to Paid: %w", updateRecordErr)
-		fmt.Printf("[PayForParkingRecord] Error updating parking record: %v\n", err)
-		return // defer 將會 rollback
+	// 透過支付供應商建立付款意圖，交易金額以供應商確認的金額為準
+	intent, tr, err = s.paymentIntentService.StartPayment(tx, pr, PaymentIntentRequest{
+		ParkingRecordID: recordID,
+		Amount:          amountDue,
+		Currency:        configs.Currency,
+		PaymentMethod:   paymentPayload.PaymentMethod,
+		Reference:       paymentPayload.PaymentReference,
+		LineItems:       lineItems,
+	}, transactionType)
+	if err != nil {
+		fmt.Printf("[PayForParkingRecord] Error: %v\n", err)
+		return
+	}
+	if tr == nil {
+		fmt.Printf("[PayForParkingRecord] Payment intent ID %d for RecordID %d is pending provider confirmation\n", intent.PaymentIntentID, recordID)
+		return
 	}
-	fmt.Printf("[PayForParkingRecord] Successfully updated ParkingRecord ID %d to Paid. TransactionID: %d\n", pr.RecordID, *pr.TransactionID)
 
-	tr = newTransaction
-	fmt.Printf("[PayForParkingRecord] Process completed successfully for RecordID: %d\n", recordID)
+	fmt.Printf("[PayForParkingRecord] Process completed successfully for RecordID: %d, TransactionID: %d\n", recordID, tr.TransactionID)
 	return
 }
 
 // --- 報表服務方法 ---
 
-// GetTotalParkingCount 獲取指定時間範圍內的總停車次數
-func (s *parkingRecordService) GetTotalParkingCount(startTime, endTime *time.Time) (int64, error) {
-	return s.parkingRecordRepo.CountParkingRecords(startTime, endTime)
+// GetTotalParkingCount 獲取指定時間範圍內的總停車次數，並依車種分組
+// vehicleClass 不為 nil 時只統計該車種
+func (s *parkingRecordService) GetTotalParkingCount(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (*dtos.TotalParkingCountResponse, error) {
+	counts, err := s.parkingRecordRepo.CountParkingRecordsByVehicleClass(parkingLotID, vehicleClass, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("error counting parking records: %w", err)
+	}
+
+	response := &dtos.TotalParkingCountResponse{ByVehicleClass: []dtos.VehicleClassCountResponse{}}
+	for _, class := range reportVehicleClasses(vehicleClass) {
+		response.ByVehicleClass = append(response.ByVehicleClass, dtos.VehicleClassCountResponse{
+			VehicleClass: class,
+			TotalCount:   counts[class],
+		})
+		response.TotalCount += counts[class]
+	}
+	return response, nil
 }
 
-// GetTotalRevenue 獲取指定時間範圍內的總收入
-func (s *parkingRecordService) GetTotalRevenue(startTime, endTime *time.Time) (float64, error) {
-	return s.parkingRecordRepo.SumPaidParkingFees(startTime, endTime)
+// GetTotalRevenue 獲取指定時間範圍內的總收入，並依車種分組，退款會從總收入中扣除
+// 逾時未到而沒收的預約訂金沒有停車記錄，依預約開始時間計入總收入並另外列出
+// vehicleClass 不為 nil 時只統計該車種
+func (s *parkingRecordService) GetTotalRevenue(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (*dtos.TotalRevenueResponse, error) {
+	netRevenues, err := s.parkingRecordRepo.SumPaidParkingFeesByVehicleClass(parkingLotID, vehicleClass, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("error summing paid parking fees: %w", err)
+	}
+
+	refunds, err := s.parkingRecordRepo.SumRefundedParkingFeesByVehicleClass(parkingLotID, vehicleClass, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("error summing refunded parking fees: %w", err)
+	}
+
+	forfeited, err := s.reservationService.SumForfeitedDeposits(parkingLotID, vehicleClass, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.TotalRevenueResponse{
+		Currency:       configs.Currency,
+		ByVehicleClass: []dtos.VehicleClassRevenueResponse{},
+	}
+	for _, class := range reportVehicleClasses(vehicleClass) {
+		netRevenue := netRevenues[class].Add(forfeited[class])
+		refunded := refunds[class]
+		response.ByVehicleClass = append(response.ByVehicleClass, dtos.VehicleClassRevenueResponse{
+			VehicleClass:      class,
+			TotalRevenue:      netRevenue,
+			GrossRevenue:      netRevenue.Add(refunded),
+			RefundedAmount:    refunded,
+			ForfeitedDeposits: forfeited[class],
+		})
+		response.TotalRevenue = response.TotalRevenue.Add(netRevenue)
+		response.RefundedAmount = response.RefundedAmount.Add(refunded)
+		response.ForfeitedDeposits = response.ForfeitedDeposits.Add(forfeited[class])
+	}
+	response.GrossRevenue = response.TotalRevenue.Add(response.RefundedAmount)
+	return response, nil
 }
 
 // GetImageAttachmentRate 獲取指定時間範圍內停車記錄的圖片附件率
-func (s *parkingRecordService) GetImageAttachmentRate(startTime, endTime *time.Time) (*dtos.ImageAttachmentRateResponse, error) {
-	totalEntries, err := s.parkingRecordRepo.CountParkingRecords(startTime, endTime)
+func (s *parkingRecordService) GetImageAttachmentRate(parkingLotID *uint, startTime, endTime *time.Time) (*dtos.ImageAttachmentRateResponse, error) {
+	totalEntries, err := s.parkingRecordRepo.CountParkingRecords(parkingLotID, startTime, endTime)
 	if err != nil {
 		return nil, fmt.Errorf("error getting total parking records for image rate: %w", err)
 	}
 
-	entriesWithImage, err := s.parkingRecordRepo.CountParkingRecordsWithImage(startTime, endTime)
+	entriesWithImage, err := s.parkingRecordRepo.CountParkingRecordsWithImage(parkingLotID, startTime, endTime)
 	if err != nil {
 		return nil, fmt.Errorf("error getting parking records with image for image rate: %w", err)
 	}
@@ -353,29 +1265,190 @@ func (s *parkingRecordService) GetImageAttachmentRate(startTime, endTime *time.T
 	}, nil
 }
 
-// GetAvailableParkingSpots 獲取停車場總容量、已佔用車位和可用車位數量
-func (s *parkingRecordService) GetAvailableParkingSpots() (*dtos.AvailableSpotsResponse, error) {
-	totalCapacity := configs.ParkingLotCapacity
+// GetAvailableParkingSpots 獲取各停車場與所有停車場合計的總容量、已佔用車位和可用車位數量，並依車種分組
+// parkingLotID 不為 nil 時只統計該停車場，vehicleClass 不為 nil 時只統計該車種
+func (s *parkingRecordService) GetAvailableParkingSpots(parkingLotID *uint, vehicleClass *string) (*dtos.AvailableSpotsResponse, error) {
+	parkingLots, err := s.parkingLotService.GetAllParkingLots()
+	if err != nil {
+		return nil, fmt.Errorf("error getting parking lots: %w", err)
+	}
 
-	occupiedSpots, err := s.parkingRecordRepo.CountActiveParkingRecords()
+	occupiedByParkingLot, err := s.parkingRecordRepo.CountActiveParkingRecordsByParkingLot()
 	if err != nil {
 		return nil, fmt.Errorf("error counting active parking records: %w", err)
 	}
+	heldByParkingLot, err := s.reservationService.HeldSpotsByParkingLot(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := s.parkingLotService.GetAllZones()
+	if err != nil {
+		return nil, fmt.Errorf("error getting zones: %w", err)
+	}
+	zonesByParkingLot := make(map[uint][]dtos.ZoneSpotsResponse)
+	for _, zone := range zones {
+		zonesByParkingLot[zone.ParkingLotID] = append(zonesByParkingLot[zone.ParkingLotID], zoneSpots(zone))
+	}
+
+	classes := reportVehicleClasses(vehicleClass)
+	totalsByClass := make(map[string]*dtos.VehicleClassSpotsResponse, len(classes))
+	response := &dtos.AvailableSpotsResponse{
+		ParkingLots:    []dtos.ParkingLotSpotsResponse{},
+		ByVehicleClass: []dtos.VehicleClassSpotsResponse{},
+	}
+	for _, parkingLot := range parkingLots {
+		if parkingLotID != nil && parkingLot.ParkingLotID != *parkingLotID {
+			continue
+		}
+
+		parkingLotZones := zonesByParkingLot[parkingLot.ParkingLotID]
+		if parkingLotZones == nil {
+			parkingLotZones = []dtos.ZoneSpotsResponse{}
+		}
+		parkingLotSpots := dtos.ParkingLotSpotsResponse{
+			ParkingLotID:   parkingLot.ParkingLotID,
+			Name:           parkingLot.Name,
+			VehicleClasses: []dtos.VehicleClassSpotsResponse{},
+			Zones:          parkingLotZones,
+		}
+
+		for _, class := range classes {
+			capacity := parkingLot.CapacityFor(class)
+			occupiedSpots := occupiedByParkingLot[parkingLot.ParkingLotID][class]
+			heldSpots := heldByParkingLot[parkingLot.ParkingLotID][class]
+			// 未開放且場內沒有該車種時不列出，指定車種時一律列出
+			if vehicleClass == nil && class != models.VehicleClassCar && capacity == 0 && occupiedSpots == 0 {
+				continue
+			}
+
+			// 操作人員強制放行時，已佔用車位可能超過容量；預約時段內保留的車位不計入可用車位
+			reservedSpots := reservedSpotsFor(parkingLot, class)
+			availableSpots := max(int64(capacity)-occupiedSpots-heldSpots, 0)
+			publicAvailableSpots := max(availableSpots-int64(reservedSpots), 0)
+			classSpots := dtos.VehicleClassSpotsResponse{
+				VehicleClass:         class,
+				TotalCapacity:        capacity,
+				OccupiedSpots:        occupiedSpots,
+				HeldSpots:            heldSpots,
+				AvailableSpots:       availableSpots,
+				ReservedSpots:        reservedSpots,
+				PublicAvailableSpots: publicAvailableSpots,
+				IsFull:               availableSpots == 0,
+			}
+			parkingLotSpots.VehicleClasses = append(parkingLotSpots.VehicleClasses, classSpots)
+			parkingLotSpots.TotalCapacity += capacity
+			parkingLotSpots.OccupiedSpots += occupiedSpots
+			parkingLotSpots.HeldSpots += heldSpots
+			parkingLotSpots.AvailableSpots += availableSpots
+			parkingLotSpots.ReservedSpots += reservedSpots
+			parkingLotSpots.PublicAvailableSpots += publicAvailableSpots
+
+			total, ok := totalsByClass[class]
+			if !ok {
+				total = &dtos.VehicleClassSpotsResponse{VehicleClass: class}
+				totalsByClass[class] = total
+			}
+			total.TotalCapacity += capacity
+			total.OccupiedSpots += occupiedSpots
+			total.HeldSpots += heldSpots
+			total.AvailableSpots += availableSpots
+			total.ReservedSpots += reservedSpots
+			total.PublicAvailableSpots += publicAvailableSpots
+		}
+		parkingLotSpots.IsFull = parkingLotSpots.AvailableSpots == 0
+
+		response.ParkingLots = append(response.ParkingLots, parkingLotSpots)
+		response.TotalCapacity += parkingLotSpots.TotalCapacity
+		response.OccupiedSpots += parkingLotSpots.OccupiedSpots
+		response.HeldSpots += parkingLotSpots.HeldSpots
+		response.AvailableSpots += parkingLotSpots.AvailableSpots
+		response.ReservedSpots += parkingLotSpots.ReservedSpots
+		response.PublicAvailableSpots += parkingLotSpots.PublicAvailableSpots
+	}
+
+	if parkingLotID != nil && len(response.ParkingLots) == 0 {
+		return nil, fmt.Errorf("parking lot ID %d not found", *parkingLotID)
+	}
+	for _, class := range classes {
+		if total, ok := totalsByClass[class]; ok {
+			total.IsFull = total.AvailableSpots == 0
+			response.ByVehicleClass = append(response.ByVehicleClass, *total)
+		}
+	}
+	return response, nil
+}
 
-	availableSpots := int64(totalCapacity) - occupiedSpots
-	if availableSpots < 0 {
-		availableSpots = 0
+// GetSignboard 產生停車場入口看板的顯示內容，每個分區一行 (例如 "B2: 14 free")
+// 停車場沒有分區時顯示非定期停車證車輛可用的車位數，只有汽車時顯示一行，開放其他車種時每個車種一行；parkingLotID 為 0 時僅有一個停車場的部署會直接使用該停車場
+func (s *parkingRecordService) GetSignboard(parkingLotID uint) (*dtos.SignboardResponse, error) {
+	parkingLot, err := s.parkingLotService.ResolveParkingLot(parkingLotID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	availability, err := s.GetAvailableParkingSpots(&parkingLot.ParkingLotID, nil)
+	if err != nil {
+		return nil, err
 	}
+	spots := availability.ParkingLots[0]
 
-	return &dtos.AvailableSpotsResponse{
-		TotalCapacity:  totalCapacity,
+	signboard := &dtos.SignboardResponse{
+		ParkingLotID: spots.ParkingLotID,
+		Name:         spots.Name,
+		Lines:        []dtos.SignboardLine{},
+	}
+	if len(spots.Zones) == 0 && len(spots.VehicleClasses) <= 1 {
+		signboard.Lines = append(signboard.Lines, signboardLine(spots.Name, spots.PublicAvailableSpots))
+	} else if len(spots.Zones) == 0 {
+		for _, class := range spots.VehicleClasses {
+			signboard.Lines = append(signboard.Lines, signboardLine(class.VehicleClass, class.PublicAvailableSpots))
+		}
+	}
+	for _, zone := range spots.Zones {
+		signboard.Lines = append(signboard.Lines, signboardLine(zone.Name, int64(zone.AvailableSpots)))
+	}
+	return signboard, nil
+}
+
+// reportVehicleClasses 回傳報表要列出的車種，vehicleClass 不為 nil 時只列出該車種
+func reportVehicleClasses(vehicleClass *string) []string {
+	if vehicleClass != nil {
+		return []string{*vehicleClass}
+	}
+	return models.VehicleClasses
+}
+
+// reservedSpotsFor 回傳停車場保留給定期停車證持有者的該車種車位數，只有汽車車位會保留
+func reservedSpotsFor(parkingLot models.ParkingLot, vehicleClass string) int {
+	if vehicleClass != models.VehicleClassCar {
+		return 0
+	}
+	return parkingLot.ReservedSpots
+}
+
+// zoneSpots 依車位感應器最近一次的回報統計分區的車位
+func zoneSpots(zone models.Zone) dtos.ZoneSpotsResponse {
+	occupiedSpots := 0
+	for _, spot := range zone.Spots {
+		if spot.Occupied {
+			occupiedSpots++
+		}
+	}
+	return dtos.ZoneSpotsResponse{
+		ZoneID:         zone.ZoneID,
+		Name:           zone.Name,
+		Level:          zone.Level,
+		TotalSpots:     len(zone.Spots),
 		OccupiedSpots:  occupiedSpots,
-		AvailableSpots: availableSpots,
-	}, nil
+		AvailableSpots: len(zone.Spots) - occupiedSpots,
+	}
 }
 
-// TODO: 需要一個費率計算函式
-// func CalculateParkingFee(entryTime, exitTime time.Time, rateStructure interface{}) float64 {
-// 	 // 根據費率結構計算費用
-// 	 return 0.0
-// }
+// signboardLine 產生看板的一行，沒有空位時顯示 FULL
+func signboardLine(label string, availableSpots int64) dtos.SignboardLine {
+	text := fmt.Sprintf("%s: %d free", label, availableSpots)
+	if availableSpots <= 0 {
+		text = fmt.Sprintf("%s: FULL", label)
+	}
+	return dtos.SignboardLine{Label: label, AvailableSpots: availableSpots, Text: text}
+}
@@ -0,0 +1,463 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"log"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentIntentService 定義付款意圖服務的介面
+type PaymentIntentService interface {
+	StartPayment(tx *gorm.DB, parkingRecord *models.ParkingRecord, request PaymentIntentRequest, transactionType string) (*models.PaymentIntent, *models.Transaction, error)
+	StartDepositPayment(tx *gorm.DB, reservation *models.Reservation, request PaymentIntentRequest) (*models.PaymentIntent, *models.Transaction, error)
+	GetPaymentIntentByID(id uint) (*models.PaymentIntent, error)
+	GetPaymentIntentsByParkingRecordID(parkingRecordID uint) ([]models.PaymentIntent, error)
+	HandleProviderCallback(providerName string, header http.Header, body []byte) (*models.PaymentIntent, error)
+	ExpireStalePaymentIntents() error
+}
+
+// paymentIntentService 是 PaymentIntentService 的實作
+type paymentIntentService struct {
+	paymentIntentRepo  repositories.PaymentIntentRepository
+	parkingRecordRepo  repositories.ParkingRecordRepository
+	reservationRepo    repositories.ReservationRepository
+	transactionService TransactionService
+	paymentProviders   *PaymentProviderRegistry
+	db                 *gorm.DB
+}
+
+// NewPaymentIntentService 建立一個新的 PaymentIntentService 實例
+func NewPaymentIntentService(piRepo repositories.PaymentIntentRepository, prRepo repositories.ParkingRecordRepository, resRepo repositories.ReservationRepository, ts TransactionService, paymentProviders *PaymentProviderRegistry, db *gorm.DB) PaymentIntentService {
+	return &paymentIntentService{
+		paymentIntentRepo:  piRepo,
+		parkingRecordRepo:  prRepo,
+		reservationRepo:    resRepo,
+		transactionService: ts,
+		paymentProviders:   paymentProviders,
+		db:                 db,
+	}
+}
+
+// StartPayment 在呼叫端的資料庫交易中建立付款意圖
+// 支付供應商若在建立時即已授權 (例如現金)，會立即請款並完成付款，回傳建立的交易；
+// 否則付款意圖維持 Pending，等待支付供應商的非同步通知
+// 呼叫端需已鎖定 parkingRecord 並確認其可付款
+func (s *paymentIntentService) StartPayment(tx *gorm.DB, parkingRecord *models.ParkingRecord, request PaymentIntentRequest, transactionType string) (*models.PaymentIntent, *models.Transaction, error) {
+	provider, intent, err := s.createIntent(tx, request, &models.PaymentIntent{
+		ParkingRecordID: parkingRecord.RecordID,
+		TransactionType: transactionType,
+	})
+	if err != nil || intent.Status != PaymentProviderStatusAuthorized {
+		return intent, nil, err
+	}
+
+	transaction, err := s.captureAndSettle(tx, provider, intent, parkingRecord)
+	if err != nil {
+		return intent, nil, err
+	}
+	return intent, transaction, nil
+}
+
+// StartDepositPayment 在呼叫端的資料庫交易中建立預約訂金的付款意圖，流程與 StartPayment 相同
+// 付款完成後將預約的訂金標記為已付款；訂金交易在預約車輛進場前不屬於任何停車記錄
+// 呼叫端需已鎖定或建立 reservation
+func (s *paymentIntentService) StartDepositPayment(tx *gorm.DB, reservation *models.Reservation, request PaymentIntentRequest) (*models.PaymentIntent, *models.Transaction, error) {
+	provider, intent, err := s.createIntent(tx, request, &models.PaymentIntent{
+		ReservationID:   &reservation.ReservationID,
+		TransactionType: "Deposit",
+	})
+	if err != nil || intent.Status != PaymentProviderStatusAuthorized {
+		return intent, nil, err
+	}
+
+	transaction, err := s.captureAndSettleDeposit(tx, provider, intent, reservation)
+	if err != nil {
+		return intent, nil, err
+	}
+	return intent, transaction, nil
+}
+
+// createIntent 透過付款方式對應的支付供應商建立付款意圖，並以 intent 已填入的付款對象與交易類型寫入資料庫
+func (s *paymentIntentService) createIntent(tx *gorm.DB, request PaymentIntentRequest, intent *models.PaymentIntent) (PaymentProvider, *models.PaymentIntent, error) {
+	provider, err := s.paymentProviders.ForPaymentMethod(request.PaymentMethod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := provider.CreateIntent(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create payment intent with provider %s: %w", provider.Name(), err)
+	}
+
+	intent.Amount = request.Amount
+	intent.Currency = request.Currency
+	intent.PaymentMethod = request.PaymentMethod
+	intent.PaymentProvider = provider.Name()
+	intent.ProviderReference = result.ProviderReference
+	intent.ClientReference = request.Reference
+	intent.LineItems = request.LineItems
+	intent.Status = PaymentProviderStatusPending
+	intent.ExpiresAt = time.Now().Add(configs.PaymentIntentTTL)
+	if result.Status == PaymentProviderStatusAuthorized {
+		intent.Status = PaymentProviderStatusAuthorized
+	}
+	if err := s.paymentIntentRepo.CreatePaymentIntent(tx, intent); err != nil {
+		return nil, nil, fmt.Errorf("failed to create payment intent record: %w", err)
+	}
+	return provider, intent, nil
+}
+
+// GetPaymentIntentByID 呼叫 repository 透過 ID 取得付款意圖
+func (s *paymentIntentService) GetPaymentIntentByID(id uint) (*models.PaymentIntent, error) {
+	return s.paymentIntentRepo.GetPaymentIntentByID(id)
+}
+
+// GetPaymentIntentsByParkingRecordID 呼叫 repository 取得停車記錄的所有付款意圖
+func (s *paymentIntentService) GetPaymentIntentsByParkingRecordID(parkingRecordID uint) ([]models.PaymentIntent, error) {
+	return s.paymentIntentRepo.GetPaymentIntentsByParkingRecordID(parkingRecordID)
+}
+
+// HandleProviderCallback 處理支付供應商的非同步通知
+// 通知需通過支付供應商的簽章驗證；相同通知重送時不會重複處理，直接回傳付款意圖目前的狀態
+// 只有在請款成功後才會將停車記錄或預約的訂金標記為已付款
+func (s *paymentIntentService) HandleProviderCallback(providerName string, header http.Header, body []byte) (intent *models.PaymentIntent, err error) {
+	provider, err := s.paymentProviders.ByName(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := provider.ParseCallback(header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // 重新拋出 panic
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			if commitErr := tx.Commit().Error; commitErr != nil {
+				err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+			}
+		}
+	}()
+
+	intent, err = s.paymentIntentRepo.GetPaymentIntentByProviderReferenceForUpdate(tx, provider.Name(), callback.ProviderReference)
+	if err != nil {
+		return nil, fmt.Errorf("error finding payment intent for reference %s: %w", callback.ProviderReference, err)
+	}
+	if intent == nil {
+		return nil, fmt.Errorf("payment intent for provider reference %s not found", callback.ProviderReference)
+	}
+
+	created, err := s.paymentIntentRepo.CreateWebhookEventIfNotExists(tx, &models.PaymentWebhookEvent{
+		PaymentProvider:   provider.Name(),
+		EventID:           callback.EventID,
+		ProviderReference: callback.ProviderReference,
+		Status:            callback.Status,
+		Payload:           string(body),
+		ReceivedAt:        time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record payment callback %s: %w", callback.EventID, err)
+	}
+	if !created {
+		log.Printf("[PaymentCallback] 通知 %s 已處理過，略過重送", callback.EventID)
+		return intent, nil
+	}
+
+	// 已完成、失敗或過期的付款意圖不再變更狀態
+	if intent.Status != PaymentProviderStatusPending && intent.Status != PaymentProviderStatusAuthorized {
+		return intent, nil
+	}
+	if time.Now().After(intent.ExpiresAt) {
+		intent.Status = "Expired"
+		intent.FailureReason = "Payment callback arrived after the intent expired"
+		if err = s.paymentIntentRepo.UpdatePaymentIntent(tx, intent); err != nil {
+			return nil, fmt.Errorf("failed to expire payment intent ID %d: %w", intent.PaymentIntentID, err)
+		}
+		if intent.ReservationID != nil {
+			if err = s.failDeposit(tx, *intent.ReservationID); err != nil {
+				return nil, err
+			}
+		}
+		return intent, nil
+	}
+
+	switch callback.Status {
+	case PaymentProviderStatusAuthorized, PaymentProviderStatusCaptured:
+		intent.Status = PaymentProviderStatusAuthorized
+		if intent.ReservationID != nil {
+			err = s.settleAuthorizedDeposit(tx, provider, intent)
+		} else {
+			err = s.settleAuthorizedPayment(tx, provider, intent)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case PaymentProviderStatusFailed:
+		intent.Status = PaymentProviderStatusFailed
+		intent.FailureReason = "Payment was declined by the payment provider"
+		if err = s.paymentIntentRepo.UpdatePaymentIntent(tx, intent); err != nil {
+			return nil, fmt.Errorf("failed to update payment intent ID %d: %w", intent.PaymentIntentID, err)
+		}
+		if intent.ReservationID != nil {
+			if err = s.failDeposit(tx, *intent.ReservationID); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		log.Printf("[PaymentCallback] 忽略付款 %s 的未知狀態 %s", callback.ProviderReference, callback.Status)
+	}
+
+	return intent, nil
+}
+
+// ExpireStalePaymentIntents 將已到期但尚未完成的付款意圖標記為 Expired
+// 訂金付款意圖到期時，預約仍在等待付款的訂金一併標記為 Failed
+func (s *paymentIntentService) ExpireStalePaymentIntents() (err error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // 重新拋出 panic
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			if commitErr := tx.Commit().Error; commitErr != nil {
+				err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+			}
+		}
+	}()
+
+	expired, err := s.paymentIntentRepo.ExpirePaymentIntents(tx, time.Now())
+	if err != nil {
+		return fmt.Errorf("error expiring payment intents: %w", err)
+	}
+	for _, intent := range expired {
+		if intent.ReservationID == nil {
+			continue
+		}
+		if err = s.failDeposit(tx, *intent.ReservationID); err != nil {
+			return err
+		}
+	}
+	if len(expired) > 0 {
+		log.Printf("[PaymentIntent] 已將 %d 筆逾期的付款意圖標記為 Expired", len(expired))
+	}
+	return nil
+}
+
+// settleAuthorizedPayment 鎖定已授權付款意圖的停車記錄，仍需付款時請款並完成付款
+// 停車記錄已不需此筆付款或請款失敗時，付款意圖會被標記為 Failed
+func (s *paymentIntentService) settleAuthorizedPayment(tx *gorm.DB, provider PaymentProvider, intent *models.PaymentIntent) error {
+	var parkingRecord models.ParkingRecord
+	if queryErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parkingRecord, intent.ParkingRecordID).Error; queryErr != nil {
+		if errors.Is(queryErr, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("parking record ID %d of payment intent ID %d not found", intent.ParkingRecordID, intent.PaymentIntentID)
+		}
+		return fmt.Errorf("error finding parking record ID %d: %w", intent.ParkingRecordID, queryErr)
+	}
+
+	if reason := payableFailureReason(&parkingRecord, intent.TransactionType); reason != "" {
+		// 停車記錄已不需此筆付款，不進行請款以避免重複收費
+		return s.failIntent(tx, intent, reason)
+	}
+
+	if _, settleErr := s.captureAndSettle(tx, provider, intent, &parkingRecord); settleErr != nil {
+		if intent.Status != PaymentProviderStatusFailed {
+			return settleErr
+		}
+		// 請款失敗時保留 Failed 狀態
+		return s.failIntent(tx, intent, intent.FailureReason)
+	}
+	return nil
+}
+
+// settleAuthorizedDeposit 鎖定已授權訂金付款意圖的預約，預約仍在等待訂金時請款並將訂金標記為已付款
+// 預約已取消、過期或車輛已進場時不請款，付款意圖與預約的訂金會被標記為 Failed
+func (s *paymentIntentService) settleAuthorizedDeposit(tx *gorm.DB, provider PaymentProvider, intent *models.PaymentIntent) error {
+	reservation, err := s.reservationRepo.GetReservationByIDForUpdate(tx, *intent.ReservationID)
+	if err != nil {
+		return fmt.Errorf("error finding reservation ID %d: %w", *intent.ReservationID, err)
+	}
+	if reservation == nil {
+		return fmt.Errorf("reservation ID %d of payment intent ID %d not found", *intent.ReservationID, intent.PaymentIntentID)
+	}
+
+	if reason := depositFailureReason(reservation); reason != "" {
+		if err := s.failIntent(tx, intent, reason); err != nil {
+			return err
+		}
+		return s.failDeposit(tx, reservation.ReservationID)
+	}
+
+	if _, settleErr := s.captureAndSettleDeposit(tx, provider, intent, reservation); settleErr != nil {
+		if intent.Status != PaymentProviderStatusFailed {
+			return settleErr
+		}
+		if err := s.failIntent(tx, intent, intent.FailureReason); err != nil {
+			return err
+		}
+		return s.failDeposit(tx, reservation.ReservationID)
+	}
+	return nil
+}
+
+// failIntent 將付款意圖標記為 Failed 並記錄原因
+func (s *paymentIntentService) failIntent(tx *gorm.DB, intent *models.PaymentIntent, reason string) error {
+	intent.Status = PaymentProviderStatusFailed
+	intent.FailureReason = reason
+	if err := s.paymentIntentRepo.UpdatePaymentIntent(tx, intent); err != nil {
+		return fmt.Errorf("failed to update payment intent ID %d: %w", intent.PaymentIntentID, err)
+	}
+	return nil
+}
+
+// failDeposit 將仍在等待付款的預約訂金標記為 Failed，預約本身維持原狀態
+func (s *paymentIntentService) failDeposit(tx *gorm.DB, reservationID uint) error {
+	err := tx.Model(&models.Reservation{}).
+		Where("reservation_id = ? AND deposit_status = ?", reservationID, models.DepositStatusPending).
+		Update("deposit_status", models.DepositStatusFailed).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark deposit of reservation ID %d as failed: %w", reservationID, err)
+	}
+	return nil
+}
+
+// captureAndSettle 對已授權的付款意圖請款，成功後建立交易記錄並將停車記錄標記為已付款
+// 請款失敗時付款意圖會被標記為 Failed 並回傳錯誤
+func (s *paymentIntentService) captureAndSettle(tx *gorm.DB, provider PaymentProvider, intent *models.PaymentIntent, parkingRecord *models.ParkingRecord) (*models.Transaction, error) {
+	transaction, err := s.capture(tx, provider, intent)
+	if err != nil {
+		return nil, err
+	}
+
+	if intent.TransactionType == "Overstay" {
+		// 補繳金額在建立付款意圖時試算，費用計算到該時間，出場寬限期也由此重新起算
+		parkingRecord.CalculatedAmount = parkingRecord.CalculatedAmount.Add(transaction.Amount)
+		parkingRecord.ActualDurationMinutes = parkingDurationMinutes(parkingRecord.EntryTime, intent.CreatedAt)
+		parkingRecord.FeeCalculatedAt = &intent.CreatedAt
+	}
+	parkingRecord.PaymentStatus = "Paid"
+	parkingRecord.PaidAt = &transaction.TransactionTime
+	parkingRecord.TransactionID = &transaction.TransactionID
+	parkingRecord.Transaction = *transaction
+	if err := s.parkingRecordRepo.UpdateParkingRecord(tx, parkingRecord); err != nil {
+		return nil, fmt.Errorf("failed to update parking record status to Paid: %w", err)
+	}
+	return transaction, nil
+}
+
+// captureAndSettleDeposit 對已授權的訂金付款意圖請款，成功後建立交易記錄並將預約的訂金標記為已付款
+// 請款失敗時付款意圖會被標記為 Failed 並回傳錯誤
+func (s *paymentIntentService) captureAndSettleDeposit(tx *gorm.DB, provider PaymentProvider, intent *models.PaymentIntent, reservation *models.Reservation) (*models.Transaction, error) {
+	transaction, err := s.capture(tx, provider, intent)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation.DepositStatus = models.DepositStatusPaid
+	reservation.DepositAmount = transaction.Amount
+	reservation.DepositTransactionID = &transaction.TransactionID
+	if err := s.reservationRepo.UpdateReservation(tx, reservation); err != nil {
+		return nil, fmt.Errorf("failed to update deposit of reservation ID %d to Paid: %w", reservation.ReservationID, err)
+	}
+	return transaction, nil
+}
+
+// capture 對已授權的付款意圖請款，成功後建立交易記錄並將付款意圖標記為 Captured
+// 請款失敗時付款意圖會被標記為 Failed 並回傳錯誤
+func (s *paymentIntentService) capture(tx *gorm.DB, provider PaymentProvider, intent *models.PaymentIntent) (*models.Transaction, error) {
+	captured, err := provider.Capture(intent.ProviderReference, intent.Amount)
+	if err == nil && !captured.Amount.Equal(intent.Amount) {
+		err = fmt.Errorf("amount_mismatch: Provider captured %s but payment intent amount is %s.", captured.Amount, intent.Amount)
+	}
+	if err != nil {
+		intent.Status = PaymentProviderStatusFailed
+		intent.FailureReason = err.Error()
+		return nil, err
+	}
+
+	captured.ClientReference = intent.ClientReference
+	gatewayResponse, err := json.Marshal(captured)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payment provider response: %w", err)
+	}
+
+	transaction := &models.Transaction{
+		ParkingRecordID:        intent.ParkingRecordID,
+		ReservationID:          intent.ReservationID,
+		Amount:                 captured.Amount,
+		TransactionTime:        time.Now(),
+		PaymentMethod:          intent.PaymentMethod,
+		TransactionType:        intent.TransactionType,
+		Status:                 "Success",
+		PaymentProvider:        provider.Name(),
+		ProviderReference:      captured.ProviderReference,
+		PaymentGatewayResponse: string(gatewayResponse),
+	}
+	if err := s.transactionService.CreateTransaction(tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction record: %w", err)
+	}
+	if err := s.paymentIntentRepo.AssignLineItemsToTransaction(tx, intent.PaymentIntentID, transaction.TransactionID); err != nil {
+		return nil, fmt.Errorf("failed to link line items of payment intent ID %d to transaction ID %d: %w", intent.PaymentIntentID, transaction.TransactionID, err)
+	}
+	for i := range intent.LineItems {
+		intent.LineItems[i].TransactionID = &transaction.TransactionID
+	}
+	transaction.LineItems = intent.LineItems
+
+	intent.Status = PaymentProviderStatusCaptured
+	intent.TransactionID = &transaction.TransactionID
+	if err := s.paymentIntentRepo.UpdatePaymentIntent(tx, intent); err != nil {
+		return nil, fmt.Errorf("failed to update payment intent ID %d: %w", intent.PaymentIntentID, err)
+	}
+
+	return transaction, nil
+}
+
+// payableFailureReason 檢查停車記錄是否仍需要此類型的付款，不需要時回傳原因
+func payableFailureReason(parkingRecord *models.ParkingRecord, transactionType string) string {
+	if parkingRecord.ExitTime != nil {
+		return "Vehicle has already exited"
+	}
+	if transactionType == "Overstay" && parkingRecord.PaymentStatus != "Paid" {
+		return "Parking record is no longer in a paid state for an overstay payment"
+	}
+	if transactionType != "Overstay" && parkingRecord.PaymentStatus == "Paid" {
+		return "Parking record has already been paid"
+	}
+	return ""
+}
+
+// depositFailureReason 檢查預約是否仍在等待訂金，不需要時回傳原因
+func depositFailureReason(reservation *models.Reservation) string {
+	if reservation.Status != models.ReservationStatusConfirmed {
+		return fmt.Sprintf("Reservation is %s and no longer accepts a deposit", reservation.Status)
+	}
+	if reservation.DepositStatus == models.DepositStatusPaid {
+		return "Reservation deposit has already been paid"
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hello-professor_backend/models"
+	"net/http"
+	"time"
+)
+
+// 支付供應商回傳的付款狀態
+const (
+	PaymentProviderStatusPending    = "Pending"
+	PaymentProviderStatusAuthorized = "Authorized"
+	PaymentProviderStatusCaptured   = "Captured"
+	PaymentProviderStatusFailed     = "Failed"
+	PaymentProviderStatusRefunded   = "Refunded"
+)
+
+// ErrRefundRejected 表示支付供應商明確拒絕退款；其他錯誤 (例如連線逾時) 無法確定退款是否已完成
+var ErrRefundRejected = errors.New("refund_rejected")
+
+// PaymentProvider 定義支付供應商 (現金、外部支付閘道等) 的介面
+type PaymentProvider interface {
+	// Name 回傳支付供應商名稱
+	Name() string
+	// CreateIntent 向支付供應商建立一筆付款意圖
+	CreateIntent(request PaymentIntentRequest) (*PaymentProviderResult, error)
+	// Capture 請款，確認並收取付款意圖的金額
+	Capture(providerReference string, amount models.Money) (*PaymentProviderResult, error)
+	// Refund 針對已請款的付款進行全額或部分退款，相同 refundReference 的重送只會退款一次
+	// 供應商明確拒絕時回傳包裝 ErrRefundRejected 的錯誤
+	Refund(providerReference string, refundReference string, amount models.Money) (*PaymentProviderResult, error)
+	// ParseCallback 解析支付供應商送來的非同步通知
+	ParseCallback(header http.Header, body []byte) (*PaymentCallback, error)
+}
+
+// PaymentIntentRequest 建立付款意圖所需的資訊
+type PaymentIntentRequest struct {
+	ParkingRecordID uint
+	Amount          models.Money
+	Currency        string
+	PaymentMethod   string
+	// Reference 客戶端提供的付款參考資訊
+	Reference string
+	// LineItems 付款明細，加總需等於 Amount；支付供應商不使用明細
+	LineItems []models.TransactionLineItem
+}
+
+// PaymentProviderResult 支付供應商的處理結果，會以 JSON 形式保存於交易的 PaymentGatewayResponse
+type PaymentProviderResult struct {
+	Provider          string       `json:"provider"`
+	ProviderReference string       `json:"providerReference"`
+	Status            string       `json:"status"`
+	Amount            models.Money `json:"amount"`
+	Currency          string       `json:"currency"`
+	Message           string       `json:"message,omitempty"`
+	ClientReference   string       `json:"clientReference,omitempty"`
+	ProcessedAt       time.Time    `json:"processedAt"`
+}
+
+// PaymentCallback 支付供應商非同步通知的內容
+type PaymentCallback struct {
+	EventID           string       `json:"eventId"`
+	ProviderReference string       `json:"providerReference"`
+	Status            string       `json:"status"`
+	Amount            models.Money `json:"amount"`
+	OccurredAt        time.Time    `json:"occurredAt"`
+}
+
+// PaymentProviderRegistry 依付款方式選擇支付供應商
+type PaymentProviderRegistry struct {
+	providers      map[string]PaymentProvider
+	methodProvider map[string]string
+}
+
+// NewPaymentProviderRegistry 建立一個新的 PaymentProviderRegistry 實例
+// methodProvider 為付款方式與支付供應商名稱的對應
+func NewPaymentProviderRegistry(methodProvider map[string]string, providers ...PaymentProvider) *PaymentProviderRegistry {
+	registry := &PaymentProviderRegistry{
+		providers:      make(map[string]PaymentProvider, len(providers)),
+		methodProvider: methodProvider,
+	}
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+	}
+	return registry
+}
+
+// ForPaymentMethod 取得付款方式對應的支付供應商
+func (r *PaymentProviderRegistry) ForPaymentMethod(paymentMethod string) (PaymentProvider, error) {
+	name, ok := r.methodProvider[paymentMethod]
+	if !ok {
+		return nil, fmt.Errorf("unsupported_payment_method: Payment method %s is not supported.", paymentMethod)
+	}
+	return r.ByName(name)
+}
+
+// ByName 透過名稱取得支付供應商
+func (r *PaymentProviderRegistry) ByName(name string) (PaymentProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported_payment_provider: Payment provider %s is not configured.", name)
+	}
+	return provider, nil
+}
+
+// newProviderReference 產生帶有前綴的隨機支付參考編號
+func newProviderReference(prefix string) string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+	}
+	return prefix + "-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/models"
+	"net/http"
+	"time"
+)
+
+// cashPaymentProvider 現金付款，由現場繳費機或人員收款，請款與退款皆立即完成
+type cashPaymentProvider struct{}
+
+// NewCashPaymentProvider 建立一個新的現金 PaymentProvider 實例
+func NewCashPaymentProvider() PaymentProvider {
+	return &cashPaymentProvider{}
+}
+
+// Name 回傳支付供應商名稱
+func (p *cashPaymentProvider) Name() string {
+	return configs.PaymentProviderCash
+}
+
+// CreateIntent 建立現金付款意圖，現金已在現場收取，建立時即為已授權
+func (p *cashPaymentProvider) CreateIntent(request PaymentIntentRequest) (*PaymentProviderResult, error) {
+	return &PaymentProviderResult{
+		Provider:          p.Name(),
+		ProviderReference: newProviderReference("CASH"),
+		Status:            PaymentProviderStatusAuthorized,
+		Amount:            request.Amount,
+		Currency:          request.Currency,
+		ClientReference:   request.Reference,
+		ProcessedAt:       time.Now(),
+	}, nil
+}
+
+// Capture 現金已收取，直接完成請款
+func (p *cashPaymentProvider) Capture(providerReference string, amount models.Money) (*PaymentProviderResult, error) {
+	return &PaymentProviderResult{
+		Provider:          p.Name(),
+		ProviderReference: providerReference,
+		Status:            PaymentProviderStatusCaptured,
+		Amount:            amount,
+		Currency:          configs.Currency,
+		ProcessedAt:       time.Now(),
+	}, nil
+}
+
+// Refund 現金退款由現場人員退還，直接完成
+func (p *cashPaymentProvider) Refund(providerReference string, refundReference string, amount models.Money) (*PaymentProviderResult, error) {
+	return &PaymentProviderResult{
+		Provider:          p.Name(),
+		ProviderReference: providerReference,
+		Status:            PaymentProviderStatusRefunded,
+		Amount:            amount,
+		Currency:          configs.Currency,
+		ProcessedAt:       time.Now(),
+	}, nil
+}
+
+// ParseCallback 現金付款沒有非同步通知
+func (p *cashPaymentProvider) ParseCallback(header http.Header, body []byte) (*PaymentCallback, error) {
+	return nil, errors.New("unsupported_callback: Cash payments do not send callbacks.")
+}
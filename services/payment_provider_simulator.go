@@ -0,0 +1,236 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/models"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// simulatorSignatureHeader 模擬支付閘道通知簽章的 HTTP 標頭
+const simulatorSignatureHeader = "X-Simulator-Signature"
+
+// simulatorIntent 模擬支付閘道中一筆付款意圖的狀態
+type simulatorIntent struct {
+	amount   models.Money
+	currency string
+	status   string
+	declined bool
+	timeout  bool
+	refunded models.Money
+}
+
+// simulatorPaymentProvider 在本機模擬外部支付閘道的行為，方便離線測試完整付款流程
+// 付款意圖建立後，模擬閘道會在 confirmDelay 後以簽章過的通知回報授權結果，收到 Authorized 後才能請款；
+// 客戶端付款參考包含 "DECLINE" 時會被拒絕，包含 "TIMEOUT" 時閘道不會送出通知，另外會依 declineRate 隨機拒絕付款
+type simulatorPaymentProvider struct {
+	confirmDelay time.Duration
+	declineRate  float64
+	callbackURL  string
+	secret       string
+	httpClient   *http.Client
+
+	mu      sync.Mutex
+	intents map[string]*simulatorIntent
+	refunds map[string]*PaymentProviderResult
+}
+
+// NewSimulatorPaymentProvider 建立一個新的模擬支付閘道 PaymentProvider 實例
+// callbackURL 為接收通知的網址，secret 為通知簽章的密鑰
+func NewSimulatorPaymentProvider(confirmDelay time.Duration, declineRate float64, callbackURL string, secret string) PaymentProvider {
+	return &simulatorPaymentProvider{
+		confirmDelay: confirmDelay,
+		declineRate:  declineRate,
+		callbackURL:  callbackURL,
+		secret:       secret,
+		httpClient:   &http.Client{Timeout: 5 * time.Second},
+		intents:      make(map[string]*simulatorIntent),
+		refunds:      make(map[string]*PaymentProviderResult),
+	}
+}
+
+// Name 回傳支付供應商名稱
+func (p *simulatorPaymentProvider) Name() string {
+	return configs.PaymentProviderSimulator
+}
+
+// CreateIntent 建立付款意圖，授權結果會在 confirmDelay 後以通知送出
+func (p *simulatorPaymentProvider) CreateIntent(request PaymentIntentRequest) (*PaymentProviderResult, error) {
+	reference := newProviderReference("SIM")
+	clientReference := strings.ToUpper(request.Reference)
+
+	intent := &simulatorIntent{
+		amount:   request.Amount,
+		currency: request.Currency,
+		status:   PaymentProviderStatusPending,
+		declined: strings.Contains(clientReference, "DECLINE") || rand.Float64() < p.declineRate,
+		timeout:  strings.Contains(clientReference, "TIMEOUT"),
+	}
+
+	p.mu.Lock()
+	p.intents[reference] = intent
+	p.mu.Unlock()
+
+	if !intent.timeout {
+		time.AfterFunc(p.confirmDelay, func() { p.confirm(reference) })
+	}
+
+	return &PaymentProviderResult{
+		Provider:          p.Name(),
+		ProviderReference: reference,
+		Status:            PaymentProviderStatusPending,
+		Amount:            request.Amount,
+		Currency:          request.Currency,
+		ClientReference:   request.Reference,
+		ProcessedAt:       time.Now(),
+	}, nil
+}
+
+// Capture 對已授權的付款請款
+func (p *simulatorPaymentProvider) Capture(providerReference string, amount models.Money) (*PaymentProviderResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[providerReference]
+	if !ok {
+		return nil, fmt.Errorf("payment_intent_not_found: Simulator has no payment intent %s.", providerReference)
+	}
+	if !intent.amount.Equal(amount) {
+		return nil, fmt.Errorf("amount_mismatch: Capture amount (%s) does not match intent amount (%s).", amount, intent.amount)
+	}
+
+	switch intent.status {
+	case PaymentProviderStatusAuthorized:
+		intent.status = PaymentProviderStatusCaptured
+	case PaymentProviderStatusCaptured:
+		// 重複請款時回傳相同結果
+	case PaymentProviderStatusFailed:
+		return nil, fmt.Errorf("payment_declined: Payment %s was declined by the payment provider.", providerReference)
+	default:
+		return nil, fmt.Errorf("payment_not_authorized: Payment %s has not been authorized yet.", providerReference)
+	}
+
+	return &PaymentProviderResult{
+		Provider:          p.Name(),
+		ProviderReference: providerReference,
+		Status:            intent.status,
+		Amount:            intent.amount,
+		Currency:          intent.currency,
+		ProcessedAt:       time.Now(),
+	}, nil
+}
+
+// Refund 對已請款的付款進行退款，累計退款金額不可超過請款金額；相同 refundReference 的重送回傳第一次的結果
+func (p *simulatorPaymentProvider) Refund(providerReference string, refundReference string, amount models.Money) (*PaymentProviderResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if result, ok := p.refunds[refundReference]; ok {
+		return result, nil
+	}
+
+	intent, ok := p.intents[providerReference]
+	if !ok {
+		return nil, fmt.Errorf("%w: Simulator has no payment intent %s.", ErrRefundRejected, providerReference)
+	}
+	if intent.status != PaymentProviderStatusCaptured {
+		return nil, fmt.Errorf("%w: Payment %s is %s and cannot be refunded.", ErrRefundRejected, providerReference, intent.status)
+	}
+	if intent.refunded.Add(amount).GreaterThan(intent.amount) {
+		return nil, fmt.Errorf("%w: Refund of %s exceeds the remaining captured amount of payment %s.", ErrRefundRejected, amount, providerReference)
+	}
+	intent.refunded = intent.refunded.Add(amount)
+
+	result := &PaymentProviderResult{
+		Provider:          p.Name(),
+		ProviderReference: newProviderReference("SIMR"),
+		Status:            PaymentProviderStatusRefunded,
+		Amount:            amount,
+		Currency:          intent.currency,
+		Message:           "Refund of " + providerReference,
+		ClientReference:   refundReference,
+		ProcessedAt:       time.Now(),
+	}
+	p.refunds[refundReference] = result
+	return result, nil
+}
+
+// ParseCallback 驗證模擬閘道通知的簽章並解析 JSON 內容
+func (p *simulatorPaymentProvider) ParseCallback(header http.Header, body []byte) (*PaymentCallback, error) {
+	if !verifyWebhookSignature(p.secret, body, header.Get(simulatorSignatureHeader)) {
+		return nil, errors.New("invalid_signature: Simulator callback signature verification failed.")
+	}
+
+	var callback PaymentCallback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return nil, fmt.Errorf("invalid_callback: Cannot parse simulator callback: %w", err)
+	}
+	if callback.EventID == "" || callback.ProviderReference == "" {
+		return nil, errors.New("invalid_callback: Simulator callback is missing eventId or providerReference.")
+	}
+	return &callback, nil
+}
+
+// confirm 決定付款意圖的授權結果並送出通知
+func (p *simulatorPaymentProvider) confirm(providerReference string) {
+	p.mu.Lock()
+	intent, ok := p.intents[providerReference]
+	if !ok || intent.status != PaymentProviderStatusPending {
+		p.mu.Unlock()
+		return
+	}
+	if intent.declined {
+		intent.status = PaymentProviderStatusFailed
+	} else {
+		intent.status = PaymentProviderStatusAuthorized
+	}
+	callback := PaymentCallback{
+		EventID:           newProviderReference("EVT"),
+		ProviderReference: providerReference,
+		Status:            intent.status,
+		Amount:            intent.amount,
+		OccurredAt:        time.Now(),
+	}
+	p.mu.Unlock()
+
+	p.sendCallback(callback)
+}
+
+// sendCallback 將簽章過的通知送到 callbackURL，失敗時會重送
+func (p *simulatorPaymentProvider) sendCallback(callback PaymentCallback) {
+	body, err := json.Marshal(callback)
+	if err != nil {
+		log.Printf("[Simulator] 無法編碼通知 %s: %v", callback.EventID, err)
+		return
+	}
+	url := strings.TrimRight(p.callbackURL, "/") + "/" + p.Name()
+
+	for attempt := 1; attempt <= configs.SimulatorCallbackRetries; attempt++ {
+		request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			log.Printf("[Simulator] 無法建立通知請求 %s: %v", callback.EventID, err)
+			return
+		}
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(simulatorSignatureHeader, signWebhookPayload(p.secret, body))
+
+		response, err := p.httpClient.Do(request)
+		if err == nil {
+			response.Body.Close()
+			if response.StatusCode < 300 {
+				return
+			}
+			err = fmt.Errorf("unexpected status %d", response.StatusCode)
+		}
+		log.Printf("[Simulator] 通知 %s 第 %d 次送出失敗: %v", callback.EventID, attempt, err)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// webhookSignaturePrefix 簽章標頭值的前綴，格式為 "sha256=<hex>"
+const webhookSignaturePrefix = "sha256="
+
+// signWebhookPayload 以 HMAC-SHA256 對通知內容簽章
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyWebhookSignature 以固定時間比較驗證通知內容的 HMAC-SHA256 簽章
+func verifyWebhookSignature(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(signWebhookPayload(secret, body)), []byte(signature))
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/licenseplate"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"strings"
+	"time"
+)
+
+// PermitDiscountCode 定期停車證在費用明細中的折抵代碼
+const PermitDiscountCode = "PERMIT"
+
+// PermitService 定義定期停車證服務的介面
+type PermitService interface {
+	CreatePermit(payload dtos.PermitPayload) (*models.Permit, error)
+	GetPermitByID(id uint) (*models.Permit, error)
+	GetAllPermits(limit int, offset int) ([]models.Permit, error)
+	UpdatePermit(id uint, payload dtos.PermitPayload) (*models.Permit, error)
+	DeletePermit(id uint) error
+	FindActivePermit(licensePlate string, parkingLotID uint, at time.Time) (*models.Permit, error)
+	CalculatePermitDiscount(record *models.ParkingRecord, fee dtos.FeeBreakdown, calculateUntil time.Time) (*dtos.FeeDiscount, error)
+	GetPermitExpiryReport(withinDays int) (*dtos.PermitExpiryReportResponse, error)
+}
+
+// permitService 是 PermitService 的實作
+type permitService struct {
+	permitRepo        repositories.PermitRepository
+	parkingLotService ParkingLotService
+	location          *time.Location
+}
+
+// NewPermitService 建立一個新的 PermitService 實例，location 為判斷每日允許時段所用的時區
+func NewPermitService(permitRepo repositories.PermitRepository, pls ParkingLotService, location *time.Location) PermitService {
+	return &permitService{
+		permitRepo:        permitRepo,
+		parkingLotService: pls,
+		location:          location,
+	}
+}
+
+// CreatePermit 新增定期停車證
+func (s *permitService) CreatePermit(payload dtos.PermitPayload) (*models.Permit, error) {
+	permit := &models.Permit{}
+	if err := s.applyPermitPayload(permit, payload); err != nil {
+		return nil, err
+	}
+	if err := s.permitRepo.CreatePermit(permit); err != nil {
+		return nil, fmt.Errorf("error creating permit: %w", err)
+	}
+	return permit, nil
+}
+
+// GetPermitByID 呼叫 repository 透過 ID 取得定期停車證
+func (s *permitService) GetPermitByID(id uint) (*models.Permit, error) {
+	return s.permitRepo.GetPermitByID(id)
+}
+
+// GetAllPermits 呼叫 repository 取得所有定期停車證 (分頁)
+func (s *permitService) GetAllPermits(limit int, offset int) ([]models.Permit, error) {
+	return s.permitRepo.GetAllPermits(limit, offset)
+}
+
+// UpdatePermit 以新的內容取代定期停車證
+func (s *permitService) UpdatePermit(id uint, payload dtos.PermitPayload) (*models.Permit, error) {
+	permit, err := s.permitRepo.GetPermitByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding permit ID %d: %w", id, err)
+	}
+	if permit == nil {
+		return nil, fmt.Errorf("permit ID %d not found", id)
+	}
+	if err := s.applyPermitPayload(permit, payload); err != nil {
+		return nil, err
+	}
+	if err := s.permitRepo.UpdatePermit(permit); err != nil {
+		return nil, fmt.Errorf("error updating permit ID %d: %w", id, err)
+	}
+	return permit, nil
+}
+
+// DeletePermit 刪除定期停車證，已進場的停車記錄之後改以一般費率計費
+func (s *permitService) DeletePermit(id uint) error {
+	permit, err := s.permitRepo.GetPermitByID(id)
+	if err != nil {
+		return fmt.Errorf("error finding permit ID %d: %w", id, err)
+	}
+	if permit == nil {
+		return fmt.Errorf("permit ID %d not found", id)
+	}
+	return s.permitRepo.DeletePermit(id)
+}
+
+// FindActivePermit 取得車牌在指定時間適用於該停車場的定期停車證，沒有則回傳 nil
+func (s *permitService) FindActivePermit(licensePlate string, parkingLotID uint, at time.Time) (*models.Permit, error) {
+	return s.permitRepo.FindActivePermitByLicensePlate(licensePlate, parkingLotID, at)
+}
+
+// CalculatePermitDiscount 計算定期停車證折抵的金額
+// 停車期間落在停車證有效期間與每日允許時段內的部分免費，其餘部分依一般費率計費；
+// 停車記錄沒有停車證或停車證已被刪除時回傳 nil
+func (s *permitService) CalculatePermitDiscount(record *models.ParkingRecord, fee dtos.FeeBreakdown, calculateUntil time.Time) (*dtos.FeeDiscount, error) {
+	if record.PermitID == nil {
+		return nil, nil
+	}
+	permit, err := s.permitRepo.GetPermitByID(*record.PermitID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding permit ID %d of parking record ID %d: %w", *record.PermitID, record.RecordID, err)
+	}
+	if permit == nil {
+		return nil, nil
+	}
+	tariff, err := s.parkingLotService.TariffForParkingLot(record.ParkingLotID, record.VehicleClass)
+	if err != nil {
+		return nil, err
+	}
+
+	// 依一般費率計算未被停車證涵蓋的時段
+	var charge models.Money
+	cursor := record.EntryTime
+	for _, covered := range s.coveredIntervals(permit, record.EntryTime, calculateUntil) {
+		if covered[0].After(cursor) {
+			charge = charge.Add(tariff.Calculate(cursor, covered[0]).TotalAmount)
+		}
+		cursor = maxTime(cursor, covered[1])
+	}
+	if calculateUntil.After(cursor) {
+		charge = charge.Add(tariff.Calculate(cursor, calculateUntil).TotalAmount)
+	}
+
+	discount := models.MaxMoney(fee.TotalAmount.Sub(charge), models.Money{})
+	return &dtos.FeeDiscount{
+		Code:        PermitDiscountCode,
+		Description: fmt.Sprintf("%s permit #%d (%s)", permit.PermitType, permit.PermitID, formatAllowedHours(permit)),
+		Amount:      discount,
+	}, nil
+}
+
+// GetPermitExpiryReport 列出 withinDays 天內即將到期與最近 withinDays 天內已到期的定期停車證
+func (s *permitService) GetPermitExpiryReport(withinDays int) (*dtos.PermitExpiryReportResponse, error) {
+	now := time.Now()
+	window := time.Duration(withinDays) * 24 * time.Hour
+
+	expiring, err := s.permitRepo.GetPermitsExpiringBetween(now, now.Add(window))
+	if err != nil {
+		return nil, fmt.Errorf("error getting expiring permits: %w", err)
+	}
+	expired, err := s.permitRepo.GetPermitsExpiringBetween(now.Add(-window), now)
+	if err != nil {
+		return nil, fmt.Errorf("error getting expired permits: %w", err)
+	}
+
+	return &dtos.PermitExpiryReportResponse{
+		WithinDays:      withinDays,
+		GeneratedAt:     now,
+		Expiring:        permitExpiries(expiring, now),
+		RecentlyExpired: permitExpiries(expired, now),
+	}, nil
+}
+
+// coveredIntervals 回傳 [start, end) 中被停車證涵蓋的時段，依時間排序且互不重疊
+func (s *permitService) coveredIntervals(permit *models.Permit, start, end time.Time) [][2]time.Time {
+	from := maxTime(start, permit.ValidFrom)
+	until := minTime(end, permit.ValidUntil)
+	if !until.After(from) {
+		return nil
+	}
+	if permit.AllowedStartMinute == permit.AllowedEndMinute {
+		return [][2]time.Time{{from, until}}
+	}
+
+	var intervals [][2]time.Time
+	// 從前一天開始，以涵蓋跨夜時段
+	for day := startOfDay(from.In(s.location)).AddDate(0, 0, -1); day.Before(until); day = day.AddDate(0, 0, 1) {
+		windowStart := day.Add(time.Duration(permit.AllowedStartMinute) * time.Minute)
+		windowEnd := day.Add(time.Duration(permit.AllowedEndMinute) * time.Minute)
+		if permit.AllowedEndMinute < permit.AllowedStartMinute {
+			windowEnd = windowEnd.AddDate(0, 0, 1)
+		}
+		intervalStart := maxTime(windowStart, from)
+		intervalEnd := minTime(windowEnd, until)
+		if intervalEnd.After(intervalStart) {
+			intervals = append(intervals, [2]time.Time{intervalStart, intervalEnd})
+		}
+	}
+	return intervals
+}
+
+// applyPermitPayload 驗證並將 payload 的內容寫入 permit
+func (s *permitService) applyPermitPayload(permit *models.Permit, payload dtos.PermitPayload) error {
+	if !payload.ValidUntil.After(payload.ValidFrom) {
+		return errors.New("invalid_permit: validUntil must be after validFrom.")
+	}
+	startMinute, err := parseClockMinute(payload.AllowedStartTime)
+	if err != nil {
+		return fmt.Errorf("invalid_permit: allowedStartTime %w", err)
+	}
+	endMinute, err := parseClockMinute(payload.AllowedEndTime)
+	if err != nil {
+		return fmt.Errorf("invalid_permit: allowedEndTime %w", err)
+	}
+
+	plates := []models.PermitPlate{}
+	seen := map[string]bool{}
+	for _, plate := range payload.LicensePlates {
+		normalized := licenseplate.Normalize(plate)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		plates = append(plates, models.PermitPlate{LicensePlate: normalized})
+	}
+	if len(plates) == 0 {
+		return errors.New("invalid_permit: At least one license plate is required.")
+	}
+	if payload.ParkingLotID != nil {
+		parkingLot, err := s.parkingLotService.GetParkingLotByID(*payload.ParkingLotID)
+		if err != nil {
+			return fmt.Errorf("error finding parking lot ID %d: %w", *payload.ParkingLotID, err)
+		}
+		if parkingLot == nil {
+			return fmt.Errorf("invalid_permit: Parking lot ID %d does not exist.", *payload.ParkingLotID)
+		}
+	}
+
+	permit.HolderName = strings.TrimSpace(payload.HolderName)
+	permit.PermitType = payload.PermitType
+	if permit.PermitType == "" {
+		permit.PermitType = "Monthly"
+	}
+	permit.ParkingLotID = payload.ParkingLotID
+	permit.ValidFrom = payload.ValidFrom
+	permit.ValidUntil = payload.ValidUntil
+	permit.AllowedStartMinute = startMinute
+	permit.AllowedEndMinute = endMinute
+	permit.Plates = plates
+	return nil
+}
+
+// parseClockMinute 將 "HH:MM" 轉為當日 00:00 起算的分鐘數，空字串視為 00:00
+func parseClockMinute(clock string) (int, error) {
+	if clock == "" {
+		return 0, nil
+	}
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("must be in HH:MM format, got %q", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// formatAllowedHours 以 "HH:MM-HH:MM" 格式顯示停車證的每日允許時段
+func formatAllowedHours(permit *models.Permit) string {
+	if permit.AllowedStartMinute == permit.AllowedEndMinute {
+		return "all day"
+	}
+	return fmt.Sprintf("%02d:%02d-%02d:%02d",
+		permit.AllowedStartMinute/60, permit.AllowedStartMinute%60,
+		permit.AllowedEndMinute/60, permit.AllowedEndMinute%60)
+}
+
+// permitExpiries 將定期停車證轉為到期報表的項目
+func permitExpiries(permits []models.Permit, now time.Time) []dtos.PermitExpiryResponse {
+	expiries := []dtos.PermitExpiryResponse{}
+	for _, permit := range permits {
+		plates := make([]string, 0, len(permit.Plates))
+		for _, plate := range permit.Plates {
+			plates = append(plates, plate.LicensePlate)
+		}
+		expiries = append(expiries, dtos.PermitExpiryResponse{
+			PermitID:      permit.PermitID,
+			HolderName:    permit.HolderName,
+			PermitType:    permit.PermitType,
+			LicensePlates: plates,
+			ValidUntil:    permit.ValidUntil,
+			DaysRemaining: int(permit.ValidUntil.Sub(now).Hours() / 24),
+		})
+	}
+	return expiries
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/licenseplate"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlateReviewService 定義車牌人工審核服務的介面
+// 進場 OCR 信心分數低於門檻的停車記錄排入審核，審核人員確認或修正車牌後寫入停車記錄的 UserVerifiedLicensePlate
+type PlateReviewService interface {
+	QueuePlateReview(tx *gorm.DB, record *models.ParkingRecord, ocr dtos.OcrReading) (*models.PlateReview, error)
+	GetPlateReviewByID(id uint) (*models.PlateReview, error)
+	GetPlateReviews(parkingLotID *uint, status string, reviewedBy string, limit int, offset int) ([]models.PlateReview, error)
+	ApprovePlateReview(id uint, payload dtos.ApprovePlateReviewPayload) (*models.PlateReview, error)
+	CorrectPlateReview(id uint, payload dtos.CorrectPlateReviewPayload) (*models.PlateReview, error)
+	GetPlateReviewThroughput(parkingLotID *uint, startTime, endTime *time.Time) (*dtos.PlateReviewThroughputResponse, error)
+}
+
+// plateReviewService 是 PlateReviewService 的實作
+type plateReviewService struct {
+	plateReviewRepo     repositories.PlateReviewRepository
+	vehicleService      VehicleService
+	confidenceThreshold float64
+	db                  *gorm.DB
+}
+
+// NewPlateReviewService 建立一個新的 PlateReviewService 實例，OCR 信心分數低於 confidenceThreshold 的車牌需人工審核
+func NewPlateReviewService(plateReviewRepo repositories.PlateReviewRepository, vs VehicleService, confidenceThreshold float64, db *gorm.DB) PlateReviewService {
+	return &plateReviewService{
+		plateReviewRepo:     plateReviewRepo,
+		vehicleService:      vs,
+		confidenceThreshold: confidenceThreshold,
+		db:                  db,
+	}
+}
+
+// QueuePlateReview 在進場的資料庫交易中，將 OCR 信心分數低於門檻的停車記錄排入審核
+// 信心分數達到門檻時不需審核，回傳 nil；其他可能車牌會轉為標準格式，並略過與辨識結果相同或重複者
+func (s *plateReviewService) QueuePlateReview(tx *gorm.DB, record *models.ParkingRecord, ocr dtos.OcrReading) (*models.PlateReview, error) {
+	if ocr.Confidence >= s.confidenceThreshold {
+		return nil, nil
+	}
+
+	review := &models.PlateReview{
+		ParkingRecordID: record.RecordID,
+		ParkingLotID:    record.ParkingLotID,
+		LicensePlate:    record.LicensePlate,
+		RawLicensePlate: record.RawLicensePlate,
+		OcrConfidence:   ocr.Confidence,
+		Status:          models.PlateReviewStatusPending,
+	}
+	seen := map[string]bool{record.LicensePlate: true}
+	for _, alternative := range ocr.Alternatives {
+		normalized := licenseplate.Normalize(alternative)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		review.Alternatives = append(review.Alternatives, models.PlateReviewAlternative{LicensePlate: normalized})
+	}
+
+	if err := s.plateReviewRepo.CreatePlateReview(tx, review); err != nil {
+		return nil, fmt.Errorf("error queuing plate review for parking record ID %d: %w", record.RecordID, err)
+	}
+	log.Printf("[QueuePlateReview] Parking record ID %d with plate %s queued for review: OCR confidence %.2f below %.2f", record.RecordID, record.LicensePlate, ocr.Confidence, s.confidenceThreshold)
+	return review, nil
+}
+
+// GetPlateReviewByID 呼叫 repository 透過 ID 取得車牌審核項目
+func (s *plateReviewService) GetPlateReviewByID(id uint) (*models.PlateReview, error) {
+	return s.plateReviewRepo.GetPlateReviewByID(id)
+}
+
+// GetPlateReviews 依停車場、狀態與審核人員篩選車牌審核項目 (分頁)，篩選條件為零值時不篩選
+func (s *plateReviewService) GetPlateReviews(parkingLotID *uint, status string, reviewedBy string, limit int, offset int) ([]models.PlateReview, error) {
+	return s.plateReviewRepo.GetPlateReviews(repositories.PlateReviewFilter{
+		ParkingLotID: parkingLotID,
+		Status:       status,
+		ReviewedBy:   reviewedBy,
+	}, limit, offset)
+}
+
+// ApprovePlateReview 確認 OCR 辨識的車牌正確，並寫入停車記錄的 UserVerifiedLicensePlate
+func (s *plateReviewService) ApprovePlateReview(id uint, payload dtos.ApprovePlateReviewPayload) (*models.PlateReview, error) {
+	return s.completePlateReview(id, payload.ReviewedBy, "")
+}
+
+// CorrectPlateReview 將停車記錄的車牌修正為審核人員確認的車牌，並寫入 UserVerifiedLicensePlate
+// 修正後的車牌與 OCR 辨識結果相同時視為確認
+func (s *plateReviewService) CorrectPlateReview(id uint, payload dtos.CorrectPlateReviewPayload) (*models.PlateReview, error) {
+	licensePlate := licenseplate.Normalize(payload.LicensePlate)
+	if licensePlate == "" {
+		return nil, errors.New("invalid_license_plate: License plate is required.")
+	}
+	return s.completePlateReview(id, payload.ReviewedBy, licensePlate)
+}
+
+// completePlateReview 在資料庫交易中完成待審核項目，verifiedLicensePlate 為空字串時確認 OCR 辨識的車牌
+// 車牌被修正時，停車記錄改為連結修正後車牌的車輛資料
+func (s *plateReviewService) completePlateReview(id uint, reviewedBy string, verifiedLicensePlate string) (review *models.PlateReview, err error) {
+	reviewedBy = strings.TrimSpace(reviewedBy)
+	if reviewedBy == "" {
+		return nil, errors.New("invalid_review: Reviewer is required.")
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+		}
+	}()
+
+	review, err = s.plateReviewRepo.GetPlateReviewByIDForUpdate(tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding plate review ID %d: %w", id, err)
+	}
+	if review == nil {
+		return nil, fmt.Errorf("plate review ID %d not found", id)
+	}
+	if review.Status != models.PlateReviewStatusPending {
+		return nil, fmt.Errorf("review_closed: Plate review ID %d was already %s by %s.", id, strings.ToLower(review.Status), *review.ReviewedBy)
+	}
+
+	var record models.ParkingRecord
+	if queryErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&record, review.ParkingRecordID).Error; queryErr != nil {
+		if errors.Is(queryErr, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("parking record ID %d of plate review ID %d not found", review.ParkingRecordID, id)
+		}
+		return nil, fmt.Errorf("error finding parking record ID %d: %w", review.ParkingRecordID, queryErr)
+	}
+
+	review.Status = models.PlateReviewStatusCorrected
+	if verifiedLicensePlate == "" || verifiedLicensePlate == review.LicensePlate {
+		verifiedLicensePlate = review.LicensePlate
+		review.Status = models.PlateReviewStatusApproved
+	}
+	now := time.Now()
+	review.VerifiedLicensePlate = &verifiedLicensePlate
+	review.ReviewedBy = &reviewedBy
+	review.ReviewedAt = &now
+	if err = s.plateReviewRepo.UpdatePlateReview(tx, review); err != nil {
+		return nil, fmt.Errorf("error updating plate review ID %d: %w", id, err)
+	}
+
+	vehicle, err := s.vehicleService.FindOrCreateVehicle(tx, verifiedLicensePlate, record.EntryTime)
+	if err != nil {
+		return nil, err
+	}
+	record.UserVerifiedLicensePlate = &verifiedLicensePlate
+	record.VehicleID = &vehicle.VehicleID
+	if err = tx.Model(&record).Updates(map[string]interface{}{"user_verified_license_plate": verifiedLicensePlate, "vehicle_id": vehicle.VehicleID}).Error; err != nil {
+		return nil, fmt.Errorf("error updating verified license plate of parking record ID %d: %w", record.RecordID, err)
+	}
+	review.ParkingRecord = &record
+	return review, nil
+}
+
+// GetPlateReviewThroughput 統計指定時間範圍內排入與完成的審核數量、修正比例、平均等候時間與各審核人員的處理量，並列出目前待審核的數量
+func (s *plateReviewService) GetPlateReviewThroughput(parkingLotID *uint, startTime, endTime *time.Time) (*dtos.PlateReviewThroughputResponse, error) {
+	queued, err := s.plateReviewRepo.CountPlateReviewsCreated(parkingLotID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("error counting queued plate reviews: %w", err)
+	}
+	pending, oldestPendingAt, err := s.plateReviewRepo.CountPendingPlateReviews(parkingLotID)
+	if err != nil {
+		return nil, fmt.Errorf("error counting pending plate reviews: %w", err)
+	}
+	stats, err := s.plateReviewRepo.GetReviewerStats(parkingLotID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reviewer stats: %w", err)
+	}
+
+	// 時間範圍完整時才能換算每小時的處理量
+	var hours float64
+	if startTime != nil && endTime != nil && endTime.After(*startTime) {
+		hours = endTime.Sub(*startTime).Hours()
+	}
+	perHour := func(count int64) *float64 {
+		if hours == 0 {
+			return nil
+		}
+		rate := float64(count) / hours
+		return &rate
+	}
+
+	response := &dtos.PlateReviewThroughputResponse{
+		QueuedCount:     queued,
+		PendingCount:    pending,
+		OldestPendingAt: oldestPendingAt,
+		ByReviewer:      []dtos.ReviewerThroughputResponse{},
+	}
+	var totalWaitSeconds float64
+	for _, stat := range stats {
+		reviewed := stat.ApprovedCount + stat.CorrectedCount
+		response.ByReviewer = append(response.ByReviewer, dtos.ReviewerThroughputResponse{
+			ReviewedBy:         stat.ReviewedBy,
+			ReviewedCount:      reviewed,
+			ApprovedCount:      stat.ApprovedCount,
+			CorrectedCount:     stat.CorrectedCount,
+			AverageWaitSeconds: stat.AverageWaitSeconds,
+			ReviewsPerHour:     perHour(reviewed),
+		})
+		response.ReviewedCount += reviewed
+		response.ApprovedCount += stat.ApprovedCount
+		response.CorrectedCount += stat.CorrectedCount
+		totalWaitSeconds += stat.AverageWaitSeconds * float64(reviewed)
+	}
+	if response.ReviewedCount > 0 {
+		response.CorrectionRate = float64(response.CorrectedCount) / float64(response.ReviewedCount)
+		response.AverageWaitSeconds = totalWaitSeconds / float64(response.ReviewedCount)
+	}
+	response.ReviewsPerHour = perHour(response.ReviewedCount)
+	return response, nil
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/licenseplate"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReservationService 定義車位預約服務的介面
+// 預約的車位自開始時間前 configs.ReservationEarlyArrival 起保留到預約結束，預約車輛在開始時間前後的抵達時段內進場時會連結預約
+type ReservationService interface {
+	CreateReservation(payload dtos.ReservationPayload) (*models.Reservation, *models.PaymentIntent, error)
+	GetReservationByID(id uint) (*models.Reservation, error)
+	GetReservations(parkingLotID *uint, licensePlate string, status string, limit int, offset int) ([]models.Reservation, error)
+	CancelReservation(id uint) (*models.Reservation, *models.Transaction, error)
+	HeldSpots(tx *gorm.DB, parkingLotID uint, vehicleClass string, at time.Time, excludeLicensePlate string) (int64, error)
+	HeldSpotsByParkingLot(at time.Time) (map[uint]map[string]int64, error)
+	FindArrivingReservation(tx *gorm.DB, parkingLotID uint, licensePlate string, vehicleClass string, at time.Time) (*models.Reservation, error)
+	FulfillReservation(tx *gorm.DB, reservation *models.Reservation, parkingRecordID uint) error
+	ExpireNoShowReservations() error
+	SumForfeitedDeposits(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]models.Money, error)
+}
+
+// reservationService 是 ReservationService 的實作
+type reservationService struct {
+	reservationRepo      repositories.ReservationRepository
+	parkingRecordRepo    repositories.ParkingRecordRepository
+	parkingLotService    ParkingLotService
+	paymentIntentService PaymentIntentService
+	transactionService   TransactionService
+	db                   *gorm.DB
+}
+
+// NewReservationService 建立一個新的 ReservationService 實例
+func NewReservationService(resRepo repositories.ReservationRepository, prRepo repositories.ParkingRecordRepository, pls ParkingLotService, pis PaymentIntentService, ts TransactionService, db *gorm.DB) ReservationService {
+	return &reservationService{
+		reservationRepo:      resRepo,
+		parkingRecordRepo:    prRepo,
+		parkingLotService:    pls,
+		paymentIntentService: pis,
+		transactionService:   ts,
+		db:                   db,
+	}
+}
+
+// CreateReservation 為車牌預約停車場指定車種的車位，可同時預付訂金
+// 與預約時段重疊的預約數量不可超過該車種非定期停車證可用的車位數；預約立即開始保留時，還需扣除目前場內的車輛
+// 訂金透過支付供應商付款，非同步付款方式會回傳 Pending 的付款意圖，付款完成前預約仍會保留車位
+func (s *reservationService) CreateReservation(payload dtos.ReservationPayload) (reservation *models.Reservation, intent *models.PaymentIntent, err error) {
+	licensePlate := licenseplate.Normalize(payload.LicensePlate)
+	if licensePlate == "" {
+		return nil, nil, errors.New("invalid_reservation: License plate is required.")
+	}
+	vehicleClass := payload.VehicleClass
+	if vehicleClass == "" {
+		vehicleClass = models.VehicleClassCar
+	}
+	if !models.IsValidVehicleClass(vehicleClass) {
+		return nil, nil, fmt.Errorf("invalid_vehicle_class: Unknown vehicle class %q.", vehicleClass)
+	}
+	now := time.Now()
+	if !payload.EndTime.After(payload.StartTime) {
+		return nil, nil, errors.New("invalid_reservation: End time must be after start time.")
+	}
+	if payload.EndTime.Sub(payload.StartTime) > configs.ReservationMaxDuration {
+		return nil, nil, fmt.Errorf("invalid_reservation: A reservation cannot be longer than %v.", configs.ReservationMaxDuration)
+	}
+	if payload.StartTime.Add(configs.ReservationNoShowGrace).Before(now) {
+		return nil, nil, errors.New("invalid_reservation: Start time has already passed.")
+	}
+
+	parkingLot, err := s.parkingLotService.ResolveParkingLot(payload.ParkingLotID, "", "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+		}
+	}()
+
+	// 與進場使用相同的停車場鎖，避免同時預約或進場超出容量
+	lockedParkingLot, err := s.parkingLotService.LockParkingLot(tx, parkingLot.ParkingLotID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding parking lot ID %d: %w", parkingLot.ParkingLotID, err)
+	}
+	if lockedParkingLot == nil {
+		return nil, nil, fmt.Errorf("parking lot ID %d not found", parkingLot.ParkingLotID)
+	}
+	parkingLot = lockedParkingLot
+	if !parkingLot.Active {
+		return nil, nil, fmt.Errorf("parking_lot_inactive: Parking lot ID %d is not accepting vehicles.", parkingLot.ParkingLotID)
+	}
+	if vehicleClass != models.VehicleClassCar && parkingLot.RateCalendarFor(vehicleClass) == "" {
+		return nil, nil, fmt.Errorf("vehicle_class_not_accepted: Parking lot ID %d does not accept %s.", parkingLot.ParkingLotID, vehicleClass)
+	}
+
+	// 預約保留車位的時段為 [開始時間 - 提早抵達時間, 結束時間)，與此時段重疊的預約皆計入
+	holdStart := payload.StartTime.Add(-configs.ReservationEarlyArrival)
+	overlapping, err := s.reservationRepo.CountConfirmedReservations(tx, parkingLot.ParkingLotID, vehicleClass, payload.EndTime.Add(configs.ReservationEarlyArrival), holdStart, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error counting reservations in parking lot ID %d: %w", parkingLot.ParkingLotID, err)
+	}
+	overlappingOthers, err := s.reservationRepo.CountConfirmedReservations(tx, parkingLot.ParkingLotID, vehicleClass, payload.EndTime.Add(configs.ReservationEarlyArrival), holdStart, licensePlate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error counting reservations in parking lot ID %d: %w", parkingLot.ParkingLotID, err)
+	}
+	if overlapping > overlappingOthers {
+		return nil, nil, fmt.Errorf("reservation_exists: License plate %s already has a reservation in parking lot ID %d overlapping this time window.", licensePlate, parkingLot.ParkingLotID)
+	}
+
+	publicSpots := int64(parkingLot.CapacityFor(vehicleClass) - reservedSpotsFor(*parkingLot, vehicleClass))
+	if overlapping >= publicSpots {
+		return nil, nil, fmt.Errorf("reservation_unavailable: Parking lot ID %d has no %s spots left to reserve between %v and %v.", parkingLot.ParkingLotID, vehicleClass, payload.StartTime, payload.EndTime)
+	}
+	if !holdStart.After(now) {
+		occupiedSpots, countErr := s.parkingRecordRepo.CountActiveParkingRecords(tx, parkingLot.ParkingLotID, vehicleClass)
+		if countErr != nil {
+			return nil, nil, fmt.Errorf("error counting vehicles in parking lot ID %d: %w", parkingLot.ParkingLotID, countErr)
+		}
+		heldSpots, countErr := s.HeldSpots(tx, parkingLot.ParkingLotID, vehicleClass, now, "")
+		if countErr != nil {
+			return nil, nil, countErr
+		}
+		if occupiedSpots+heldSpots >= publicSpots {
+			return nil, nil, fmt.Errorf("reservation_unavailable: Parking lot ID %d has no %s spots left to reserve right now.", parkingLot.ParkingLotID, vehicleClass)
+		}
+	}
+
+	reservation = &models.Reservation{
+		ParkingLotID:  parkingLot.ParkingLotID,
+		VehicleClass:  vehicleClass,
+		LicensePlate:  licensePlate,
+		StartTime:     payload.StartTime,
+		EndTime:       payload.EndTime,
+		Status:        models.ReservationStatusConfirmed,
+		DepositStatus: models.DepositStatusNone,
+	}
+	if payload.PaymentMethod != "" {
+		reservation.DepositStatus = models.DepositStatusPending
+		reservation.DepositAmount = configs.ReservationDeposit
+	}
+	if err = s.reservationRepo.CreateReservation(tx, reservation); err != nil {
+		return nil, nil, fmt.Errorf("error creating reservation: %w", err)
+	}
+
+	if payload.PaymentMethod != "" {
+		intent, _, err = s.paymentIntentService.StartDepositPayment(tx, reservation, PaymentIntentRequest{
+			Amount:        configs.ReservationDeposit,
+			Currency:      configs.Currency,
+			PaymentMethod: payload.PaymentMethod,
+			Reference:     payload.PaymentReference,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return reservation, intent, nil
+}
+
+// GetReservationByID 呼叫 repository 透過 ID 取得預約
+func (s *reservationService) GetReservationByID(id uint) (*models.Reservation, error) {
+	return s.reservationRepo.GetReservationByID(id)
+}
+
+// GetReservations 依停車場、車牌與狀態篩選預約 (分頁)，篩選條件為零值時不篩選
+func (s *reservationService) GetReservations(parkingLotID *uint, licensePlate string, status string, limit int, offset int) ([]models.Reservation, error) {
+	return s.reservationRepo.GetReservations(repositories.ReservationFilter{
+		ParkingLotID: parkingLotID,
+		LicensePlate: licensePlate,
+		Status:       status,
+	}, limit, offset)
+}
+
+// CancelReservation 取消尚未使用的預約並釋出保留的車位
+// 在預約開始前取消時全額退還已付的訂金，開始後取消則與逾時未到相同沒收訂金
+// 退款失敗時預約仍會取消並回傳錯誤，訂金可由人員透過交易退款處理；退款仍為 Pending 時訂金維持 Paid 直到退款完成
+func (s *reservationService) CancelReservation(id uint) (*models.Reservation, *models.Transaction, error) {
+	now := time.Now()
+	reservation, err := s.cancel(id, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reservation.DepositStatus != models.DepositStatusPaid || reservation.DepositTransactionID == nil {
+		return reservation, nil, nil
+	}
+
+	refund, err := s.transactionService.RefundTransaction(*reservation.DepositTransactionID, dtos.RefundTransactionPayload{
+		Reason:   fmt.Sprintf("Reservation ID %d cancelled before its start time", reservation.ReservationID),
+		Operator: "system",
+	})
+	if err != nil {
+		return reservation, nil, fmt.Errorf("deposit_refund_failed: Reservation ID %d was cancelled but its deposit could not be refunded: %w", reservation.ReservationID, err)
+	}
+	// 支付供應商尚未確認的退款由背景工作重送，完成時才將訂金標記為 Refunded
+	if refund.Status == "Success" {
+		reservation.DepositStatus = models.DepositStatusRefunded
+	}
+	return reservation, refund, nil
+}
+
+// cancel 在資料庫交易中將 Confirmed 的預約標記為 Cancelled，開始後取消時沒收已付的訂金
+func (s *reservationService) cancel(id uint, now time.Time) (reservation *models.Reservation, err error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+		}
+	}()
+
+	reservation, err = s.reservationRepo.GetReservationByIDForUpdate(tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding reservation ID %d: %w", id, err)
+	}
+	if reservation == nil {
+		return nil, fmt.Errorf("reservation ID %d not found", id)
+	}
+	if reservation.Status != models.ReservationStatusConfirmed {
+		return nil, fmt.Errorf("invalid_reservation_state: Reservation ID %d is %s and cannot be cancelled.", id, reservation.Status)
+	}
+
+	reservation.Status = models.ReservationStatusCancelled
+	reservation.CancelledAt = &now
+	if reservation.DepositStatus == models.DepositStatusPaid && !now.Before(reservation.StartTime) {
+		reservation.DepositStatus = models.DepositStatusForfeited
+	}
+	if err = s.reservationRepo.UpdateReservation(tx, reservation); err != nil {
+		return nil, fmt.Errorf("error cancelling reservation ID %d: %w", id, err)
+	}
+	return reservation, nil
+}
+
+// HeldSpots 計算停車場該車種在 at 時保留給預約的車位數，excludeLicensePlate 不為空字串時不計入該車牌的預約
+func (s *reservationService) HeldSpots(tx *gorm.DB, parkingLotID uint, vehicleClass string, at time.Time, excludeLicensePlate string) (int64, error) {
+	held, err := s.reservationRepo.CountConfirmedReservations(tx, parkingLotID, vehicleClass, at.Add(configs.ReservationEarlyArrival), at, excludeLicensePlate)
+	if err != nil {
+		return 0, fmt.Errorf("error counting held reservations in parking lot ID %d: %w", parkingLotID, err)
+	}
+	return held, nil
+}
+
+// HeldSpotsByParkingLot 依停車場與車種計算在 at 時保留給預約的車位數
+func (s *reservationService) HeldSpotsByParkingLot(at time.Time) (map[uint]map[string]int64, error) {
+	held, err := s.reservationRepo.CountConfirmedReservationsByParkingLot(at.Add(configs.ReservationEarlyArrival), at)
+	if err != nil {
+		return nil, fmt.Errorf("error counting held reservations: %w", err)
+	}
+	return held, nil
+}
+
+// FindArrivingReservation 在資料庫交易中取得車牌在 at 時位於抵達時段內的預約並鎖定，沒有時回傳 nil
+// 抵達時段為預約開始前 configs.ReservationEarlyArrival 至開始後 configs.ReservationNoShowGrace
+func (s *reservationService) FindArrivingReservation(tx *gorm.DB, parkingLotID uint, licensePlate string, vehicleClass string, at time.Time) (*models.Reservation, error) {
+	reservation, err := s.reservationRepo.FindArrivingReservationForUpdate(tx, parkingLotID, licensePlate, vehicleClass, at.Add(-configs.ReservationNoShowGrace), at.Add(configs.ReservationEarlyArrival))
+	if err != nil {
+		return nil, fmt.Errorf("error finding reservation for license plate %s: %w", licensePlate, err)
+	}
+	return reservation, nil
+}
+
+// FulfillReservation 將預約連結到預約車輛進場的停車記錄並標記為 Fulfilled
+// 已付的訂金交易會一併連結到停車記錄，折抵停車費用
+func (s *reservationService) FulfillReservation(tx *gorm.DB, reservation *models.Reservation, parkingRecordID uint) error {
+	reservation.Status = models.ReservationStatusFulfilled
+	reservation.ParkingRecordID = &parkingRecordID
+	if err := s.reservationRepo.UpdateReservation(tx, reservation); err != nil {
+		return fmt.Errorf("error fulfilling reservation ID %d: %w", reservation.ReservationID, err)
+	}
+	if err := s.transactionService.LinkReservationTransactions(tx, reservation.ReservationID, parkingRecordID); err != nil {
+		return fmt.Errorf("error linking deposit of reservation ID %d to parking record ID %d: %w", reservation.ReservationID, parkingRecordID, err)
+	}
+	return nil
+}
+
+// ExpireNoShowReservations 將超過抵達時段仍未進場的預約標記為 Expired 並釋出保留的車位，已付的訂金不退還
+func (s *reservationService) ExpireNoShowReservations() error {
+	expired, err := s.reservationRepo.ExpireReservations(time.Now().Add(-configs.ReservationNoShowGrace))
+	if err != nil {
+		return fmt.Errorf("error expiring reservations: %w", err)
+	}
+	if expired > 0 {
+		log.Printf("[Reservation] 已將 %d 筆逾時未到的預約標記為 Expired", expired)
+	}
+	return nil
+}
+
+// SumForfeitedDeposits 依車種計算在指定時間範圍內開始且逾時未到而沒收的訂金總額
+func (s *reservationService) SumForfeitedDeposits(parkingLotID *uint, vehicleClass *string, startTime, endTime *time.Time) (map[string]models.Money, error) {
+	sums, err := s.reservationRepo.SumForfeitedDepositsByVehicleClass(parkingLotID, vehicleClass, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("error summing forfeited deposits: %w", err)
+	}
+	return sums, nil
+}
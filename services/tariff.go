@@ -0,0 +1,204 @@
+package services
+
+import (
+	"hello-professor_backend/configs"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"time"
+)
+
+// Tariff 定義停車費率計算的介面，所有計算停車費用的地方都應透過此介面
+type Tariff interface {
+	// Calculate 計算從 entryTime 到 exitTime 的停車費用與明細
+	Calculate(entryTime, exitTime time.Time) dtos.FeeBreakdown
+	// NextUnitStart 回傳 at 之後下一個計費單位開始的時間，費用可能在此時變動
+	NextUnitStart(entryTime, at time.Time) time.Time
+}
+
+// ParkingLotTariffs 定義依停車場與車種取得計費 Tariff 的介面，每個停車場的每種車種可使用不同的費率
+type ParkingLotTariffs interface {
+	// TariffForParkingLot 回傳停車場該車種使用的 Tariff
+	TariffForParkingLot(parkingLotID uint, vehicleClass string) (Tariff, error)
+}
+
+const (
+	dayTypeWeekday = "Weekday"
+	dayTypeWeekend = "Weekend"
+	dayTypeHoliday = "Holiday"
+)
+
+// scheduleTariff 是以費率行事曆為基礎的費率實作
+// 停車時長會無條件進位到計費單位，每個單位依其起始時間歸屬到對應的日曆日與時段，
+// 先套用時段上限，再套用每日上限
+type scheduleTariff struct {
+	calendar     configs.RateCalendar
+	unitDuration time.Duration
+	location     *time.Location
+}
+
+// NewScheduleTariff 建立一個新的依費率行事曆計費的 Tariff 實例
+func NewScheduleTariff(calendar configs.RateCalendar, unitDuration time.Duration, location *time.Location) Tariff {
+	return &scheduleTariff{
+		calendar:     calendar,
+		unitDuration: unitDuration,
+		location:     location,
+	}
+}
+
+// NewUnitTariff 建立一個全天單一費率的 Tariff 實例，maxDailyCharge 為 0 表示不設每日上限
+func NewUnitTariff(ratePerUnit models.Money, unitDuration time.Duration, maxDailyCharge models.Money, location *time.Location) Tariff {
+	flat := configs.RateSchedule{
+		Name:           "Flat",
+		Bands:          []configs.RateBand{{Name: "AllDay", StartMinute: 0, EndMinute: 24 * 60, RatePerUnit: ratePerUnit}},
+		MaxDailyCharge: maxDailyCharge,
+	}
+	calendar := configs.RateCalendar{Weekday: flat, Weekend: flat, Holiday: flat}
+	return NewScheduleTariff(calendar, unitDuration, location)
+}
+
+// NewCalendarTariff 以 configs 中的計費單位與時區，依指定的費率行事曆建立 Tariff 實例
+func NewCalendarTariff(calendar configs.RateCalendar) Tariff {
+	unitDuration := time.Duration(configs.UnitDurationHours * float64(time.Hour))
+	return NewScheduleTariff(calendar, unitDuration, configs.ParkingLotLocation)
+}
+
+// Calculate 計算停車費用，將停車期間依日曆日與時段切分後逐段計價
+func (t *scheduleTariff) Calculate(entryTime, exitTime time.Time) dtos.FeeBreakdown {
+	breakdown := dtos.FeeBreakdown{
+		DurationMinutes: parkingDurationMinutes(entryTime, exitTime),
+		Segments:        []dtos.FeeSegment{},
+		Days:            []dtos.DailyFee{},
+	}
+	if !exitTime.After(entryTime) {
+		return breakdown
+	}
+
+	totalUnits := unitsStartedBefore(entryTime, exitTime, t.unitDuration)
+	breakdown.BillableUnits = totalUnits
+
+	// unitsBefore 回傳在 boundary 之前開始的計費單位數，不超過總單位數
+	unitsBefore := func(boundary time.Time) int {
+		units := unitsStartedBefore(entryTime, boundary, t.unitDuration)
+		if units > totalUnits {
+			units = totalUnits
+		}
+		return units
+	}
+
+	for dayStart := startOfDay(entryTime.In(t.location)); dayStart.Before(exitTime); dayStart = dayStart.AddDate(0, 0, 1) {
+		schedule, dayType, holidayName := t.scheduleFor(dayStart)
+		day := dtos.DailyFee{
+			Date:        dayStart.Format("2006-01-02"),
+			DayType:     dayType,
+			HolidayName: holidayName,
+		}
+
+		for _, band := range schedule.Bands {
+			segmentStart := maxTime(dayStart.Add(time.Duration(band.StartMinute)*time.Minute), entryTime)
+			segmentEnd := minTime(dayStart.Add(time.Duration(band.EndMinute)*time.Minute), exitTime)
+			if !segmentEnd.After(segmentStart) {
+				continue
+			}
+
+			units := unitsBefore(segmentEnd) - unitsBefore(segmentStart)
+			if units <= 0 {
+				continue
+			}
+
+			uncapped := band.RatePerUnit.Mul(int64(units))
+			segment := dtos.FeeSegment{
+				Date:           day.Date,
+				DayType:        dayType,
+				Band:           band.Name,
+				StartTime:      segmentStart,
+				EndTime:        segmentEnd,
+				BillableUnits:  units,
+				RatePerUnit:    band.RatePerUnit,
+				UncappedAmount: uncapped,
+				Amount:         uncapped,
+			}
+			if band.MaxCharge.IsPositive() && uncapped.GreaterThan(band.MaxCharge) {
+				segment.Amount = band.MaxCharge
+				segment.CapApplied = true
+			}
+			breakdown.Segments = append(breakdown.Segments, segment)
+
+			day.BillableUnits += units
+			day.UncappedAmount = day.UncappedAmount.Add(segment.Amount)
+		}
+
+		if day.BillableUnits == 0 {
+			continue
+		}
+		day.Amount = day.UncappedAmount
+		if schedule.MaxDailyCharge.IsPositive() && day.UncappedAmount.GreaterThan(schedule.MaxDailyCharge) {
+			day.Amount = schedule.MaxDailyCharge
+			day.CapApplied = true
+		}
+		breakdown.Days = append(breakdown.Days, day)
+		breakdown.TotalAmount = breakdown.TotalAmount.Add(day.Amount)
+	}
+
+	return breakdown
+}
+
+// NextUnitStart 回傳 at 之後下一個計費單位開始的時間
+func (t *scheduleTariff) NextUnitStart(entryTime, at time.Time) time.Time {
+	units := unitsStartedBefore(entryTime, at, t.unitDuration)
+	next := entryTime.Add(time.Duration(units) * t.unitDuration)
+	if !next.After(at) {
+		next = next.Add(t.unitDuration)
+	}
+	return next
+}
+
+// scheduleFor 依日期判斷要套用的費率表，優先順序為國定假日、週末、平日
+func (t *scheduleTariff) scheduleFor(day time.Time) (configs.RateSchedule, string, string) {
+	if name, ok := t.calendar.Holidays[day.Format("2006-01-02")]; ok {
+		return t.calendar.Holiday, dayTypeHoliday, name
+	}
+	for _, weekday := range t.calendar.WeekendDays {
+		if day.Weekday() == weekday {
+			return t.calendar.Weekend, dayTypeWeekend, ""
+		}
+	}
+	return t.calendar.Weekday, dayTypeWeekday, ""
+}
+
+// unitsStartedBefore 計算從 entryTime 起算，在 boundary 之前開始的計費單位數量 (即無條件進位)
+func unitsStartedBefore(entryTime, boundary time.Time, unitDuration time.Duration) int {
+	elapsed := boundary.Sub(entryTime)
+	if elapsed <= 0 {
+		return 0
+	}
+	return int((elapsed + unitDuration - 1) / unitDuration)
+}
+
+// parkingDurationMinutes 計算停車時長（分鐘），不足一分鐘捨去，負值視為 0
+func parkingDurationMinutes(entryTime, exitTime time.Time) int {
+	minutes := int(exitTime.Sub(entryTime).Minutes())
+	if minutes < 0 {
+		minutes = 0
+	}
+	return minutes
+}
+
+// startOfDay 回傳 t 所在時區當天的 00:00
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
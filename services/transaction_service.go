@@ -1,10 +1,18 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/dtos"
 	"hello-professor_backend/models"
 	"hello-professor_backend/repositories"
+	"log"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionService 定義交易服務的介面
@@ -15,16 +23,29 @@ type TransactionService interface {
 	UpdateTransaction(transaction *models.Transaction) error
 	DeleteTransaction(id uint) error
 	GetAllTransactions(limit int, offset int) ([]models.Transaction, error)
+	GetNetPaidAmountByParkingRecordID(parkingRecordID uint) (models.Money, error)
+	RefundTransaction(transactionID uint, refundPayload dtos.RefundTransactionPayload) (*models.Transaction, error)
+	RetryPendingRefunds() error
+	LinkReservationTransactions(tx *gorm.DB, reservationID uint, parkingRecordID uint) error
+	GetBilledLineItemsByParkingRecordID(tx *gorm.DB, parkingRecordID uint) ([]models.TransactionLineItem, error)
 }
 
 // transactionService 是 TransactionService 的實作
 type transactionService struct {
-	transactionRepo repositories.TransactionRepository
+	transactionRepo   repositories.TransactionRepository
+	parkingRecordRepo repositories.ParkingRecordRepository
+	paymentProviders  *PaymentProviderRegistry
+	db                *gorm.DB
 }
 
 // NewTransactionService 建立一個新的 TransactionService 實例
-func NewTransactionService(repo repositories.TransactionRepository) TransactionService {
-	return &transactionService{transactionRepo: repo}
+func NewTransactionService(repo repositories.TransactionRepository, prRepo repositories.ParkingRecordRepository, paymentProviders *PaymentProviderRegistry, db *gorm.DB) TransactionService {
+	return &transactionService{
+		transactionRepo:   repo,
+		parkingRecordRepo: prRepo,
+		paymentProviders:  paymentProviders,
+		db:                db,
+	}
 }
 
 // CreateTransaction 呼叫 repository 來新增交易記錄
@@ -60,3 +81,267 @@ func (s *transactionService) DeleteTransaction(id uint) error {
 func (s *transactionService) GetAllTransactions(limit int, offset int) ([]models.Transaction, error) {
 	return s.transactionRepo.GetAllTransactions(limit, offset)
 }
+
+// GetNetPaidAmountByParkingRecordID 取得停車記錄扣除退款後的實收金額
+func (s *transactionService) GetNetPaidAmountByParkingRecordID(parkingRecordID uint) (models.Money, error) {
+	return s.transactionRepo.SumNetPaidAmountByParkingRecordID(nil, parkingRecordID)
+}
+
+// LinkReservationTransactions 將預約的訂金交易連結到預約車輛的停車記錄，訂金因此計入該停車記錄的實收金額並折抵停車費
+func (s *transactionService) LinkReservationTransactions(tx *gorm.DB, reservationID uint, parkingRecordID uint) error {
+	return s.transactionRepo.AssignReservationTransactions(tx, reservationID, parkingRecordID)
+}
+
+// GetBilledLineItemsByParkingRecordID 取得停車記錄已付款交易依明細類型與充電加總的明細金額，用來計算下一筆付款只需補收的差額
+func (s *transactionService) GetBilledLineItemsByParkingRecordID(tx *gorm.DB, parkingRecordID uint) ([]models.TransactionLineItem, error) {
+	return s.transactionRepo.SumLineItemAmountsByParkingRecordID(tx, parkingRecordID)
+}
+
+// RefundTransaction 針對一筆成功的付款交易進行全額或部分退款
+// 先在資料庫交易中建立一筆連結到原始交易的 Pending 負數退款交易，提交後才透過支付供應商退款，
+// 再依結果將退款交易標記為 Success 或 Failed；成功時一併更新原始交易與停車記錄的狀態
+// 支付供應商未確認結果時 (例如連線逾時) 回傳仍為 Pending 的退款交易，由 RetryPendingRefunds 重送
+func (s *transactionService) RefundTransaction(transactionID uint, refundPayload dtos.RefundTransactionPayload) (*models.Transaction, error) {
+	original, refund, err := s.createPendingRefund(transactionID, refundPayload)
+	if err != nil {
+		return nil, err
+	}
+	return s.settlePendingRefund(original, refund)
+}
+
+// RetryPendingRefunds 重送建立超過 configs.RefundRetryDelay 仍為 Pending 的退款，沿用相同的退款參考編號
+func (s *transactionService) RetryPendingRefunds() error {
+	refunds, err := s.transactionRepo.GetPendingRefunds(time.Now().Add(-configs.RefundRetryDelay))
+	if err != nil {
+		return fmt.Errorf("error finding pending refunds: %w", err)
+	}
+	for i := range refunds {
+		refund := &refunds[i]
+		original, err := s.transactionRepo.GetTransactionByID(*refund.OriginalTransactionID)
+		if err != nil || original == nil {
+			log.Printf("[Refund] 找不到退款交易 ID %d 的原始交易 ID %d: %v", refund.TransactionID, *refund.OriginalTransactionID, err)
+			continue
+		}
+		if _, err := s.settlePendingRefund(original, refund); err != nil {
+			log.Printf("[Refund] 重送退款交易 ID %d 失敗: %v", refund.TransactionID, err)
+		}
+	}
+	return nil
+}
+
+// settlePendingRefund 透過原始交易的支付供應商送出 Pending 的退款並記錄結果
+// 退款參考編號固定為 REFUND-{退款交易編號}，重送時支付供應商不會重複退款；沒有供應商參考編號的舊交易僅記錄退款，由現場人員另行處理
+// 供應商明確拒絕時退款交易標記為 Failed 並回傳錯誤，無法確定結果時維持 Pending
+func (s *transactionService) settlePendingRefund(original *models.Transaction, refund *models.Transaction) (*models.Transaction, error) {
+	var providerResult *PaymentProviderResult
+	var refundErr error
+	if original.PaymentProvider != "" && original.ProviderReference != "" {
+		provider, providerErr := s.paymentProviders.ByName(original.PaymentProvider)
+		if providerErr != nil {
+			log.Printf("[Refund] 退款交易 ID %d 維持 Pending: %v", refund.TransactionID, providerErr)
+			return refund, nil
+		}
+		providerResult, providerErr = provider.Refund(original.ProviderReference, fmt.Sprintf("REFUND-%d", refund.TransactionID), refund.Amount.Neg())
+		if providerErr != nil {
+			if !errors.Is(providerErr, ErrRefundRejected) {
+				log.Printf("[Refund] 支付供應商 %s 未確認退款交易 ID %d 的結果，維持 Pending 稍後重送: %v", original.PaymentProvider, refund.TransactionID, providerErr)
+				return refund, nil
+			}
+			refundErr = fmt.Errorf("provider_refund_failed: Payment provider %s rejected the refund: %w", original.PaymentProvider, providerErr)
+		}
+	}
+
+	if err := s.completeRefund(refund, providerResult, refundErr); err != nil {
+		return nil, err
+	}
+	if refund.Status == "Failed" {
+		if refundErr == nil {
+			refundErr = fmt.Errorf("provider_refund_failed: Payment provider %s rejected refund transaction ID %d.", original.PaymentProvider, refund.TransactionID)
+		}
+		return nil, refundErr
+	}
+	return refund, nil
+}
+
+// createPendingRefund 在資料庫交易中檢查原始交易的可退款金額，並建立一筆 Pending 的退款交易
+// 等待處理中的退款會從可退款金額中扣除，避免同時送出的退款超過原始金額
+func (s *transactionService) createPendingRefund(transactionID uint, refundPayload dtos.RefundTransactionPayload) (original *models.Transaction, refund *models.Transaction, err error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // 重新拋出 panic
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			if commitErr := tx.Commit().Error; commitErr != nil {
+				err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+			}
+		}
+	}()
+
+	original, err = s.transactionRepo.GetTransactionByIDForUpdate(tx, transactionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding transaction ID %d: %w", transactionID, err)
+	}
+	if original == nil {
+		return nil, nil, fmt.Errorf("transaction ID %d not found", transactionID)
+	}
+
+	if original.TransactionType == "Refund" {
+		return nil, nil, fmt.Errorf("invalid_refund: Transaction ID %d is itself a refund and cannot be refunded.", transactionID)
+	}
+	if original.Status == "Refunded" {
+		return nil, nil, fmt.Errorf("already_refunded: Transaction ID %d has already been fully refunded.", transactionID)
+	}
+	if original.Status != "Success" {
+		return nil, nil, fmt.Errorf("invalid_refund: Only successful transactions can be refunded. Transaction ID %d has status %s.", transactionID, original.Status)
+	}
+
+	alreadyRefunded, err := s.transactionRepo.SumRefundedAmount(tx, transactionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error summing refunds for transaction ID %d: %w", transactionID, err)
+	}
+	pendingRefunds, err := s.transactionRepo.SumPendingRefundAmount(tx, transactionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error summing pending refunds for transaction ID %d: %w", transactionID, err)
+	}
+	refundable := original.Amount.Sub(alreadyRefunded).Sub(pendingRefunds)
+
+	refundAmount := refundable
+	if refundPayload.Amount != nil {
+		refundAmount = *refundPayload.Amount
+	}
+	if !refundAmount.IsPositive() || refundAmount.GreaterThan(refundable) {
+		return nil, nil, fmt.Errorf("invalid_refund_amount: Refund amount (%s) must be greater than 0 and not exceed the refundable amount (%s) of transaction ID %d.", refundAmount, refundable, transactionID)
+	}
+	if original.PaymentProvider != "" && original.ProviderReference != "" {
+		if _, err = s.paymentProviders.ByName(original.PaymentProvider); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	refund = &models.Transaction{
+		ParkingRecordID:       original.ParkingRecordID,
+		ReservationID:         original.ReservationID,
+		Amount:                refundAmount.Neg(),
+		TransactionTime:       time.Now(),
+		PaymentMethod:         original.PaymentMethod,
+		TransactionType:       "Refund",
+		Status:                "Pending",
+		PaymentProvider:       original.PaymentProvider,
+		OriginalTransactionID: &original.TransactionID,
+		RefundReason:          refundPayload.Reason,
+		Operator:              refundPayload.Operator,
+	}
+	if err = s.transactionRepo.CreateTransaction(tx, refund); err != nil {
+		return nil, nil, fmt.Errorf("failed to create refund transaction: %w", err)
+	}
+	return original, refund, nil
+}
+
+// completeRefund 在資料庫交易中依支付供應商的結果將 Pending 的退款交易標記為 Success 或 Failed
+// 退款成功且原始交易已全額退款時，將原始交易、預約訂金與停車記錄標記為 Refunded；退款已由其他請求處理時 refund 改為目前的狀態
+func (s *transactionService) completeRefund(refund *models.Transaction, providerResult *PaymentProviderResult, refundErr error) (err error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // 重新拋出 panic
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			if commitErr := tx.Commit().Error; commitErr != nil {
+				err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+			}
+		}
+	}()
+
+	original, err := s.transactionRepo.GetTransactionByIDForUpdate(tx, *refund.OriginalTransactionID)
+	if err != nil {
+		return fmt.Errorf("error finding transaction ID %d: %w", *refund.OriginalTransactionID, err)
+	}
+	if original == nil {
+		return fmt.Errorf("transaction ID %d not found", *refund.OriginalTransactionID)
+	}
+	// 原始交易已鎖定，同一筆退款的重送在此依序處理
+	if err = tx.First(refund, refund.TransactionID).Error; err != nil {
+		return fmt.Errorf("error finding refund transaction ID %d: %w", refund.TransactionID, err)
+	}
+	if refund.Status != "Pending" {
+		return nil
+	}
+
+	if refundErr != nil {
+		refund.Status = "Failed"
+		refund.PaymentGatewayResponse = refundErr.Error()
+		if err = tx.Save(refund).Error; err != nil {
+			return fmt.Errorf("failed to mark refund transaction ID %d as failed: %w", refund.TransactionID, err)
+		}
+		return nil
+	}
+
+	refund.Status = "Success"
+	if providerResult != nil {
+		gatewayResponse, marshalErr := json.Marshal(providerResult)
+		if marshalErr != nil {
+			return fmt.Errorf("failed to encode payment provider response: %w", marshalErr)
+		}
+		refund.ProviderReference = providerResult.ProviderReference
+		refund.PaymentGatewayResponse = string(gatewayResponse)
+	}
+	if err = tx.Save(refund).Error; err != nil {
+		return fmt.Errorf("failed to mark refund transaction ID %d as successful: %w", refund.TransactionID, err)
+	}
+
+	refunded, err := s.transactionRepo.SumRefundedAmount(tx, original.TransactionID)
+	if err != nil {
+		return fmt.Errorf("error summing refunds for transaction ID %d: %w", original.TransactionID, err)
+	}
+	if refunded.LessThan(original.Amount) {
+		return nil
+	}
+
+	if err = tx.Model(original).Update("status", "Refunded").Error; err != nil {
+		return fmt.Errorf("failed to mark transaction ID %d as refunded: %w", original.TransactionID, err)
+	}
+
+	// 預約訂金全額退款時，一併更新預約的訂金狀態
+	if original.ReservationID != nil {
+		if err = tx.Model(&models.Reservation{}).Where("reservation_id = ?", *original.ReservationID).Update("deposit_status", models.DepositStatusRefunded).Error; err != nil {
+			return fmt.Errorf("failed to mark deposit of reservation ID %d as refunded: %w", *original.ReservationID, err)
+		}
+	}
+	// 預約車輛尚未進場的訂金沒有停車記錄
+	if original.ParkingRecordID == 0 {
+		return nil
+	}
+
+	// 停車記錄的實收金額退至 0 時，將付款狀態標記為 Refunded
+	var parkingRecord models.ParkingRecord
+	if queryErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parkingRecord, original.ParkingRecordID).Error; queryErr != nil {
+		if errors.Is(queryErr, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("parking record ID %d of transaction ID %d not found", original.ParkingRecordID, original.TransactionID)
+		}
+		return fmt.Errorf("error finding parking record ID %d: %w", original.ParkingRecordID, queryErr)
+	}
+	netPaid, err := s.transactionRepo.SumNetPaidAmountByParkingRecordID(tx, parkingRecord.RecordID)
+	if err != nil {
+		return fmt.Errorf("error summing payments for parking record ID %d: %w", parkingRecord.RecordID, err)
+	}
+	if !netPaid.IsPositive() {
+		parkingRecord.PaymentStatus = "Refunded"
+		if err = s.parkingRecordRepo.UpdateParkingRecord(tx, &parkingRecord); err != nil {
+			return fmt.Errorf("failed to update parking record ID %d status to Refunded: %w", parkingRecord.RecordID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ValidationService 定義商家與停車折抵服務的介面
+type ValidationService interface {
+	CreateMerchant(payload dtos.CreateMerchantPayload) (*models.Merchant, error)
+	GetMerchantByID(id uint) (*models.Merchant, error)
+	GetAllMerchants() ([]models.Merchant, error)
+	CreateValidationCode(merchantID uint, payload dtos.CreateValidationCodePayload) (*models.ValidationCode, error)
+	GetValidationCodesByMerchantID(merchantID uint) ([]models.ValidationCode, error)
+	ApplyValidation(recordID uint, code string) (*models.ParkingValidation, error)
+	GetValidationsByParkingRecordID(recordID uint) ([]models.ParkingValidation, error)
+	CalculateDiscounts(tx *gorm.DB, record *models.ParkingRecord, fee dtos.FeeBreakdown, calculateUntil time.Time) ([]dtos.FeeDiscount, error)
+	RecordDiscounts(tx *gorm.DB, discounts []dtos.FeeDiscount) error
+	GetMerchantSubsidyReport(parkingLotID *uint, startTime, endTime *time.Time) (*dtos.MerchantSubsidyReportResponse, error)
+}
+
+// validationService 是 ValidationService 的實作
+type validationService struct {
+	merchantRepo repositories.MerchantRepository
+	tariffs      ParkingLotTariffs
+	db           *gorm.DB
+}
+
+// NewValidationService 建立一個新的 ValidationService 實例
+func NewValidationService(merchantRepo repositories.MerchantRepository, tariffs ParkingLotTariffs, db *gorm.DB) ValidationService {
+	return &validationService{
+		merchantRepo: merchantRepo,
+		tariffs:      tariffs,
+		db:           db,
+	}
+}
+
+// CreateMerchant 新增商家
+func (s *validationService) CreateMerchant(payload dtos.CreateMerchantPayload) (*models.Merchant, error) {
+	merchant := &models.Merchant{
+		Name:         strings.TrimSpace(payload.Name),
+		ContactEmail: strings.TrimSpace(payload.ContactEmail),
+		Active:       true,
+	}
+	if err := s.merchantRepo.CreateMerchant(merchant); err != nil {
+		return nil, fmt.Errorf("error creating merchant: %w", err)
+	}
+	return merchant, nil
+}
+
+// GetMerchantByID 呼叫 repository 透過 ID 取得商家
+func (s *validationService) GetMerchantByID(id uint) (*models.Merchant, error) {
+	return s.merchantRepo.GetMerchantByID(id)
+}
+
+// GetAllMerchants 呼叫 repository 取得所有商家
+func (s *validationService) GetAllMerchants() ([]models.Merchant, error) {
+	return s.merchantRepo.GetAllMerchants()
+}
+
+// CreateValidationCode 為商家發放折抵碼，未指定折抵碼時自動產生
+func (s *validationService) CreateValidationCode(merchantID uint, payload dtos.CreateValidationCodePayload) (*models.ValidationCode, error) {
+	merchant, err := s.merchantRepo.GetMerchantByID(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding merchant ID %d: %w", merchantID, err)
+	}
+	if merchant == nil {
+		return nil, fmt.Errorf("merchant ID %d not found", merchantID)
+	}
+	if !merchant.Active {
+		return nil, fmt.Errorf("merchant_inactive: Merchant ID %d is not active.", merchantID)
+	}
+
+	code := &models.ValidationCode{
+		MerchantID:   merchantID,
+		Code:         normalizeValidationCode(payload.Code),
+		DiscountType: payload.DiscountType,
+		MaxUses:      payload.MaxUses,
+		ExpiresAt:    payload.ExpiresAt,
+		Active:       true,
+	}
+	if code.Code == "" {
+		code.Code = newProviderReference("VAL")
+	}
+	if payload.MaxUses < 0 {
+		return nil, errors.New("invalid_validation_code: maxUses must not be negative.")
+	}
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("invalid_validation_code: expiresAt must be in the future.")
+	}
+
+	switch payload.DiscountType {
+	case models.ValidationTypeFixedAmount:
+		if !payload.Amount.IsPositive() {
+			return nil, errors.New("invalid_validation_code: amount must be greater than 0 for FixedAmount codes.")
+		}
+		code.Amount = payload.Amount
+	case models.ValidationTypeFlatFee:
+		if payload.Amount.IsNegative() {
+			return nil, errors.New("invalid_validation_code: amount must not be negative for FlatFee codes.")
+		}
+		code.Amount = payload.Amount
+	case models.ValidationTypePercentage:
+		if payload.Percent < 1 || payload.Percent > 100 {
+			return nil, errors.New("invalid_validation_code: percent must be between 1 and 100 for Percentage codes.")
+		}
+		code.Percent = payload.Percent
+	case models.ValidationTypeFreeMinutes:
+		if payload.FreeMinutes <= 0 {
+			return nil, errors.New("invalid_validation_code: freeMinutes must be greater than 0 for FreeMinutes codes.")
+		}
+		code.FreeMinutes = payload.FreeMinutes
+	default:
+		return nil, fmt.Errorf("invalid_validation_code: Unsupported discount type %s.", payload.DiscountType)
+	}
+
+	if err := s.merchantRepo.CreateValidationCode(code); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("validation_code_exists: Validation code %s already exists.", code.Code)
+		}
+		return nil, fmt.Errorf("error creating validation code: %w", err)
+	}
+	return code, nil
+}
+
+// GetValidationCodesByMerchantID 呼叫 repository 取得商家發放的所有折抵碼
+func (s *validationService) GetValidationCodesByMerchantID(merchantID uint) ([]models.ValidationCode, error) {
+	return s.merchantRepo.GetValidationCodesByMerchantID(merchantID)
+}
+
+// ApplyValidation 將折抵碼套用到尚未付款且仍在場內的停車記錄，並增加折抵碼的使用次數
+// 已準備付款的記錄會清除計算好的費用，需重新呼叫 prepare-payment 以取得折抵後的金額
+func (s *validationService) ApplyValidation(recordID uint, code string) (validation *models.ParkingValidation, err error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // 重新拋出 panic
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			if commitErr := tx.Commit().Error; commitErr != nil {
+				err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+			}
+		}
+	}()
+
+	normalized := normalizeValidationCode(code)
+	validationCode, err := s.merchantRepo.GetValidationCodeByCodeForUpdate(tx, normalized)
+	if err != nil {
+		return nil, fmt.Errorf("error finding validation code %s: %w", normalized, err)
+	}
+	if validationCode == nil {
+		return nil, fmt.Errorf("validation code %s not found", normalized)
+	}
+
+	now := time.Now()
+	if !validationCode.Active || !validationCode.Merchant.Active {
+		return nil, fmt.Errorf("invalid_validation_code: Validation code %s is no longer active.", normalized)
+	}
+	if validationCode.ExpiresAt != nil && now.After(*validationCode.ExpiresAt) {
+		return nil, fmt.Errorf("validation_code_expired: Validation code %s expired at %v.", normalized, *validationCode.ExpiresAt)
+	}
+	if validationCode.MaxUses > 0 && validationCode.UsedCount >= validationCode.MaxUses {
+		return nil, fmt.Errorf("validation_code_exhausted: Validation code %s has reached its usage limit of %d.", normalized, validationCode.MaxUses)
+	}
+
+	var record models.ParkingRecord
+	if queryErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&record, recordID).Error; queryErr != nil {
+		if errors.Is(queryErr, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("parking record ID %d not found", recordID)
+		}
+		return nil, fmt.Errorf("error finding parking record ID %d: %w", recordID, queryErr)
+	}
+	if record.ExitTime != nil {
+		return nil, fmt.Errorf("vehicle_exited: Cannot apply a validation to parking record ID %d after the vehicle exited.", recordID)
+	}
+	if record.PaymentStatus != "Pending" {
+		return nil, fmt.Errorf("already_paid: Cannot apply a validation to parking record ID %d with payment status %s.", recordID, record.PaymentStatus)
+	}
+
+	existing, err := s.merchantRepo.GetParkingValidationsByParkingRecordID(tx, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validations of parking record ID %d: %w", recordID, err)
+	}
+	for _, applied := range existing {
+		if applied.ValidationCodeID == validationCode.ValidationCodeID {
+			return nil, fmt.Errorf("validation_already_applied: Validation code %s is already applied to parking record ID %d.", normalized, recordID)
+		}
+	}
+
+	validationCode.UsedCount++
+	if err = s.merchantRepo.UpdateValidationCode(tx, validationCode); err != nil {
+		return nil, fmt.Errorf("error updating usage of validation code %s: %w", normalized, err)
+	}
+
+	validation = &models.ParkingValidation{
+		ParkingRecordID:  recordID,
+		ValidationCodeID: validationCode.ValidationCodeID,
+		MerchantID:       validationCode.MerchantID,
+		Code:             validationCode.Code,
+		AppliedAt:        now,
+	}
+	if err = s.merchantRepo.CreateParkingValidation(tx, validation); err != nil {
+		return nil, fmt.Errorf("error applying validation code %s: %w", normalized, err)
+	}
+	validation.ValidationCode = *validationCode
+
+	// 已計算的應付金額未包含此折抵，清除後需重新準備付款
+	if record.CalculatedAmount.IsPositive() {
+		if err = tx.Model(&record).Update("calculated_amount", models.Money{}).Error; err != nil {
+			return nil, fmt.Errorf("error resetting calculated fee of parking record ID %d: %w", recordID, err)
+		}
+	}
+
+	return validation, nil
+}
+
+// GetValidationsByParkingRecordID 取得停車記錄套用的所有折抵
+func (s *validationService) GetValidationsByParkingRecordID(recordID uint) ([]models.ParkingValidation, error) {
+	return s.merchantRepo.GetParkingValidationsByParkingRecordID(nil, recordID)
+}
+
+// CalculateDiscounts 依套用順序計算停車記錄各折抵的折抵金額
+// 每個折抵以前面折抵後的剩餘金額為基礎計算，折抵總額不會超過原始費用
+func (s *validationService) CalculateDiscounts(tx *gorm.DB, record *models.ParkingRecord, fee dtos.FeeBreakdown, calculateUntil time.Time) ([]dtos.FeeDiscount, error) {
+	validations, err := s.merchantRepo.GetParkingValidationsByParkingRecordID(tx, record.RecordID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validations of parking record ID %d: %w", record.RecordID, err)
+	}
+
+	if len(validations) == 0 {
+		return []dtos.FeeDiscount{}, nil
+	}
+	tariff, err := s.tariffs.TariffForParkingLot(record.ParkingLotID, record.VehicleClass)
+	if err != nil {
+		return nil, err
+	}
+
+	discounts := []dtos.FeeDiscount{}
+	remaining := fee.TotalAmount
+	for _, validation := range validations {
+		code := validation.ValidationCode
+		var discount models.Money
+		var description string
+
+		switch code.DiscountType {
+		case models.ValidationTypeFixedAmount:
+			discount = code.Amount
+			description = fmt.Sprintf("%s off", code.Amount)
+		case models.ValidationTypePercentage:
+			discount = remaining.Percent(int64(code.Percent))
+			description = fmt.Sprintf("%d%% off", code.Percent)
+		case models.ValidationTypeFreeMinutes:
+			freeUntil := minTime(record.EntryTime.Add(time.Duration(code.FreeMinutes)*time.Minute), calculateUntil)
+			discount = tariff.Calculate(record.EntryTime, freeUntil).TotalAmount
+			description = fmt.Sprintf("First %d minutes free", code.FreeMinutes)
+		case models.ValidationTypeFlatFee:
+			discount = remaining.Sub(code.Amount)
+			description = fmt.Sprintf("Flat fee of %s", code.Amount)
+		}
+		discount = models.MinMoney(models.MaxMoney(discount, models.Money{}), remaining)
+		remaining = remaining.Sub(discount)
+
+		discounts = append(discounts, dtos.FeeDiscount{
+			Code:                validation.Code,
+			Description:         description,
+			Amount:              discount,
+			ParkingValidationID: validation.ParkingValidationID,
+			MerchantID:          validation.MerchantID,
+		})
+	}
+	return discounts, nil
+}
+
+// RecordDiscounts 保存各折抵實際折抵的金額，作為商家補貼報表的依據
+func (s *validationService) RecordDiscounts(tx *gorm.DB, discounts []dtos.FeeDiscount) error {
+	for _, discount := range discounts {
+		if discount.ParkingValidationID == 0 {
+			continue
+		}
+		if err := s.merchantRepo.UpdateParkingValidationDiscount(tx, discount.ParkingValidationID, discount.Amount); err != nil {
+			return fmt.Errorf("error saving discount of parking validation ID %d: %w", discount.ParkingValidationID, err)
+		}
+	}
+	return nil
+}
+
+// GetMerchantSubsidyReport 統計各商家在指定時間範圍內補貼的停車費用
+func (s *validationService) GetMerchantSubsidyReport(parkingLotID *uint, startTime, endTime *time.Time) (*dtos.MerchantSubsidyReportResponse, error) {
+	subsidies, err := s.merchantRepo.SumMerchantSubsidies(parkingLotID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("error summing merchant subsidies: %w", err)
+	}
+
+	report := &dtos.MerchantSubsidyReportResponse{
+		Merchants: []dtos.MerchantSubsidyResponse{},
+		Currency:  configs.Currency,
+	}
+	for _, subsidy := range subsidies {
+		report.Merchants = append(report.Merchants, dtos.MerchantSubsidyResponse{
+			MerchantID:      subsidy.MerchantID,
+			MerchantName:    subsidy.MerchantName,
+			ValidationCount: subsidy.ValidationCount,
+			SubsidyAmount:   subsidy.SubsidyAmount,
+		})
+		report.TotalSubsidy = report.TotalSubsidy.Add(subsidy.SubsidyAmount)
+	}
+	return report, nil
+}
+
+// normalizeValidationCode 去除空白並轉為大寫，折抵碼不分大小寫
+func normalizeValidationCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
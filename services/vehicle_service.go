@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VehicleService 定義車輛資料服務的介面
+// 車輛資料在第一次進場時自動建立，現場人員可補充廠牌、顏色與備註
+type VehicleService interface {
+	FindOrCreateVehicle(tx *gorm.DB, licensePlate string, seenAt time.Time) (*models.Vehicle, error)
+	GetVehicleProfile(id uint) (*dtos.VehicleProfileResponse, error)
+	GetVehicleProfileByLicensePlate(licensePlate string) (*dtos.VehicleProfileResponse, error)
+	GetVehicles(licensePlateQuery string, limit int, offset int) ([]models.Vehicle, error)
+	UpdateVehicleDetails(id uint, payload dtos.VehicleDetailsPayload) (*models.Vehicle, error)
+	GetVehicleVisits(id uint, limit int, offset int) ([]models.ParkingRecord, error)
+}
+
+// vehicleService 是 VehicleService 的實作
+type vehicleService struct {
+	vehicleRepo repositories.VehicleRepository
+}
+
+// NewVehicleService 建立一個新的 VehicleService 實例
+func NewVehicleService(vehicleRepo repositories.VehicleRepository) VehicleService {
+	return &vehicleService{vehicleRepo: vehicleRepo}
+}
+
+// FindOrCreateVehicle 在資料庫交易中取得標準格式車牌的車輛資料，不存在時建立，並記錄 seenAt 為進場時間
+func (s *vehicleService) FindOrCreateVehicle(tx *gorm.DB, licensePlate string, seenAt time.Time) (*models.Vehicle, error) {
+	vehicle, err := s.vehicleRepo.UpsertVehicle(tx, licensePlate, seenAt)
+	if err != nil {
+		return nil, fmt.Errorf("error finding or creating vehicle for license plate %s: %w", licensePlate, err)
+	}
+	return vehicle, nil
+}
+
+// GetVehicleProfile 取得車輛資料與其停車記錄的統計，車輛不存在時回傳 nil
+func (s *vehicleService) GetVehicleProfile(id uint) (*dtos.VehicleProfileResponse, error) {
+	vehicle, err := s.vehicleRepo.GetVehicleByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding vehicle ID %d: %w", id, err)
+	}
+	if vehicle == nil {
+		return nil, nil
+	}
+	return s.vehicleProfile(vehicle)
+}
+
+// GetVehicleProfileByLicensePlate 透過車牌取得車輛資料與其停車記錄的統計，車輛不存在時回傳 nil
+func (s *vehicleService) GetVehicleProfileByLicensePlate(licensePlate string) (*dtos.VehicleProfileResponse, error) {
+	vehicle, err := s.vehicleRepo.GetVehicleByLicensePlate(licensePlate)
+	if err != nil {
+		return nil, fmt.Errorf("error finding vehicle for license plate %s: %w", licensePlate, err)
+	}
+	if vehicle == nil {
+		return nil, nil
+	}
+	return s.vehicleProfile(vehicle)
+}
+
+// GetVehicles 呼叫 repository 取得車輛資料 (分頁)，可依車牌片段篩選
+func (s *vehicleService) GetVehicles(licensePlateQuery string, limit int, offset int) ([]models.Vehicle, error) {
+	return s.vehicleRepo.GetVehicles(licensePlateQuery, limit, offset)
+}
+
+// UpdateVehicleDetails 以新的內容取代車輛的廠牌、顏色與備註，空白的欄位會被清除
+func (s *vehicleService) UpdateVehicleDetails(id uint, payload dtos.VehicleDetailsPayload) (*models.Vehicle, error) {
+	vehicle, err := s.vehicleRepo.GetVehicleByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding vehicle ID %d: %w", id, err)
+	}
+	if vehicle == nil {
+		return nil, fmt.Errorf("vehicle ID %d not found", id)
+	}
+	vehicle.Make = trimmedOrNil(payload.Make)
+	vehicle.Color = trimmedOrNil(payload.Color)
+	vehicle.Notes = trimmedOrNil(payload.Notes)
+	if err := s.vehicleRepo.UpdateVehicle(vehicle); err != nil {
+		return nil, fmt.Errorf("error updating vehicle ID %d: %w", id, err)
+	}
+	return vehicle, nil
+}
+
+// GetVehicleVisits 取得車輛的停車記錄 (分頁)，依進場時間由新到舊排序
+func (s *vehicleService) GetVehicleVisits(id uint, limit int, offset int) ([]models.ParkingRecord, error) {
+	vehicle, err := s.vehicleRepo.GetVehicleByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding vehicle ID %d: %w", id, err)
+	}
+	if vehicle == nil {
+		return nil, fmt.Errorf("vehicle ID %d not found", id)
+	}
+	return s.vehicleRepo.GetVehicleVisits(id, limit, offset)
+}
+
+// vehicleProfile 統計車輛的到訪次數、消費總額、平均停留時間與未付款的停車記錄
+func (s *vehicleService) vehicleProfile(vehicle *models.Vehicle) (*dtos.VehicleProfileResponse, error) {
+	stats, err := s.vehicleRepo.GetVehicleVisitStats(vehicle.VehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting visit stats of vehicle ID %d: %w", vehicle.VehicleID, err)
+	}
+	spend, err := s.vehicleRepo.SumVehicleSpend(vehicle.VehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("error summing spend of vehicle ID %d: %w", vehicle.VehicleID, err)
+	}
+	return &dtos.VehicleProfileResponse{
+		Vehicle:               *vehicle,
+		VisitCount:            stats.VisitCount,
+		TotalSpend:            spend,
+		AverageDwellMinutes:   stats.AverageDwellMinutes,
+		UnpaidVisitCount:      stats.UnpaidVisitCount,
+		HasUnpaidBalance:      stats.UnpaidVisitCount > 0,
+		ActiveParkingRecordID: stats.ActiveParkingRecordID,
+	}, nil
+}
+
+// trimmedOrNil 去除字串前後的空白，空字串回傳 nil
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"hello-professor_backend/models"
+	"log"
+	"sync"
+)
+
+const (
+	// WatchlistAlertEventCreated 串流中代表新警示的事件名稱
+	WatchlistAlertEventCreated = "alert"
+	// WatchlistAlertEventAcknowledged 串流中代表警示已被確認的事件名稱
+	WatchlistAlertEventAcknowledged = "acknowledged"
+)
+
+// watchlistAlertSubscriberBuffer 每個訂閱者可暫存的事件數，處理不及的訂閱者會遺漏事件，可再透過查詢警示補齊
+const watchlistAlertSubscriberBuffer = 32
+
+// WatchlistAlertEvent 推送給操作台的警示事件
+type WatchlistAlertEvent struct {
+	Name  string
+	Alert models.WatchlistAlert
+}
+
+// watchlistAlertSubscriber 訂閱警示串流的操作台，parkingLotID 為 nil 時接收所有停車場的警示
+type watchlistAlertSubscriber struct {
+	parkingLotID *uint
+	events       chan WatchlistAlertEvent
+}
+
+// watchlistAlertHub 將警示事件廣播給目前連線的操作台，只保存在記憶體中
+type watchlistAlertHub struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]*watchlistAlertSubscriber
+}
+
+// newWatchlistAlertHub 建立一個沒有訂閱者的 watchlistAlertHub
+func newWatchlistAlertHub() *watchlistAlertHub {
+	return &watchlistAlertHub{subscribers: map[int]*watchlistAlertSubscriber{}}
+}
+
+// subscribe 新增訂閱者，回傳接收事件的 channel 與取消訂閱的函式；取消訂閱後 channel 會被關閉
+func (h *watchlistAlertHub) subscribe(parkingLotID *uint) (<-chan WatchlistAlertEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	subscriber := &watchlistAlertSubscriber{
+		parkingLotID: parkingLotID,
+		events:       make(chan WatchlistAlertEvent, watchlistAlertSubscriberBuffer),
+	}
+	h.subscribers[id] = subscriber
+
+	var once sync.Once
+	return subscriber.events, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subscribers, id)
+			close(subscriber.events)
+		})
+	}
+}
+
+// publish 將事件送給訂閱該停車場的操作台，不會等待處理不及的訂閱者
+func (h *watchlistAlertHub) publish(event WatchlistAlertEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, subscriber := range h.subscribers {
+		if subscriber.parkingLotID != nil && *subscriber.parkingLotID != event.Alert.ParkingLotID {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			log.Printf("[WatchlistAlertHub] Subscriber %d is not keeping up; dropped %s event for watchlist alert ID %d", id, event.Name, event.Alert.WatchlistAlertID)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hello-professor_backend/configs"
+	"hello-professor_backend/dtos"
+	"hello-professor_backend/licenseplate"
+	"hello-professor_backend/models"
+	"hello-professor_backend/repositories"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WatchlistHit 車牌符合的監控名單項目，LicensePlate 為符合的車牌
+type WatchlistHit struct {
+	Entry        models.WatchlistEntry
+	LicensePlate string
+	MatchType    string
+	Similarity   float64
+}
+
+// DeniesEntry 是否應拒絕進場；模糊符合可能是誤判，只發出警示
+func (h WatchlistHit) DeniesEntry() bool {
+	return h.Entry.Action == models.WatchlistActionDenyEntry && h.MatchType != models.WatchlistMatchFuzzy
+}
+
+// WatchlistService 定義監控名單服務的介面
+// 車輛進出場時比對監控名單，符合時產生警示並推送給訂閱的操作台
+type WatchlistService interface {
+	CreateWatchlistEntry(payload dtos.WatchlistEntryPayload) (*models.WatchlistEntry, error)
+	GetWatchlistEntryByID(id uint) (*models.WatchlistEntry, error)
+	GetWatchlistEntries(category string, action string, activeOnly bool, limit int, offset int) ([]models.WatchlistEntry, error)
+	UpdateWatchlistEntry(id uint, payload dtos.WatchlistEntryPayload) (*models.WatchlistEntry, error)
+	DeleteWatchlistEntry(id uint) error
+
+	CheckLicensePlates(at time.Time, licensePlates ...string) ([]WatchlistHit, error)
+	RecordAlerts(tx *gorm.DB, hits []WatchlistHit, event string, parkingLotID uint, parkingRecordID *uint) ([]models.WatchlistAlert, error)
+	PublishAlerts(alerts []models.WatchlistAlert)
+
+	GetWatchlistAlertByID(id uint) (*models.WatchlistAlert, error)
+	GetWatchlistAlerts(parkingLotID *uint, category string, acknowledged *bool, limit int, offset int) ([]models.WatchlistAlert, error)
+	AcknowledgeWatchlistAlert(id uint, payload dtos.AcknowledgeWatchlistAlertPayload) (*models.WatchlistAlert, error)
+	SubscribeAlerts(parkingLotID *uint) (<-chan WatchlistAlertEvent, func())
+}
+
+// watchlistService 是 WatchlistService 的實作
+type watchlistService struct {
+	watchlistRepo repositories.WatchlistRepository
+	hub           *watchlistAlertHub
+	db            *gorm.DB
+}
+
+// NewWatchlistService 建立一個新的 WatchlistService 實例
+func NewWatchlistService(watchlistRepo repositories.WatchlistRepository, db *gorm.DB) WatchlistService {
+	return &watchlistService{
+		watchlistRepo: watchlistRepo,
+		hub:           newWatchlistAlertHub(),
+		db:            db,
+	}
+}
+
+// CreateWatchlistEntry 新增監控名單項目，車牌會轉為標準格式
+func (s *watchlistService) CreateWatchlistEntry(payload dtos.WatchlistEntryPayload) (*models.WatchlistEntry, error) {
+	entry := &models.WatchlistEntry{Active: true}
+	if err := applyWatchlistEntryPayload(entry, payload); err != nil {
+		return nil, err
+	}
+	if err := s.watchlistRepo.CreateWatchlistEntry(entry); err != nil {
+		return nil, fmt.Errorf("error creating watchlist entry: %w", err)
+	}
+	return entry, nil
+}
+
+// GetWatchlistEntryByID 呼叫 repository 透過 ID 取得監控名單項目
+func (s *watchlistService) GetWatchlistEntryByID(id uint) (*models.WatchlistEntry, error) {
+	return s.watchlistRepo.GetWatchlistEntryByID(id)
+}
+
+// GetWatchlistEntries 依類別與處理方式篩選監控名單項目 (分頁)，activeOnly 為 true 時排除停用與已失效的項目
+func (s *watchlistService) GetWatchlistEntries(category string, action string, activeOnly bool, limit int, offset int) ([]models.WatchlistEntry, error) {
+	return s.watchlistRepo.GetWatchlistEntries(repositories.WatchlistEntryFilter{
+		Category:   category,
+		Action:     action,
+		ActiveOnly: activeOnly,
+	}, limit, offset)
+}
+
+// UpdateWatchlistEntry 以新的內容取代監控名單項目
+func (s *watchlistService) UpdateWatchlistEntry(id uint, payload dtos.WatchlistEntryPayload) (*models.WatchlistEntry, error) {
+	entry, err := s.watchlistRepo.GetWatchlistEntryByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding watchlist entry ID %d: %w", id, err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("watchlist entry ID %d not found", id)
+	}
+	entry.Active = true
+	if err := applyWatchlistEntryPayload(entry, payload); err != nil {
+		return nil, err
+	}
+	if err := s.watchlistRepo.UpdateWatchlistEntry(entry); err != nil {
+		return nil, fmt.Errorf("error updating watchlist entry ID %d: %w", id, err)
+	}
+	return entry, nil
+}
+
+// DeleteWatchlistEntry 刪除監控名單項目，已產生的警示會保留
+func (s *watchlistService) DeleteWatchlistEntry(id uint) error {
+	entry, err := s.watchlistRepo.GetWatchlistEntryByID(id)
+	if err != nil {
+		return fmt.Errorf("error finding watchlist entry ID %d: %w", id, err)
+	}
+	if entry == nil {
+		return fmt.Errorf("watchlist entry ID %d not found", id)
+	}
+	return s.watchlistRepo.DeleteWatchlistEntry(id)
+}
+
+// CheckLicensePlates 比對標準格式的車牌與在指定時間有效的監控名單
+// 車牌相同為 Exact，只差在 O/0、I/1、B/8 等易混淆字元為 Variant，相似度達 configs.WatchlistFuzzyMinSimilarity 為 Fuzzy
+// 多個車牌符合同一項目時只保留最相符者；結果依相似度由高到低排列
+func (s *watchlistService) CheckLicensePlates(at time.Time, licensePlates ...string) ([]WatchlistHit, error) {
+	best := map[uint]WatchlistHit{}
+	for _, licensePlate := range licensePlates {
+		if licensePlate == "" {
+			continue
+		}
+		matches, err := s.watchlistRepo.FindWatchlistMatches(licensePlate, at, configs.WatchlistFuzzyMinSimilarity)
+		if err != nil {
+			return nil, fmt.Errorf("error checking watchlist for license plate %s: %w", licensePlate, err)
+		}
+		for _, match := range matches {
+			hit := WatchlistHit{
+				Entry:        match.Entry,
+				LicensePlate: licensePlate,
+				MatchType:    models.WatchlistMatchFuzzy,
+				Similarity:   match.Similarity,
+			}
+			if match.Entry.LicensePlate == licensePlate {
+				hit.MatchType = models.WatchlistMatchExact
+				hit.Similarity = 1
+			} else if licenseplate.SearchKey(match.Entry.LicensePlate) == licenseplate.SearchKey(licensePlate) {
+				hit.MatchType = models.WatchlistMatchVariant
+				hit.Similarity = 1
+			}
+			if previous, ok := best[match.Entry.WatchlistEntryID]; !ok || watchlistMatchRank(hit) > watchlistMatchRank(previous) {
+				best[match.Entry.WatchlistEntryID] = hit
+			}
+		}
+	}
+
+	hits := make([]WatchlistHit, 0, len(best))
+	for _, hit := range best {
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if watchlistMatchRank(hits[i]) != watchlistMatchRank(hits[j]) {
+			return watchlistMatchRank(hits[i]) > watchlistMatchRank(hits[j])
+		}
+		return hits[i].Entry.WatchlistEntryID < hits[j].Entry.WatchlistEntryID
+	})
+	return hits, nil
+}
+
+// RecordAlerts 為符合的監控名單項目產生警示，保留當時的名單內容
+// parkingRecordID 不為 nil 時，同一停車記錄在同一時機已產生過的警示不會重複產生 (例如出場付款後再次通過出口)
+// 警示需在資料庫交易提交後再以 PublishAlerts 推送
+func (s *watchlistService) RecordAlerts(tx *gorm.DB, hits []WatchlistHit, event string, parkingLotID uint, parkingRecordID *uint) ([]models.WatchlistAlert, error) {
+	var alerts []models.WatchlistAlert
+	for _, hit := range hits {
+		if parkingRecordID != nil {
+			exists, err := s.watchlistRepo.WatchlistAlertExists(hit.Entry.WatchlistEntryID, *parkingRecordID, event)
+			if err != nil {
+				return nil, fmt.Errorf("error checking watchlist alerts of parking record ID %d: %w", *parkingRecordID, err)
+			}
+			if exists {
+				continue
+			}
+		}
+		alert := models.WatchlistAlert{
+			WatchlistEntryID:      hit.Entry.WatchlistEntryID,
+			ParkingLotID:          parkingLotID,
+			ParkingRecordID:       parkingRecordID,
+			Event:                 event,
+			LicensePlate:          hit.LicensePlate,
+			WatchlistLicensePlate: hit.Entry.LicensePlate,
+			MatchType:             hit.MatchType,
+			Similarity:            hit.Similarity,
+			Category:              hit.Entry.Category,
+			Action:                hit.Entry.Action,
+			Reason:                hit.Entry.Reason,
+		}
+		if err := s.watchlistRepo.CreateWatchlistAlert(tx, &alert); err != nil {
+			return nil, fmt.Errorf("error creating watchlist alert for watchlist entry ID %d: %w", hit.Entry.WatchlistEntryID, err)
+		}
+		log.Printf("[Watchlist] %s alert ID %d: %s matched watchlist entry ID %d (%s, %s) in parking lot ID %d (%s, similarity %.2f)", event, alert.WatchlistAlertID, hit.LicensePlate, hit.Entry.WatchlistEntryID, hit.Entry.LicensePlate, hit.Entry.Category, parkingLotID, hit.MatchType, hit.Similarity)
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// PublishAlerts 將新警示推送給訂閱的操作台
+func (s *watchlistService) PublishAlerts(alerts []models.WatchlistAlert) {
+	for _, alert := range alerts {
+		s.hub.publish(WatchlistAlertEvent{Name: WatchlistAlertEventCreated, Alert: alert})
+	}
+}
+
+// GetWatchlistAlertByID 呼叫 repository 透過 ID 取得監控名單警示
+func (s *watchlistService) GetWatchlistAlertByID(id uint) (*models.WatchlistAlert, error) {
+	return s.watchlistRepo.GetWatchlistAlertByID(id)
+}
+
+// GetWatchlistAlerts 依停車場、類別與是否已確認篩選監控名單警示 (分頁)，篩選條件為零值時不篩選
+func (s *watchlistService) GetWatchlistAlerts(parkingLotID *uint, category string, acknowledged *bool, limit int, offset int) ([]models.WatchlistAlert, error) {
+	return s.watchlistRepo.GetWatchlistAlerts(repositories.WatchlistAlertFilter{
+		ParkingLotID: parkingLotID,
+		Category:     category,
+		Acknowledged: acknowledged,
+	}, limit, offset)
+}
+
+// AcknowledgeWatchlistAlert 記錄操作人員已確認並處理警示，並通知訂閱的操作台
+func (s *watchlistService) AcknowledgeWatchlistAlert(id uint, payload dtos.AcknowledgeWatchlistAlertPayload) (alert *models.WatchlistAlert, err error) {
+	acknowledgedBy := strings.TrimSpace(payload.AcknowledgedBy)
+	if acknowledgedBy == "" {
+		return nil, errors.New("invalid_acknowledgement: Operator is required.")
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else if commitErr := tx.Commit().Error; commitErr != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", commitErr)
+		} else {
+			s.hub.publish(WatchlistAlertEvent{Name: WatchlistAlertEventAcknowledged, Alert: *alert})
+		}
+	}()
+
+	alert, err = s.watchlistRepo.GetWatchlistAlertByIDForUpdate(tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding watchlist alert ID %d: %w", id, err)
+	}
+	if alert == nil {
+		return nil, fmt.Errorf("watchlist alert ID %d not found", id)
+	}
+	if alert.AcknowledgedAt != nil {
+		return nil, fmt.Errorf("alert_acknowledged: Watchlist alert ID %d was already acknowledged by %s.", id, *alert.AcknowledgedBy)
+	}
+
+	now := time.Now()
+	alert.AcknowledgedBy = &acknowledgedBy
+	alert.AcknowledgedAt = &now
+	if note := strings.TrimSpace(payload.Note); note != "" {
+		alert.AcknowledgeNote = &note
+	}
+	if err = s.watchlistRepo.UpdateWatchlistAlert(tx, alert); err != nil {
+		return nil, fmt.Errorf("error updating watchlist alert ID %d: %w", id, err)
+	}
+	return alert, nil
+}
+
+// SubscribeAlerts 訂閱監控名單警示串流，parkingLotID 為 nil 時接收所有停車場的警示
+// 回傳接收事件的 channel 與取消訂閱的函式，連線結束時必須呼叫取消訂閱
+func (s *watchlistService) SubscribeAlerts(parkingLotID *uint) (<-chan WatchlistAlertEvent, func()) {
+	return s.hub.subscribe(parkingLotID)
+}
+
+// watchlistMatchRank 比較兩個符合結果時的優先順序：Exact 優先於 Variant，再依相似度
+func watchlistMatchRank(hit WatchlistHit) float64 {
+	switch hit.MatchType {
+	case models.WatchlistMatchExact:
+		return 3
+	case models.WatchlistMatchVariant:
+		return 2
+	}
+	return hit.Similarity
+}
+
+// watchlistReasons 將符合項目的類別與原因組合為一行說明
+func watchlistReasons(hits []WatchlistHit) string {
+	reasons := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		reason := fmt.Sprintf("%s watchlist entry #%d (%s)", hit.Entry.Category, hit.Entry.WatchlistEntryID, hit.MatchType)
+		if hit.Entry.Reason != "" {
+			reason += ": " + hit.Entry.Reason
+		}
+		reasons = append(reasons, reason)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// applyWatchlistEntryPayload 驗證並將 payload 的內容寫入 entry
+func applyWatchlistEntryPayload(entry *models.WatchlistEntry, payload dtos.WatchlistEntryPayload) error {
+	licensePlate := licenseplate.Normalize(payload.LicensePlate)
+	if licensePlate == "" {
+		return errors.New("invalid_license_plate: License plate is required.")
+	}
+	if !models.IsValidWatchlistCategory(payload.Category) {
+		return fmt.Errorf("invalid_watchlist_entry: Unknown category %q; must be one of Stolen, Banned, VIP, Other.", payload.Category)
+	}
+	if !models.IsValidWatchlistAction(payload.Action) {
+		return fmt.Errorf("invalid_watchlist_entry: Unknown action %q; must be one of Alert, DenyEntry, Flag.", payload.Action)
+	}
+
+	entry.LicensePlate = licensePlate
+	entry.Category = payload.Category
+	entry.Action = payload.Action
+	entry.Reason = strings.TrimSpace(payload.Reason)
+	entry.CreatedBy = strings.TrimSpace(payload.CreatedBy)
+	if payload.Active != nil {
+		entry.Active = *payload.Active
+	}
+	entry.ExpiresAt = payload.ExpiresAt
+	return nil
+}
@@ -0,0 +1,104 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	// 註冊 GIF 與 PNG 解碼器供 image.Decode 使用
+	_ "image/gif"
+	_ "image/png"
+)
+
+// ErrUnsupportedFormat 影像格式無法以標準函式庫解碼 (例如 WebP)
+var ErrUnsupportedFormat = errors.New("thumbnail: unsupported image format")
+
+// ErrTooLarge 影像宣告的像素數超過上限，解碼會佔用過多記憶體
+var ErrTooLarge = errors.New("thumbnail: image dimensions too large")
+
+// ContentType 縮圖一律輸出為 JPEG
+const ContentType = "image/jpeg"
+
+// Generate 將 JPEG、PNG 或 GIF (第一格) 影像縮小為長邊不超過 maxSize 的 JPEG 縮圖
+// 以區域平均縮小避免鋸齒，不會放大較小的影像；透明部分以白色填滿
+// 解碼前先讀取影像標頭的尺寸，寬乘高超過 maxPixels 時回傳 ErrTooLarge，避免小檔案宣告巨大尺寸耗盡記憶體
+func Generate(data []byte, maxSize int, quality int, maxPixels int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("thumbnail: reading image header: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > int64(maxPixels) {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrTooLarge, config.Width, config.Height, maxPixels)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("thumbnail: decoding image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), maxSize)
+	if width == 0 || height == 0 {
+		return nil, errors.New("thumbnail: image is empty")
+	}
+
+	// 先轉為不透明的 RGBA，縮小時可直接讀取像素
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Over)
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, scale(rgba, width, height), &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("thumbnail: encoding JPEG: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// fit 計算長邊不超過 maxSize 並維持比例的尺寸
+func fit(width, height, maxSize int) (int, int) {
+	if width <= maxSize && height <= maxSize {
+		return width, height
+	}
+	if width >= height {
+		return maxSize, max(1, height*maxSize/width)
+	}
+	return max(1, width*maxSize/height), maxSize
+}
+
+// scale 以區域平均將 src 縮小為 width x height，每個輸出像素為其涵蓋的來源像素的平均
+func scale(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+			var r, g, b, count uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(row[sx*4])
+					g += uint64(row[sx*4+1])
+					b += uint64(row[sx*4+2])
+					count++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / count)
+			dst.Pix[i+1] = uint8(g / count)
+			dst.Pix[i+2] = uint8(b / count)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
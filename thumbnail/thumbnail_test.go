@@ -0,0 +1,118 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// encodePNG 產生 width x height、填滿 fill 的 PNG
+func encodePNG(t *testing.T, width, height int, fill color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode returned error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateSize(t *testing.T) {
+	tests := []struct {
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{width: 1000, height: 500, wantWidth: 320, wantHeight: 160},
+		{width: 500, height: 1000, wantWidth: 160, wantHeight: 320},
+		{width: 640, height: 640, wantWidth: 320, wantHeight: 320},
+		{width: 1920, height: 1080, wantWidth: 320, wantHeight: 180},
+		{width: 100, height: 50, wantWidth: 100, wantHeight: 50}, // 不放大較小的影像
+		{width: 320, height: 200, wantWidth: 320, wantHeight: 200},
+		{width: 2000, height: 3, wantWidth: 320, wantHeight: 1}, // 短邊至少 1 像素
+	}
+	for _, tt := range tests {
+		data, err := Generate(encodePNG(t, tt.width, tt.height, color.NRGBA{R: 200, G: 30, B: 30, A: 255}), 320, 80, 50_000_000)
+		if err != nil {
+			t.Errorf("Generate(%dx%d) returned error: %v", tt.width, tt.height, err)
+			continue
+		}
+		config, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || format != "jpeg" {
+			t.Errorf("Generate(%dx%d) did not produce a JPEG: format %q, error %v", tt.width, tt.height, format, err)
+			continue
+		}
+		if config.Width != tt.wantWidth || config.Height != tt.wantHeight {
+			t.Errorf("Generate(%dx%d) = %dx%d, want %dx%d", tt.width, tt.height, config.Width, config.Height, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}
+
+func TestGenerateFillsTransparencyWithWhite(t *testing.T) {
+	data, err := Generate(encodePNG(t, 40, 40, color.NRGBA{}), 320, 90, 50_000_000)
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("jpeg.Decode returned error: %v", err)
+	}
+	r, g, b, _ := img.At(20, 20).RGBA()
+	if r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("transparent pixel became (%d, %d, %d), want white", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestGenerateRejectsTooManyPixels(t *testing.T) {
+	if _, err := Generate(encodePNG(t, 100, 100, color.Black), 320, 80, 9_999); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Generate of 10000 pixels with a 9999 pixel limit returned %v, want ErrTooLarge", err)
+	}
+	if _, err := Generate(encodePNG(t, 100, 100, color.Black), 320, 80, 10_000); err != nil {
+		t.Errorf("Generate of 10000 pixels with a 10000 pixel limit returned error: %v", err)
+	}
+
+	// 小檔案宣告巨大尺寸時，讀取標頭後即拒絕，不會解碼
+	forged := encodePNG(t, 1, 1, color.Black)
+	ihdr := forged[8+8 : 8+8+13] // 簽章 (8) 與 IHDR 的長度、類型 (8) 之後
+	binary.BigEndian.PutUint32(ihdr[0:4], 60000)
+	binary.BigEndian.PutUint32(ihdr[4:8], 60000)
+	binary.BigEndian.PutUint32(forged[8+8+13:], crc32.ChecksumIEEE(forged[8+4:8+8+13]))
+	if _, err := Generate(forged, 320, 80, 50_000_000); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Generate of a PNG declaring 60000x60000 returned %v, want ErrTooLarge", err)
+	}
+}
+
+func TestGenerateRejectsUnsupportedFormat(t *testing.T) {
+	webp := []byte("RIFF\x1a\x00\x00\x00WEBPVP8L\x0d\x00\x00\x00\x2f\x00\x00\x00\x10\x07\x10\x11\x11\x88\x88\xfe\x07\x00")
+	if _, err := Generate(webp, 320, 80, 50_000_000); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Generate of a WebP image returned %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		width, height, maxSize int
+		wantWidth, wantHeight  int
+	}{
+		{width: 800, height: 600, maxSize: 320, wantWidth: 320, wantHeight: 240},
+		{width: 600, height: 800, maxSize: 320, wantWidth: 240, wantHeight: 320},
+		{width: 321, height: 321, maxSize: 320, wantWidth: 320, wantHeight: 320},
+		{width: 320, height: 100, maxSize: 320, wantWidth: 320, wantHeight: 100},
+		{width: 1, height: 5000, maxSize: 320, wantWidth: 1, wantHeight: 320},
+	}
+	for _, tt := range tests {
+		if w, h := fit(tt.width, tt.height, tt.maxSize); w != tt.wantWidth || h != tt.wantHeight {
+			t.Errorf("fit(%d, %d, %d) = %dx%d, want %dx%d", tt.width, tt.height, tt.maxSize, w, h, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}